@@ -1,21 +1,29 @@
 package handlers
 
 import (
+	"blog-backend/internal/domain"
 	"blog-backend/internal/services"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 // AuthHandler maneja las peticiones HTTP relacionadas con autenticación
 type AuthHandler struct {
-	authService *services.AuthService
+	authService              *services.AuthService
+	passwordResetService     *services.PasswordResetService
+	emailVerificationService *services.EmailVerificationService
 }
 
 // NewAuthHandler crea una nueva instancia del handler de autenticación
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, passwordResetService *services.PasswordResetService, emailVerificationService *services.EmailVerificationService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:              authService,
+		passwordResetService:     passwordResetService,
+		emailVerificationService: emailVerificationService,
 	}
 }
 
@@ -25,13 +33,48 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginTwoFactorRequest define la estructura del segundo paso del login
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// RefreshTokenRequest define la estructura de las peticiones de refresco y logout
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
 // ChangePasswordRequest define la estructura de la petición de cambio de contraseña
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
 	NewPassword string `json:"new_password" binding:"required,min=6"`
 }
 
-// Login autentica un usuario y retorna un token JWT
+// ChangeEmailRequest define la estructura de la petición de cambio de correo
+type ChangeEmailRequest struct {
+	Password string `json:"password" binding:"required"`
+	Email    string `json:"email"`
+}
+
+// ForgotPasswordRequest define la estructura de la petición de restablecimiento de contraseña
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// ResetPasswordRequest define la estructura de la petición que fija la nueva contraseña
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// VerifyEmailRequest define la estructura de la petición de verificación de correo
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Login autentica un usuario y retorna un token de acceso y uno de refresco.
+// Si el usuario tiene activa la autenticación en dos pasos retorna en su lugar
+// un desafío que se completa en LoginTwoFactor.
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -39,19 +82,124 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, user, err := h.authService.Login(req.Username, req.Password)
+	client := domain.LoginClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	result, err := h.authService.Login(c.Request.Context(), req.Username, req.Password, client)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciales inválidas"})
+		respondLoginError(c, err)
+		return
+	}
+
+	respondLoginResult(c, result)
+}
+
+// LoginTwoFactor completa el login de un usuario con la autenticación en dos
+// pasos activa con el desafío de Login y un código TOTP o de recuperación
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	client := domain.LoginClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	result, err := h.authService.CompleteTwoFactorLogin(c.Request.Context(), req.ChallengeToken, req.Code, client)
+	if err != nil {
+		respondLoginError(c, err)
+		return
+	}
+
+	respondLoginResult(c, result)
+}
+
+// respondLoginResult responde con los tokens de un login completado o, si falta
+// el segundo paso, con su desafío
+func respondLoginResult(c *gin.Context, result *domain.LoginResult) {
+	if result.ChallengeToken != "" {
+		c.JSON(http.StatusOK, gin.H{
+			"message":             "Introduce el código de verificación para completar el login",
+			"two_factor_required": true,
+			"challenge_token":     result.ChallengeToken,
+			"expires_in":          result.ChallengeExpiresIn,
+		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login exitoso",
-		"token":   token,
-		"user":    user,
+		"message":       "Login exitoso",
+		"token":         result.Tokens.AccessToken,
+		"refresh_token": result.Tokens.RefreshToken,
+		"expires_in":    result.Tokens.ExpiresIn,
+		"user":          result.User,
 	})
 }
 
+// respondLoginError traduce los errores de los dos pasos del login a respuestas HTTP
+func respondLoginError(c *gin.Context, err error) {
+	if retryAfter := domain.RetryAfter(err); retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+
+	switch {
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Credenciales inválidas"})
+	case errors.Is(err, domain.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "El desafío de login no es válido o ha expirado"})
+	case errors.Is(err, domain.ErrInvalidTOTPCode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Código de verificación inválido"})
+	case errors.Is(err, domain.ErrAccountLocked):
+		c.JSON(http.StatusLocked, gin.H{"error": "Cuenta bloqueada temporalmente por demasiados intentos fallidos"})
+	case errors.Is(err, domain.ErrLoginThrottled):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Demasiados intentos fallidos, espera antes de volver a intentarlo"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+	}
+}
+
+// Refresh rota el token de refresco y emite un nuevo token de acceso
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	tokens, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidToken:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token de refresco inválido"})
+		case domain.ErrTokenReused:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token de refresco reutilizado, la sesión fue revocada"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revoca la sesión asociada al token de refresco
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		switch err {
+		case domain.ErrInvalidToken:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token de refresco inválido"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sesión cerrada exitosamente"})
+}
+
 // ChangePassword cambia la contraseña de un usuario autenticado
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	// Obtener usuario del contexto (seteado por el middleware de autenticación)
@@ -74,7 +222,7 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.ChangePassword(uid, req.OldPassword, req.NewPassword); err != nil {
+	if err := h.authService.ChangePassword(c.Request.Context(), uid, req.OldPassword, req.NewPassword); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Error cambiando contraseña"})
 		return
 	}
@@ -82,6 +230,142 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Contraseña cambiada exitosamente"})
 }
 
+// ChangeEmail cambia el correo del usuario autenticado
+func (h *AuthHandler) ChangeEmail(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	user, err := h.emailVerificationService.ChangeEmail(c.Request.Context(), userID, req.Password, req.Email)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidEmail:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Correo electrónico inválido"})
+		case domain.ErrInvalidCredentials:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Contraseña incorrecta"})
+		case domain.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+		case domain.ErrEmailAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "El correo electrónico ya está registrado"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Correo electrónico actualizado exitosamente",
+		"user":    user,
+	})
+}
+
+// VerifyEmail verifica el correo de un usuario con el token del enlace enviado
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	user, err := h.emailVerificationService.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidToken:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El enlace de verificación no es válido o ha expirado"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Correo electrónico verificado exitosamente",
+		"user":    user,
+	})
+}
+
+// ResendVerification reenvía el enlace de verificación al correo del usuario autenticado
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	if err := h.emailVerificationService.ResendVerification(c.Request.Context(), userID); err != nil {
+		if retryAfter := domain.RetryAfter(err); retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+
+		switch {
+		case errors.Is(err, domain.ErrEmailRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El usuario no tiene correo electrónico"})
+		case errors.Is(err, domain.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+		case errors.Is(err, domain.ErrEmailAlreadyVerified):
+			c.JSON(http.StatusConflict, gin.H{"error": "El correo electrónico ya está verificado"})
+		case errors.Is(err, domain.ErrResendThrottled):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "El enlace de verificación se envió hace poco, espera antes de pedir otro"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Enlace de verificación enviado"})
+}
+
+// ForgotPassword envía un enlace de restablecimiento de contraseña. La respuesta
+// es la misma tanto si el correo está registrado como si no.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	if err := h.passwordResetService.RequestReset(c.Request.Context(), req.Email); err != nil {
+		switch err {
+		case domain.ErrInvalidEmail:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Correo electrónico inválido"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Si el correo está registrado recibirás un enlace para restablecer la contraseña"})
+}
+
+// ResetPassword fija una nueva contraseña con un token de restablecimiento
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		switch err {
+		case domain.ErrInvalidToken:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El enlace de restablecimiento no es válido o ha expirado"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Contraseña restablecida exitosamente"})
+}
+
 // GetProfile obtiene el perfil del usuario autenticado
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	// Obtener usuario del contexto (seteado por el middleware de autenticación)
@@ -93,3 +377,45 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user)
 }
+
+// LoginHistory lista una página del historial de inicios de sesión del usuario autenticado
+func (h *AuthHandler) LoginHistory(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	h.listLoginAttempts(c, userID)
+}
+
+// ListUserLogins lista una página del historial de inicios de sesión de cualquier usuario
+func (h *AuthHandler) ListUserLogins(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	h.listLoginAttempts(c, id)
+}
+
+// listLoginAttempts responde con una página del historial de inicios de sesión de userID
+func (h *AuthHandler) listLoginAttempts(c *gin.Context, userID int64) {
+	page, ok := parsePageRequest(c, domain.LoginAttemptSortOrders)
+	if !ok {
+		return
+	}
+
+	attempts, err := h.authService.LoginHistory(c.Request.Context(), userID, page)
+	if err != nil {
+		switch err {
+		case domain.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, attempts)
+}
@@ -5,6 +5,7 @@ import (
 	"blog-backend/internal/services"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,14 +24,30 @@ func NewBlogHandler(blogService *services.BlogService) *BlogHandler {
 
 // CreateBlogRequest define la estructura de la petición de creación de blog
 type CreateBlogRequest struct {
-	Title   string `json:"title" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	Title       string            `json:"title" binding:"required"`
+	Content     string            `json:"content" binding:"required"`
+	Status      domain.BlogStatus `json:"status"`
+	PublishedAt *time.Time        `json:"published_at"`
+	Tags        []string          `json:"tags"`
 }
 
-// UpdateBlogRequest define la estructura de la petición de actualización de blog
+// UpdateBlogRequest define la estructura de la petición de actualización de blog.
+// Si tags se omite se conservan las etiquetas actuales.
 type UpdateBlogRequest struct {
-	Title   string `json:"title" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	Title   string   `json:"title" binding:"required"`
+	Content string   `json:"content" binding:"required"`
+	Tags    []string `json:"tags"`
+}
+
+// ChangeBlogStatusRequest define la estructura de la petición de cambio de estado
+type ChangeBlogStatusRequest struct {
+	Status      domain.BlogStatus `json:"status" binding:"required"`
+	PublishedAt *time.Time        `json:"published_at"`
+}
+
+// CommentModerationRequest define la estructura de la petición para activar la moderación de comentarios
+type CommentModerationRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
 }
 
 // CreateBlog crea un nuevo blog
@@ -54,9 +71,24 @@ func (h *BlogHandler) CreateBlog(c *gin.Context) {
 		return
 	}
 
-	blog, err := h.blogService.CreateBlog(req.Title, req.Content, uid)
+	blog, err := h.blogService.CreateBlog(c.Request.Context(), req.Title, req.Content, uid, req.Status, req.PublishedAt, req.Tags)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando blog"})
+		switch err {
+		case domain.ErrInvalidTag:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Etiqueta inválida"})
+		case domain.ErrTooManyTags:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Un blog admite como máximo 10 etiquetas"})
+		case domain.ErrInvalidStatus:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Estado de blog inválido"})
+		case domain.ErrInvalidSchedule:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "La fecha de publicación programada debe estar en el futuro"})
+		case domain.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para crear o publicar blogs"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error creando blog"})
+		}
 		return
 	}
 
@@ -75,7 +107,8 @@ func (h *BlogHandler) GetBlog(c *gin.Context) {
 		return
 	}
 
-	blog, err := h.blogService.GetBlogByID(id)
+	uid, role := optionalUser(c)
+	blog, err := h.blogService.GetBlogByID(c.Request.Context(), id, uid, role)
 	if err != nil {
 		switch err {
 		case domain.ErrBlogNotFound:
@@ -89,7 +122,7 @@ func (h *BlogHandler) GetBlog(c *gin.Context) {
 	c.JSON(http.StatusOK, blog)
 }
 
-// GetBlogsByAuthor obtiene todos los blogs de un autor
+// GetBlogsByAuthor obtiene una página de blogs de un autor
 func (h *BlogHandler) GetBlogsByAuthor(c *gin.Context) {
 	authorIDStr := c.Param("authorId")
 	authorID, err := strconv.ParseInt(authorIDStr, 10, 64)
@@ -98,7 +131,13 @@ func (h *BlogHandler) GetBlogsByAuthor(c *gin.Context) {
 		return
 	}
 
-	blogs, err := h.blogService.GetBlogsByAuthor(authorID)
+	page, ok := parsePageRequest(c, domain.BlogSortOrders)
+	if !ok {
+		return
+	}
+
+	uid, role := optionalUser(c)
+	blogs, err := h.blogService.GetBlogsByAuthor(c.Request.Context(), authorID, page, uid, role)
 	if err != nil {
 		switch err {
 		case domain.ErrUserNotFound:
@@ -112,11 +151,21 @@ func (h *BlogHandler) GetBlogsByAuthor(c *gin.Context) {
 	c.JSON(http.StatusOK, blogs)
 }
 
-// ListBlogs lista todos los blogs
+// ListBlogs lista una página de blogs (?tag=<slug> filtra por etiqueta)
 func (h *BlogHandler) ListBlogs(c *gin.Context) {
-	blogs, err := h.blogService.ListBlogs()
+	page, ok := parsePageRequest(c, domain.BlogSortOrders)
+	if !ok {
+		return
+	}
+
+	blogs, err := h.blogService.ListBlogs(c.Request.Context(), page, c.Query("tag"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		switch err {
+		case domain.ErrInvalidTag:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Etiqueta inválida"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
 		return
 	}
 
@@ -163,13 +212,17 @@ func (h *BlogHandler) UpdateBlog(c *gin.Context) {
 		return
 	}
 
-	blog, err := h.blogService.UpdateBlog(id, req.Title, req.Content, uid, role)
+	blog, err := h.blogService.UpdateBlog(c.Request.Context(), id, req.Title, req.Content, req.Tags, uid, role)
 	if err != nil {
 		switch err {
 		case domain.ErrBlogNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Blog no encontrado"})
 		case domain.ErrForbidden:
 			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para editar este blog"})
+		case domain.ErrInvalidTag:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Etiqueta inválida"})
+		case domain.ErrTooManyTags:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Un blog admite como máximo 10 etiquetas"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
 		}
@@ -182,6 +235,108 @@ func (h *BlogHandler) UpdateBlog(c *gin.Context) {
 	})
 }
 
+// ChangeBlogStatus cambia el estado de publicación de un blog
+func (h *BlogHandler) ChangeBlogStatus(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req ChangeBlogStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	// Obtener información del usuario autenticado
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	role, ok := userRole.(domain.Role)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	blog, err := h.blogService.ChangeStatus(c.Request.Context(), id, req.Status, req.PublishedAt, uid, role)
+	if err != nil {
+		switch err {
+		case domain.ErrBlogNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog no encontrado"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para cambiar el estado de este blog"})
+		case domain.ErrInvalidStatus:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Estado de blog inválido"})
+		case domain.ErrInvalidSchedule:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "La fecha de publicación programada debe estar en el futuro"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Estado del blog actualizado exitosamente",
+		"blog":    blog,
+	})
+}
+
+// SetCommentModeration activa o desactiva la moderación previa de los comentarios de un blog
+func (h *BlogHandler) SetCommentModeration(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req CommentModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	uid, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	blog, err := h.blogService.SetCommentModeration(c.Request.Context(), id, *req.Enabled, uid, role)
+	if err != nil {
+		switch err {
+		case domain.ErrBlogNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog no encontrado"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para modificar este blog"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Moderación de comentarios actualizada exitosamente",
+		"blog":    blog,
+	})
+}
+
 // DeleteBlog elimina un blog
 func (h *BlogHandler) DeleteBlog(c *gin.Context) {
 	idStr := c.Param("id")
@@ -216,12 +371,14 @@ func (h *BlogHandler) DeleteBlog(c *gin.Context) {
 		return
 	}
 
-	if err := h.blogService.DeleteBlog(id, uid, role); err != nil {
+	if err := h.blogService.DeleteBlog(c.Request.Context(), id, uid, role); err != nil {
 		switch err {
 		case domain.ErrBlogNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Blog no encontrado"})
 		case domain.ErrForbidden:
 			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para eliminar este blog"})
+		case domain.ErrBlogHasComments:
+			c.JSON(http.StatusConflict, gin.H{"error": "El blog tiene comentarios y no se puede eliminar"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
 		}
@@ -230,3 +387,179 @@ func (h *BlogHandler) DeleteBlog(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Blog eliminado exitosamente"})
 }
+
+// ListRevisions obtiene el historial de ediciones de un blog
+func (h *BlogHandler) ListRevisions(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	// Obtener información del usuario autenticado
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	role, ok := userRole.(domain.Role)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	revisions, err := h.blogService.ListRevisions(c.Request.Context(), id, uid, role)
+	if err != nil {
+		switch err {
+		case domain.ErrBlogNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog no encontrado"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver el historial de este blog"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
+// DiffRevisions compara dos revisiones de un blog (?from=<id>&to=<id>)
+func (h *BlogHandler) DiffRevisions(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	fromID, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Revisión de origen inválida"})
+		return
+	}
+
+	toID, err := strconv.ParseInt(c.Query("to"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Revisión de destino inválida"})
+		return
+	}
+
+	// Obtener información del usuario autenticado
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	role, ok := userRole.(domain.Role)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	diff, err := h.blogService.DiffRevisions(c.Request.Context(), id, fromID, toID, uid, role)
+	if err != nil {
+		switch err {
+		case domain.ErrBlogNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog no encontrado"})
+		case domain.ErrRevisionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revisión no encontrada"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para ver el historial de este blog"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RestoreRevision restaura el contenido de un blog a una revisión anterior
+func (h *BlogHandler) RestoreRevision(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	revisionIDStr := c.Param("revisionId")
+	revisionID, err := strconv.ParseInt(revisionIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de revisión inválido"})
+		return
+	}
+
+	// Obtener información del usuario autenticado
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return
+	}
+
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	role, ok := userRole.(domain.Role)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	blog, err := h.blogService.RestoreRevision(c.Request.Context(), id, revisionID, uid, role)
+	if err != nil {
+		switch err {
+		case domain.ErrBlogNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog no encontrado"})
+		case domain.ErrRevisionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revisión no encontrada"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para editar este blog"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Revisión restaurada exitosamente",
+		"blog":    blog,
+	})
+}
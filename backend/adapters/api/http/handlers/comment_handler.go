@@ -3,6 +3,8 @@ package handlers
 import (
 	"blog-backend/internal/domain"
 	"blog-backend/internal/services"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -23,7 +25,8 @@ func NewCommentHandler(commentService *services.CommentService) *CommentHandler
 
 // CreateCommentRequest define la estructura de la petición de creación de comentario
 type CreateCommentRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content  string `json:"content" binding:"required"`
+	ParentID *int64 `json:"parent_id"`
 }
 
 // UpdateCommentRequest define la estructura de la petición de actualización de comentario
@@ -31,9 +34,14 @@ type UpdateCommentRequest struct {
 	Content string `json:"content" binding:"required"`
 }
 
+// ModerateCommentRequest define la estructura (opcional) de una decisión de moderación
+type ModerateCommentRequest struct {
+	Reason string `json:"reason"`
+}
+
 // CreateComment crea un nuevo comentario
 func (h *CommentHandler) CreateComment(c *gin.Context) {
-	blogIDStr := c.Param("blogId")
+	blogIDStr := c.Param("id")
 	blogID, err := strconv.ParseInt(blogIDStr, 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de blog inválido"})
@@ -59,35 +67,54 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		return
 	}
 
-	comment, err := h.commentService.CreateComment(blogID, uid, req.Content)
+	comment, err := h.commentService.CreateComment(c.Request.Context(), blogID, uid, req.Content, req.ParentID)
 	if err != nil {
 		switch err {
 		case domain.ErrBlogNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Blog no encontrado"})
+		case domain.ErrInvalidParentComment:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El comentario padre no existe en este blog"})
+		case domain.ErrCommentDepthExceeded:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Se alcanzó la profundidad máxima de respuestas"})
 		case domain.ErrUserNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para comentar"})
+		case domain.ErrCommentRejected:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "El comentario fue rechazado por el filtro de contenido"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
 		}
 		return
 	}
 
+	message := "Comentario creado exitosamente"
+	if comment.Status == domain.CommentStatusPending {
+		message = "Comentario enviado; se publicará cuando sea aprobado"
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
-		"message":  "Comentario creado exitosamente",
-		"comment":  comment,
+		"message": message,
+		"comment": comment,
 	})
 }
 
-// GetCommentsByBlog obtiene todos los comentarios de un blog
+// GetCommentsByBlog obtiene una página de hilos de comentarios de un blog
 func (h *CommentHandler) GetCommentsByBlog(c *gin.Context) {
-	blogIDStr := c.Param("blogId")
+	blogIDStr := c.Param("id")
 	blogID, err := strconv.ParseInt(blogIDStr, 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de blog inválido"})
 		return
 	}
 
-	comments, err := h.commentService.GetCommentsByBlog(blogID)
+	page, ok := parsePageRequest(c, domain.CommentSortOrders)
+	if !ok {
+		return
+	}
+
+	uid, role := optionalUser(c)
+	comments, err := h.commentService.GetCommentsByBlog(c.Request.Context(), blogID, page, uid, role)
 	if err != nil {
 		switch err {
 		case domain.ErrBlogNotFound:
@@ -141,13 +168,17 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 		return
 	}
 
-	comment, err := h.commentService.UpdateComment(id, req.Content, uid, role)
+	comment, err := h.commentService.UpdateComment(c.Request.Context(), id, req.Content, uid, role)
 	if err != nil {
 		switch err {
 		case domain.ErrCommentNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Comentario no encontrado"})
 		case domain.ErrForbidden:
 			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para editar este comentario"})
+		case domain.ErrCommentRejected:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "El comentario fue rechazado por el filtro de contenido"})
+		case domain.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
 		}
@@ -155,8 +186,8 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":  "Comentario actualizado exitosamente",
-		"comment":  comment,
+		"message": "Comentario actualizado exitosamente",
+		"comment": comment,
 	})
 }
 
@@ -194,7 +225,7 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	if err := h.commentService.DeleteComment(id, uid, role); err != nil {
+	if err := h.commentService.DeleteComment(c.Request.Context(), id, uid, role); err != nil {
 		switch err {
 		case domain.ErrCommentNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Comentario no encontrado"})
@@ -208,3 +239,110 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Comentario eliminado exitosamente"})
 }
+
+// ListModerationQueue lista la cola de moderación (?status=pending|rejected|spam).
+// En /blogs/:id la cola se limita a ese blog; en la ruta de administración
+// puede filtrarse con ?blog_id=.
+func (h *CommentHandler) ListModerationQueue(c *gin.Context) {
+	blogIDStr := c.Param("id")
+	if blogIDStr == "" {
+		blogIDStr = c.Query("blog_id")
+	}
+
+	var blogID int64
+	if blogIDStr != "" {
+		id, err := strconv.ParseInt(blogIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID de blog inválido"})
+			return
+		}
+		blogID = id
+	}
+
+	page, ok := parsePageRequest(c, domain.CommentSortOrders)
+	if !ok {
+		return
+	}
+
+	uid, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	filter := domain.ModerationFilter{Status: domain.CommentStatus(c.Query("status")), BlogID: blogID}
+	comments, err := h.commentService.ListModerationQueue(c.Request.Context(), filter, page, uid, role)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidCommentStatus:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Estado de comentario inválido"})
+		case domain.ErrBlogNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog no encontrado"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para moderar estos comentarios"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// ApproveComment aprueba un comentario para que se muestre en su blog
+func (h *CommentHandler) ApproveComment(c *gin.Context) {
+	h.moderateComment(c, domain.CommentStatusApproved, "Comentario aprobado exitosamente")
+}
+
+// RejectComment rechaza un comentario
+func (h *CommentHandler) RejectComment(c *gin.Context) {
+	h.moderateComment(c, domain.CommentStatusRejected, "Comentario rechazado exitosamente")
+}
+
+// MarkCommentSpam marca un comentario como spam
+func (h *CommentHandler) MarkCommentSpam(c *gin.Context) {
+	h.moderateComment(c, domain.CommentStatusSpam, "Comentario marcado como spam")
+}
+
+// moderateComment aplica una decisión de moderación con un motivo opcional
+func (h *CommentHandler) moderateComment(c *gin.Context, status domain.CommentStatus, message string) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	// El cuerpo es opcional
+	var req ModerateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	uid, role, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	comment, err := h.commentService.ModerateComment(c.Request.Context(), id, status, req.Reason, uid, role)
+	if err != nil {
+		switch err {
+		case domain.ErrCommentNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Comentario no encontrado"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permisos para moderar este comentario"})
+		case domain.ErrInvalidModeration:
+			c.JSON(http.StatusConflict, gin.H{"error": "El comentario ya tiene ese estado"})
+		case domain.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "El motivo admite como máximo 500 caracteres"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"comment": comment,
+	})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"blog-backend/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// optionalUser obtiene el usuario seteado por el middleware de autenticación opcional.
+// Para visitantes anónimos retorna ID 0 y rol vacío.
+func optionalUser(c *gin.Context) (int64, domain.Role) {
+	var uid int64
+	var role domain.Role
+
+	if userID, exists := c.Get("user_id"); exists {
+		uid, _ = userID.(int64)
+	}
+	if userRole, exists := c.Get("user_role"); exists {
+		role, _ = userRole.(domain.Role)
+	}
+
+	return uid, role
+}
+
+// currentUser obtiene el usuario seteado por el middleware de autenticación.
+// Si falta responde con el error correspondiente y retorna false.
+func currentUser(c *gin.Context) (int64, domain.Role, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return 0, "", false
+	}
+
+	userRole, exists := c.Get("user_role")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		return 0, "", false
+	}
+
+	uid, ok := userID.(int64)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return 0, "", false
+	}
+
+	role, ok := userRole.(domain.Role)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return 0, "", false
+	}
+
+	return uid, role, true
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DatabasePinger comprueba la conexión con la base de datos; lo implementa *sql.DB
+type DatabasePinger interface {
+	PingContext(ctx context.Context) error
+	Stats() sql.DBStats
+}
+
+// HealthHandler responde a las sondas de vida y de disponibilidad del servidor
+type HealthHandler struct {
+	db          DatabasePinger
+	pingTimeout time.Duration
+	draining    atomic.Bool
+}
+
+// NewHealthHandler crea una nueva instancia del handler de salud. pingTimeout
+// limita cuánto espera la sonda de disponibilidad a la base de datos.
+func NewHealthHandler(db DatabasePinger, pingTimeout time.Duration) *HealthHandler {
+	return &HealthHandler{
+		db:          db,
+		pingTimeout: pingTimeout,
+	}
+}
+
+// StartDraining marca el servidor como en cierre: la sonda de disponibilidad
+// falla para que el balanceador deje de enviarle peticiones nuevas
+func (h *HealthHandler) StartDraining() {
+	h.draining.Store(true)
+}
+
+// Live indica que el proceso está en marcha. No consulta la base de datos para
+// que una caída de esta no provoque reinicios del servidor.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready indica si el servidor puede atender peticiones: no está cerrándose y
+// la base de datos responde. Incluye el estado del pool de conexiones.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	stats := h.db.Stats()
+	database := gin.H{
+		"status":               "up",
+		"open_connections":     stats.OpenConnections,
+		"in_use":               stats.InUse,
+		"idle":                 stats.Idle,
+		"max_open_connections": stats.MaxOpenConnections,
+		"wait_count":           stats.WaitCount,
+		"wait_duration_ms":     stats.WaitDuration.Milliseconds(),
+	}
+
+	if h.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down", "database": database})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.pingTimeout)
+	defer cancel()
+
+	if err := h.db.PingContext(ctx); err != nil {
+		database["status"] = "down"
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "database": database})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "database": database})
+}
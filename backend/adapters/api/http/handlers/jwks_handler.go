@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"blog-backend/internal/domain"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeySetProvider retorna las claves públicas que verifican los tokens de acceso
+type KeySetProvider interface {
+	JWKS() domain.JSONWebKeySet
+}
+
+// JWKSHandler publica las claves públicas de verificación de los tokens
+type JWKSHandler struct {
+	keys KeySetProvider
+}
+
+// NewJWKSHandler crea una nueva instancia del handler de claves públicas
+func NewJWKSHandler(keys KeySetProvider) *JWKSHandler {
+	return &JWKSHandler{
+		keys: keys,
+	}
+}
+
+// GetKeySet retorna el conjunto de claves en formato JWKS. Los clientes pueden
+// guardarlo en caché unos minutos, pero deben volver a pedirlo al encontrar un
+// kid desconocido, porque la clave nueva firma en cuanto se genera.
+func (h *JWKSHandler) GetKeySet(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
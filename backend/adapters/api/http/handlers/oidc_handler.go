@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oidcStateCookie guarda en el navegador el state del login con OpenID Connect
+// en curso. Su ruta incluye la del callback, que es donde se comprueba.
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/auth/oidc"
+)
+
+// OIDCHandler maneja las peticiones HTTP del inicio de sesión con OpenID Connect
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+}
+
+// NewOIDCHandler crea una nueva instancia del handler de OpenID Connect
+func NewOIDCHandler(oidcService *services.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// OIDCCallbackRequest define la estructura de la petición con la que el frontend
+// entrega los parámetros de la vuelta del proveedor de identidad
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// StartLogin retorna la URL del proveedor de identidad a la que se debe redirigir al usuario
+func (h *OIDCHandler) StartLogin(c *gin.Context) {
+	authURL, state, err := h.oidcService.StartLogin(c.Request.Context())
+	if err != nil {
+		respondOIDCError(c, err)
+		return
+	}
+
+	setOIDCStateCookie(c, state)
+	c.JSON(http.StatusOK, gin.H{"authorization_url": authURL})
+}
+
+// StartLink retorna la URL del proveedor de identidad para vincular su cuenta con el usuario autenticado
+func (h *OIDCHandler) StartLink(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	authURL, state, err := h.oidcService.StartLink(c.Request.Context(), userID)
+	if err != nil {
+		respondOIDCError(c, err)
+		return
+	}
+
+	setOIDCStateCookie(c, state)
+	c.JSON(http.StatusOK, gin.H{"authorization_url": authURL})
+}
+
+// Callback completa el login con el código de autorización del proveedor y
+// responde como Login. El state tiene que coincidir con el de la cookie que
+// se puso al iniciar el login en este navegador.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	// Sin la cookie el state no es de este navegador y el login se rechaza
+	browserState, _ := c.Cookie(oidcStateCookie)
+	clearOIDCStateCookie(c)
+
+	client := domain.LoginClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	result, err := h.oidcService.CompleteLogin(c.Request.Context(), req.Code, req.State, browserState, client)
+	if err != nil {
+		respondOIDCError(c, err)
+		return
+	}
+
+	respondLoginResult(c, result)
+}
+
+// ListIdentities lista las identidades externas vinculadas al usuario autenticado
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	identities, err := h.oidcService.ListIdentities(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+// Unlink desvincula una identidad externa del usuario autenticado
+func (h *OIDCHandler) Unlink(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if err := h.oidcService.Unlink(c.Request.Context(), userID, id); err != nil {
+		switch err {
+		case domain.ErrIdentityNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Identidad externa no encontrada"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Identidad externa desvinculada exitosamente"})
+}
+
+// setOIDCStateCookie guarda el state en una cookie HttpOnly de sesión. La
+// expiración del login la controla el servidor.
+func setOIDCStateCookie(c *gin.Context, state string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, 0, oidcStateCookiePath, "", isSecureRequest(c), true)
+}
+
+// clearOIDCStateCookie elimina la cookie del state: cada state sirve una vez
+func clearOIDCStateCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, oidcStateCookiePath, "", isSecureRequest(c), true)
+}
+
+// isSecureRequest indica si la petición llegó por HTTPS, directamente o a
+// través de un proxy
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
+
+// respondOIDCError traduce los errores del inicio de sesión con OpenID Connect a
+// respuestas HTTP; los del login en sí se responden como en Login
+func respondOIDCError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrOIDCDisabled):
+		c.JSON(http.StatusNotFound, gin.H{"error": "El inicio de sesión con OpenID Connect no está configurado"})
+	case errors.Is(err, domain.ErrIdentityProvider):
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Error comunicándose con el proveedor de identidad"})
+	case errors.Is(err, domain.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "El inicio de sesión con el proveedor no es válido o ha expirado"})
+	case errors.Is(err, domain.ErrIdentityLinked):
+		c.JSON(http.StatusConflict, gin.H{"error": "La cuenta del proveedor ya está vinculada a otro usuario"})
+	case errors.Is(err, domain.ErrAccountNotLinked):
+		c.JSON(http.StatusForbidden, gin.H{"error": "La cuenta del proveedor no está vinculada a ningún usuario"})
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+	default:
+		respondLoginError(c, err)
+	}
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"blog-backend/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePageRequest lee los parámetros ?limit=&cursor=&sort= de la petición.
+// Si son inválidos responde 400 y retorna false.
+func parsePageRequest(c *gin.Context, allowed []domain.SortOrder) (domain.PageRequest, bool) {
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro limit inválido"})
+			return domain.PageRequest{}, false
+		}
+		limit = l
+	}
+
+	page, err := domain.NewPageRequest(limit, c.Query("cursor"), domain.SortOrder(c.Query("sort")), allowed...)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidCursor:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cursor de paginación inválido"})
+		case domain.ErrInvalidSort:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Orden de listado inválido"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro limit inválido"})
+		}
+		return domain.PageRequest{}, false
+	}
+
+	return page, true
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler maneja las peticiones HTTP de gestión de roles y permisos
+type RoleHandler struct {
+	roleService *services.RoleService
+}
+
+// NewRoleHandler crea una nueva instancia del handler de roles
+func NewRoleHandler(roleService *services.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+	}
+}
+
+// CreateRoleRequest define la estructura de la petición de creación de rol
+type CreateRoleRequest struct {
+	Name        domain.Role         `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	Permissions []domain.Permission `json:"permissions"`
+}
+
+// UpdateRoleRequest define la estructura de la petición de actualización de rol
+type UpdateRoleRequest struct {
+	Description string              `json:"description"`
+	Permissions []domain.Permission `json:"permissions"`
+}
+
+// ListPermissions lista los permisos que se pueden asignar a un rol
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"permissions": h.roleService.ListPermissions()})
+}
+
+// ListRoles lista los roles con sus permisos
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+// CreateRole crea un rol nuevo
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	role, err := h.roleService.CreateRole(c.Request.Context(), req.Name, req.Description, req.Permissions)
+	if err != nil {
+		switch err {
+		case domain.ErrRoleAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "El rol ya existe"})
+		case domain.ErrInvalidRole:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Nombre de rol inválido"})
+		case domain.ErrInvalidPermission:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Permiso inválido"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Rol creado exitosamente",
+		"role":    role,
+	})
+}
+
+// UpdateRole actualiza la descripción y los permisos de un rol
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	name := domain.Role(c.Param("name"))
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(c.Request.Context(), name, req.Description, req.Permissions)
+	if err != nil {
+		switch err {
+		case domain.ErrRoleNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rol no encontrado"})
+		case domain.ErrAdminRoleImmutable:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Los permisos del rol de administrador no se pueden modificar"})
+		case domain.ErrInvalidPermission:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Permiso inválido"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Rol actualizado exitosamente",
+		"role":    role,
+	})
+}
+
+// DeleteRole elimina un rol que no esté asignado a ningún usuario
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	name := domain.Role(c.Param("name"))
+
+	if err := h.roleService.DeleteRole(c.Request.Context(), name); err != nil {
+		switch err {
+		case domain.ErrRoleNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rol no encontrado"})
+		case domain.ErrBuiltInRole:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Los roles predefinidos no se pueden eliminar"})
+		case domain.ErrRoleInUse:
+			c.JSON(http.StatusConflict, gin.H{"error": "El rol está asignado a usuarios"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rol eliminado exitosamente"})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler maneja las peticiones HTTP de búsqueda
+type SearchHandler struct {
+	searchService *services.SearchService
+}
+
+// NewSearchHandler crea una nueva instancia del handler de búsqueda
+func NewSearchHandler(searchService *services.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search busca blogs y comentarios por texto completo
+func (h *SearchHandler) Search(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "El parámetro q es requerido"})
+		return
+	}
+
+	var authorID int64
+	if authorStr := c.Query("author"); authorStr != "" {
+		id, err := strconv.ParseInt(authorStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ID de autor inválido"})
+			return
+		}
+		authorID = id
+	}
+
+	from, err := parseDateParam(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Fecha from inválida"})
+		return
+	}
+
+	to, err := parseDateParam(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Fecha to inválida"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro limit inválido"})
+		return
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetro offset inválido"})
+		return
+	}
+
+	results, err := h.searchService.Search(c.Request.Context(), query, domain.SearchKind(c.Query("type")), authorID, from, to, limit, offset)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parámetros de búsqueda inválidos"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// parseDateParam interpreta una fecha en formato RFC 3339 o AAAA-MM-DD
+func parseDateParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TagHandler maneja las peticiones HTTP relacionadas con etiquetas
+type TagHandler struct {
+	tagService *services.TagService
+}
+
+// NewTagHandler crea una nueva instancia del handler de etiquetas
+func NewTagHandler(tagService *services.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+// RenameTagRequest define la estructura de la petición de renombrado de etiqueta
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// MergeTagRequest define la estructura de la petición de fusión de etiquetas
+type MergeTagRequest struct {
+	TargetID int64 `json:"target_id" binding:"required"`
+}
+
+// ListTags lista las etiquetas con su número de blogs publicados
+func (h *TagHandler) ListTags(c *gin.Context) {
+	tags, err := h.tagService.ListTags(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// RenameTag cambia el nombre de una etiqueta (solo administradores)
+func (h *TagHandler) RenameTag(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req RenameTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.RenameTag(c.Request.Context(), id, req.Name)
+	if err != nil {
+		switch err {
+		case domain.ErrTagNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Etiqueta no encontrada"})
+		case domain.ErrInvalidTag:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Etiqueta inválida"})
+		case domain.ErrTagAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "Ya existe una etiqueta con ese nombre; usa la fusión de etiquetas"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Etiqueta renombrada exitosamente",
+		"tag":     tag,
+	})
+}
+
+// MergeTag fusiona una etiqueta en otra (solo administradores)
+func (h *TagHandler) MergeTag(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req MergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	tag, err := h.tagService.MergeTags(c.Request.Context(), id, req.TargetID)
+	if err != nil {
+		switch err {
+		case domain.ErrTagNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Etiqueta no encontrada"})
+		case domain.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No se puede fusionar una etiqueta consigo misma"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Etiquetas fusionadas exitosamente",
+		"tag":     tag,
+	})
+}
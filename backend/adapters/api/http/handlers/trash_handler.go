@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrashHandler maneja las peticiones HTTP de la papelera (solo administradores)
+type TrashHandler struct {
+	trashService *services.TrashService
+}
+
+// NewTrashHandler crea una nueva instancia del handler de la papelera
+func NewTrashHandler(trashService *services.TrashService) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// ListDeletedBlogs lista una página de blogs de la papelera
+func (h *TrashHandler) ListDeletedBlogs(c *gin.Context) {
+	page, ok := parsePageRequest(c, domain.TrashSortOrders)
+	if !ok {
+		return
+	}
+
+	blogs, err := h.trashService.ListDeletedBlogs(c.Request.Context(), page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, blogs)
+}
+
+// ListDeletedComments lista una página de comentarios de la papelera
+func (h *TrashHandler) ListDeletedComments(c *gin.Context) {
+	page, ok := parsePageRequest(c, domain.TrashSortOrders)
+	if !ok {
+		return
+	}
+
+	comments, err := h.trashService.ListDeletedComments(c.Request.Context(), page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// ListDeletedUsers lista una página de usuarios de la papelera
+func (h *TrashHandler) ListDeletedUsers(c *gin.Context) {
+	page, ok := parsePageRequest(c, domain.TrashSortOrders)
+	if !ok {
+		return
+	}
+
+	users, err := h.trashService.ListDeletedUsers(c.Request.Context(), page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// RestoreBlog saca un blog de la papelera
+func (h *TrashHandler) RestoreBlog(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if err := h.trashService.RestoreBlog(c.Request.Context(), id); err != nil {
+		switch err {
+		case domain.ErrBlogNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Blog no encontrado en la papelera"})
+		case domain.ErrAuthorDeleted:
+			c.JSON(http.StatusConflict, gin.H{"error": "El autor del blog está en la papelera; restáuralo primero"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Blog restaurado exitosamente"})
+}
+
+// RestoreComment saca un comentario de la papelera
+func (h *TrashHandler) RestoreComment(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if err := h.trashService.RestoreComment(c.Request.Context(), id); err != nil {
+		switch err {
+		case domain.ErrCommentNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Comentario no encontrado en la papelera"})
+		case domain.ErrBlogDeleted:
+			c.JSON(http.StatusConflict, gin.H{"error": "El blog del comentario está en la papelera; restáuralo primero"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comentario restaurado exitosamente"})
+}
+
+// RestoreUser saca un usuario de la papelera
+func (h *TrashHandler) RestoreUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if err := h.trashService.RestoreUser(c.Request.Context(), id); err != nil {
+		switch err {
+		case domain.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado en la papelera"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Usuario restaurado exitosamente"})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactorHandler maneja las peticiones HTTP de la autenticación en dos pasos
+type TwoFactorHandler struct {
+	twoFactorService *services.TwoFactorService
+}
+
+// NewTwoFactorHandler crea una nueva instancia del handler de autenticación en dos pasos
+func NewTwoFactorHandler(twoFactorService *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+	}
+}
+
+// TwoFactorCodeRequest define la estructura de las peticiones que llevan un código TOTP
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactorRequest define la estructura de la petición de desactivación
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// GetStatus obtiene el estado de la autenticación en dos pasos del usuario autenticado
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.twoFactorService.Status(c.Request.Context(), userID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Enroll genera el secreto TOTP del usuario autenticado y su URI de aprovisionamiento
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.twoFactorService.Enroll(c.Request.Context(), userID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// Confirm activa la autenticación en dos pasos y retorna los códigos de recuperación
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Autenticación en dos pasos activada exitosamente",
+		"recovery_codes": codes,
+	})
+}
+
+// RegenerateRecoveryCodes reemplaza los códigos de recuperación del usuario autenticado
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Códigos de recuperación generados exitosamente",
+		"recovery_codes": codes,
+	})
+}
+
+// Disable desactiva la autenticación en dos pasos del usuario autenticado
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, _, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.Request.Context(), userID, req.Password, req.Code); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Autenticación en dos pasos desactivada exitosamente"})
+}
+
+// ResetUser desactiva la autenticación en dos pasos de cualquier usuario y cierra sus sesiones
+func (h *TwoFactorHandler) ResetUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	_, callerRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.twoFactorService.Reset(c.Request.Context(), id, callerRole); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Autenticación en dos pasos restablecida exitosamente"})
+}
+
+// respondError traduce los errores del servicio de autenticación en dos pasos a respuestas HTTP
+func (h *TwoFactorHandler) respondError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+	case domain.ErrInvalidCredentials:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Contraseña incorrecta"})
+	case domain.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para gestionar este usuario"})
+	case domain.ErrInvalidTOTPCode:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Código de verificación inválido"})
+	case domain.ErrTwoFactorEnabled:
+		c.JSON(http.StatusConflict, gin.H{"error": "La autenticación en dos pasos ya está activada"})
+	case domain.ErrTwoFactorDisabled:
+		c.JSON(http.StatusConflict, gin.H{"error": "La autenticación en dos pasos no está activada"})
+	case domain.ErrNoTOTPEnrollment:
+		c.JSON(http.StatusConflict, gin.H{"error": "Primero debes generar un secreto de autenticación en dos pasos"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+	}
+}
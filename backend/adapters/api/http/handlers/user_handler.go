@@ -21,17 +21,27 @@ func NewUserHandler(userService *services.UserService) *UserHandler {
 	}
 }
 
-// RegisterRequest define la estructura de la petición de registro
+// RegisterRequest define la estructura de la petición de registro público
 type RegisterRequest struct {
-	Username string     `json:"username" binding:"required"`
-	Password string     `json:"password" binding:"required,min=6"`
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// CreateUserRequest define la estructura de la petición de creación de usuario por un administrador
+type CreateUserRequest struct {
+	Username string      `json:"username" binding:"required"`
+	Email    string      `json:"email"`
+	Password string      `json:"password" binding:"required,min=6"`
 	Role     domain.Role `json:"role" binding:"required"`
 }
 
 // UpdateUserRequest define la estructura de la petición de actualización
 type UpdateUserRequest struct {
-	Username string     `json:"username" binding:"required"`
-	Role     domain.Role `json:"role" binding:"required"`
+	Username string `json:"username" binding:"required"`
+	// Email es opcional: si no se envía se conserva el actual
+	Email *string     `json:"email"`
+	Role  domain.Role `json:"role" binding:"required"`
 }
 
 // Register registra un nuevo usuario
@@ -42,11 +52,15 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.Register(req.Username, req.Password, req.Role)
+	user, err := h.userService.Register(c.Request.Context(), req.Username, req.Email, req.Password)
 	if err != nil {
 		switch err {
+		case domain.ErrInvalidEmail, domain.ErrEmailRequired:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Correo electrónico inválido"})
 		case domain.ErrUserAlreadyExists:
 			c.JSON(http.StatusConflict, gin.H{"error": "El usuario ya existe"})
+		case domain.ErrEmailAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "El correo electrónico ya está registrado"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
 		}
@@ -59,6 +73,44 @@ func (h *UserHandler) Register(c *gin.Context) {
 	})
 }
 
+// CreateUser crea un usuario con un rol que el solicitante pueda asignar
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Datos inválidos: " + err.Error()})
+		return
+	}
+
+	_, callerRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.CreateUser(c.Request.Context(), req.Username, req.Email, req.Password, req.Role, callerRole)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidRole:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Rol inválido"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para asignar este rol"})
+		case domain.ErrInvalidEmail:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Correo electrónico inválido"})
+		case domain.ErrUserAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "El usuario ya existe"})
+		case domain.ErrEmailAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "El correo electrónico ya está registrado"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Usuario creado exitosamente",
+		"user":    user,
+	})
+}
+
 // GetUser obtiene un usuario por su ID
 func (h *UserHandler) GetUser(c *gin.Context) {
 	idStr := c.Param("id")
@@ -68,7 +120,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetUserByID(id)
+	user, err := h.userService.GetUserByID(c.Request.Context(), id)
 	if err != nil {
 		switch err {
 		case domain.ErrUserNotFound:
@@ -84,7 +136,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 // ListUsers lista todos los usuarios
 func (h *UserHandler) ListUsers(c *gin.Context) {
-	users, err := h.userService.ListUsers()
+	users, err := h.userService.ListUsers(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
 		return
@@ -108,9 +160,24 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.UpdateUser(id, req.Username, req.Role)
+	_, callerRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.UpdateUser(c.Request.Context(), id, req.Username, req.Email, req.Role, callerRole)
 	if err != nil {
 		switch err {
+		case domain.ErrInvalidRole:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Rol inválido"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para asignar este rol"})
+		case domain.ErrInvalidEmail:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Correo electrónico inválido"})
+		case domain.ErrEmailAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "El correo electrónico ya está registrado"})
+		case domain.ErrUserAlreadyExists:
+			c.JSON(http.StatusConflict, gin.H{"error": "El usuario ya existe"})
 		case domain.ErrUserNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
 		default:
@@ -134,10 +201,19 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.DeleteUser(id); err != nil {
+	_, callerRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.DeleteUser(c.Request.Context(), id, callerRole); err != nil {
 		switch err {
 		case domain.ErrUserNotFound:
 			c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Este usuario no se puede eliminar"})
+		case domain.ErrUserHasContent:
+			c.JSON(http.StatusConflict, gin.H{"error": "El usuario tiene blogs o comentarios y no se puede eliminar"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
 		}
@@ -146,3 +222,36 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Usuario eliminado exitosamente"})
 }
+
+// UnlockUser levanta el bloqueo de inicio de sesión de un usuario
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	_, callerRole, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.UnlockUser(c.Request.Context(), id, callerRole)
+	if err != nil {
+		switch err {
+		case domain.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Usuario no encontrado"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "No tienes permiso para gestionar este usuario"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Usuario desbloqueado exitosamente",
+		"user":    user,
+	})
+}
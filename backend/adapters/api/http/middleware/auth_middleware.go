@@ -3,21 +3,42 @@ package middleware
 import (
 	"blog-backend/internal/domain"
 	"blog-backend/internal/ports"
+	"context"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// TokenValidator valida un token de acceso y retorna el usuario autenticado
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, token string) (*domain.User, error)
+}
+
+// AuthPolicy define las exigencias de seguridad de las cuentas que aplican
+// RequireVerifiedEmail y RequireTwoFactor
+type AuthPolicy struct {
+	// RequireVerifiedEmail rechaza a los usuarios que no verificaron su correo
+	RequireVerifiedEmail bool
+	// TwoFactorRoles son los roles que deben activar la autenticación en dos pasos
+	TwoFactorRoles []domain.Role
+}
+
 // AuthMiddleware verifica la autenticación del usuario mediante JWT
+// y sus permisos mediante el Authorizer
 type AuthMiddleware struct {
-	authService ports.AuthService
+	authService TokenValidator
+	authorizer  ports.Authorizer
+	policy      AuthPolicy
 }
 
 // NewAuthMiddleware crea una nueva instancia del middleware de autenticación
-func NewAuthMiddleware(authService ports.AuthService) *AuthMiddleware {
+func NewAuthMiddleware(authService TokenValidator, authorizer ports.Authorizer, policy AuthPolicy) *AuthMiddleware {
 	return &AuthMiddleware{
 		authService: authService,
+		authorizer:  authorizer,
+		policy:      policy,
 	}
 }
 
@@ -40,7 +61,7 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 		}
 
 		token := tokenParts[1]
-		user, err := m.authService.ValidateToken(token)
+		user, err := m.authService.ValidateToken(c.Request.Context(), token)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token inválido"})
 			c.Abort()
@@ -56,8 +77,8 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 	}
 }
 
-// RequireRole verifica que el usuario tenga un rol específico
-func (m *AuthMiddleware) RequireRole(requiredRole domain.Role) gin.HandlerFunc {
+// RequirePermission verifica que el rol del usuario conceda el permiso indicado
+func (m *AuthMiddleware) RequirePermission(permission domain.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("user_role")
 		if !exists {
@@ -73,8 +94,60 @@ func (m *AuthMiddleware) RequireRole(requiredRole domain.Role) gin.HandlerFunc {
 			return
 		}
 
-		if role != requiredRole && role != domain.RoleAdmin {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Acceso prohibido"})
+		if err := m.authorizer.Authorize(c.Request.Context(), role, permission); err != nil {
+			if err == domain.ErrForbidden {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Acceso prohibido"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+			}
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail verifica que el usuario autenticado haya verificado su
+// correo. Si la verificación no es obligatoria deja pasar todas las peticiones.
+func (m *AuthMiddleware) RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.policy.RequireVerifiedEmail {
+			c.Next()
+			return
+		}
+
+		user, ok := contextUser(c)
+		if !ok {
+			return
+		}
+
+		if !user.IsEmailVerified() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Debes verificar tu correo electrónico antes de publicar"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireTwoFactor verifica que el usuario autenticado haya activado la
+// autenticación en dos pasos si su rol la exige
+func (m *AuthMiddleware) RequireTwoFactor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(m.policy.TwoFactorRoles) == 0 {
+			c.Next()
+			return
+		}
+
+		user, ok := contextUser(c)
+		if !ok {
+			return
+		}
+
+		if !user.TwoFactorEnabled() && slices.Contains(m.policy.TwoFactorRoles, user.Role) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Debes activar la autenticación en dos pasos para continuar"})
 			c.Abort()
 			return
 		}
@@ -83,9 +156,24 @@ func (m *AuthMiddleware) RequireRole(requiredRole domain.Role) gin.HandlerFunc {
 	}
 }
 
-// RequireAdmin verifica que el usuario sea administrador
-func (m *AuthMiddleware) RequireAdmin() gin.HandlerFunc {
-	return m.RequireRole(domain.RoleAdmin)
+// contextUser obtiene el usuario seteado por Authenticate. Si falta responde
+// con el error correspondiente, aborta la petición y retorna false.
+func contextUser(c *gin.Context) (*domain.User, bool) {
+	value, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Usuario no autenticado"})
+		c.Abort()
+		return nil, false
+	}
+
+	user, ok := value.(*domain.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error interno del servidor"})
+		c.Abort()
+		return nil, false
+	}
+
+	return user, true
 }
 
 // OptionalAuth permite acceso opcional con autenticación
@@ -104,7 +192,7 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 		}
 
 		token := tokenParts[1]
-		user, err := m.authService.ValidateToken(token)
+		user, err := m.authService.ValidateToken(c.Request.Context(), token)
 		if err != nil {
 			c.Next()
 			return
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitPolicy define un token bucket: como máximo Requests peticiones
+// seguidas, que se recuperan de forma continua a lo largo de Period.
+// Una política con Requests <= 0 no limita.
+type RateLimitPolicy struct {
+	// Name separa los buckets de cada política en el almacén
+	Name     string
+	Requests int
+	Period   time.Duration
+}
+
+// RateLimitResult es el resultado de consumir una petición de un bucket
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter es el tiempo hasta que vuelva a haber una petición disponible
+	RetryAfter time.Duration
+}
+
+// RateLimitStore guarda el estado de los buckets. Permite sustituir el
+// almacén en memoria por uno compartido entre réplicas.
+type RateLimitStore interface {
+	Take(key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error)
+}
+
+// RateLimitKey obtiene la clave del bucket de una petición
+type RateLimitKey func(c *gin.Context) string
+
+// KeyByIP agrupa las peticiones por dirección IP del cliente
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUser agrupa las peticiones por usuario autenticado y, si no lo hay,
+// por dirección IP. Debe usarse después de Authenticate u OptionalAuth.
+func KeyByUser(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		if uid, ok := userID.(int64); ok {
+			return "user:" + strconv.FormatInt(uid, 10)
+		}
+	}
+	return KeyByIP(c)
+}
+
+// RateLimiter limita la frecuencia de peticiones por ruta
+type RateLimiter struct {
+	store RateLimitStore
+}
+
+// NewRateLimiter crea un limitador que guarda los buckets en store
+func NewRateLimiter(store RateLimitStore) *RateLimiter {
+	return &RateLimiter{store: store}
+}
+
+// Limit aplica la política a todas las peticiones de la ruta
+func (l *RateLimiter) Limit(policy RateLimitPolicy, key RateLimitKey) gin.HandlerFunc {
+	if policy.Requests <= 0 || policy.Period <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		result, err := l.store.Take(policy.Name+":"+key(c), policy, time.Now())
+		if err != nil {
+			// Si el almacén falla se deja pasar la petición para no tumbar la API
+			log.Printf("Error consultando el límite de peticiones %s: %v", policy.Name, err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(policy.Requests))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Demasiadas peticiones, inténtalo más tarde",
+				"retry_after": retryAfter,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// LimitWrites aplica la política solo a las peticiones que modifican datos
+// (todas salvo GET, HEAD y OPTIONS)
+func (l *RateLimiter) LimitWrites(policy RateLimitPolicy, key RateLimitKey) gin.HandlerFunc {
+	limit := l.Limit(policy, key)
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+		default:
+			limit(c)
+		}
+	}
+}
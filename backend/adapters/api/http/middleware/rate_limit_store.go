@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval es cada cuánto se eliminan de memoria los buckets llenos
+const sweepInterval = time.Minute
+
+// bucket es el estado de un token bucket
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+// MemoryRateLimitStore guarda los buckets en memoria del proceso. Solo es
+// válido con una única réplica del servidor.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimitStore crea un almacén de buckets en memoria
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*bucket)}
+}
+
+// Take consume una petición del bucket de key
+func (s *MemoryRateLimitStore) Take(key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	capacity := float64(policy.Requests)
+	rate := capacity / float64(policy.Period) // tokens por nanosegundo
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now, period: policy.Period}
+		s.buckets[key] = b
+	} else if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+float64(elapsed)*rate)
+		b.updated = now
+	}
+
+	if b.tokens < 1 {
+		return RateLimitResult{
+			Allowed:    false,
+			Remaining:  0,
+			RetryAfter: time.Duration((1 - b.tokens) / rate),
+		}, nil
+	}
+
+	b.tokens--
+	return RateLimitResult{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+// sweep elimina los buckets que ya se han rellenado por completo; equivalen
+// a un bucket nuevo y no hace falta conservarlos
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	policy := RateLimitPolicy{Name: "prueba", Requests: 3, Period: time.Minute}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name string
+		key  string
+		// elapsed es el tiempo desde el inicio de la prueba
+		elapsed    time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}{
+		{name: "ráfaga 1", key: "a", allowed: true, remaining: 2},
+		{name: "ráfaga 2", key: "a", allowed: true, remaining: 1},
+		{name: "ráfaga 3", key: "a", allowed: true, remaining: 0},
+		{name: "ráfaga agotada", key: "a", allowed: false, retryAfter: 20 * time.Second},
+		{name: "otra clave", key: "b", allowed: true, remaining: 2},
+		{name: "recarga parcial", key: "a", elapsed: 10 * time.Second, allowed: false, retryAfter: 10 * time.Second},
+		{name: "recarga de una petición", key: "a", elapsed: 20 * time.Second, allowed: true, remaining: 0},
+		{name: "recarga completa", key: "a", elapsed: 10 * time.Minute, allowed: true, remaining: 2},
+		{name: "sin pasar de la capacidad", key: "a", elapsed: time.Hour, allowed: true, remaining: 2},
+	}
+
+	for _, step := range steps {
+		result, err := store.Take(step.key, policy, start.Add(step.elapsed))
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		want := RateLimitResult{Allowed: step.allowed, Remaining: step.remaining, RetryAfter: step.retryAfter}
+		if result != want {
+			t.Errorf("%s: Take = %+v, se esperaba %+v", step.name, result, want)
+		}
+	}
+}
+
+func TestMemoryRateLimitStoreSweep(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	policy := RateLimitPolicy{Name: "prueba", Requests: 3, Period: 30 * time.Second}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	take := func(key string, at time.Duration) {
+		t.Helper()
+		if _, err := store.Take(key, policy, start.Add(at)); err != nil {
+			t.Fatalf("Take(%q): %v", key, err)
+		}
+	}
+	keys := func() map[string]bool {
+		found := make(map[string]bool)
+		for key := range store.buckets {
+			found[key] = true
+		}
+		return found
+	}
+
+	take("a", 0)
+	take("b", 45*time.Second)
+
+	// Antes de sweepInterval no se barre aunque "a" ya se haya rellenado
+	take("c", 50*time.Second)
+	if got := keys(); len(got) != 3 {
+		t.Fatalf("buckets = %v, se esperaban 3", got)
+	}
+
+	// Pasado sweepInterval se eliminan los buckets que se han rellenado por completo
+	take("d", sweepInterval+time.Second)
+	if got := keys(); got["a"] || !got["b"] || !got["c"] || !got["d"] {
+		t.Errorf("buckets tras el barrido = %v", got)
+	}
+
+	// Un bucket eliminado equivale a uno nuevo
+	result, err := store.Take("a", policy, start.Add(sweepInterval+2*time.Second))
+	if err != nil || !result.Allowed || result.Remaining != 2 {
+		t.Errorf("Take tras el barrido = %+v, %v", result, err)
+	}
+}
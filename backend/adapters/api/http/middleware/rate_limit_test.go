@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// failingStore simula un almacén de buckets caído
+type failingStore struct{}
+
+func (failingStore) Take(string, RateLimitPolicy, time.Time) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("almacén caído")
+}
+
+// newLimitedRouter crea un router con la ruta /prueba limitada por handler
+func newLimitedRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/prueba", handler, func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+// request hace una petición a /prueba desde ip
+func request(router *gin.Engine, method, ip string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(method, "/prueba", nil)
+	req.RemoteAddr = ip + ":1234"
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimiterLimit(t *testing.T) {
+	limiter := NewRateLimiter(NewMemoryRateLimitStore())
+	router := newLimitedRouter(limiter.Limit(RateLimitPolicy{Name: "prueba", Requests: 2, Period: time.Hour}, KeyByIP))
+
+	steps := []struct {
+		ip         string
+		status     int
+		remaining  string
+		retryAfter string
+	}{
+		{ip: "10.0.0.1", status: http.StatusOK, remaining: "1"},
+		{ip: "10.0.0.1", status: http.StatusOK, remaining: "0"},
+		// La ráfaga de dos peticiones se recupera en una hora: la siguiente en media
+		{ip: "10.0.0.1", status: http.StatusTooManyRequests, remaining: "0", retryAfter: "1800"},
+		{ip: "10.0.0.2", status: http.StatusOK, remaining: "1"},
+	}
+
+	for i, step := range steps {
+		recorder := request(router, http.MethodPost, step.ip)
+		if recorder.Code != step.status {
+			t.Fatalf("petición %d: estado %d, se esperaba %d", i+1, recorder.Code, step.status)
+		}
+		header := recorder.Header()
+		if header.Get("X-RateLimit-Limit") != "2" || header.Get("X-RateLimit-Remaining") != step.remaining {
+			t.Errorf("petición %d: cabeceras %v", i+1, header)
+		}
+		if header.Get("Retry-After") != step.retryAfter {
+			t.Errorf("petición %d: Retry-After = %q, se esperaba %q", i+1, header.Get("Retry-After"), step.retryAfter)
+		}
+	}
+}
+
+func TestRateLimiterPassThrough(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter *RateLimiter
+		policy  RateLimitPolicy
+	}{
+		{name: "política desactivada", limiter: NewRateLimiter(NewMemoryRateLimitStore()), policy: RateLimitPolicy{Name: "prueba", Period: time.Hour}},
+		{name: "sin periodo", limiter: NewRateLimiter(NewMemoryRateLimitStore()), policy: RateLimitPolicy{Name: "prueba", Requests: 1}},
+		{name: "almacén caído", limiter: NewRateLimiter(failingStore{}), policy: RateLimitPolicy{Name: "prueba", Requests: 1, Period: time.Hour}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newLimitedRouter(tt.limiter.Limit(tt.policy, KeyByIP))
+			for i := 0; i < 3; i++ {
+				if recorder := request(router, http.MethodPost, "10.0.0.1"); recorder.Code != http.StatusOK {
+					t.Fatalf("petición %d: estado %d", i+1, recorder.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterLimitWrites(t *testing.T) {
+	limiter := NewRateLimiter(NewMemoryRateLimitStore())
+	router := newLimitedRouter(limiter.LimitWrites(RateLimitPolicy{Name: "prueba", Requests: 1, Period: time.Hour}, KeyByIP))
+
+	for _, method := range []string{http.MethodGet, http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPost} {
+		if recorder := request(router, method, "10.0.0.1"); recorder.Code != http.StatusOK {
+			t.Fatalf("%s: estado %d", method, recorder.Code)
+		}
+	}
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		if recorder := request(router, method, "10.0.0.1"); recorder.Code != http.StatusTooManyRequests {
+			t.Errorf("%s: estado %d, se esperaba 429", method, recorder.Code)
+		}
+	}
+}
+
+func TestKeyByUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.RemoteAddr = "10.0.0.1:1234"
+
+	if key := KeyByUser(c); key != "ip:10.0.0.1" {
+		t.Errorf("KeyByUser sin usuario = %q", key)
+	}
+	c.Set("user_id", int64(7))
+	if key := KeyByUser(c); key != "user:7" {
+		t.Errorf("KeyByUser = %q", key)
+	}
+}
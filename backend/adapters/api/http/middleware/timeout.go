@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout limita la duración de las operaciones de cada petición. El contexto
+// de la petición, que los handlers pasan a los servicios y estos a los
+// repositorios, se cancela al cumplirse timeout o al desconectarse el cliente,
+// y con él las consultas en curso. Un timeout <= 0 no limita.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -3,96 +3,243 @@ package httprouter
 import (
 	"blog-backend/adapters/api/http/handlers"
 	"blog-backend/adapters/api/http/middleware"
+	"blog-backend/internal/domain"
 	"blog-backend/internal/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Router configura todas las rutas de la aplicación
 type Router struct {
-	userHandler    *handlers.UserHandler
-	authHandler    *handlers.AuthHandler
-	blogHandler    *handlers.BlogHandler
-	commentHandler *handlers.CommentHandler
-	authMiddleware *middleware.AuthMiddleware
+	userHandler      *handlers.UserHandler
+	authHandler      *handlers.AuthHandler
+	twoFactorHandler *handlers.TwoFactorHandler
+	oidcHandler      *handlers.OIDCHandler
+	blogHandler      *handlers.BlogHandler
+	commentHandler   *handlers.CommentHandler
+	searchHandler    *handlers.SearchHandler
+	tagHandler       *handlers.TagHandler
+	trashHandler     *handlers.TrashHandler
+	roleHandler      *handlers.RoleHandler
+	jwksHandler      *handlers.JWKSHandler
+	healthHandler    *handlers.HealthHandler
+	authMiddleware   *middleware.AuthMiddleware
+	rateLimiter      *middleware.RateLimiter
+	rateLimits       RateLimits
+	queryTimeout     time.Duration
+}
+
+// RateLimits agrupa las políticas de limitación de peticiones de cada tipo de ruta
+type RateLimits struct {
+	Login         middleware.RateLimitPolicy
+	Register      middleware.RateLimitPolicy
+	PasswordReset middleware.RateLimitPolicy
+	VerifyEmail   middleware.RateLimitPolicy
+	Refresh       middleware.RateLimitPolicy
+	Search        middleware.RateLimitPolicy
+	Write         middleware.RateLimitPolicy
+	Comment       middleware.RateLimitPolicy
 }
 
 // NewRouter crea una nueva instancia del router
 func NewRouter(
 	userService *services.UserService,
 	authService *services.AuthService,
+	passwordResetService *services.PasswordResetService,
+	emailVerificationService *services.EmailVerificationService,
+	twoFactorService *services.TwoFactorService,
+	oidcService *services.OIDCService,
 	blogService *services.BlogService,
 	commentService *services.CommentService,
+	searchService *services.SearchService,
+	tagService *services.TagService,
+	trashService *services.TrashService,
+	roleService *services.RoleService,
+	keySet handlers.KeySetProvider,
+	healthHandler *handlers.HealthHandler,
 	authMiddleware *middleware.AuthMiddleware,
+	rateLimiter *middleware.RateLimiter,
+	rateLimits RateLimits,
+	queryTimeout time.Duration,
 ) *Router {
 	return &Router{
-		userHandler:    handlers.NewUserHandler(userService),
-		authHandler:    handlers.NewAuthHandler(authService),
-		blogHandler:    handlers.NewBlogHandler(blogService),
-		commentHandler: handlers.NewCommentHandler(commentService),
-		authMiddleware: authMiddleware,
+		userHandler:      handlers.NewUserHandler(userService),
+		authHandler:      handlers.NewAuthHandler(authService, passwordResetService, emailVerificationService),
+		twoFactorHandler: handlers.NewTwoFactorHandler(twoFactorService),
+		oidcHandler:      handlers.NewOIDCHandler(oidcService),
+		blogHandler:      handlers.NewBlogHandler(blogService),
+		commentHandler:   handlers.NewCommentHandler(commentService),
+		searchHandler:    handlers.NewSearchHandler(searchService),
+		tagHandler:       handlers.NewTagHandler(tagService),
+		trashHandler:     handlers.NewTrashHandler(trashService),
+		roleHandler:      handlers.NewRoleHandler(roleService),
+		jwksHandler:      handlers.NewJWKSHandler(keySet),
+		healthHandler:    healthHandler,
+		authMiddleware:   authMiddleware,
+		rateLimiter:      rateLimiter,
+		rateLimits:       rateLimits,
+		queryTimeout:     queryTimeout,
 	}
 }
 
 // SetupRoutes configura todas las rutas de la aplicación
 func (r *Router) SetupRoutes() *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
 
 	// Middleware global
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(middleware.Timeout(r.queryTimeout))
 
 	// Rutas públicas
 	public := router.Group("/api")
 	{
 		// Autenticación
-		public.POST("/auth/login", r.authHandler.Login)
-		public.POST("/auth/register", r.userHandler.Register)
+		public.POST("/auth/login", r.rateLimiter.Limit(r.rateLimits.Login, middleware.KeyByIP), r.authHandler.Login)
+		public.POST("/auth/login/2fa", r.rateLimiter.Limit(r.rateLimits.Login, middleware.KeyByIP), r.authHandler.LoginTwoFactor)
+		public.GET("/auth/oidc/login", r.rateLimiter.Limit(r.rateLimits.Login, middleware.KeyByIP), r.oidcHandler.StartLogin)
+		public.POST("/auth/oidc/callback", r.rateLimiter.Limit(r.rateLimits.Login, middleware.KeyByIP), r.oidcHandler.Callback)
+		public.POST("/auth/register", r.rateLimiter.Limit(r.rateLimits.Register, middleware.KeyByIP), r.userHandler.Register)
+		public.POST("/auth/forgot-password", r.rateLimiter.Limit(r.rateLimits.PasswordReset, middleware.KeyByIP), r.authHandler.ForgotPassword)
+		public.POST("/auth/reset-password", r.rateLimiter.Limit(r.rateLimits.PasswordReset, middleware.KeyByIP), r.authHandler.ResetPassword)
+		public.POST("/auth/verify-email", r.rateLimiter.Limit(r.rateLimits.VerifyEmail, middleware.KeyByIP), r.authHandler.VerifyEmail)
+		public.POST("/auth/refresh", r.rateLimiter.Limit(r.rateLimits.Refresh, middleware.KeyByIP), r.authHandler.Refresh)
+		public.POST("/auth/logout", r.authHandler.Logout)
 
-		// Blogs públicos (solo lectura)
+		// Blogs públicos (solo lectura). Con autenticación opcional el autor
+		// y los administradores también ven los blogs no publicados.
 		public.GET("/blogs", r.blogHandler.ListBlogs)
-		public.GET("/blogs/author/:authorId", r.blogHandler.GetBlogsByAuthor)
-		public.GET("/blogs/:id", r.blogHandler.GetBlog)
+		public.GET("/blogs/author/:authorId", r.authMiddleware.OptionalAuth(), r.blogHandler.GetBlogsByAuthor)
+		public.GET("/blogs/:id", r.authMiddleware.OptionalAuth(), r.blogHandler.GetBlog)
 
 		// Comentarios públicos (solo lectura)
-		public.GET("/blogs/:id/comments", r.commentHandler.GetCommentsByBlog)
+		public.GET("/blogs/:id/comments", r.authMiddleware.OptionalAuth(), r.commentHandler.GetCommentsByBlog)
+
+		// Búsqueda de texto completo
+		public.GET("/search", r.rateLimiter.Limit(r.rateLimits.Search, middleware.KeyByIP), r.searchHandler.Search)
+
+		// Etiquetas con su número de blogs publicados
+		public.GET("/tags", r.tagHandler.ListTags)
+	}
+
+	// Cuenta del usuario autenticado. No exige la autenticación en dos pasos
+	// para que los usuarios obligados a usarla puedan activarla.
+	account := router.Group("/api/auth")
+	account.Use(r.authMiddleware.Authenticate())
+	account.Use(r.rateLimiter.LimitWrites(r.rateLimits.Write, middleware.KeyByUser))
+	{
+		// Perfil de usuario
+		account.GET("/profile", r.authHandler.GetProfile)
+		account.PUT("/change-password", r.authHandler.ChangePassword)
+		account.PUT("/email", r.authHandler.ChangeEmail)
+		account.POST("/verify-email/resend", r.authHandler.ResendVerification)
+		account.GET("/login-history", r.authHandler.LoginHistory)
+
+		// Autenticación en dos pasos
+		account.GET("/2fa", r.twoFactorHandler.GetStatus)
+		account.POST("/2fa/enroll", r.twoFactorHandler.Enroll)
+		account.POST("/2fa/confirm", r.twoFactorHandler.Confirm)
+		account.POST("/2fa/recovery-codes", r.twoFactorHandler.RegenerateRecoveryCodes)
+		account.DELETE("/2fa", r.twoFactorHandler.Disable)
+
+		// Identidades de proveedores OpenID Connect vinculadas
+		account.POST("/oidc/link", r.oidcHandler.StartLink)
+		account.GET("/oidc/identities", r.oidcHandler.ListIdentities)
+		account.DELETE("/oidc/identities/:id", r.oidcHandler.Unlink)
 	}
 
 	// Rutas protegidas (requieren autenticación)
 	protected := router.Group("/api")
 	protected.Use(r.authMiddleware.Authenticate())
+	protected.Use(r.authMiddleware.RequireTwoFactor())
+	protected.Use(r.rateLimiter.LimitWrites(r.rateLimits.Write, middleware.KeyByUser))
 	{
-		// Perfil de usuario
-		protected.GET("/auth/profile", r.authHandler.GetProfile)
-		protected.PUT("/auth/change-password", r.authHandler.ChangePassword)
-
 		// Gestión de blogs (autenticados)
-		protected.POST("/blogs", r.blogHandler.CreateBlog)
+		protected.POST("/blogs", r.authMiddleware.RequireVerifiedEmail(), r.blogHandler.CreateBlog)
 		protected.PUT("/blogs/:id", r.blogHandler.UpdateBlog)
+		protected.PUT("/blogs/:id/status", r.blogHandler.ChangeBlogStatus)
+		protected.PUT("/blogs/:id/comment-moderation", r.blogHandler.SetCommentModeration)
 		protected.DELETE("/blogs/:id", r.blogHandler.DeleteBlog)
 
+		// Historial de ediciones de blogs (autor o administrador)
+		protected.GET("/blogs/:id/revisions", r.blogHandler.ListRevisions)
+		protected.GET("/blogs/:id/revisions/diff", r.blogHandler.DiffRevisions)
+		protected.POST("/blogs/:id/revisions/:revisionId/restore", r.blogHandler.RestoreRevision)
+
 		// Gestión de comentarios (autenticados)
-		protected.POST("/blogs/:id/comments", r.commentHandler.CreateComment)
+		protected.POST("/blogs/:id/comments", r.authMiddleware.RequireVerifiedEmail(),
+			r.rateLimiter.Limit(r.rateLimits.Comment, middleware.KeyByUser), r.commentHandler.CreateComment)
 		protected.PUT("/comments/:id", r.commentHandler.UpdateComment)
 		protected.DELETE("/comments/:id", r.commentHandler.DeleteComment)
+
+		// Moderación de comentarios (autor del blog o comment:moderate)
+		protected.GET("/blogs/:id/comments/moderation", r.commentHandler.ListModerationQueue)
+		protected.POST("/comments/:id/approve", r.commentHandler.ApproveComment)
+		protected.POST("/comments/:id/reject", r.commentHandler.RejectComment)
+		protected.POST("/comments/:id/spam", r.commentHandler.MarkCommentSpam)
 	}
 
-	// Rutas de administración (solo administradores)
+	// Rutas de administración (cada grupo requiere su permiso)
 	admin := router.Group("/api/admin")
 	admin.Use(r.authMiddleware.Authenticate())
-	admin.Use(r.authMiddleware.RequireAdmin())
+	admin.Use(r.authMiddleware.RequireTwoFactor())
+	admin.Use(r.rateLimiter.LimitWrites(r.rateLimits.Write, middleware.KeyByUser))
+
+	// Gestión de usuarios
+	users := admin.Group("/users", r.authMiddleware.RequirePermission(domain.PermUserManage))
+	{
+		users.GET("", r.userHandler.ListUsers)
+		users.POST("", r.userHandler.CreateUser)
+		users.GET("/:id", r.userHandler.GetUser)
+		users.PUT("/:id", r.userHandler.UpdateUser)
+		users.DELETE("/:id", r.userHandler.DeleteUser)
+		users.POST("/:id/unlock", r.userHandler.UnlockUser)
+		users.GET("/:id/logins", r.authHandler.ListUserLogins)
+		users.DELETE("/:id/2fa", r.twoFactorHandler.ResetUser)
+	}
+
+	// Gestión de roles y permisos
+	roles := admin.Group("", r.authMiddleware.RequirePermission(domain.PermRoleManage))
+	{
+		roles.GET("/permissions", r.roleHandler.ListPermissions)
+		roles.GET("/roles", r.roleHandler.ListRoles)
+		roles.POST("/roles", r.roleHandler.CreateRole)
+		roles.PUT("/roles/:name", r.roleHandler.UpdateRole)
+		roles.DELETE("/roles/:name", r.roleHandler.DeleteRole)
+	}
+
+	// Cola de moderación global
+	moderation := admin.Group("/moderation", r.authMiddleware.RequirePermission(domain.PermCommentModerate))
 	{
-		// Gestión de usuarios (solo administradores)
-		admin.GET("/users", r.userHandler.ListUsers)
-		admin.GET("/users/:id", r.userHandler.GetUser)
-		admin.PUT("/users/:id", r.userHandler.UpdateUser)
-		admin.DELETE("/users/:id", r.userHandler.DeleteUser)
+		moderation.GET("/comments", r.commentHandler.ListModerationQueue)
 	}
 
-	// Ruta de salud
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok", "message": "Servidor funcionando correctamente"})
-	})
+	// Gestión de etiquetas
+	tags := admin.Group("/tags", r.authMiddleware.RequirePermission(domain.PermTagManage))
+	{
+		tags.PUT("/:id", r.tagHandler.RenameTag)
+		tags.POST("/:id/merge", r.tagHandler.MergeTag)
+	}
+
+	// Papelera: elementos eliminados pendientes de purga
+	trash := admin.Group("/trash", r.authMiddleware.RequirePermission(domain.PermTrashManage))
+	{
+		trash.GET("/blogs", r.trashHandler.ListDeletedBlogs)
+		trash.GET("/comments", r.trashHandler.ListDeletedComments)
+		trash.GET("/users", r.trashHandler.ListDeletedUsers)
+		trash.POST("/blogs/:id/restore", r.trashHandler.RestoreBlog)
+		trash.POST("/comments/:id/restore", r.trashHandler.RestoreComment)
+		trash.POST("/users/:id/restore", r.trashHandler.RestoreUser)
+	}
+
+	// Sondas de vida y de disponibilidad; /health se conserva como sonda de vida
+	router.GET("/livez", r.healthHandler.Live)
+	router.GET("/readyz", r.healthHandler.Ready)
+	router.GET("/health", r.healthHandler.Live)
+
+	// Claves públicas para que otros servicios verifiquen los tokens de acceso
+	router.GET("/.well-known/jwks.json", r.jwksHandler.GetKeySet)
 
 	return router
 }
@@ -0,0 +1,276 @@
+//go:build cgo
+
+package httprouter_test
+
+import (
+	httprouter "blog-backend/adapters/api/http"
+	"blog-backend/adapters/api/http/handlers"
+	"blog-backend/adapters/api/http/middleware"
+	"blog-backend/adapters/auth"
+	"blog-backend/adapters/filter"
+	"blog-backend/adapters/mail"
+	"blog-backend/adapters/persistence"
+	"blog-backend/adapters/persistence/migrations"
+	"blog-backend/adapters/persistence/sqlite"
+	"blog-backend/internal/domain"
+	"blog-backend/internal/services"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestServer monta la API completa sobre una base de datos SQLite en
+// memoria con todas las migraciones aplicadas, igual que cmd/server
+func newTestServer(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	db, err := sqlite.Open(sqlite.MemoryPath)
+	if err != nil {
+		t.Fatalf("abriendo la base de datos: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	dialect := persistence.DialectSQLite
+	migrator, err := persistence.NewMigrator(db, dialect, migrations.SQLiteFS, time.Minute)
+	if err != nil {
+		t.Fatalf("cargando migraciones: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("aplicando migraciones: %v", err)
+	}
+
+	userRepo := persistence.NewUserRepositorySQL(db)
+	blogRepo := persistence.NewBlogRepositorySQL(db, dialect)
+	commentRepo := persistence.NewCommentRepositorySQL(db, dialect)
+	refreshTokenRepo := persistence.NewRefreshTokenRepositorySQL(db)
+	loginAttemptRepo := persistence.NewLoginAttemptRepositorySQL(db)
+	revisionRepo := persistence.NewRevisionRepositorySQL(db)
+	tagRepo := persistence.NewTagRepositorySQL(db, dialect)
+	roleRepo := persistence.NewRoleRepositorySQL(db)
+	txManager := persistence.NewTxManagerSQL(db)
+
+	tokenKeys := auth.NewHMACKeys("secreto-de-pruebas")
+	jwtService := auth.NewJWTService(tokenKeys, 15*time.Minute)
+	authorizer := auth.NewRoleAuthorizer(roleRepo)
+	linkSigner := auth.NewHMACLinkSigner("secreto-de-pruebas")
+	totpCipher, err := auth.NewAESGCMCipher("secreto-de-pruebas")
+	if err != nil {
+		t.Fatalf("creando el cifrado TOTP: %v", err)
+	}
+	mailer := mail.NewLogMailer(log.New(io.Discard, "", 0), "blog@example.com")
+
+	emailVerificationService := services.NewEmailVerificationService(userRepo, jwtService, linkSigner, mailer,
+		"http://localhost/verificar", time.Hour, time.Minute)
+	userService := services.NewUserService(userRepo, roleRepo, refreshTokenRepo, blogRepo, commentRepo, jwtService, emailVerificationService,
+		txManager, domain.DeletionCascade)
+	twoFactorService := services.NewTwoFactorService(userRepo, roleRepo, persistence.NewRecoveryCodeRepositorySQL(db), refreshTokenRepo, jwtService,
+		auth.NewTOTPService("Blog"), totpCipher)
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, loginAttemptRepo, jwtService, linkSigner, twoFactorService,
+		24*time.Hour, 5*time.Minute, domain.LockoutPolicy{})
+	oidcService := services.NewOIDCService(nil, persistence.NewIdentityRepositorySQL(db), persistence.NewOIDCStateRepositorySQL(db),
+		userRepo, jwtService, authService, domain.IdentityPolicy{}, 10*time.Minute)
+	passwordResetService := services.NewPasswordResetService(userRepo, persistence.NewPasswordResetTokenRepositorySQL(db), refreshTokenRepo,
+		jwtService, mailer, txManager, "http://localhost/restablecer", time.Hour)
+	blogService := services.NewBlogService(blogRepo, userRepo, revisionRepo, tagRepo, commentRepo, authorizer, txManager, domain.DeletionCascade)
+	commentService := services.NewCommentService(commentRepo, blogRepo, userRepo, revisionRepo, authorizer, filter.NewChain(), txManager, false)
+	searchService := services.NewSearchService(persistence.NewSearchRepositorySQL(db, dialect))
+	tagService := services.NewTagService(tagRepo)
+	trashService := services.NewTrashService(blogRepo, commentRepo, userRepo, txManager, 30*24*time.Hour)
+	roleService := services.NewRoleService(roleRepo)
+
+	authMiddleware := middleware.NewAuthMiddleware(authService, authorizer, middleware.AuthPolicy{})
+	rateLimiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore())
+	healthHandler := handlers.NewHealthHandler(db, time.Second)
+
+	gin.SetMode(gin.TestMode)
+	router := httprouter.NewRouter(userService, authService, passwordResetService, emailVerificationService, twoFactorService, oidcService,
+		blogService, commentService, searchService, tagService, trashService, roleService,
+		tokenKeys, healthHandler, authMiddleware, rateLimiter, httprouter.RateLimits{}, 5*time.Second)
+	return router.SetupRoutes()
+}
+
+// doJSON envía body como JSON a la API y decodifica la respuesta en out, si no es nil
+func doJSON(t *testing.T, server *gin.Engine, method, path, token string, body, out interface{}) int {
+	t.Helper()
+
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("codificando la petición: %v", err)
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, req)
+
+	if out != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decodificando la respuesta %q: %v", method, path, recorder.Body.String(), err)
+		}
+	}
+	return recorder.Code
+}
+
+// register registra un usuario y retorna su ID
+func register(t *testing.T, server *gin.Engine, username string) int64 {
+	t.Helper()
+
+	var resp struct {
+		User domain.User `json:"user"`
+	}
+	body := map[string]string{"username": username, "email": username + "@example.com", "password": "secreto123"}
+	if code := doJSON(t, server, http.MethodPost, "/api/auth/register", "", body, &resp); code != http.StatusCreated {
+		t.Fatalf("registrando %s: estado %d", username, code)
+	}
+	return resp.User.ID
+}
+
+// login inicia sesión y retorna el token de acceso
+func login(t *testing.T, server *gin.Engine, username string) string {
+	t.Helper()
+
+	var resp struct {
+		Token string `json:"token"`
+	}
+	body := map[string]string{"username": username, "password": "secreto123"}
+	if code := doJSON(t, server, http.MethodPost, "/api/auth/login", "", body, &resp); code != http.StatusOK {
+		t.Fatalf("iniciando sesión como %s: estado %d", username, code)
+	}
+	return resp.Token
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	server := newTestServer(t)
+
+	userID := register(t, server, "ana")
+
+	body := map[string]string{"username": "ana", "email": "otra@example.com", "password": "secreto123"}
+	if code := doJSON(t, server, http.MethodPost, "/api/auth/register", "", body, nil); code != http.StatusConflict {
+		t.Errorf("registro duplicado: estado %d, se esperaba %d", code, http.StatusConflict)
+	}
+
+	body = map[string]string{"username": "ana", "password": "incorrecta"}
+	if code := doJSON(t, server, http.MethodPost, "/api/auth/login", "", body, nil); code != http.StatusUnauthorized {
+		t.Errorf("login con contraseña incorrecta: estado %d, se esperaba %d", code, http.StatusUnauthorized)
+	}
+
+	token := login(t, server, "ana")
+
+	var profile domain.User
+	if code := doJSON(t, server, http.MethodGet, "/api/auth/profile", token, nil, &profile); code != http.StatusOK {
+		t.Fatalf("perfil: estado %d", code)
+	}
+	if profile.ID != userID || profile.Username != "ana" {
+		t.Errorf("perfil = %+v, se esperaba el usuario %d", profile, userID)
+	}
+
+	if code := doJSON(t, server, http.MethodGet, "/api/auth/profile", "", nil, nil); code != http.StatusUnauthorized {
+		t.Errorf("perfil sin token: estado %d, se esperaba %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestCreateBlogAndComment(t *testing.T) {
+	server := newTestServer(t)
+
+	authorID := register(t, server, "ana")
+	readerID := register(t, server, "luis")
+	authorToken := login(t, server, "ana")
+	readerToken := login(t, server, "luis")
+
+	blogBody := map[string]interface{}{"title": "Primer blog", "content": "Contenido", "status": domain.BlogStatusPublished}
+	if code := doJSON(t, server, http.MethodPost, "/api/blogs", "", blogBody, nil); code != http.StatusUnauthorized {
+		t.Errorf("blog sin token: estado %d, se esperaba %d", code, http.StatusUnauthorized)
+	}
+
+	var created struct {
+		Blog domain.Blog `json:"blog"`
+	}
+	if code := doJSON(t, server, http.MethodPost, "/api/blogs", authorToken, blogBody, &created); code != http.StatusCreated {
+		t.Fatalf("creando el blog: estado %d", code)
+	}
+	if created.Blog.AuthorID != authorID || created.Blog.Status != domain.BlogStatusPublished {
+		t.Errorf("blog creado = %+v, se esperaba publicado por %d", created.Blog, authorID)
+	}
+
+	var blog domain.Blog
+	blogPath := fmt.Sprintf("/api/blogs/%d", created.Blog.ID)
+	if code := doJSON(t, server, http.MethodGet, blogPath, "", nil, &blog); code != http.StatusOK {
+		t.Fatalf("leyendo el blog: estado %d", code)
+	}
+	if blog.Title != "Primer blog" {
+		t.Errorf("título = %q, se esperaba %q", blog.Title, "Primer blog")
+	}
+
+	var comment struct {
+		Comment domain.Comment `json:"comment"`
+	}
+	commentBody := map[string]string{"content": "Buen artículo"}
+	if code := doJSON(t, server, http.MethodPost, blogPath+"/comments", readerToken, commentBody, &comment); code != http.StatusCreated {
+		t.Fatalf("creando el comentario: estado %d", code)
+	}
+	if comment.Comment.UserID != readerID || comment.Comment.Status != domain.CommentStatusApproved {
+		t.Errorf("comentario creado = %+v, se esperaba aprobado de %d", comment.Comment, readerID)
+	}
+
+	reply := map[string]interface{}{"content": "Gracias", "parent_id": comment.Comment.ID}
+	if code := doJSON(t, server, http.MethodPost, blogPath+"/comments", authorToken, reply, nil); code != http.StatusCreated {
+		t.Fatalf("respondiendo al comentario: estado %d", code)
+	}
+
+	if code := doJSON(t, server, http.MethodPost, "/api/blogs/999/comments", readerToken, commentBody, nil); code != http.StatusNotFound {
+		t.Errorf("comentario en un blog inexistente: estado %d, se esperaba %d", code, http.StatusNotFound)
+	}
+
+	var thread domain.Page[*domain.CommentNode]
+	if code := doJSON(t, server, http.MethodGet, blogPath+"/comments", "", nil, &thread); code != http.StatusOK {
+		t.Fatalf("listando los comentarios: estado %d", code)
+	}
+	if len(thread.Items) != 1 || thread.Items[0].Content != "Buen artículo" {
+		t.Fatalf("hilos = %+v, se esperaba solo el comentario raíz", thread.Items)
+	}
+	if replies := thread.Items[0].Replies; len(replies) != 1 || replies[0].Content != "Gracias" {
+		t.Errorf("respuestas = %+v, se esperaba la respuesta del autor", replies)
+	}
+}
+
+func TestListTagsReportsEmptyTags(t *testing.T) {
+	server := newTestServer(t)
+
+	register(t, server, "ana")
+	token := login(t, server, "ana")
+
+	// La etiqueta de un borrador existe pero no cuenta ningún blog público
+	draft := map[string]interface{}{"title": "Borrador", "content": "Contenido", "status": domain.BlogStatusDraft, "tags": []string{"Go"}}
+	if code := doJSON(t, server, http.MethodPost, "/api/blogs", token, draft, nil); code != http.StatusCreated {
+		t.Fatalf("creando el borrador: estado %d", code)
+	}
+
+	var resp struct {
+		Tags []map[string]interface{} `json:"tags"`
+	}
+	if code := doJSON(t, server, http.MethodGet, "/api/tags", "", nil, &resp); code != http.StatusOK {
+		t.Fatalf("listando las etiquetas: estado %d", code)
+	}
+	tags := resp.Tags
+	if len(tags) != 1 || tags[0]["name"] != "Go" {
+		t.Fatalf("etiquetas = %v, se esperaba solo Go", tags)
+	}
+	if count, ok := tags[0]["post_count"]; !ok || count != float64(0) {
+		t.Errorf("post_count = %v (presente: %v), se esperaba 0", count, ok)
+	}
+}
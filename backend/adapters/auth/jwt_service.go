@@ -2,6 +2,10 @@ package auth
 
 import (
 	"blog-backend/internal/domain"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -11,48 +15,72 @@ import (
 
 // JWTService implementa la interfaz AuthService del dominio
 type JWTService struct {
-	secretKey []byte
+	keys      TokenKeys
+	accessTTL time.Duration
 }
 
 // Claims define la estructura del token JWT
 type Claims struct {
-	UserID   int64        `json:"user_id"`
-	Username string       `json:"username"`
-	Role     domain.Role  `json:"role"`
+	UserID    int64       `json:"user_id"`
+	Username  string      `json:"username"`
+	Role      domain.Role `json:"role"`
+	SessionID string      `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-// NewJWTService crea una nueva instancia del servicio JWT
-func NewJWTService(secretKey string) *JWTService {
+// NewJWTService crea una nueva instancia del servicio JWT que firma y verifica
+// los tokens de acceso con keys
+func NewJWTService(keys TokenKeys, accessTTL time.Duration) *JWTService {
 	return &JWTService{
-		secretKey: []byte(secretKey),
+		keys:      keys,
+		accessTTL: accessTTL,
 	}
 }
 
-// GenerateToken genera un token JWT para un usuario
-func (j *JWTService) GenerateToken(user *domain.User) (string, error) {
+// AccessTokenTTL retorna la duración de los tokens de acceso
+func (j *JWTService) AccessTokenTTL() time.Duration {
+	return j.accessTTL
+}
+
+// GenerateToken genera un token JWT de acceso para un usuario dentro de una sesión
+func (j *JWTService) GenerateToken(user *domain.User, sessionID string) (string, error) {
 	claims := Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+	kid, method, key, err := j.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString(key)
 }
 
-// ValidateToken valida un token JWT y retorna el usuario
-func (j *JWTService) ValidateToken(tokenString string) (*domain.User, error) {
+// ValidateToken valida un token JWT y retorna sus claims
+func (j *JWTService) ValidateToken(tokenString string) (*domain.TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		method, key, err := j.keys.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		// El algoritmo lo decide la clave, no la cabecera del token
+		if token.Method.Alg() != method.Alg() {
 			return nil, errors.New("método de firma inesperado")
 		}
-		return j.secretKey, nil
+		return key, nil
 	})
 
 	if err != nil {
@@ -60,10 +88,11 @@ func (j *JWTService) ValidateToken(tokenString string) (*domain.User, error) {
 	}
 
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return &domain.User{
-			ID:       claims.UserID,
-			Username: claims.Username,
-			Role:     claims.Role,
+		return &domain.TokenClaims{
+			UserID:    claims.UserID,
+			Username:  claims.Username,
+			Role:      claims.Role,
+			SessionID: claims.SessionID,
 		}, nil
 	}
 
@@ -81,3 +110,18 @@ func (j *JWTService) CheckPassword(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// GenerateOpaqueToken genera un token aleatorio de 256 bits codificado en base64 URL
+func (j *JWTService) GenerateOpaqueToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// HashToken calcula el hash SHA-256 de un token opaco para almacenarlo
+func (j *JWTService) HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// HMACLinkSigner firma los enlaces con HMAC-SHA256. El token es el JSON de los
+// datos y la firma, ambos en base64 URL y separados por un punto.
+type HMACLinkSigner struct {
+	secretKey []byte
+}
+
+// signedLinkPayload es la representación de domain.SignedLink dentro del token
+type signedLinkPayload struct {
+	Purpose   string `json:"p"`
+	UserID    int64  `json:"u"`
+	Email     string `json:"e"`
+	ExpiresAt int64  `json:"x"`
+}
+
+// NewHMACLinkSigner crea un firmante de enlaces con la clave indicada
+func NewHMACLinkSigner(secretKey string) ports.LinkSigner {
+	return &HMACLinkSigner{secretKey: []byte(secretKey)}
+}
+
+// Sign firma los datos de un enlace
+func (s *HMACLinkSigner) Sign(link domain.SignedLink) (string, error) {
+	payload, err := json.Marshal(signedLinkPayload{
+		Purpose:   link.Purpose,
+		UserID:    link.UserID,
+		Email:     link.Email,
+		ExpiresAt: link.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded)), nil
+}
+
+// Verify comprueba la firma, la expiración y el propósito de un enlace
+func (s *HMACLinkSigner) Verify(purpose, token string) (*domain.SignedLink, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, domain.ErrInvalidToken
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.mac(encoded)) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	var data signedLinkPayload
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	link := &domain.SignedLink{
+		Purpose:   data.Purpose,
+		UserID:    data.UserID,
+		Email:     data.Email,
+		ExpiresAt: time.Unix(data.ExpiresAt, 0),
+	}
+	if link.Purpose != purpose || !time.Now().Before(link.ExpiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return link, nil
+}
+
+// mac calcula la firma de los datos codificados
+func (s *HMACLinkSigner) mac(encoded string) []byte {
+	h := hmac.New(sha256.New, s.secretKey)
+	h.Write([]byte(encoded))
+	return h.Sum(nil)
+}
@@ -0,0 +1,424 @@
+package auth
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// oidcHTTPTimeout es el tiempo máximo de cada petición al proveedor
+	oidcHTTPTimeout = 10 * time.Second
+	// oidcKeysRefreshInterval es el tiempo mínimo entre dos descargas de las
+	// claves del proveedor cuando un ID token usa una clave desconocida
+	oidcKeysRefreshInterval = time.Minute
+	// oidcClockSkew es la desviación de reloj que se tolera al validar el ID token
+	oidcClockSkew = time.Minute
+)
+
+// oidcSigningMethods son los algoritmos de firma de ID tokens que se aceptan
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// OIDCProvider implementa la interfaz IdentityProvider con el flujo de código
+// de autorización de OpenID Connect y PKCE (S256). Los endpoints y las claves
+// del proveedor se descubren a partir del issuer en el primer uso.
+type OIDCProvider struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	roleClaim    string
+	client       *http.Client
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// oidcDiscovery son los campos usados del documento de descubrimiento del proveedor
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// jsonWebKey es una clave pública del proveedor en formato JWK (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// NewOIDCProvider crea un proveedor OpenID Connect. name identifica al proveedor
+// en las identidades vinculadas, redirectURL debe estar registrada en el proveedor
+// y roleClaim es el claim del ID token con los valores de la asignación de roles.
+// Sin clientSecret el cliente se identifica como cliente público.
+func NewOIDCProvider(name, issuer, clientID, clientSecret, redirectURL string, scopes []string, roleClaim string) ports.IdentityProvider {
+	if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	return &OIDCProvider{
+		name:         name,
+		issuer:       issuer,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       scopes,
+		roleClaim:    roleClaim,
+		client:       &http.Client{Timeout: oidcHTTPTimeout},
+	}
+}
+
+// Name retorna el nombre del proveedor
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL construye la URL de autorización con el desafío PKCE derivado de codeVerifier
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: endpoint de autorización inválido: %v", domain.ErrIdentityProvider, err)
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.clientID)
+	query.Set("redirect_uri", p.redirectURL)
+	query.Set("scope", strings.Join(p.scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange canjea el código de autorización en el endpoint de tokens y verifica
+// la firma, el emisor, la audiencia, la expiración y el nonce del ID token
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*domain.IdentityClaims, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.redirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if p.clientSecret == "" {
+		form.Set("client_id", p.clientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrIdentityProvider, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrIdentityProvider, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: respuesta de tokens inválida: %v", domain.ErrIdentityProvider, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// Un código inválido, caducado o con otro code_verifier es un error del cliente
+		if body.Error == "invalid_grant" {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("%w: el endpoint de tokens respondió %d %s", domain.ErrIdentityProvider, resp.StatusCode, body.Error)
+	}
+
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("%w: la respuesta de tokens no incluye id_token", domain.ErrIdentityProvider)
+	}
+
+	return p.verifyIDToken(ctx, body.IDToken, nonce)
+}
+
+// verifyIDToken valida un ID token y extrae sus claims
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, idToken, nonce string) (*domain.IdentityClaims, error) {
+	claims := jwt.MapClaims{}
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		return p.signingKey(ctx, token)
+	}
+	_, err := jwt.ParseWithClaims(idToken, claims, keyFunc,
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+	if err != nil {
+		if errors.Is(err, domain.ErrIdentityProvider) {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidToken
+	}
+
+	// Con varias audiencias el token debe estar emitido para este cliente
+	if azp, ok := claims["azp"].(string); ok && azp != p.clientID {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, domain.ErrInvalidToken
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, domain.ErrInvalidToken
+	}
+
+	identity := &domain.IdentityClaims{Subject: subject}
+	identity.Email, _ = claims["email"].(string)
+	identity.PreferredUsername, _ = claims["preferred_username"].(string)
+
+	// Algunos proveedores envían email_verified como texto
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = verified
+	case string:
+		identity.EmailVerified = verified == "true"
+	}
+
+	switch roles := claims[p.roleClaim].(type) {
+	case string:
+		identity.Roles = []string{roles}
+	case []interface{}:
+		for _, role := range roles {
+			if value, ok := role.(string); ok {
+				identity.Roles = append(identity.Roles, value)
+			}
+		}
+	}
+
+	return identity, nil
+}
+
+// signingKey busca la clave pública con la que se firmó el ID token. Si el kid
+// es desconocido vuelve a descargar las claves, por si el proveedor las rotó.
+func (p *OIDCProvider) signingKey(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+
+	if time.Since(p.keysFetchedAt) < oidcKeysRefreshInterval {
+		return nil, errors.New("clave de firma desconocida")
+	}
+
+	if err := p.fetchKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, errors.New("clave de firma desconocida")
+}
+
+// lookupKey busca una clave por su kid. Un token sin kid solo se acepta si el
+// proveedor publica una única clave. Requiere p.mu.
+func (p *OIDCProvider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" {
+		if len(p.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// fetchKeys descarga las claves públicas del proveedor. Requiere p.mu.
+func (p *OIDCProvider) fetchKeys(ctx context.Context) error {
+	discovery, err := p.discoverLocked(ctx)
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, discovery.JWKSURI, &set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Las claves de tipos o curvas no soportados se ignoran
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	return nil
+}
+
+// discover obtiene el documento de descubrimiento del proveedor
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.discoverLocked(ctx)
+}
+
+// discoverLocked obtiene el documento de descubrimiento la primera vez y lo
+// reutiliza después. Requiere p.mu.
+func (p *OIDCProvider) discoverLocked(ctx context.Context) (*oidcDiscovery, error) {
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	discovery := &oidcDiscovery{}
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.issuer, "/")+"/.well-known/openid-configuration", discovery); err != nil {
+		return nil, err
+	}
+
+	if discovery.Issuer != p.issuer {
+		return nil, fmt.Errorf("%w: el issuer descubierto %q no coincide con %q", domain.ErrIdentityProvider, discovery.Issuer, p.issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: el documento de descubrimiento está incompleto", domain.ErrIdentityProvider)
+	}
+
+	p.discovery = discovery
+	return discovery, nil
+}
+
+// getJSON descarga y decodifica un documento JSON del proveedor
+func (p *OIDCProvider) getJSON(ctx context.Context, rawURL string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrIdentityProvider, err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrIdentityProvider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s respondió %d", domain.ErrIdentityProvider, rawURL, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("%w: respuesta inválida de %s: %v", domain.ErrIdentityProvider, rawURL, err)
+	}
+	return nil
+}
+
+// publicKey convierte una clave JWK RSA o EC en una clave pública
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+			return nil, errors.New("exponente RSA inválido")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curva no soportada: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("tipo de clave no soportado: %s", k.Kty)
+	}
+}
+
+// decodeBigInt decodifica un entero en base64 URL sin relleno
+func decodeBigInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(bytes) == 0 {
+		return nil, errors.New("entero de clave inválido")
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"blog-backend/internal/domain"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// stubProvider es un proveedor OpenID Connect mínimo para las pruebas: publica
+// el documento de descubrimiento y sus claves, y canjea un único código
+// verificando el code_verifier PKCE contra el desafío de la URL de autorización.
+type stubProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu sync.Mutex
+	// challenge es el code_challenge registrado para code
+	code      string
+	challenge string
+	// claims son los claims del ID token que emite el endpoint de tokens
+	claims jwt.MapClaims
+	// signingKey firma el ID token; por defecto key
+	signingKey *rsa.PrivateKey
+	// issuer sobrescribe el issuer del documento de descubrimiento
+	issuer string
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generando clave RSA: %v", err)
+	}
+
+	stub := &stubProvider{t: t, key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", stub.handleDiscovery)
+	mux.HandleFunc("/jwks", stub.handleJWKS)
+	mux.HandleFunc("/token", stub.handleToken)
+	stub.server = httptest.NewServer(mux)
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func (s *stubProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	issuer := s.issuer
+	s.mu.Unlock()
+	if issuer == "" {
+		issuer = s.server.URL
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 issuer,
+		"authorization_endpoint": s.server.URL + "/authorize",
+		"token_endpoint":         s.server.URL + "/token",
+		"jwks_uri":               s.server.URL + "/jwks",
+	})
+}
+
+func (s *stubProvider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "stub-key",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (s *stubProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		s.t.Errorf("formulario de tokens inválido: %v", err)
+	}
+
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("code") != s.code ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != s.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	signingKey := s.signingKey
+	if signingKey == nil {
+		signingKey = s.key
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, s.claims)
+	token.Header["kid"] = "stub-key"
+	idToken, err := token.SignedString(signingKey)
+	if err != nil {
+		s.t.Errorf("firmando ID token: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"access_token": "stub-access-token",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+// authorize simula que el usuario se autentica en el proveedor: registra el
+// desafío PKCE de la URL de autorización y retorna el código emitido
+func (s *stubProvider) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("URL de autorización inválida: %v", err)
+	}
+	if parsed.Query().Get("code_challenge_method") != "S256" {
+		t.Fatalf("code_challenge_method = %q, se esperaba S256", parsed.Query().Get("code_challenge_method"))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.code = "stub-code"
+	s.challenge = parsed.Query().Get("code_challenge")
+	return s.code
+}
+
+// validClaims retorna los claims de un ID token válido para el cliente de las pruebas
+func (s *stubProvider) validClaims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":                s.server.URL,
+		"aud":                "blog",
+		"sub":                "user-123",
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"iat":                now.Unix(),
+		"nonce":              nonce,
+		"email":              "ana@example.com",
+		"email_verified":     true,
+		"preferred_username": "ana",
+		"groups":             []string{"staff", "blog-admins"},
+	}
+}
+
+func newTestOIDCProvider(stub *stubProvider) *OIDCProvider {
+	return NewOIDCProvider("corp", stub.server.URL, "blog", "secret", "http://localhost:4200/oidc/callback",
+		[]string{"email", "profile"}, "groups").(*OIDCProvider)
+}
+
+func TestOIDCProviderAuthCodeURL(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := newTestOIDCProvider(stub)
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("URL de autorización inválida: %v", err)
+	}
+	challenge := sha256.Sum256([]byte("verifier-1"))
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "blog",
+		"redirect_uri":          "http://localhost:4200/oidc/callback",
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(challenge[:]),
+		"code_challenge_method": "S256",
+	}
+	if parsed.Path != "/authorize" {
+		t.Errorf("path = %q, se esperaba /authorize", parsed.Path)
+	}
+	for param, value := range want {
+		if got := parsed.Query().Get(param); got != value {
+			t.Errorf("%s = %q, se esperaba %q", param, got, value)
+		}
+	}
+	if parsed.Query().Has("code_verifier") {
+		t.Error("el code_verifier no debe viajar en la URL de autorización")
+	}
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := newTestOIDCProvider(stub)
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code := stub.authorize(t, authURL)
+	stub.claims = stub.validClaims("nonce-1")
+
+	claims, err := provider.Exchange(ctx, code, "verifier-1", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	if claims.Subject != "user-123" || claims.Email != "ana@example.com" || !claims.EmailVerified || claims.PreferredUsername != "ana" {
+		t.Errorf("claims inesperados: %+v", claims)
+	}
+	if len(claims.Roles) != 2 || claims.Roles[0] != "staff" || claims.Roles[1] != "blog-admins" {
+		t.Errorf("roles = %v, se esperaba [staff blog-admins]", claims.Roles)
+	}
+}
+
+func TestOIDCProviderExchangeRejectsInvalidTokens(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generando clave RSA: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		verifier string
+		modify   func(stub *stubProvider, claims jwt.MapClaims)
+	}{
+		{
+			name:     "code_verifier distinto",
+			verifier: "otro-verifier",
+		},
+		{
+			name:   "nonce distinto",
+			modify: func(_ *stubProvider, claims jwt.MapClaims) { claims["nonce"] = "otro-nonce" },
+		},
+		{
+			name:   "otra audiencia",
+			modify: func(_ *stubProvider, claims jwt.MapClaims) { claims["aud"] = "otro-cliente" },
+		},
+		{
+			name:   "otro emisor",
+			modify: func(_ *stubProvider, claims jwt.MapClaims) { claims["iss"] = "https://otro.example.com" },
+		},
+		{
+			name: "expirado",
+			modify: func(_ *stubProvider, claims jwt.MapClaims) {
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			},
+		},
+		{
+			name: "emitido para otro cliente",
+			modify: func(_ *stubProvider, claims jwt.MapClaims) {
+				claims["aud"] = []string{"blog", "otro-cliente"}
+				claims["azp"] = "otro-cliente"
+			},
+		},
+		{
+			name:   "sin sub",
+			modify: func(_ *stubProvider, claims jwt.MapClaims) { delete(claims, "sub") },
+		},
+		{
+			name:   "firmado con otra clave",
+			modify: func(stub *stubProvider, _ jwt.MapClaims) { stub.signingKey = otherKey },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newStubProvider(t)
+			provider := newTestOIDCProvider(stub)
+
+			authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+			code := stub.authorize(t, authURL)
+			stub.claims = stub.validClaims("nonce-1")
+			if tt.modify != nil {
+				tt.modify(stub, stub.claims)
+			}
+
+			verifier := tt.verifier
+			if verifier == "" {
+				verifier = "verifier-1"
+			}
+
+			if _, err := provider.Exchange(ctx, code, verifier, "nonce-1"); err != domain.ErrInvalidToken {
+				t.Errorf("Exchange = %v, se esperaba ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestOIDCProviderRejectsIssuerMismatch(t *testing.T) {
+	stub := newStubProvider(t)
+	stub.issuer = "https://otro.example.com"
+	provider := newTestOIDCProvider(stub)
+
+	_, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if !errors.Is(err, domain.ErrIdentityProvider) {
+		t.Errorf("AuthCodeURL = %v, se esperaba ErrIdentityProvider", err)
+	}
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+)
+
+// RoleAuthorizer implementa la interfaz Authorizer con los permisos de cada rol
+// almacenados en la base de datos. Se consultan en cada verificación para que
+// los cambios de un administrador se apliquen de inmediato.
+type RoleAuthorizer struct {
+	roleRepo ports.RoleRepository
+}
+
+// NewRoleAuthorizer crea una nueva instancia del autorizador basado en roles
+func NewRoleAuthorizer(roleRepo ports.RoleRepository) ports.Authorizer {
+	return &RoleAuthorizer{roleRepo: roleRepo}
+}
+
+// Authorize verifica que el rol conceda el permiso. Los visitantes anónimos
+// (rol vacío) no tienen ningún permiso.
+func (a *RoleAuthorizer) Authorize(ctx context.Context, role domain.Role, permission domain.Permission) error {
+	if role == "" {
+		return domain.ErrForbidden
+	}
+
+	definition, err := a.roleRepo.FindByName(ctx, role)
+	if err != nil {
+		if err == domain.ErrRoleNotFound {
+			return domain.ErrForbidden
+		}
+		return err
+	}
+
+	if !definition.Has(permission) {
+		return domain.ErrForbidden
+	}
+
+	return nil
+}
+
+// AuthorizeOwner permite la acción al propietario del recurso; el resto de
+// usuarios necesita el permiso
+func (a *RoleAuthorizer) AuthorizeOwner(ctx context.Context, userID int64, role domain.Role, ownerID int64, permission domain.Permission) error {
+	if userID != 0 && userID == ownerID {
+		return nil
+	}
+	return a.Authorize(ctx, role, permission)
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"blog-backend/internal/ports"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// sealedPrefix identifica los secretos cifrados por AESGCMCipher y la versión
+// del formato. Los valores sin prefijo se guardaron en claro antes de cifrar.
+const sealedPrefix = "v1:"
+
+// errInvalidCiphertext indica un secreto cifrado manipulado o cifrado con otra clave
+var errInvalidCiphertext = errors.New("secreto cifrado inválido")
+
+// AESGCMCipher implementa la interfaz SecretCipher con AES-256-GCM. El valor
+// cifrado es el prefijo de versión seguido del nonce y el texto cifrado en base64.
+type AESGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMCipher crea un cifrador cuya clave es el SHA-256 de secretKey
+func NewAESGCMCipher(secretKey string) (ports.SecretCipher, error) {
+	key := sha256.Sum256([]byte(secretKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCMCipher{aead: aead}, nil
+}
+
+// Encrypt cifra el secreto con un nonce aleatorio
+func (c *AESGCMCipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt descifra un secreto de Encrypt. Los secretos guardados en claro
+// antes de cifrarlos se retornan tal cual.
+func (c *AESGCMCipher) Decrypt(ciphertext string) (string, error) {
+	encoded, ok := strings.CutPrefix(ciphertext, sealedPrefix)
+	if !ok {
+		return ciphertext, nil
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", errInvalidCiphertext
+	}
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", errInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
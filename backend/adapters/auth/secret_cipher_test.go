@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"blog-backend/internal/ports"
+	"strings"
+	"testing"
+)
+
+func TestAESGCMCipher(t *testing.T) {
+	secretCipher, err := NewAESGCMCipher("clave")
+	if err != nil {
+		t.Fatalf("NewAESGCMCipher: %v", err)
+	}
+
+	sealed, err := secretCipher.Encrypt(rfc6238Secret)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !strings.HasPrefix(sealed, sealedPrefix) || strings.Contains(sealed, rfc6238Secret) {
+		t.Fatalf("Encrypt = %q", sealed)
+	}
+	if len(sealed) > 255 {
+		t.Errorf("el secreto cifrado ocupa %d caracteres y no cabe en users.totp_secret", len(sealed))
+	}
+	if again, _ := secretCipher.Encrypt(rfc6238Secret); again == sealed {
+		t.Error("Encrypt no usa un nonce aleatorio")
+	}
+
+	plaintext, err := secretCipher.Decrypt(sealed)
+	if err != nil || plaintext != rfc6238Secret {
+		t.Fatalf("Decrypt = %q, %v", plaintext, err)
+	}
+
+	// Los secretos guardados en claro antes de cifrarlos se siguen leyendo
+	if plaintext, err := secretCipher.Decrypt(rfc6238Secret); err != nil || plaintext != rfc6238Secret {
+		t.Errorf("Decrypt de un secreto en claro = %q, %v", plaintext, err)
+	}
+
+	other, err := NewAESGCMCipher("otra clave")
+	if err != nil {
+		t.Fatalf("NewAESGCMCipher: %v", err)
+	}
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if strings.HasSuffix(sealed, "AA") {
+		tampered = sealed[:len(sealed)-2] + "BB"
+	}
+	invalid := map[string]struct {
+		cipher ports.SecretCipher
+		value  string
+	}{
+		"otra clave":  {cipher: other, value: sealed},
+		"manipulado":  {cipher: secretCipher, value: tampered},
+		"truncado":    {cipher: secretCipher, value: sealedPrefix + "AAAA"},
+		"base64 roto": {cipher: secretCipher, value: sealedPrefix + "%%%"},
+	}
+	for name, tt := range invalid {
+		if _, err := tt.cipher.Decrypt(tt.value); err != errInvalidCiphertext {
+			t.Errorf("%s: Decrypt = %v, se esperaba errInvalidCiphertext", name, err)
+		}
+	}
+}
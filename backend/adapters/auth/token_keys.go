@@ -0,0 +1,347 @@
+package auth
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// rsaKeyBits es el tamaño de las claves RSA generadas
+	rsaKeyBits = 2048
+	// keyReloadInterval es el tiempo mínimo entre dos recargas de las claves
+	// cuando un token usa un kid desconocido
+	keyReloadInterval = time.Minute
+)
+
+// TokenKeys son las claves con las que JWTService firma y verifica los tokens de acceso
+type TokenKeys interface {
+	// SigningKey retorna la clave activa: su kid (vacío si no tiene), el método de firma y la clave privada
+	SigningKey() (string, jwt.SigningMethod, interface{}, error)
+	// VerificationKey retorna el método de firma y la clave que verifican un token firmado con kid
+	VerificationKey(kid string) (jwt.SigningMethod, interface{}, error)
+	// JWKS retorna las claves públicas de verificación
+	JWKS() domain.JSONWebKeySet
+}
+
+// HMACKeys firma los tokens con HS256 y un único secreto compartido. Como el
+// secreto también verifica, no se publica ninguna clave.
+type HMACKeys struct {
+	secretKey []byte
+}
+
+// NewHMACKeys crea las claves HS256 con el secreto indicado
+func NewHMACKeys(secretKey string) TokenKeys {
+	return &HMACKeys{secretKey: []byte(secretKey)}
+}
+
+// SigningKey retorna el secreto compartido
+func (k *HMACKeys) SigningKey() (string, jwt.SigningMethod, interface{}, error) {
+	return "", jwt.SigningMethodHS256, k.secretKey, nil
+}
+
+// VerificationKey retorna el secreto compartido
+func (k *HMACKeys) VerificationKey(kid string) (jwt.SigningMethod, interface{}, error) {
+	return jwt.SigningMethodHS256, k.secretKey, nil
+}
+
+// JWKS retorna un conjunto vacío: el secreto no se puede publicar
+func (k *HMACKeys) JWKS() domain.JSONWebKeySet {
+	return domain.JSONWebKeySet{Keys: []domain.JSONWebKey{}}
+}
+
+// KeyManager gestiona las claves asimétricas (RS256 o EdDSA) guardadas en el
+// repositorio: firma con la más reciente, verifica con todas las que no han
+// caducado y las rota periódicamente. Las claves se comparten entre réplicas a
+// través del repositorio.
+type KeyManager struct {
+	repo             ports.SigningKeyRepository
+	algorithm        string
+	rotationInterval time.Duration
+	retention        time.Duration
+
+	mu      sync.RWMutex
+	signing *managedKey
+	// keys son las claves sin caducar, de la más nueva a la más antigua
+	keys     []*managedKey
+	loadedAt time.Time
+}
+
+// managedKey es una clave de firma decodificada
+type managedKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.Signer
+}
+
+// NewKeyManager crea el gestor de claves y genera la primera clave si no hay
+// ninguna activa. rotationInterval es la antigüedad a partir de la que se rota
+// la clave activa y retention el tiempo que una clave retirada sigue
+// verificando, que debe cubrir la duración de los tokens de acceso.
+func NewKeyManager(ctx context.Context, repo ports.SigningKeyRepository, algorithm string, rotationInterval, retention time.Duration) (*KeyManager, error) {
+	if _, err := signingMethod(algorithm); err != nil {
+		return nil, err
+	}
+
+	m := &KeyManager{
+		repo:             repo,
+		algorithm:        algorithm,
+		rotationInterval: rotationInterval,
+		retention:        retention,
+	}
+	if _, err := m.RotateKeys(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RotateKeys genera una clave nueva si la activa es más antigua que el
+// intervalo de rotación o usa otro algoritmo, retira las demás, elimina las
+// caducadas y recarga las claves, incluidas las que rotaron otras réplicas.
+// Retorna si se generó una clave.
+func (m *KeyManager) RotateKeys(ctx context.Context) (bool, error) {
+	now := time.Now()
+
+	if _, err := m.repo.DeleteExpired(ctx, now); err != nil {
+		return false, err
+	}
+
+	keys, err := m.repo.ListUsable(ctx, now)
+	if err != nil {
+		return false, err
+	}
+
+	active := activeKey(keys)
+	rotated := false
+	if active == nil || active.Algorithm != m.algorithm || now.Sub(active.CreatedAt) >= m.rotationInterval {
+		if active, err = generateSigningKey(m.algorithm); err != nil {
+			return false, err
+		}
+		if err := m.repo.Create(ctx, active); err != nil {
+			return false, err
+		}
+		rotated = true
+	}
+
+	// También se retiran las claves que otra réplica generó a la vez
+	for _, key := range keys {
+		if !key.IsRetired() && key.ID != active.ID {
+			if err := m.repo.Retire(ctx, key.ID, now, now.Add(m.retention)); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return rotated, m.reload(ctx)
+}
+
+// reload carga las claves sin caducar del repositorio
+func (m *KeyManager) reload(ctx context.Context) error {
+	keys, err := m.repo.ListUsable(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	decodedKeys := make([]*managedKey, 0, len(keys))
+	var signing *managedKey
+	for _, key := range keys {
+		decoded, err := decodeSigningKey(key)
+		if err != nil {
+			return fmt.Errorf("error decodificando la clave de firma %s: %w", key.ID, err)
+		}
+		decodedKeys = append(decodedKeys, decoded)
+
+		// Las claves vienen de la más nueva a la más antigua
+		if signing == nil && !key.IsRetired() && key.Algorithm == m.algorithm {
+			signing = decoded
+		}
+	}
+
+	if signing == nil {
+		return errors.New("no hay ninguna clave de firma activa")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.signing = signing
+	m.keys = decodedKeys
+	m.loadedAt = time.Now()
+	return nil
+}
+
+// SigningKey retorna la clave activa
+func (m *KeyManager) SigningKey() (string, jwt.SigningMethod, interface{}, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.signing.id, m.signing.method, m.signing.private, nil
+}
+
+// VerificationKey retorna la clave pública de kid. Si no la conoce recarga las
+// claves, por si otra réplica acaba de rotarlas.
+func (m *KeyManager) VerificationKey(kid string) (jwt.SigningMethod, interface{}, error) {
+	if key, ok := m.lookup(kid); ok {
+		return key.method, key.private.Public(), nil
+	}
+
+	m.mu.RLock()
+	stale := time.Since(m.loadedAt) >= keyReloadInterval
+	m.mu.RUnlock()
+
+	// La validación de tokens no recibe contexto, la recarga no se cancela
+	if stale {
+		if err := m.reload(context.Background()); err != nil {
+			return nil, nil, err
+		}
+		if key, ok := m.lookup(kid); ok {
+			return key.method, key.private.Public(), nil
+		}
+	}
+
+	return nil, nil, errors.New("clave de firma desconocida")
+}
+
+// lookup busca una clave de verificación cargada por su kid
+func (m *KeyManager) lookup(kid string) (*managedKey, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, key := range m.keys {
+		if key.id == kid {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// JWKS retorna las claves públicas de todas las claves sin caducar
+func (m *KeyManager) JWKS() domain.JSONWebKeySet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := domain.JSONWebKeySet{Keys: make([]domain.JSONWebKey, 0, len(m.keys))}
+	for _, key := range m.keys {
+		set.Keys = append(set.Keys, key.jwk())
+	}
+	return set
+}
+
+// jwk convierte la clave pública en formato JWK
+func (k *managedKey) jwk() domain.JSONWebKey {
+	jwk := domain.JSONWebKey{Kid: k.id, Use: "sig", Alg: k.method.Alg()}
+
+	switch public := k.private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// activeKey retorna la clave sin retirar más reciente; keys viene ordenado de la más nueva a la más antigua
+func activeKey(keys []domain.SigningKey) *domain.SigningKey {
+	for i := range keys {
+		if !keys[i].IsRetired() {
+			return &keys[i]
+		}
+	}
+	return nil
+}
+
+// signingMethod retorna el método de firma de un algoritmo asimétrico soportado
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case domain.SigningAlgorithmRS256:
+		return jwt.SigningMethodRS256, nil
+	case domain.SigningAlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("algoritmo de firma no soportado: %q", algorithm)
+	}
+}
+
+// generateSigningKey genera una clave nueva con un kid aleatorio
+func generateSigningKey(algorithm string) (*domain.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case domain.SigningAlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case domain.SigningAlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("algoritmo de firma no soportado: %q", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &domain.SigningKey{
+		ID:         base64.RawURLEncoding.EncodeToString(id),
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	}, nil
+}
+
+// decodeSigningKey decodifica la clave privada PEM de una clave guardada
+func decodeSigningKey(key domain.SigningKey) (*managedKey, error) {
+	method, err := signingMethod(key.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, errors.New("PEM inválido")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var private crypto.Signer
+	switch parsed := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.Algorithm != domain.SigningAlgorithmRS256 {
+			return nil, errors.New("la clave no corresponde al algoritmo")
+		}
+		private = parsed
+	case ed25519.PrivateKey:
+		if key.Algorithm != domain.SigningAlgorithmEdDSA {
+			return nil, errors.New("la clave no corresponde al algoritmo")
+		}
+		private = parsed
+	default:
+		return nil, errors.New("tipo de clave no soportado")
+	}
+
+	return &managedKey{id: key.ID, method: method, private: private}, nil
+}
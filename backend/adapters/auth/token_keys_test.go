@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ctx es el contexto de las llamadas de los tests del paquete
+var ctx = context.Background()
+
+// memorySigningKeyRepo guarda las claves de firma en memoria
+type memorySigningKeyRepo struct {
+	mu   sync.Mutex
+	keys []domain.SigningKey
+}
+
+func (r *memorySigningKeyRepo) Create(_ context.Context, key *domain.SigningKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key.CreatedAt = time.Now()
+	r.keys = append(r.keys, *key)
+	return nil
+}
+
+func (r *memorySigningKeyRepo) ListUsable(_ context.Context, now time.Time) ([]domain.SigningKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// De la más nueva a la más antigua
+	var keys []domain.SigningKey
+	for i := len(r.keys) - 1; i >= 0; i-- {
+		if key := r.keys[i]; key.ExpiresAt == nil || key.ExpiresAt.After(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *memorySigningKeyRepo) Retire(_ context.Context, id string, retiredAt, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.keys {
+		if r.keys[i].ID == id && r.keys[i].RetiredAt == nil {
+			r.keys[i].RetiredAt = &retiredAt
+			r.keys[i].ExpiresAt = &expiresAt
+		}
+	}
+	return nil
+}
+
+func (r *memorySigningKeyRepo) DeleteExpired(_ context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var kept []domain.SigningKey
+	for _, key := range r.keys {
+		if key.ExpiresAt == nil || key.ExpiresAt.After(now) {
+			kept = append(kept, key)
+		}
+	}
+	deleted := int64(len(r.keys) - len(kept))
+	r.keys = kept
+	return deleted, nil
+}
+
+func TestKeyManagerRotation(t *testing.T) {
+	for _, algorithm := range []string{domain.SigningAlgorithmRS256, domain.SigningAlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			repo := &memorySigningKeyRepo{}
+			// Con intervalo cero cada comprobación rota la clave
+			manager, err := NewKeyManager(ctx, repo, algorithm, 0, time.Hour)
+			if err != nil {
+				t.Fatalf("NewKeyManager: %v", err)
+			}
+			jwtService := NewJWTService(manager, time.Minute)
+			user := &domain.User{ID: 7, Username: "ana", Role: domain.RoleUser}
+
+			oldToken, err := jwtService.GenerateToken(user, "sesion-1")
+			if err != nil {
+				t.Fatalf("GenerateToken: %v", err)
+			}
+			oldKid, _, _, _ := manager.SigningKey()
+
+			rotated, err := manager.RotateKeys(ctx)
+			if err != nil || !rotated {
+				t.Fatalf("RotateKeys = %v, %v; se esperaba una rotación", rotated, err)
+			}
+			newKid, _, _, _ := manager.SigningKey()
+			if newKid == oldKid {
+				t.Fatal("la clave activa no cambió tras la rotación")
+			}
+
+			// Los tokens firmados con la clave retirada siguen siendo válidos
+			claims, err := jwtService.ValidateToken(oldToken)
+			if err != nil {
+				t.Fatalf("ValidateToken(token anterior): %v", err)
+			}
+			if claims.UserID != 7 || claims.SessionID != "sesion-1" {
+				t.Errorf("claims inesperados: %+v", claims)
+			}
+
+			newToken, err := jwtService.GenerateToken(user, "sesion-1")
+			if err != nil {
+				t.Fatalf("GenerateToken: %v", err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified: %v", err)
+			}
+			if parsed.Header["kid"] != newKid || parsed.Method.Alg() != algorithm {
+				t.Errorf("cabecera = %v, se esperaba kid %q y alg %q", parsed.Header, newKid, algorithm)
+			}
+
+			jwks := manager.JWKS()
+			if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != newKid || jwks.Keys[1].Kid != oldKid {
+				t.Errorf("JWKS = %+v, se esperaban las claves %q y %q", jwks.Keys, newKid, oldKid)
+			}
+		})
+	}
+}
+
+func TestKeyManagerKeepsFreshKey(t *testing.T) {
+	repo := &memorySigningKeyRepo{}
+	manager, err := NewKeyManager(ctx, repo, domain.SigningAlgorithmEdDSA, 24*time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+
+	rotated, err := manager.RotateKeys(ctx)
+	if err != nil || rotated {
+		t.Errorf("RotateKeys = %v, %v; la clave todavía no debía rotar", rotated, err)
+	}
+
+	// Otra réplica con el mismo repositorio reutiliza la clave activa
+	other, err := NewKeyManager(ctx, repo, domain.SigningAlgorithmEdDSA, 24*time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+	kid, _, _, _ := manager.SigningKey()
+	otherKid, _, _, _ := other.SigningKey()
+	if kid != otherKid {
+		t.Errorf("las réplicas firman con claves distintas: %q y %q", kid, otherKid)
+	}
+}
+
+func TestJWTServiceRejectsForeignTokens(t *testing.T) {
+	manager, err := NewKeyManager(ctx, &memorySigningKeyRepo{}, domain.SigningAlgorithmRS256, 24*time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+	jwtService := NewJWTService(manager, time.Minute)
+	kid, _, _, _ := manager.SigningKey()
+
+	claims := Claims{
+		UserID: 7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+
+	// Un token HS256 con el kid de la clave RSA no se acepta
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hmacToken.Header["kid"] = kid
+	signed, err := hmacToken.SignedString([]byte("secreto"))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	if _, err := jwtService.ValidateToken(signed); err == nil {
+		t.Error("se aceptó un token HS256 con el kid de una clave RS256")
+	}
+
+	// Ni uno firmado por otra instancia
+	other, err := NewKeyManager(ctx, &memorySigningKeyRepo{}, domain.SigningAlgorithmRS256, 24*time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("NewKeyManager: %v", err)
+	}
+	foreign, err := NewJWTService(other, time.Minute).GenerateToken(&domain.User{ID: 7}, "sesion-1")
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if _, err := jwtService.ValidateToken(foreign); err == nil {
+		t.Error("se aceptó un token firmado con una clave desconocida")
+	}
+}
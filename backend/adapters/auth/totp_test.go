@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret es el secreto SHA-1 de los vectores de prueba del RFC 6238
+// ("12345678901234567890") codificado en base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPServiceValidateRFC6238(t *testing.T) {
+	// Los vectores del RFC usan 8 dígitos; con 6 son sus últimos 6 dígitos
+	tests := []struct {
+		unix int64
+		code string
+		step int64
+	}{
+		{unix: 59, code: "287082", step: 1},
+		{unix: 1111111109, code: "081804", step: 37037036},
+		{unix: 1111111111, code: "050471", step: 37037037},
+		{unix: 1234567890, code: "005924", step: 41152263},
+		{unix: 2000000000, code: "279037", step: 66666666},
+		{unix: 20000000000, code: "353130", step: 666666666},
+	}
+
+	totp := NewTOTPService("Blog")
+	for _, tt := range tests {
+		step, ok := totp.Validate(rfc6238Secret, tt.code, time.Unix(tt.unix, 0))
+		if !ok || step != tt.step {
+			t.Errorf("Validate(%q, %d) = %d, %v; se esperaba %d", tt.code, tt.unix, step, ok, tt.step)
+		}
+		// El secreto también se acepta en minúsculas
+		if _, ok := totp.Validate(strings.ToLower(rfc6238Secret), tt.code, time.Unix(tt.unix, 0)); !ok {
+			t.Errorf("Validate con el secreto en minúsculas rechaza %q", tt.code)
+		}
+	}
+}
+
+func TestTOTPServiceValidateSkew(t *testing.T) {
+	totp := NewTOTPService("Blog")
+	// El código 050471 corresponde al intervalo 37037037 (de 1111111110 a 1111111139)
+	tests := []struct {
+		name string
+		unix int64
+		ok   bool
+	}{
+		{name: "intervalo actual", unix: 1111111111, ok: true},
+		{name: "un intervalo después", unix: 1111111140, ok: true},
+		{name: "un intervalo antes", unix: 1111111109, ok: true},
+		{name: "dos intervalos después", unix: 1111111170, ok: false},
+		{name: "dos intervalos antes", unix: 1111111079, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := totp.Validate(rfc6238Secret, "050471", time.Unix(tt.unix, 0))
+			if ok != tt.ok {
+				t.Fatalf("Validate = %v, se esperaba %v", ok, tt.ok)
+			}
+			// El intervalo retornado es el del código, no el actual
+			if ok && step != 37037037 {
+				t.Errorf("intervalo = %d", step)
+			}
+		})
+	}
+}
+
+func TestTOTPServiceValidateInvalid(t *testing.T) {
+	totp := NewTOTPService("Blog")
+	now := time.Unix(59, 0)
+	tests := map[string]struct{ secret, code string }{
+		"código incorrecto":   {secret: rfc6238Secret, code: "287083"},
+		"código corto":        {secret: rfc6238Secret, code: "28708"},
+		"código de 8 dígitos": {secret: rfc6238Secret, code: "94287082"},
+		"secreto inválido":    {secret: "no-es-base32!", code: "287082"},
+		"secreto vacío":       {secret: "", code: "287082"},
+	}
+	for name, tt := range tests {
+		if _, ok := totp.Validate(tt.secret, tt.code, now); ok {
+			t.Errorf("%s: Validate aceptó el código", name)
+		}
+	}
+}
+
+func TestTOTPServiceGenerateSecret(t *testing.T) {
+	totp := NewTOTPService("Blog")
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	key, err := base32NoPadding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Fatalf("secreto %q: %d bytes, %v", secret, len(key), err)
+	}
+	if other, _ := totp.GenerateSecret(); other == secret {
+		t.Error("GenerateSecret repitió el secreto")
+	}
+
+	// El código del intervalo actual del secreto generado es válido
+	now := time.Now()
+	code := totpCode(key, now.Unix()/30)
+	if _, ok := totp.Validate(secret, code, now); !ok {
+		t.Errorf("Validate rechaza el código %q del secreto generado", code)
+	}
+}
+
+func TestTOTPServiceProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(NewTOTPService("Mi Blog").ProvisioningURI(rfc6238Secret, "ana@example.com"))
+	if err != nil {
+		t.Fatalf("URI inválida: %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Mi Blog:ana@example.com" {
+		t.Errorf("URI = %s", uri)
+	}
+	query := uri.Query()
+	if query.Get("secret") != rfc6238Secret || query.Get("issuer") != "Mi Blog" || query.Get("algorithm") != "SHA1" ||
+		query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("parámetros = %v", query)
+	}
+}
+
+func TestTOTPServiceGenerateRecoveryCode(t *testing.T) {
+	totp := NewTOTPService("Blog")
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		code, err := totp.GenerateRecoveryCode()
+		if err != nil {
+			t.Fatalf("GenerateRecoveryCode: %v", err)
+		}
+		if len(code) != 11 || code[5] != '-' || strings.ToLower(code) != code {
+			t.Fatalf("código con formato inválido: %q", code)
+		}
+		if seen[code] {
+			t.Fatalf("código repetido: %q", code)
+		}
+		seen[code] = true
+	}
+}
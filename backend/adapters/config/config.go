@@ -1,55 +1,415 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// DefaultJWTSecret es el secreto de desarrollo que se usa si no se define JWT_SECRET_KEY
+const DefaultJWTSecret = "your-secret-key-change-in-production"
+
+// EnvironmentProduction es el valor de APP_ENV de los despliegues en producción
+const EnvironmentProduction = "production"
+
 // Config contiene toda la configuración de la aplicación
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Bootstrap BootstrapConfig
+	Scheduler SchedulerConfig
+	Comments  CommentsConfig
+	Deletion  DeletionConfig
+	RateLimit RateLimitConfig
+	Login     LoginConfig
+	Mail      MailConfig
+
+	EmailVerification EmailVerificationConfig
+	TwoFactor         TwoFactorConfig
+	OIDC              OIDCConfig
 }
 
 // ServerConfig contiene la configuración del servidor
 type ServerConfig struct {
 	Port string
 	Host string
+	// Environment es el entorno de ejecución (APP_ENV); en producción se
+	// rechazan los secretos por defecto
+	Environment string
+	// TrustedProxies son los proxies de los que se acepta X-Forwarded-For para
+	// obtener la IP del cliente (vacío: se usa la IP de la conexión)
+	TrustedProxies []string
+
+	// ReadTimeout es el tiempo máximo para leer una petición completa
+	ReadTimeout time.Duration
+	// WriteTimeout es el tiempo máximo para responder una petición; debe
+	// superar a DatabaseConfig.QueryTimeout
+	WriteTimeout time.Duration
+	// IdleTimeout es el tiempo que se mantiene abierta una conexión inactiva
+	IdleTimeout time.Duration
+	// ShutdownTimeout es el tiempo que se espera a que terminen las peticiones
+	// en curso al detener el servidor
+	ShutdownTimeout time.Duration
+	// ShutdownDelay es el tiempo que la sonda de disponibilidad falla antes de
+	// dejar de aceptar conexiones, para que el balanceador deje de enviar peticiones
+	ShutdownDelay time.Duration
 }
 
 // DatabaseConfig contiene la configuración de la base de datos
 type DatabaseConfig struct {
+	// Driver es el motor de base de datos: mysql (MySQL/MariaDB) o sqlite
+	Driver string
+	// Path es el archivo de la base de datos SQLite (":memory:" para una base
+	// temporal); las demás opciones de conexión solo se usan con MySQL
+	Path string
+
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
+
+	// AutoMigrate aplica las migraciones pendientes al iniciar el servidor
+	AutoMigrate bool
+	// MigrationLockTimeout es el tiempo máximo de espera por el bloqueo de migraciones
+	MigrationLockTimeout time.Duration
+	// QueryTimeout es el tiempo máximo que una petición HTTP puede pasar
+	// consultando la base de datos; 0 no limita
+	QueryTimeout time.Duration
+	// PingTimeout es el tiempo máximo de respuesta de la base de datos en la
+	// sonda de disponibilidad
+	PingTimeout time.Duration
+
+	// MaxOpenConns es el máximo de conexiones abiertas del pool (0 no limita)
+	MaxOpenConns int
+	// MaxIdleConns es el máximo de conexiones inactivas que conserva el pool
+	MaxIdleConns int
+	// ConnMaxLifetime es el tiempo tras el que se renueva una conexión (0 no la renueva)
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime es el tiempo tras el que se cierra una conexión inactiva (0 no la cierra)
+	ConnMaxIdleTime time.Duration
 }
 
 // JWTConfig contiene la configuración de JWT
 type JWTConfig struct {
-	SecretKey string
+	// SigningAlgorithm es RS256 o EdDSA (claves rotadas guardadas en la base de
+	// datos) o HS256 (SecretKey)
+	SigningAlgorithm string
+	SecretKey        string
+	// KeyRotationInterval es la antigüedad a partir de la que se rota la clave de firma
+	KeyRotationInterval time.Duration
+	// KeyCheckInterval es cada cuánto se comprueba si toca rotar la clave
+	KeyCheckInterval time.Duration
+	// AccessTokenTTL es la duración de los tokens de acceso
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL es la duración de los tokens de refresco
+	RefreshTokenTTL time.Duration
+}
+
+// BootstrapConfig contiene las credenciales del primer administrador.
+// Solo se usan si todavía no existe ningún administrador.
+type BootstrapConfig struct {
+	AdminUsername string
+	AdminPassword string
+}
+
+// SchedulerConfig contiene la configuración de las tareas en segundo plano
+type SchedulerConfig struct {
+	// PublishInterval es cada cuánto se publican los blogs programados
+	PublishInterval time.Duration
+	// PurgeInterval es cada cuánto se vacían los elementos caducados de la papelera
+	PurgeInterval time.Duration
+	// TrashRetention es el tiempo que un elemento permanece en la papelera
+	TrashRetention time.Duration
+}
+
+// CommentsConfig contiene la configuración de los comentarios
+type CommentsConfig struct {
+	// ModerateAll deja pendientes de aprobación los comentarios nuevos de todos los blogs
+	ModerateAll bool
+
+	// MaxLinks es el número de enlaces a partir del cual un comentario queda pendiente (negativo lo desactiva)
+	MaxLinks int
+	// BannedWords son las palabras o expresiones prohibidas
+	BannedWords []string
+	// BannedWordsAction es lo que se hace con los comentarios que las contienen ("hold" o "reject")
+	BannedWordsAction string
+	// DuplicateWindow es el periodo en el que se rechaza repetir un comentario (cero lo desactiva)
+	DuplicateWindow time.Duration
+	// NewAccountAge es la antigüedad por debajo de la cual una cuenta se considera nueva (cero lo desactiva)
+	NewAccountAge time.Duration
+	// NewAccountMaxPerHour es el máximo de comentarios por hora de una cuenta nueva
+	NewAccountMaxPerHour int
+}
+
+// DeletionConfig contiene las políticas de eliminación de contenido
+type DeletionConfig struct {
+	// UserPolicy es lo que ocurre con los blogs y comentarios de un usuario al
+	// eliminarlo ("cascade", "reassign" o "block")
+	UserPolicy string
+	// BlogPolicy es lo que ocurre con los comentarios de un blog al eliminarlo ("cascade" o "block")
+	BlogPolicy string
+}
+
+// RateLimitPolicy permite como máximo Requests peticiones seguidas, que se
+// recuperan a lo largo de Period. Requests <= 0 desactiva el límite.
+type RateLimitPolicy struct {
+	Requests int
+	Period   time.Duration
+}
+
+// RateLimitConfig contiene los límites de peticiones de cada tipo de ruta
+type RateLimitConfig struct {
+	Enabled bool
+	// Login, Register, PasswordReset, VerifyEmail, Refresh y Search se limitan por IP
+	Login         RateLimitPolicy
+	Register      RateLimitPolicy
+	PasswordReset RateLimitPolicy
+	VerifyEmail   RateLimitPolicy
+	Refresh       RateLimitPolicy
+	Search        RateLimitPolicy
+	// Write se aplica por usuario a todas las rutas autenticadas que modifican datos
+	Write RateLimitPolicy
+	// Comment se aplica por usuario a la creación de comentarios, además de Write
+	Comment RateLimitPolicy
+}
+
+// LoginConfig contiene la protección contra ataques de fuerza bruta en el inicio de sesión
+type LoginConfig struct {
+	// MaxAccountFailures fallos seguidos bloquean la cuenta durante LockoutDuration
+	MaxAccountFailures int
+	LockoutDuration    time.Duration
+	// MaxIPFailures fallos desde una IP dentro de FailureWindow bloquean la IP
+	MaxIPFailures int
+	FailureWindow time.Duration
+	// A partir de DelayAfter fallos se exige esperar BaseDelay, duplicándose hasta MaxDelay
+	DelayAfter int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// MailConfig contiene el envío de correos y el restablecimiento de contraseña
+type MailConfig struct {
+	// Driver es smtp, file (un archivo .eml por correo en FileDir) o log
+	Driver string
+	From   string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	FileDir string
+
+	// PasswordResetURL es la página del frontend que recibe el token de restablecimiento
+	PasswordResetURL string
+	PasswordResetTTL time.Duration
+}
+
+// EmailVerificationConfig contiene la verificación del correo de las cuentas nuevas
+type EmailVerificationConfig struct {
+	// Required impide crear blogs y comentarios a los usuarios sin correo verificado
+	Required bool
+	// Secret firma los enlaces de verificación y los desafíos del login en dos
+	// pasos; por defecto es JWT_SECRET_KEY
+	Secret         string
+	URL            string
+	LinkTTL        time.Duration
+	ResendCooldown time.Duration
+}
+
+// TwoFactorConfig contiene la autenticación en dos pasos con TOTP
+type TwoFactorConfig struct {
+	// Issuer es el nombre de la cuenta que muestran las aplicaciones de autenticación
+	Issuer string
+	// RequireForAdmins impide a los administradores usar la API hasta activarla
+	RequireForAdmins bool
+	// ChallengeTTL es la validez del desafío entre los dos pasos del login
+	ChallengeTTL time.Duration
+	// EncryptionKey cifra los secretos TOTP guardados; por defecto es JWT_SECRET_KEY
+	EncryptionKey string
+}
+
+// OIDCConfig contiene el inicio de sesión con un proveedor OpenID Connect.
+// Sin IssuerURL el inicio de sesión con OpenID Connect queda desactivado.
+type OIDCConfig struct {
+	// ProviderName identifica al proveedor en las identidades vinculadas
+	ProviderName string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL es la página del frontend a la que vuelve el proveedor
+	RedirectURL string
+	Scopes      []string
+	// RoleClaim es el claim del ID token con los valores de RoleMapping
+	RoleClaim string
+	// RoleMapping son reglas "valor=Rol" separadas por comas; gana la primera que coincide
+	RoleMapping     string
+	AutoCreateUsers bool
+	LinkByEmail     bool
+	// StateTTL es el tiempo que tiene el usuario para autenticarse en el proveedor
+	StateTTL time.Duration
 }
 
 // Load carga la configuración desde variables de entorno
 func Load() *Config {
+	jwtSecret := getEnv("JWT_SECRET_KEY", DefaultJWTSecret)
+
 	return &Config{
 		Server: ServerConfig{
 			Port: getEnv("SERVER_PORT", "8080"),
 			Host: getEnv("SERVER_HOST", "0.0.0.0"),
+
+			Environment: getEnv("APP_ENV", "development"),
+
+			TrustedProxies: getEnvAsList("TRUSTED_PROXIES"),
+
+			ReadTimeout:     time.Duration(getEnvAsInt("SERVER_READ_TIMEOUT", 15)) * time.Second,
+			WriteTimeout:    time.Duration(getEnvAsInt("SERVER_WRITE_TIMEOUT", 30)) * time.Second,
+			IdleTimeout:     time.Duration(getEnvAsInt("SERVER_IDLE_TIMEOUT", 120)) * time.Second,
+			ShutdownTimeout: time.Duration(getEnvAsInt("SERVER_SHUTDOWN_TIMEOUT", 30)) * time.Second,
+			ShutdownDelay:   time.Duration(getEnvAsInt("SERVER_SHUTDOWN_DELAY", 0)) * time.Second,
 		},
 		Database: DatabaseConfig{
+			Driver: getEnv("DB_DRIVER", "mysql"),
+			Path:   getEnv("DB_PATH", "blog.db"),
+
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnv("DB_PORT", "3306"),
 			User:     getEnv("DB_USER", "root"),
 			Password: getEnv("DB_PASSWORD", ""),
 			DBName:   getEnv("DB_NAME", "blog_db"),
+
+			AutoMigrate:          getEnvAsBool("DB_AUTO_MIGRATE", true),
+			MigrationLockTimeout: time.Duration(getEnvAsInt("DB_MIGRATION_LOCK_TIMEOUT", 60)) * time.Second,
+			QueryTimeout:         time.Duration(getEnvAsInt("DB_QUERY_TIMEOUT", 10)) * time.Second,
+			PingTimeout:          time.Duration(getEnvAsInt("DB_PING_TIMEOUT", 2)) * time.Second,
+
+			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 25),
+			ConnMaxLifetime: time.Duration(getEnvAsInt("DB_CONN_MAX_LIFETIME", 300)) * time.Second,
+			ConnMaxIdleTime: time.Duration(getEnvAsInt("DB_CONN_MAX_IDLE_TIME", 0)) * time.Second,
 		},
 		JWT: JWTConfig{
-			SecretKey: getEnv("JWT_SECRET_KEY", "your-secret-key-change-in-production"),
+			SigningAlgorithm:    getEnv("JWT_SIGNING_ALGORITHM", "RS256"),
+			SecretKey:           jwtSecret,
+			KeyRotationInterval: time.Duration(getEnvAsInt("JWT_KEY_ROTATION_DAYS", 30)) * 24 * time.Hour,
+			KeyCheckInterval:    time.Duration(getEnvAsInt("JWT_KEY_CHECK_INTERVAL_MINUTES", 60)) * time.Minute,
+			AccessTokenTTL:      time.Duration(getEnvAsInt("JWT_ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute,
+			RefreshTokenTTL:     time.Duration(getEnvAsInt("JWT_REFRESH_TOKEN_TTL_HOURS", 720)) * time.Hour,
+		},
+		Bootstrap: BootstrapConfig{
+			AdminUsername: getEnv("BOOTSTRAP_ADMIN_USERNAME", ""),
+			AdminPassword: getEnv("BOOTSTRAP_ADMIN_PASSWORD", ""),
+		},
+		Scheduler: SchedulerConfig{
+			PublishInterval: time.Duration(getEnvAsInt("SCHEDULER_PUBLISH_INTERVAL_SECONDS", 60)) * time.Second,
+			PurgeInterval:   time.Duration(getEnvAsInt("SCHEDULER_PURGE_INTERVAL_SECONDS", 3600)) * time.Second,
+			TrashRetention:  time.Duration(getEnvAsInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		},
+		Comments: CommentsConfig{
+			ModerateAll: getEnvAsBool("COMMENT_MODERATION_ENABLED", false),
+
+			MaxLinks:             getEnvAsInt("COMMENT_MAX_LINKS", 2),
+			BannedWords:          getEnvAsList("COMMENT_BANNED_WORDS"),
+			BannedWordsAction:    getEnv("COMMENT_BANNED_WORDS_ACTION", "reject"),
+			DuplicateWindow:      time.Duration(getEnvAsInt("COMMENT_DUPLICATE_WINDOW_HOURS", 24)) * time.Hour,
+			NewAccountAge:        time.Duration(getEnvAsInt("COMMENT_NEW_ACCOUNT_HOURS", 24)) * time.Hour,
+			NewAccountMaxPerHour: getEnvAsInt("COMMENT_NEW_ACCOUNT_MAX_PER_HOUR", 3),
+		},
+		Deletion: DeletionConfig{
+			UserPolicy: getEnv("USER_DELETION_POLICY", "cascade"),
+			BlogPolicy: getEnv("BLOG_DELETION_POLICY", "cascade"),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:       getEnvAsBool("RATE_LIMIT_ENABLED", true),
+			Login:         getRateLimitPolicy("LOGIN", 5, 60),
+			Register:      getRateLimitPolicy("REGISTER", 5, 3600),
+			PasswordReset: getRateLimitPolicy("PASSWORD_RESET", 5, 3600),
+			VerifyEmail:   getRateLimitPolicy("VERIFY_EMAIL", 10, 600),
+			Refresh:       getRateLimitPolicy("REFRESH", 30, 60),
+			Search:        getRateLimitPolicy("SEARCH", 30, 60),
+			Write:         getRateLimitPolicy("WRITE", 60, 60),
+			Comment:       getRateLimitPolicy("COMMENT", 10, 60),
 		},
+		Login: LoginConfig{
+			MaxAccountFailures: getEnvAsInt("LOGIN_MAX_ACCOUNT_FAILURES", 5),
+			LockoutDuration:    time.Duration(getEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+			MaxIPFailures:      getEnvAsInt("LOGIN_MAX_IP_FAILURES", 20),
+			FailureWindow:      time.Duration(getEnvAsInt("LOGIN_FAILURE_WINDOW_MINUTES", 15)) * time.Minute,
+			DelayAfter:         getEnvAsInt("LOGIN_DELAY_AFTER_FAILURES", 3),
+			BaseDelay:          time.Duration(getEnvAsInt("LOGIN_BASE_DELAY_SECONDS", 1)) * time.Second,
+			MaxDelay:           time.Duration(getEnvAsInt("LOGIN_MAX_DELAY_SECONDS", 30)) * time.Second,
+		},
+		Mail: MailConfig{
+			Driver: getEnv("MAIL_DRIVER", "log"),
+			From:   getEnv("MAIL_FROM", "Blog <no-reply@localhost>"),
+
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+			FileDir: getEnv("MAIL_FILE_DIR", "mail"),
+
+			PasswordResetURL: getEnv("PASSWORD_RESET_URL", "http://localhost:4200/reset-password"),
+			PasswordResetTTL: time.Duration(getEnvAsInt("PASSWORD_RESET_TOKEN_TTL_MINUTES", 60)) * time.Minute,
+		},
+		EmailVerification: EmailVerificationConfig{
+			Required:       getEnvAsBool("REQUIRE_EMAIL_VERIFICATION", false),
+			Secret:         getEnv("EMAIL_VERIFICATION_SECRET", jwtSecret),
+			URL:            getEnv("EMAIL_VERIFICATION_URL", "http://localhost:4200/verify-email"),
+			LinkTTL:        time.Duration(getEnvAsInt("EMAIL_VERIFICATION_TTL_HOURS", 48)) * time.Hour,
+			ResendCooldown: time.Duration(getEnvAsInt("EMAIL_VERIFICATION_RESEND_SECONDS", 60)) * time.Second,
+		},
+		TwoFactor: TwoFactorConfig{
+			Issuer:           getEnv("TOTP_ISSUER", "Blog"),
+			RequireForAdmins: getEnvAsBool("REQUIRE_2FA_FOR_ADMINS", false),
+			ChallengeTTL:     time.Duration(getEnvAsInt("TWO_FACTOR_CHALLENGE_TTL_MINUTES", 5)) * time.Minute,
+			EncryptionKey:    getEnv("TOTP_ENCRYPTION_KEY", jwtSecret),
+		},
+		OIDC: OIDCConfig{
+			ProviderName: getEnv("OIDC_PROVIDER_NAME", "oidc"),
+			IssuerURL:    getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:     getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:4200/oidc/callback"),
+			Scopes:       getEnvAsList("OIDC_SCOPES"),
+			RoleClaim:    getEnv("OIDC_ROLE_CLAIM", "groups"),
+			RoleMapping:  getEnv("OIDC_ROLE_MAPPING", ""),
+
+			AutoCreateUsers: getEnvAsBool("OIDC_AUTO_CREATE_USERS", true),
+			LinkByEmail:     getEnvAsBool("OIDC_LINK_BY_EMAIL", false),
+			StateTTL:        time.Duration(getEnvAsInt("OIDC_STATE_TTL_MINUTES", 10)) * time.Minute,
+		},
+	}
+}
+
+// IsProduction indica si la aplicación se ejecuta en producción
+func (c *Config) IsProduction() bool {
+	return c.Server.Environment == EnvironmentProduction
+}
+
+// Validate rechaza las configuraciones inseguras. En producción el secreto por
+// defecto no puede firmar tokens ni enlaces, aunque los tokens de acceso usen
+// claves asimétricas.
+func (c *Config) Validate() error {
+	if !c.IsProduction() {
+		return nil
+	}
+	if c.JWT.SecretKey == DefaultJWTSecret {
+		return errors.New("JWT_SECRET_KEY no puede usar el valor por defecto en producción")
+	}
+	if c.EmailVerification.Secret == DefaultJWTSecret {
+		return errors.New("EMAIL_VERIFICATION_SECRET no puede usar el valor por defecto en producción")
 	}
+	if c.TwoFactor.EncryptionKey == DefaultJWTSecret {
+		return errors.New("TOTP_ENCRYPTION_KEY no puede usar el valor por defecto en producción")
+	}
+	return nil
 }
 
 // getEnv obtiene una variable de entorno o retorna un valor por defecto
@@ -69,3 +429,32 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsBool obtiene una variable de entorno como booleano o retorna un valor por defecto
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList obtiene una variable de entorno como lista separada por comas
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getRateLimitPolicy lee las variables RATE_LIMIT_<name>_REQUESTS y RATE_LIMIT_<name>_PERIOD_SECONDS
+func getRateLimitPolicy(name string, requests, periodSeconds int) RateLimitPolicy {
+	return RateLimitPolicy{
+		Requests: getEnvAsInt("RATE_LIMIT_"+name+"_REQUESTS", requests),
+		Period:   time.Duration(getEnvAsInt("RATE_LIMIT_"+name+"_PERIOD_SECONDS", periodSeconds)) * time.Second,
+	}
+}
@@ -0,0 +1,44 @@
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BannedWordsFilter detecta palabras o expresiones prohibidas. La comparación
+// ignora mayúsculas y signos de puntuación y solo reconoce palabras completas.
+type BannedWordsFilter struct {
+	words  []string
+	action domain.FilterAction
+}
+
+// NewBannedWordsFilter crea un filtro que aplica action a los comentarios que
+// contienen alguna de las palabras indicadas
+func NewBannedWordsFilter(words []string, action domain.FilterAction) ports.ContentFilter {
+	normalized := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = normalizeText(word); word != "" {
+			normalized = append(normalized, word)
+		}
+	}
+	return &BannedWordsFilter{words: normalized, action: action}
+}
+
+// Check busca las palabras prohibidas en el comentario
+func (f *BannedWordsFilter) Check(ctx context.Context, input domain.FilterInput) (domain.FilterVerdict, error) {
+	// Los espacios en los extremos permiten buscar palabras completas
+	content := " " + normalizeText(input.Content) + " "
+	for _, word := range f.words {
+		if strings.Contains(content, " "+word+" ") {
+			return domain.FilterVerdict{
+				Action: f.action,
+				Filter: "banned_words",
+				Reason: fmt.Sprintf("el comentario contiene la expresión prohibida %q", word),
+			}, nil
+		}
+	}
+	return domain.AllowVerdict, nil
+}
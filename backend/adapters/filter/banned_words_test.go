@@ -0,0 +1,44 @@
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"testing"
+)
+
+func TestBannedWordsFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    domain.FilterAction
+	}{
+		{name: "limpio", content: "un comentario normal", want: domain.FilterAllow},
+		{name: "palabra prohibida", content: "esto es spam", want: domain.FilterReject},
+		{name: "mayúsculas y puntuación", content: "¡¡SPAM!!", want: domain.FilterReject},
+		{name: "parte de otra palabra", content: "spammer", want: domain.FilterAllow},
+		{name: "expresión", content: "Compra  ya, barato", want: domain.FilterReject},
+		{name: "expresión separada", content: "compra hoy y ya", want: domain.FilterAllow},
+		{name: "vacío", content: "", want: domain.FilterAllow},
+	}
+
+	// Las palabras vacías tras normalizar se ignoran
+	filter := NewBannedWordsFilter([]string{"Spam", "compra ya", " ", "!!"}, domain.FilterReject)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := filter.Check(ctx, domain.FilterInput{Content: tt.content})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if verdict.Action != tt.want {
+				t.Errorf("Check = %+v, se esperaba %q", verdict, tt.want)
+			}
+			if tt.want != domain.FilterAllow && verdict.Filter != "banned_words" {
+				t.Errorf("filtro = %q", verdict.Filter)
+			}
+		})
+	}
+
+	verdict, err := NewBannedWordsFilter([]string{"spam"}, domain.FilterHold).Check(ctx, domain.FilterInput{Content: "spam"})
+	if err != nil || verdict.Action != domain.FilterHold {
+		t.Errorf("con la acción hold: %+v, %v", verdict, err)
+	}
+}
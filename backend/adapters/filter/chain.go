@@ -0,0 +1,49 @@
+// Package filter contiene los filtros de contenido que evalúan los comentarios
+// antes de guardarlos: límite de enlaces, palabras prohibidas, contenido
+// duplicado y limitación de cuentas nuevas.
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"strings"
+	"unicode"
+)
+
+// Chain aplica varios filtros y se queda con el veredicto más restrictivo
+type Chain struct {
+	filters []ports.ContentFilter
+}
+
+// NewChain crea un filtro que combina los filtros indicados en orden
+func NewChain(filters ...ports.ContentFilter) ports.ContentFilter {
+	return &Chain{filters: filters}
+}
+
+// Check evalúa el comentario con cada filtro. Un rechazo detiene la evaluación.
+func (c *Chain) Check(ctx context.Context, input domain.FilterInput) (domain.FilterVerdict, error) {
+	result := domain.AllowVerdict
+	for _, filter := range c.filters {
+		verdict, err := filter.Check(ctx, input)
+		if err != nil {
+			return domain.FilterVerdict{}, err
+		}
+		if verdict.StricterThan(result) {
+			result = verdict
+		}
+		if result.Action == domain.FilterReject {
+			break
+		}
+	}
+	return result, nil
+}
+
+// normalizeText pasa el texto a minúsculas y reduce a un espacio cada secuencia
+// de caracteres que no son letras ni dígitos
+func normalizeText(text string) string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
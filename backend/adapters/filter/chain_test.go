@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"errors"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	allow := domain.AllowVerdict
+	hold := domain.FilterVerdict{Action: domain.FilterHold, Filter: "primero"}
+	otherHold := domain.FilterVerdict{Action: domain.FilterHold, Filter: "segundo"}
+	reject := domain.FilterVerdict{Action: domain.FilterReject, Filter: "rechazo"}
+
+	tests := []struct {
+		name     string
+		verdicts []domain.FilterVerdict
+		want     domain.FilterVerdict
+		// calls es el número de filtros evaluados
+		calls int
+	}{
+		{name: "sin filtros", want: allow},
+		{name: "todos permiten", verdicts: []domain.FilterVerdict{allow, allow}, want: allow, calls: 2},
+		{name: "gana el más restrictivo", verdicts: []domain.FilterVerdict{allow, hold, allow}, want: hold, calls: 3},
+		{name: "a igualdad gana el primero", verdicts: []domain.FilterVerdict{hold, otherHold}, want: hold, calls: 2},
+		{name: "el rechazo detiene la evaluación", verdicts: []domain.FilterVerdict{hold, reject, allow}, want: reject, calls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var filters []*fixedFilter
+			chain := &Chain{}
+			for _, verdict := range tt.verdicts {
+				filter := &fixedFilter{verdict: verdict}
+				filters = append(filters, filter)
+				chain.filters = append(chain.filters, filter)
+			}
+
+			verdict, err := chain.Check(ctx, domain.FilterInput{Content: "hola"})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if verdict != tt.want {
+				t.Errorf("Check = %+v, se esperaba %+v", verdict, tt.want)
+			}
+			calls := 0
+			for _, filter := range filters {
+				calls += filter.calls
+			}
+			if calls != tt.calls {
+				t.Errorf("se evaluaron %d filtros, se esperaban %d", calls, tt.calls)
+			}
+		})
+	}
+
+	failing := &fixedFilter{err: errors.New("filtro caído")}
+	if _, err := NewChain(&fixedFilter{verdict: hold}, failing).Check(ctx, domain.FilterInput{}); !errors.Is(err, failing.err) {
+		t.Errorf("Check con un filtro que falla = %v", err)
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := map[string]string{
+		"":                  "",
+		"Hola":              "hola",
+		"  ¡Hola,   MUNDO!": "hola mundo",
+		"año 2024...":       "año 2024",
+		"a-b_c":             "a b c",
+	}
+	for text, want := range tests {
+		if got := normalizeText(text); got != want {
+			t.Errorf("normalizeText(%q) = %q, se esperaba %q", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"time"
+)
+
+// DuplicateFilter rechaza los comentarios que repiten el contenido de otro
+// comentario reciente del mismo usuario
+type DuplicateFilter struct {
+	commentRepo ports.CommentRepository
+	window      time.Duration
+}
+
+// NewDuplicateFilter crea un filtro que compara con los comentarios del usuario de los últimos window
+func NewDuplicateFilter(commentRepo ports.CommentRepository, window time.Duration) ports.ContentFilter {
+	return &DuplicateFilter{commentRepo: commentRepo, window: window}
+}
+
+// Check compara el contenido normalizado con los comentarios recientes del autor
+func (f *DuplicateFilter) Check(ctx context.Context, input domain.FilterInput) (domain.FilterVerdict, error) {
+	content := normalizeText(input.Content)
+	if content == "" {
+		return domain.AllowVerdict, nil
+	}
+
+	recent, err := f.commentRepo.FindRecentByUser(ctx, input.Author.ID, time.Now().Add(-f.window))
+	if err != nil {
+		return domain.FilterVerdict{}, err
+	}
+
+	for _, comment := range recent {
+		if comment.ID != input.CommentID && normalizeText(comment.Content) == content {
+			return domain.FilterVerdict{
+				Action: domain.FilterReject,
+				Filter: "duplicate",
+				Reason: "el comentario repite el contenido de otro comentario reciente",
+			}, nil
+		}
+	}
+	return domain.AllowVerdict, nil
+}
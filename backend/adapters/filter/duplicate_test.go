@@ -0,0 +1,50 @@
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDuplicateFilter(t *testing.T) {
+	now := time.Now()
+	author := &domain.User{ID: 1}
+	repo := &recentComments{comments: []domain.Comment{
+		{ID: 1, UserID: 1, Content: "¡Gran artículo!", CreatedAt: now.Add(-time.Minute)},
+		{ID: 2, UserID: 1, Content: "antiguo", CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: 3, UserID: 2, Content: "de otro usuario", CreatedAt: now.Add(-time.Minute)},
+	}}
+
+	tests := []struct {
+		name      string
+		commentID int64
+		content   string
+		want      domain.FilterAction
+	}{
+		{name: "nuevo", content: "otra cosa", want: domain.FilterAllow},
+		{name: "repetido", content: "gran artículo", want: domain.FilterReject},
+		{name: "fuera de la ventana", content: "antiguo", want: domain.FilterAllow},
+		{name: "de otro usuario", content: "de otro usuario", want: domain.FilterAllow},
+		{name: "edición del mismo comentario", commentID: 1, content: "Gran artículo", want: domain.FilterAllow},
+		{name: "solo puntuación", content: "!!!", want: domain.FilterAllow},
+	}
+
+	filter := NewDuplicateFilter(repo, time.Hour)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := filter.Check(ctx, domain.FilterInput{CommentID: tt.commentID, Author: author, Content: tt.content})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if verdict.Action != tt.want {
+				t.Errorf("Check = %+v, se esperaba %q", verdict, tt.want)
+			}
+		})
+	}
+
+	repo.err = errors.New("base de datos caída")
+	if _, err := filter.Check(ctx, domain.FilterInput{Author: author, Content: "hola"}); !errors.Is(err, repo.err) {
+		t.Errorf("Check con error del repositorio = %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"time"
+)
+
+// ctx es el contexto de las llamadas de los tests del paquete
+var ctx = context.Background()
+
+// recentComments implementa la consulta de comentarios recientes que usan los
+// filtros; el resto de ports.CommentRepository no se usa
+type recentComments struct {
+	ports.CommentRepository
+	comments []domain.Comment
+	err      error
+}
+
+func (r *recentComments) FindRecentByUser(_ context.Context, userID int64, since time.Time) ([]domain.Comment, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	var recent []domain.Comment
+	for _, comment := range r.comments {
+		if comment.UserID == userID && !comment.CreatedAt.Before(since) {
+			recent = append(recent, comment)
+		}
+	}
+	return recent, nil
+}
+
+// fixedFilter retorna siempre el mismo veredicto y cuenta sus llamadas
+type fixedFilter struct {
+	verdict domain.FilterVerdict
+	err     error
+	calls   int
+}
+
+func (f *fixedFilter) Check(context.Context, domain.FilterInput) (domain.FilterVerdict, error) {
+	f.calls++
+	return f.verdict, f.err
+}
@@ -0,0 +1,36 @@
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// linkPattern reconoce enlaces con esquema http(s) o que empiezan por www.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// LinkFilter deja pendientes de moderación los comentarios con demasiados enlaces
+type LinkFilter struct {
+	maxLinks int
+}
+
+// NewLinkFilter crea un filtro que admite como máximo maxLinks enlaces por comentario
+func NewLinkFilter(maxLinks int) ports.ContentFilter {
+	return &LinkFilter{maxLinks: maxLinks}
+}
+
+// Check cuenta los enlaces del comentario
+func (f *LinkFilter) Check(ctx context.Context, input domain.FilterInput) (domain.FilterVerdict, error) {
+	links := len(linkPattern.FindAllString(input.Content, -1))
+	if links <= f.maxLinks {
+		return domain.AllowVerdict, nil
+	}
+
+	return domain.FilterVerdict{
+		Action: domain.FilterHold,
+		Filter: "links",
+		Reason: fmt.Sprintf("el comentario contiene %d enlaces (máximo %d)", links, f.maxLinks),
+	}, nil
+}
@@ -0,0 +1,36 @@
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"testing"
+)
+
+func TestLinkFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    domain.FilterAction
+	}{
+		{name: "sin enlaces", content: "hola", want: domain.FilterAllow},
+		{name: "en el límite", content: "mira https://a.example y http://b.example", want: domain.FilterAllow},
+		{name: "sobre el límite", content: "https://a.example http://b.example www.c.example", want: domain.FilterHold},
+		{name: "mayúsculas", content: "HTTPS://a.example WWW.b.example Http://c.example", want: domain.FilterHold},
+		{name: "sin esquema ni www", content: "a.example b.example c.example", want: domain.FilterAllow},
+	}
+
+	filter := NewLinkFilter(2)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := filter.Check(ctx, domain.FilterInput{Content: tt.content})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if verdict.Action != tt.want {
+				t.Errorf("Check = %+v, se esperaba %q", verdict, tt.want)
+			}
+			if tt.want == domain.FilterHold && (verdict.Filter != "links" || verdict.Reason == "") {
+				t.Errorf("veredicto sin filtro o motivo: %+v", verdict)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+	"time"
+)
+
+// NewAccountFilter limita cuántos comentarios por hora pueden publicar las cuentas recientes
+type NewAccountFilter struct {
+	commentRepo ports.CommentRepository
+	minAge      time.Duration
+	maxPerHour  int
+}
+
+// NewNewAccountFilter crea un filtro que limita a maxPerHour comentarios por
+// hora a las cuentas con menos de minAge de antigüedad
+func NewNewAccountFilter(commentRepo ports.CommentRepository, minAge time.Duration, maxPerHour int) ports.ContentFilter {
+	return &NewAccountFilter{commentRepo: commentRepo, minAge: minAge, maxPerHour: maxPerHour}
+}
+
+// Check cuenta los comentarios de la última hora de las cuentas nuevas. Las
+// ediciones no cuentan como comentarios nuevos.
+func (f *NewAccountFilter) Check(ctx context.Context, input domain.FilterInput) (domain.FilterVerdict, error) {
+	now := time.Now()
+	if input.CommentID != 0 || now.Sub(input.Author.CreatedAt) >= f.minAge {
+		return domain.AllowVerdict, nil
+	}
+
+	recent, err := f.commentRepo.FindRecentByUser(ctx, input.Author.ID, now.Add(-time.Hour))
+	if err != nil {
+		return domain.FilterVerdict{}, err
+	}
+
+	if len(recent) < f.maxPerHour {
+		return domain.AllowVerdict, nil
+	}
+
+	return domain.FilterVerdict{
+		Action: domain.FilterReject,
+		Filter: "new_account",
+		Reason: fmt.Sprintf("las cuentas nuevas pueden publicar como máximo %d comentarios por hora", f.maxPerHour),
+	}, nil
+}
@@ -0,0 +1,57 @@
+package filter
+
+import (
+	"blog-backend/internal/domain"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewAccountFilter(t *testing.T) {
+	now := time.Now()
+	recent := func(userID int64, count int, age time.Duration) []domain.Comment {
+		comments := make([]domain.Comment, count)
+		for i := range comments {
+			comments[i] = domain.Comment{ID: int64(i + 1), UserID: userID, CreatedAt: now.Add(-age)}
+		}
+		return comments
+	}
+
+	tests := []struct {
+		name       string
+		accountAge time.Duration
+		comments   []domain.Comment
+		commentID  int64
+		want       domain.FilterAction
+	}{
+		{name: "cuenta nueva bajo el límite", accountAge: time.Hour, comments: recent(1, 2, time.Minute), want: domain.FilterAllow},
+		{name: "cuenta nueva en el límite", accountAge: time.Hour, comments: recent(1, 3, time.Minute), want: domain.FilterReject},
+		{name: "comentarios de hace más de una hora", accountAge: time.Hour, comments: recent(1, 3, 2*time.Hour), want: domain.FilterAllow},
+		{name: "comentarios de otro usuario", accountAge: time.Hour, comments: recent(2, 3, time.Minute), want: domain.FilterAllow},
+		{name: "cuenta antigua", accountAge: 48 * time.Hour, comments: recent(1, 10, time.Minute), want: domain.FilterAllow},
+		{name: "edición", accountAge: time.Hour, comments: recent(1, 3, time.Minute), commentID: 1, want: domain.FilterAllow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := NewNewAccountFilter(&recentComments{comments: tt.comments}, 24*time.Hour, 3)
+			author := &domain.User{ID: 1, CreatedAt: now.Add(-tt.accountAge)}
+			verdict, err := filter.Check(ctx, domain.FilterInput{CommentID: tt.commentID, Author: author, Content: "hola"})
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if verdict.Action != tt.want {
+				t.Errorf("Check = %+v, se esperaba %q", verdict, tt.want)
+			}
+			if tt.want == domain.FilterReject && verdict.Filter != "new_account" {
+				t.Errorf("filtro = %q", verdict.Filter)
+			}
+		})
+	}
+
+	failing := &recentComments{err: errors.New("base de datos caída")}
+	author := &domain.User{ID: 1, CreatedAt: now}
+	if _, err := NewNewAccountFilter(failing, time.Hour, 3).Check(ctx, domain.FilterInput{Author: author}); !errors.Is(err, failing.err) {
+		t.Errorf("Check con error del repositorio = %v", err)
+	}
+}
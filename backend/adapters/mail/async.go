@@ -0,0 +1,51 @@
+package mail
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// AsyncMailer envía los correos en segundo plano con otro Mailer. Send retorna
+// de inmediato y los errores de envío solo se registran en el log, de modo que
+// la respuesta a quien pidió el correo no depende de si se envió ni de cuánto
+// tardó el servidor de correo.
+type AsyncMailer struct {
+	mailer  ports.Mailer
+	timeout time.Duration
+	pending sync.WaitGroup
+}
+
+// NewAsyncMailer crea un mailer que envía con mailer en segundo plano. Cada
+// envío se cancela si tarda más que timeout.
+func NewAsyncMailer(mailer ports.Mailer, timeout time.Duration) *AsyncMailer {
+	return &AsyncMailer{mailer: mailer, timeout: timeout}
+}
+
+var _ ports.Mailer = (*AsyncMailer)(nil)
+
+// Send programa el envío del correo y retorna nil. El envío no se cancela
+// cuando termina la petición que lo originó.
+func (m *AsyncMailer) Send(ctx context.Context, message domain.EmailMessage) error {
+	ctx = context.WithoutCancel(ctx)
+
+	m.pending.Add(1)
+	go func() {
+		defer m.pending.Done()
+
+		ctx, cancel := context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+		if err := m.mailer.Send(ctx, message); err != nil {
+			log.Printf("Error enviando correo a %s: %v", message.To, err)
+		}
+	}()
+	return nil
+}
+
+// Wait espera a que terminen los envíos en curso
+func (m *AsyncMailer) Wait() {
+	m.pending.Wait()
+}
@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingMailer espera a que se cierre release antes de fallar cada envío
+type blockingMailer struct {
+	release chan struct{}
+	sent    chan domain.EmailMessage
+}
+
+func (m *blockingMailer) Send(ctx context.Context, message domain.EmailMessage) error {
+	<-m.release
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	m.sent <- message
+	return errors.New("servidor no disponible")
+}
+
+func TestAsyncMailer(t *testing.T) {
+	inner := &blockingMailer{release: make(chan struct{}), sent: make(chan domain.EmailMessage, 1)}
+	mailer := NewAsyncMailer(inner, time.Minute)
+
+	// Send no espera al envío ni devuelve su error, aunque la petición termine
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := mailer.Send(ctx, domain.EmailMessage{To: "ana@example.com"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	cancel()
+
+	close(inner.release)
+	mailer.Wait()
+	select {
+	case message := <-inner.sent:
+		if message.To != "ana@example.com" {
+			t.Errorf("destinatario = %q", message.To)
+		}
+	default:
+		t.Fatal("el correo no se envió")
+	}
+}
@@ -0,0 +1,63 @@
+package mail
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// FileMailer guarda cada correo como un archivo .eml en un directorio en lugar
+// de enviarlo. Las pruebas pueden leer los mensajes del directorio.
+type FileMailer struct {
+	dir  string
+	from string
+	seq  atomic.Int64
+}
+
+// NewFileMailer crea un mailer que escribe los correos en dir, creándolo si no existe
+func NewFileMailer(dir, from string) (ports.Mailer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("error creando directorio de correos %s: %w", dir, err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send escribe el correo en un archivo nuevo. El nombre empieza por la fecha
+// para que los archivos se ordenen por envío.
+func (m *FileMailer) Send(ctx context.Context, message domain.EmailMessage) error {
+	now := time.Now()
+	data, err := formatMessage(m.from, message, now)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%06d.eml", now.UTC().Format("20060102T150405.000000000"), m.seq.Add(1))
+	if err := os.WriteFile(filepath.Join(m.dir, name), data, 0o640); err != nil {
+		return fmt.Errorf("error guardando correo: %w", err)
+	}
+	return nil
+}
+
+// LogMailer escribe los correos en el log en lugar de enviarlos. Solo es
+// adecuado para desarrollo: los enlaces de los correos quedan en el log.
+type LogMailer struct {
+	logger *log.Logger
+	from   string
+}
+
+// NewLogMailer crea un mailer que escribe los correos en logger
+func NewLogMailer(logger *log.Logger, from string) ports.Mailer {
+	return &LogMailer{logger: logger, from: from}
+}
+
+// Send escribe el correo en el log
+func (m *LogMailer) Send(ctx context.Context, message domain.EmailMessage) error {
+	m.logger.Printf("Correo de %s para %s: %s\n%s", m.from, message.To, message.Subject, message.Body)
+	return nil
+}
@@ -0,0 +1,44 @@
+// Package mail contiene los adaptadores de envío de correo: SMTP para
+// producción y adaptadores que escriben los mensajes en archivos o en el log,
+// útiles en desarrollo y en pruebas sin red.
+package mail
+
+import (
+	"blog-backend/internal/domain"
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+)
+
+// errHeaderInjection se retorna si el destinatario o el asunto contienen saltos de línea
+var errHeaderInjection = errors.New("el destinatario o el asunto contienen saltos de línea")
+
+// formatMessage construye el mensaje RFC 5322 en UTF-8 con el cuerpo en quoted-printable
+func formatMessage(from string, message domain.EmailMessage, now time.Time) ([]byte, error) {
+	if strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return nil, errHeaderInjection
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(strings.ReplaceAll(message.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,108 @@
+package mail
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer envía los correos a través de un servidor SMTP. Si el servidor lo
+// admite, la conexión se cifra con STARTTLS antes de autenticarse.
+type SMTPMailer struct {
+	addr string
+	host string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer crea un mailer SMTP. Sin usuario no se usa autenticación.
+func NewSMTPMailer(host string, port int, username, password, from string) ports.Mailer {
+	mailer := &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		host: host,
+		from: from,
+	}
+	if username != "" {
+		mailer.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return mailer
+}
+
+// Send envía un correo. Si ctx se cancela, la conexión con el servidor se cierra.
+func (m *SMTPMailer) Send(ctx context.Context, message domain.EmailMessage) error {
+	data, err := formatMessage(m.from, message, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// El remitente del sobre SMTP es la dirección sin el nombre visible
+	sender := m.from
+	if address, err := netmail.ParseAddress(m.from); err == nil {
+		sender = address.Address
+	}
+
+	if err := m.sendMail(ctx, sender, message.To, data); err != nil {
+		return fmt.Errorf("error enviando correo a través de %s: %w", m.addr, err)
+	}
+	return nil
+}
+
+// sendMail hace lo mismo que smtp.SendMail con una conexión ligada a ctx
+func (m *SMTPMailer) sendMail(ctx context.Context, from, to string, data []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+	if m.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("el servidor no admite autenticación")
+		}
+		if err := client.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
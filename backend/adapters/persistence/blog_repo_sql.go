@@ -3,24 +3,33 @@ package persistence
 import (
 	"blog-backend/internal/domain"
 	"blog-backend/internal/ports"
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // BlogRepositorySQL implementa la interfaz BlogRepository usando SQL
 type BlogRepositorySQL struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect Dialect
 }
 
 // NewBlogRepositorySQL crea una nueva instancia del repositorio SQL de blogs
-func NewBlogRepositorySQL(db *sql.DB) ports.BlogRepository {
-	return &BlogRepositorySQL{db: db}
+func NewBlogRepositorySQL(db *sql.DB, dialect Dialect) ports.BlogRepository {
+	return &BlogRepositorySQL{db: db, dialect: dialect}
 }
 
 // Create crea un nuevo blog en la base de datos
-func (r *BlogRepositorySQL) Create(blog *domain.Blog) error {
-	query := `INSERT INTO blogs (title, content, author_id) VALUES (?, ?, ?)`
-	result, err := r.db.Exec(query, blog.Title, blog.Content, blog.AuthorID)
+func (r *BlogRepositorySQL) Create(ctx context.Context, blog *domain.Blog) error {
+	now := time.Now()
+	blog.CreatedAt = now
+	blog.UpdatedAt = now
+
+	query := `INSERT INTO blogs (title, content, author_id, status, published_at, comment_moderation, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, blog.Title, blog.Content, blog.AuthorID, blog.Status, blog.PublishedAt, blog.CommentModeration,
+		blog.CreatedAt, blog.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("error creando blog: %w", err)
 	}
@@ -34,12 +43,38 @@ func (r *BlogRepositorySQL) Create(blog *domain.Blog) error {
 	return nil
 }
 
+// blogSelect selecciona las columnas de un blog junto con su número de comentarios aprobados
+const blogSelect = `SELECT b.id, b.title, b.content, b.author_id, b.status, b.published_at, b.comment_moderation,
+	b.created_at, b.updated_at, b.deleted_at,
+	(SELECT COUNT(*) FROM comments c WHERE c.blog_id = b.id AND c.deleted_at IS NULL AND c.status = 'approved') AS comments_count
+	FROM blogs b`
+
+// blogColumns son las columnas que expone blogSelect
+const blogColumns = `id, title, content, author_id, status, published_at, comment_moderation, created_at, updated_at, deleted_at, comments_count`
+
+// scanBlog lee un blog con las columnas de blogColumns
+func scanBlog(scanner rowScanner, blog *domain.Blog) error {
+	var publishedAt, deletedAt sql.NullTime
+	if err := scanner.Scan(&blog.ID, &blog.Title, &blog.Content, &blog.AuthorID, &blog.Status,
+		&publishedAt, &blog.CommentModeration, &blog.CreatedAt, &blog.UpdatedAt, &deletedAt, &blog.CommentsCount); err != nil {
+		return err
+	}
+
+	if publishedAt.Valid {
+		blog.PublishedAt = &publishedAt.Time
+	}
+	if deletedAt.Valid {
+		blog.DeletedAt = &deletedAt.Time
+	}
+	return nil
+}
+
 // FindByID busca un blog por su ID
-func (r *BlogRepositorySQL) FindByID(id int64) (*domain.Blog, error) {
-	query := `SELECT id, title, content, author_id FROM blogs WHERE id = ?`
+func (r *BlogRepositorySQL) FindByID(ctx context.Context, id int64) (*domain.Blog, error) {
+	query := blogSelect + ` WHERE b.id = ? AND b.deleted_at IS NULL`
 	blog := &domain.Blog{}
 	
-	err := r.db.QueryRow(query, id).Scan(&blog.ID, &blog.Title, &blog.Content, &blog.AuthorID)
+	err := scanBlog(conn(ctx, r.db).QueryRowContext(ctx, query, id), blog)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrBlogNotFound
@@ -50,44 +85,136 @@ func (r *BlogRepositorySQL) FindByID(id int64) (*domain.Blog, error) {
 	return blog, nil
 }
 
-// FindByAuthorID busca todos los blogs de un autor
-func (r *BlogRepositorySQL) FindByAuthorID(authorID int64) ([]domain.Blog, error) {
-	query := `SELECT id, title, content, author_id FROM blogs WHERE author_id = ? ORDER BY id`
-	rows, err := r.db.Query(query, authorID)
+// FindByIDForUpdate busca un blog por su ID y lo bloquea hasta que termine la
+// transacción de ctx, de modo que nadie pueda modificarlo ni eliminarlo mientras tanto
+func (r *BlogRepositorySQL) FindByIDForUpdate(ctx context.Context, id int64) (*domain.Blog, error) {
+	query := blogSelect + ` WHERE b.id = ? AND b.deleted_at IS NULL` + r.dialect.forUpdate()
+	blog := &domain.Blog{}
+
+	err := scanBlog(conn(ctx, r.db).QueryRowContext(ctx, query, id), blog)
 	if err != nil {
-		return nil, fmt.Errorf("error buscando blogs por autor: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrBlogNotFound
+		}
+		return nil, fmt.Errorf("error bloqueando blog: %w", err)
 	}
-	defer rows.Close()
 
-	var blogs []domain.Blog
-	for rows.Next() {
-		var blog domain.Blog
-		if err := rows.Scan(&blog.ID, &blog.Title, &blog.Content, &blog.AuthorID); err != nil {
-			return nil, fmt.Errorf("error escaneando blog: %w", err)
-		}
-		blogs = append(blogs, blog)
+	return blog, nil
+}
+
+// ExistsByAuthor indica si un autor tiene algún blog fuera de la papelera
+func (r *BlogRepositorySQL) ExistsByAuthor(ctx context.Context, authorID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM blogs WHERE author_id = ? AND deleted_at IS NULL)`
+	var exists bool
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, authorID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("error verificando blogs del autor: %w", err)
 	}
+	return exists, nil
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterando blogs: %w", err)
+// ReassignAuthor pasa todos los blogs de un autor, incluidos los de la papelera, a otro
+func (r *BlogRepositorySQL) ReassignAuthor(ctx context.Context, fromID, toID int64) error {
+	query := `UPDATE blogs SET author_id = ? WHERE author_id = ?`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, toID, fromID); err != nil {
+		return fmt.Errorf("error reasignando blogs: %w", err)
 	}
+	return nil
+}
 
+// FindByAuthorID busca una página de blogs de un autor
+func (r *BlogRepositorySQL) FindByAuthorID(ctx context.Context, authorID int64, filter domain.BlogFilter, page domain.PageRequest) (*domain.Page[domain.Blog], error) {
+	blogs, err := r.listPage(ctx, filter, page, `deleted_at IS NULL AND author_id = ?`, authorID)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando blogs por autor: %w", err)
+	}
 	return blogs, nil
 }
 
-// List lista todos los blogs
-func (r *BlogRepositorySQL) List() ([]domain.Blog, error) {
-	query := `SELECT id, title, content, author_id FROM blogs ORDER BY id`
-	rows, err := r.db.Query(query)
+// List lista una página de blogs
+func (r *BlogRepositorySQL) List(ctx context.Context, filter domain.BlogFilter, page domain.PageRequest) (*domain.Page[domain.Blog], error) {
+	blogs, err := r.listPage(ctx, filter, page, `deleted_at IS NULL`)
 	if err != nil {
 		return nil, fmt.Errorf("error listando blogs: %w", err)
 	}
+	return blogs, nil
+}
+
+// FindDeleted busca un blog de la papelera por su ID
+func (r *BlogRepositorySQL) FindDeleted(ctx context.Context, id int64) (*domain.Blog, error) {
+	query := blogSelect + ` WHERE b.id = ? AND b.deleted_at IS NOT NULL`
+	blog := &domain.Blog{}
+
+	err := scanBlog(conn(ctx, r.db).QueryRowContext(ctx, query, id), blog)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrBlogNotFound
+		}
+		return nil, fmt.Errorf("error buscando blog eliminado: %w", err)
+	}
+
+	return blog, nil
+}
+
+// ListDeleted lista una página de blogs de la papelera
+func (r *BlogRepositorySQL) ListDeleted(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.Blog], error) {
+	blogs, err := r.listPage(ctx, domain.BlogFilter{}, page, `deleted_at IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("error listando blogs eliminados: %w", err)
+	}
+	return blogs, nil
+}
+
+// listPage consulta una página de blogs con paginación por clave (keyset).
+// condition es una condición sobre las columnas del blog.
+func (r *BlogRepositorySQL) listPage(ctx context.Context, filter domain.BlogFilter, page domain.PageRequest, condition string, args ...interface{}) (*domain.Page[domain.Blog], error) {
+	conditions := []string{condition}
+	if filter.PublishedOnly {
+		conditions = append(conditions, `(status = ? OR (status = ? AND published_at <= ?))`)
+		args = append(args, domain.BlogStatusPublished, domain.BlogStatusScheduled, time.Now())
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, `id IN (SELECT bt.blog_id FROM blog_tags bt JOIN tags tg ON tg.id = bt.tag_id WHERE tg.slug = ?)`)
+		args = append(args, filter.Tag)
+	}
+
+	var orderBy string
+	switch page.Sort {
+	case domain.SortOldest:
+		orderBy = `id ASC`
+		if page.After != nil {
+			conditions = append(conditions, `id > ?`)
+			args = append(args, page.After.ID)
+		}
+	case domain.SortMostCommented:
+		orderBy = `comments_count DESC, id DESC`
+		if page.After != nil {
+			conditions = append(conditions, `(comments_count < ? OR (comments_count = ? AND id < ?))`)
+			args = append(args, page.After.Count, page.After.Count, page.After.ID)
+		}
+	default:
+		// Los borradores no tienen fecha de publicación y se ordenan por la de creación
+		orderBy = `COALESCE(published_at, created_at) DESC, id DESC`
+		if page.After != nil && page.After.At != nil {
+			conditions = append(conditions, `(COALESCE(published_at, created_at) < ? OR (COALESCE(published_at, created_at) = ? AND id < ?))`)
+			args = append(args, *page.After.At, *page.After.At, page.After.ID)
+		}
+	}
+
+	// La subconsulta permite filtrar y ordenar por comments_count
+	query := `SELECT ` + blogColumns + ` FROM (` + blogSelect + `) AS t WHERE ` + strings.Join(conditions, ` AND `) +
+		` ORDER BY ` + orderBy + ` LIMIT ?`
+	args = append(args, page.Limit+1)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var blogs []domain.Blog
 	for rows.Next() {
 		var blog domain.Blog
-		if err := rows.Scan(&blog.ID, &blog.Title, &blog.Content, &blog.AuthorID); err != nil {
+		if err := scanBlog(rows, &blog); err != nil {
 			return nil, fmt.Errorf("error escaneando blog: %w", err)
 		}
 		blogs = append(blogs, blog)
@@ -97,13 +224,22 @@ func (r *BlogRepositorySQL) List() ([]domain.Blog, error) {
 		return nil, fmt.Errorf("error iterando blogs: %w", err)
 	}
 
-	return blogs, nil
+	return domain.NewPage(blogs, page, blogCursor), nil
+}
+
+// blogCursor obtiene la posición de un blog para la siguiente página
+func blogCursor(blog domain.Blog) domain.Cursor {
+	at := blog.SortTime()
+	return domain.Cursor{ID: blog.ID, Count: blog.CommentsCount, At: &at}
 }
 
 // Update actualiza un blog existente
-func (r *BlogRepositorySQL) Update(blog *domain.Blog) error {
-	query := `UPDATE blogs SET title = ?, content = ? WHERE id = ?`
-	result, err := r.db.Exec(query, blog.Title, blog.Content, blog.ID)
+func (r *BlogRepositorySQL) Update(ctx context.Context, blog *domain.Blog) error {
+	blog.UpdatedAt = time.Now()
+
+	query := `UPDATE blogs SET title = ?, content = ?, status = ?, published_at = ?, comment_moderation = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, blog.Title, blog.Content, blog.Status, blog.PublishedAt, blog.CommentModeration, blog.UpdatedAt, blog.ID)
 	if err != nil {
 		return fmt.Errorf("error actualizando blog: %w", err)
 	}
@@ -120,10 +256,10 @@ func (r *BlogRepositorySQL) Update(blog *domain.Blog) error {
 	return nil
 }
 
-// Delete elimina un blog por su ID
-func (r *BlogRepositorySQL) Delete(id int64) error {
-	query := `DELETE FROM blogs WHERE id = ?`
-	result, err := r.db.Exec(query, id)
+// Delete mueve un blog a la papelera
+func (r *BlogRepositorySQL) Delete(ctx context.Context, id int64) error {
+	query := `UPDATE blogs SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("error eliminando blog: %w", err)
 	}
@@ -139,3 +275,55 @@ func (r *BlogRepositorySQL) Delete(id int64) error {
 
 	return nil
 }
+
+// PublishDue publica los blogs programados cuya fecha de publicación ya pasó
+func (r *BlogRepositorySQL) PublishDue(ctx context.Context, now time.Time) (int64, error) {
+	query := `UPDATE blogs SET status = ?, updated_at = ? WHERE status = ? AND published_at <= ? AND deleted_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, domain.BlogStatusPublished, now, domain.BlogStatusScheduled, now)
+	if err != nil {
+		return 0, fmt.Errorf("error publicando blogs programados: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// Restore saca un blog de la papelera
+func (r *BlogRepositorySQL) Restore(ctx context.Context, id int64) error {
+	query := `UPDATE blogs SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("error restaurando blog: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrBlogNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeleted elimina definitivamente los blogs que están en la papelera desde antes de before
+func (r *BlogRepositorySQL) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM blogs WHERE deleted_at < ?`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("error purgando blogs eliminados: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	return rowsAffected, nil
+}
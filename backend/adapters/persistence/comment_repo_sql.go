@@ -3,24 +3,93 @@ package persistence
 import (
 	"blog-backend/internal/domain"
 	"blog-backend/internal/ports"
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // CommentRepositorySQL implementa la interfaz CommentRepository usando SQL
 type CommentRepositorySQL struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect Dialect
 }
 
 // NewCommentRepositorySQL crea una nueva instancia del repositorio SQL de comentarios
-func NewCommentRepositorySQL(db *sql.DB) ports.CommentRepository {
-	return &CommentRepositorySQL{db: db}
+func NewCommentRepositorySQL(db *sql.DB, dialect Dialect) ports.CommentRepository {
+	return &CommentRepositorySQL{db: db, dialect: dialect}
+}
+
+// commentColumns son las columnas que se leen de un comentario
+const commentColumns = `id, blog_id, user_id, parent_id, root_id, depth, content, removed,
+	status, moderation_reason, moderated_by, moderated_at, filter_action, filter_name, filter_reason,
+	created_at, updated_at, deleted_at`
+
+// commentVisible selecciona los comentarios activos y los eliminados que conservan respuestas
+const commentVisible = `(deleted_at IS NULL OR removed = TRUE)`
+
+// commentVisibleTo limita los comentarios de un hilo a los aprobados y a los
+// pendientes que puede ver quien consulta
+func commentVisibleTo(visibility domain.CommentVisibility) (string, []interface{}) {
+	condition := `(status = ? OR (status = ? AND (? OR user_id = ?)))`
+	return condition, []interface{}{domain.CommentStatusApproved, domain.CommentStatusPending, visibility.Moderator, visibility.ViewerID}
+}
+
+// rowScanner abstrae *sql.Row y *sql.Rows para reutilizar el escaneo
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanComment lee un comentario con las columnas de commentColumns
+func scanComment(scanner rowScanner, comment *domain.Comment) error {
+	var parentID, rootID, moderatedBy sql.NullInt64
+	var moderationReason, filterAction, filterName, filterReason sql.NullString
+	var moderatedAt, deletedAt sql.NullTime
+	if err := scanner.Scan(&comment.ID, &comment.BlogID, &comment.UserID, &parentID, &rootID,
+		&comment.Depth, &comment.Content, &comment.Removed, &comment.Status, &moderationReason, &moderatedBy, &moderatedAt,
+		&filterAction, &filterName, &filterReason, &comment.CreatedAt, &comment.UpdatedAt, &deletedAt); err != nil {
+		return err
+	}
+
+	if parentID.Valid {
+		comment.ParentID = &parentID.Int64
+	}
+	if rootID.Valid {
+		comment.RootID = &rootID.Int64
+	}
+	comment.ModerationReason = moderationReason.String
+	if moderatedBy.Valid {
+		comment.ModeratedBy = &moderatedBy.Int64
+	}
+	if moderatedAt.Valid {
+		comment.ModeratedAt = &moderatedAt.Time
+	}
+	if filterAction.Valid {
+		comment.FilterVerdict = &domain.FilterVerdict{
+			Action: domain.FilterAction(filterAction.String),
+			Filter: filterName.String,
+			Reason: filterReason.String,
+		}
+	}
+	if deletedAt.Valid {
+		comment.DeletedAt = &deletedAt.Time
+	}
+	return nil
 }
 
 // Create crea un nuevo comentario en la base de datos
-func (r *CommentRepositorySQL) Create(comment *domain.Comment) error {
-	query := `INSERT INTO comments (blog_id, user_id, content) VALUES (?, ?, ?)`
-	result, err := r.db.Exec(query, comment.BlogID, comment.UserID, comment.Content)
+func (r *CommentRepositorySQL) Create(ctx context.Context, comment *domain.Comment) error {
+	now := time.Now()
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+
+	filterAction, filterName, filterReason := verdictColumns(comment.FilterVerdict)
+
+	query := `INSERT INTO comments (blog_id, user_id, parent_id, root_id, depth, content, status,
+		filter_action, filter_name, filter_reason, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, comment.BlogID, comment.UserID, comment.ParentID, comment.RootID, comment.Depth, comment.Content,
+		comment.Status, filterAction, filterName, filterReason, comment.CreatedAt, comment.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("error creando comentario: %w", err)
 	}
@@ -34,12 +103,21 @@ func (r *CommentRepositorySQL) Create(comment *domain.Comment) error {
 	return nil
 }
 
-// FindByID busca un comentario por su ID
-func (r *CommentRepositorySQL) FindByID(id int64) (*domain.Comment, error) {
-	query := `SELECT id, blog_id, user_id, content FROM comments WHERE id = ?`
+// verdictColumns convierte el veredicto del filtro de contenido en los valores de sus columnas
+func verdictColumns(verdict *domain.FilterVerdict) (action, name, reason interface{}) {
+	if verdict == nil {
+		return nil, nil, nil
+	}
+	return verdict.Action, verdict.Filter, verdict.Reason
+}
+
+// FindByID busca un comentario por su ID. Los comentarios eliminados que
+// conservan respuestas se devuelven con Removed; el resto de la papelera no.
+func (r *CommentRepositorySQL) FindByID(ctx context.Context, id int64) (*domain.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE id = ? AND ` + commentVisible
 	comment := &domain.Comment{}
 	
-	err := r.db.QueryRow(query, id).Scan(&comment.ID, &comment.BlogID, &comment.UserID, &comment.Content)
+	err := scanComment(conn(ctx, r.db).QueryRowContext(ctx, query, id), comment)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrCommentNotFound
@@ -50,44 +128,149 @@ func (r *CommentRepositorySQL) FindByID(id int64) (*domain.Comment, error) {
 	return comment, nil
 }
 
-// FindByBlogID busca todos los comentarios de un blog
-func (r *CommentRepositorySQL) FindByBlogID(blogID int64) ([]domain.Comment, error) {
-	query := `SELECT id, blog_id, user_id, content FROM comments WHERE blog_id = ? ORDER BY id`
-	rows, err := r.db.Query(query, blogID)
+// FindByIDForUpdate busca un comentario por su ID y lo bloquea hasta que
+// termine la transacción de ctx, de modo que nadie pueda modificarlo mientras tanto
+func (r *CommentRepositorySQL) FindByIDForUpdate(ctx context.Context, id int64) (*domain.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE id = ? AND ` + commentVisible + r.dialect.forUpdate()
+	comment := &domain.Comment{}
+
+	err := scanComment(conn(ctx, r.db).QueryRowContext(ctx, query, id), comment)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("error bloqueando comentario: %w", err)
+	}
+
+	return comment, nil
+}
+
+// FindByBlogID busca una página de comentarios raíz de un blog
+func (r *CommentRepositorySQL) FindByBlogID(ctx context.Context, blogID int64, page domain.PageRequest, visibility domain.CommentVisibility) (*domain.Page[domain.Comment], error) {
+	// Los comentarios raíz no visibles se incluyen si tienen alguna respuesta visible
+	statusCondition, statusArgs := commentVisibleTo(visibility)
+	args := append([]interface{}{blogID}, statusArgs...)
+	args = append(args, statusArgs...)
+	filter := `blog_id = ? AND parent_id IS NULL AND ` + commentVisible + ` AND (` + statusCondition +
+		` OR EXISTS (SELECT 1 FROM comments replies WHERE replies.root_id = comments.id AND ` + commentVisible + ` AND ` + statusCondition + `))`
+	comments, err := r.listPage(ctx, page, filter, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error buscando comentarios por blog: %w", err)
 	}
-	defer rows.Close()
+	return comments, nil
+}
 
-	var comments []domain.Comment
-	for rows.Next() {
-		var comment domain.Comment
-		if err := rows.Scan(&comment.ID, &comment.BlogID, &comment.UserID, &comment.Content); err != nil {
-			return nil, fmt.Errorf("error escaneando comentario: %w", err)
+// FindByUserID busca una página de comentarios aprobados de un usuario
+func (r *CommentRepositorySQL) FindByUserID(ctx context.Context, userID int64, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	comments, err := r.listPage(ctx, page, `user_id = ? AND deleted_at IS NULL AND status = ?`, userID, domain.CommentStatusApproved)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando comentarios por usuario: %w", err)
+	}
+	return comments, nil
+}
+
+// listPage consulta una página de comentarios con paginación por clave (keyset).
+// filter es una condición sobre las columnas del comentario.
+func (r *CommentRepositorySQL) listPage(ctx context.Context, page domain.PageRequest, filter string, args ...interface{}) (*domain.Page[domain.Comment], error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE ` + filter
+
+	orderBy := `id DESC`
+	if page.Sort == domain.SortOldest {
+		orderBy = `id ASC`
+		if page.After != nil {
+			query += ` AND id > ?`
+			args = append(args, page.After.ID)
 		}
-		comments = append(comments, comment)
+	} else if page.After != nil {
+		query += ` AND id < ?`
+		args = append(args, page.After.ID)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterando comentarios: %w", err)
+	query += ` ORDER BY ` + orderBy + ` LIMIT ?`
+	args = append(args, page.Limit+1)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return comments, nil
+	comments, err := scanComments(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewPage(comments, page, commentCursor), nil
 }
 
-// FindByUserID busca todos los comentarios de un usuario
-func (r *CommentRepositorySQL) FindByUserID(userID int64) ([]domain.Comment, error) {
-	query := `SELECT id, blog_id, user_id, content FROM comments WHERE user_id = ? ORDER BY id`
-	rows, err := r.db.Query(query, userID)
+// FindByRootIDs busca todas las respuestas de los hilos indicados, sea cual sea
+// su estado de moderación, ordenadas por ID
+func (r *CommentRepositorySQL) FindByRootIDs(ctx context.Context, rootIDs []int64) ([]domain.Comment, error) {
+	if len(rootIDs) == 0 {
+		return []domain.Comment{}, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(rootIDs)), ",")
+	args := make([]interface{}, len(rootIDs))
+	for i, id := range rootIDs {
+		args[i] = id
+	}
+
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE root_id IN (` + placeholders + `) AND ` + commentVisible + ` ORDER BY id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("error buscando comentarios por usuario: %w", err)
+		return nil, fmt.Errorf("error buscando respuestas: %w", err)
 	}
 	defer rows.Close()
 
+	return scanComments(rows)
+}
+
+// CountReplies cuenta las respuestas directas de un comentario
+func (r *CommentRepositorySQL) CountReplies(ctx context.Context, id int64) (int64, error) {
+	query := `SELECT COUNT(*) FROM comments WHERE parent_id = ? AND ` + commentVisible
+	var count int64
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error contando respuestas: %w", err)
+	}
+	return count, nil
+}
+
+// FindRecentByUser busca los comentarios de un usuario creados desde since,
+// sea cual sea su estado de moderación
+func (r *CommentRepositorySQL) FindRecentByUser(ctx context.Context, userID int64, since time.Time) ([]domain.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE user_id = ? AND created_at >= ? AND deleted_at IS NULL ORDER BY id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando comentarios recientes: %w", err)
+	}
+	defer rows.Close()
+
+	return scanComments(rows)
+}
+
+// ListForModeration lista una página de la cola de moderación
+func (r *CommentRepositorySQL) ListForModeration(ctx context.Context, filter domain.ModerationFilter, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	condition := `status = ? AND deleted_at IS NULL`
+	args := []interface{}{filter.Status}
+	if filter.BlogID != 0 {
+		condition += ` AND blog_id = ?`
+		args = append(args, filter.BlogID)
+	}
+
+	comments, err := r.listPage(ctx, page, condition, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listando cola de moderación: %w", err)
+	}
+	return comments, nil
+}
+
+// scanComments lee todas las filas de comentarios de rows
+func scanComments(rows *sql.Rows) ([]domain.Comment, error) {
 	var comments []domain.Comment
 	for rows.Next() {
 		var comment domain.Comment
-		if err := rows.Scan(&comment.ID, &comment.BlogID, &comment.UserID, &comment.Content); err != nil {
+		if err := scanComment(rows, &comment); err != nil {
 			return nil, fmt.Errorf("error escaneando comentario: %w", err)
 		}
 		comments = append(comments, comment)
@@ -100,10 +283,20 @@ func (r *CommentRepositorySQL) FindByUserID(userID int64) ([]domain.Comment, err
 	return comments, nil
 }
 
+// commentCursor obtiene la posición de un comentario para la siguiente página
+func commentCursor(comment domain.Comment) domain.Cursor {
+	return domain.Cursor{ID: comment.ID}
+}
+
 // Update actualiza un comentario existente
-func (r *CommentRepositorySQL) Update(comment *domain.Comment) error {
-	query := `UPDATE comments SET content = ? WHERE id = ?`
-	result, err := r.db.Exec(query, comment.Content, comment.ID)
+func (r *CommentRepositorySQL) Update(ctx context.Context, comment *domain.Comment) error {
+	comment.UpdatedAt = time.Now()
+
+	filterAction, filterName, filterReason := verdictColumns(comment.FilterVerdict)
+
+	query := `UPDATE comments SET content = ?, status = ?, filter_action = ?, filter_name = ?, filter_reason = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, comment.Content, comment.Status, filterAction, filterName, filterReason, comment.UpdatedAt, comment.ID)
 	if err != nil {
 		return fmt.Errorf("error actualizando comentario: %w", err)
 	}
@@ -120,10 +313,52 @@ func (r *CommentRepositorySQL) Update(comment *domain.Comment) error {
 	return nil
 }
 
-// Delete elimina un comentario por su ID
-func (r *CommentRepositorySQL) Delete(id int64) error {
-	query := `DELETE FROM comments WHERE id = ?`
-	result, err := r.db.Exec(query, id)
+// UpdateModeration guarda la decisión de moderación de un comentario
+func (r *CommentRepositorySQL) UpdateModeration(ctx context.Context, comment *domain.Comment) error {
+	query := `UPDATE comments SET status = ?, moderation_reason = ?, moderated_by = ?, moderated_at = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, comment.Status, comment.ModerationReason, comment.ModeratedBy, comment.ModeratedAt, comment.ID)
+	if err != nil {
+		return fmt.Errorf("error moderando comentario: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// MarkRemoved convierte un comentario en un nodo eliminado que conserva sus
+// respuestas. El contenido se guarda en la papelera para poder restaurarlo.
+func (r *CommentRepositorySQL) MarkRemoved(ctx context.Context, id int64) error {
+	query := `UPDATE comments SET removed = TRUE, deleted_at = COALESCE(deleted_at, ?) WHERE id = ?`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("error marcando comentario como eliminado: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// Delete mueve un comentario a la papelera. Un nodo eliminado que ya no
+// conserva respuestas pasa a ser un comentario normal de la papelera.
+func (r *CommentRepositorySQL) Delete(ctx context.Context, id int64) error {
+	query := `UPDATE comments SET removed = FALSE, deleted_at = COALESCE(deleted_at, ?) WHERE id = ? AND ` + commentVisible
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("error eliminando comentario: %w", err)
 	}
@@ -139,3 +374,116 @@ func (r *CommentRepositorySQL) Delete(id int64) error {
 
 	return nil
 }
+
+// DeleteByBlog mueve a la papelera los comentarios de un blog que ya está en
+// ella, con la misma fecha de eliminación que el blog
+func (r *CommentRepositorySQL) DeleteByBlog(ctx context.Context, blogID int64) error {
+	query := `UPDATE comments SET deleted_at = (SELECT deleted_at FROM blogs WHERE id = ?) WHERE blog_id = ? AND deleted_at IS NULL`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, blogID, blogID); err != nil {
+		return fmt.Errorf("error eliminando comentarios del blog: %w", err)
+	}
+	return nil
+}
+
+// RestoreByBlog saca de la papelera los comentarios que se eliminaron junto con
+// un blog, es decir, los que tienen su misma fecha de eliminación. Debe
+// llamarse antes de restaurar el blog.
+func (r *CommentRepositorySQL) RestoreByBlog(ctx context.Context, blogID int64) error {
+	query := `UPDATE comments SET deleted_at = NULL WHERE blog_id = ? AND deleted_at = (SELECT deleted_at FROM blogs WHERE id = ?)`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, blogID, blogID); err != nil {
+		return fmt.Errorf("error restaurando comentarios del blog: %w", err)
+	}
+	return nil
+}
+
+// ExistsByUser indica si un usuario tiene algún comentario fuera de la papelera
+func (r *CommentRepositorySQL) ExistsByUser(ctx context.Context, userID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM comments WHERE user_id = ? AND deleted_at IS NULL)`
+	var exists bool
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("error verificando comentarios del usuario: %w", err)
+	}
+	return exists, nil
+}
+
+// ExistsByBlog indica si un blog tiene algún comentario fuera de la papelera,
+// sea cual sea su estado de moderación
+func (r *CommentRepositorySQL) ExistsByBlog(ctx context.Context, blogID int64) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM comments WHERE blog_id = ? AND deleted_at IS NULL)`
+	var exists bool
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, blogID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("error verificando comentarios del blog: %w", err)
+	}
+	return exists, nil
+}
+
+// ReassignUser pasa todos los comentarios de un usuario, incluidos los de la papelera, a otro
+func (r *CommentRepositorySQL) ReassignUser(ctx context.Context, fromID, toID int64) error {
+	query := `UPDATE comments SET user_id = ? WHERE user_id = ?`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, toID, fromID); err != nil {
+		return fmt.Errorf("error reasignando comentarios: %w", err)
+	}
+	return nil
+}
+
+// FindDeleted busca un comentario de la papelera por su ID
+func (r *CommentRepositorySQL) FindDeleted(ctx context.Context, id int64) (*domain.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE id = ? AND deleted_at IS NOT NULL`
+	comment := &domain.Comment{}
+
+	err := scanComment(conn(ctx, r.db).QueryRowContext(ctx, query, id), comment)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("error buscando comentario eliminado: %w", err)
+	}
+
+	return comment, nil
+}
+
+// ListDeleted lista una página de comentarios de la papelera
+func (r *CommentRepositorySQL) ListDeleted(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	comments, err := r.listPage(ctx, page, `deleted_at IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("error listando comentarios eliminados: %w", err)
+	}
+	return comments, nil
+}
+
+// Restore saca un comentario de la papelera
+func (r *CommentRepositorySQL) Restore(ctx context.Context, id int64) error {
+	query := `UPDATE comments SET removed = FALSE, deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("error restaurando comentario: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeleted elimina definitivamente los comentarios que están en la papelera
+// desde antes de before. Los nodos eliminados que conservan respuestas se mantienen.
+func (r *CommentRepositorySQL) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM comments WHERE deleted_at < ? AND removed = FALSE`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("error purgando comentarios eliminados: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	return rowsAffected, nil
+}
@@ -0,0 +1,72 @@
+package persistence
+
+import (
+	"blog-backend/adapters/persistence/migrations"
+	"blog-backend/adapters/persistence/repotest"
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// testDatabaseEnv es la variable con el DSN de una base de datos MySQL de
+// pruebas, con las mismas opciones que usa el servidor (parseTime=true y
+// clientFoundRows=true). Su contenido se borra en cada prueba.
+const testDatabaseEnv = "TEST_DATABASE_DSN"
+
+var (
+	migrateOnce sync.Once
+	migrateErr  error
+)
+
+func TestRepositoryContract(t *testing.T) {
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s no está definida", testDatabaseEnv)
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("abriendo la base de datos: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		resetDatabase(t, db)
+		return repotest.Repositories{
+			Users:           NewUserRepositorySQL(db),
+			Blogs:           NewBlogRepositorySQL(db, DialectMySQL),
+			Comments:        NewCommentRepositorySQL(db, DialectMySQL),
+			Tags:            NewTagRepositorySQL(db, DialectMySQL),
+			Tx:              NewTxManagerSQL(db),
+			BackdateComment: repotest.BackdateCommentSQL(db),
+		}
+	})
+}
+
+// resetDatabase aplica las migraciones pendientes la primera vez y elimina los
+// usuarios y etiquetas; el resto de las filas caen en cascada
+func resetDatabase(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	migrateOnce.Do(func() {
+		migrator, err := NewMigrator(db, DialectMySQL, migrations.FS, time.Minute)
+		if err != nil {
+			migrateErr = err
+			return
+		}
+		_, migrateErr = migrator.Up()
+	})
+	if migrateErr != nil {
+		t.Fatalf("aplicando migraciones: %v", migrateErr)
+	}
+
+	for _, table := range []string{"users", "tags"} {
+		if _, err := db.Exec(`DELETE FROM ` + table); err != nil {
+			t.Fatalf("vaciando %s: %v", table, err)
+		}
+	}
+}
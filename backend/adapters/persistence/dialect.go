@@ -0,0 +1,31 @@
+package persistence
+
+// Dialect identifica el motor de base de datos de los repositorios SQL. Casi
+// todas las consultas son comunes; el dialecto solo elige las pocas sentencias
+// propias de cada motor.
+type Dialect string
+
+const (
+	// DialectMySQL es MySQL o MariaDB
+	DialectMySQL Dialect = "mysql"
+	// DialectSQLite es SQLite 3.35 o posterior
+	DialectSQLite Dialect = "sqlite"
+)
+
+// insertIgnore retorna el INSERT que descarta las filas con claves duplicadas
+func (d Dialect) insertIgnore() string {
+	if d == DialectSQLite {
+		return `INSERT OR IGNORE`
+	}
+	return `INSERT IGNORE`
+}
+
+// forUpdate retorna la cláusula que bloquea las filas leídas hasta el final de
+// la transacción. SQLite no la tiene: sus transacciones toman el bloqueo de
+// escritura de toda la base de datos al empezar (_txlock=immediate).
+func (d Dialect) forUpdate() string {
+	if d == DialectSQLite {
+		return ``
+	}
+	return ` FOR UPDATE`
+}
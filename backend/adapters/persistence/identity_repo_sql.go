@@ -0,0 +1,133 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// IdentityRepositorySQL implementa la interfaz IdentityRepository usando SQL
+type IdentityRepositorySQL struct {
+	db *sql.DB
+}
+
+// NewIdentityRepositorySQL crea una nueva instancia del repositorio SQL de identidades externas
+func NewIdentityRepositorySQL(db *sql.DB) ports.IdentityRepository {
+	return &IdentityRepositorySQL{db: db}
+}
+
+// identityColumns son las columnas que lee scanIdentity, en orden
+const identityColumns = `id, user_id, provider, subject, email, created_at, last_login_at`
+
+// scanIdentity lee una identidad externa de una fila con identityColumns
+func scanIdentity(row interface{ Scan(...any) error }) (*domain.ExternalIdentity, error) {
+	identity := &domain.ExternalIdentity{}
+	var email sql.NullString
+	var lastLoginAt sql.NullTime
+
+	err := row.Scan(&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &email, &identity.CreatedAt, &lastLoginAt)
+	if err != nil {
+		return nil, err
+	}
+
+	identity.Email = email.String
+	if lastLoginAt.Valid {
+		identity.LastLoginAt = &lastLoginAt.Time
+	}
+	return identity, nil
+}
+
+// Create vincula una identidad externa con un usuario. Si la identidad ya está
+// vinculada retorna ErrIdentityLinked.
+func (r *IdentityRepositorySQL) Create(ctx context.Context, identity *domain.ExternalIdentity) error {
+	if _, err := r.FindBySubject(ctx, identity.Provider, identity.Subject); err == nil {
+		return domain.ErrIdentityLinked
+	} else if err != domain.ErrIdentityNotFound {
+		return err
+	}
+
+	identity.CreatedAt = time.Now()
+	query := `INSERT INTO user_identities (user_id, provider, subject, email, created_at, last_login_at) VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, identity.UserID, identity.Provider, identity.Subject, nullableEmail(identity.Email), identity.CreatedAt, identity.LastLoginAt)
+	if err != nil {
+		return fmt.Errorf("error vinculando identidad externa: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error obteniendo ID de la identidad externa: %w", err)
+	}
+
+	identity.ID = id
+	return nil
+}
+
+// FindBySubject busca una identidad externa por su proveedor y su identificador en él
+func (r *IdentityRepositorySQL) FindBySubject(ctx context.Context, provider, subject string) (*domain.ExternalIdentity, error) {
+	query := `SELECT ` + identityColumns + ` FROM user_identities WHERE provider = ? AND subject = ?`
+	identity, err := scanIdentity(conn(ctx, r.db).QueryRowContext(ctx, query, provider, subject))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrIdentityNotFound
+		}
+		return nil, fmt.Errorf("error buscando identidad externa: %w", err)
+	}
+	return identity, nil
+}
+
+// ListByUser lista las identidades externas vinculadas a un usuario
+func (r *IdentityRepositorySQL) ListByUser(ctx context.Context, userID int64) ([]domain.ExternalIdentity, error) {
+	query := `SELECT ` + identityColumns + ` FROM user_identities WHERE user_id = ? ORDER BY created_at, id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listando identidades externas: %w", err)
+	}
+	defer rows.Close()
+
+	identities := []domain.ExternalIdentity{}
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error escaneando identidad externa: %w", err)
+		}
+		identities = append(identities, *identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando identidades externas: %w", err)
+	}
+
+	return identities, nil
+}
+
+// UpdateLastLogin registra el último inicio de sesión con una identidad externa
+func (r *IdentityRepositorySQL) UpdateLastLogin(ctx context.Context, id int64, at time.Time) error {
+	query := `UPDATE user_identities SET last_login_at = ? WHERE id = ?`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, at, id); err != nil {
+		return fmt.Errorf("error actualizando identidad externa: %w", err)
+	}
+	return nil
+}
+
+// Delete desvincula una identidad externa de su usuario
+func (r *IdentityRepositorySQL) Delete(ctx context.Context, id, userID int64) error {
+	query := `DELETE FROM user_identities WHERE id = ? AND user_id = ?`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("error desvinculando identidad externa: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrIdentityNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LoginAttemptRepositorySQL implementa la interfaz LoginAttemptRepository usando SQL
+type LoginAttemptRepositorySQL struct {
+	db *sql.DB
+}
+
+// NewLoginAttemptRepositorySQL crea una nueva instancia del repositorio SQL de auditoría de inicios de sesión
+func NewLoginAttemptRepositorySQL(db *sql.DB) ports.LoginAttemptRepository {
+	return &LoginAttemptRepositorySQL{db: db}
+}
+
+// Create registra un intento de inicio de sesión
+func (r *LoginAttemptRepositorySQL) Create(ctx context.Context, attempt *domain.LoginAttempt) error {
+	attempt.CreatedAt = time.Now()
+
+	var reason interface{}
+	if attempt.Reason != "" {
+		reason = attempt.Reason
+	}
+
+	query := `INSERT INTO login_attempts (user_id, username, ip, user_agent, success, reason, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, attempt.UserID, attempt.Username, attempt.IP, attempt.UserAgent, attempt.Success, reason, attempt.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error registrando intento de inicio de sesión: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error obteniendo ID del intento de inicio de sesión: %w", err)
+	}
+
+	attempt.ID = id
+	return nil
+}
+
+// CountFailuresByIP cuenta los fallos por credenciales inválidas desde una IP
+// desde since y retorna también la fecha del más antiguo
+func (r *LoginAttemptRepositorySQL) CountFailuresByIP(ctx context.Context, ip string, since time.Time) (int64, time.Time, error) {
+	query := `SELECT COUNT(*), MIN(created_at) FROM login_attempts WHERE ip = ? AND reason = ? AND created_at >= ?`
+
+	var count int64
+	var oldest sql.NullTime
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, ip, domain.LoginReasonInvalidCredentials, since).Scan(&count, &oldest); err != nil {
+		return 0, time.Time{}, fmt.Errorf("error contando intentos fallidos: %w", err)
+	}
+
+	return count, oldest.Time, nil
+}
+
+// ListByUser lista una página del historial de inicios de sesión de un usuario
+func (r *LoginAttemptRepositorySQL) ListByUser(ctx context.Context, userID int64, page domain.PageRequest) (*domain.Page[domain.LoginAttempt], error) {
+	query := `SELECT id, user_id, username, ip, user_agent, success, reason, created_at FROM login_attempts WHERE user_id = ?`
+	args := []interface{}{userID}
+
+	orderBy := `id DESC`
+	if page.Sort == domain.SortOldest {
+		orderBy = `id ASC`
+		if page.After != nil {
+			query += ` AND id > ?`
+			args = append(args, page.After.ID)
+		}
+	} else if page.After != nil {
+		query += ` AND id < ?`
+		args = append(args, page.After.ID)
+	}
+
+	query += ` ORDER BY ` + orderBy + ` LIMIT ?`
+	args = append(args, page.Limit+1)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listando inicios de sesión: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []domain.LoginAttempt
+	for rows.Next() {
+		var attempt domain.LoginAttempt
+		var attemptUserID sql.NullInt64
+		var reason sql.NullString
+		if err := rows.Scan(&attempt.ID, &attemptUserID, &attempt.Username, &attempt.IP, &attempt.UserAgent,
+			&attempt.Success, &reason, &attempt.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error escaneando inicio de sesión: %w", err)
+		}
+		if attemptUserID.Valid {
+			attempt.UserID = &attemptUserID.Int64
+		}
+		attempt.Reason = reason.String
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando inicios de sesión: %w", err)
+	}
+
+	return domain.NewPage(attempts, page, loginAttemptCursor), nil
+}
+
+// loginAttemptCursor obtiene la posición de un intento para la siguiente página
+func loginAttemptCursor(attempt domain.LoginAttempt) domain.Cursor {
+	return domain.Cursor{ID: attempt.ID}
+}
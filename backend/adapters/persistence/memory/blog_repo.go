@@ -0,0 +1,298 @@
+package memory
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+	"time"
+)
+
+// BlogRepository implementa la interfaz BlogRepository en memoria
+type BlogRepository struct {
+	store *Store
+}
+
+// NewBlogRepository crea un repositorio de blogs sobre el almacén indicado
+func NewBlogRepository(store *Store) *BlogRepository {
+	return &BlogRepository{store: store}
+}
+
+var _ ports.BlogRepository = (*BlogRepository)(nil)
+
+// Create crea un nuevo blog. El autor debe existir, como exige la clave foránea.
+func (r *BlogRepository) Create(ctx context.Context, blog *domain.Blog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[blog.AuthorID]; !ok {
+		return fmt.Errorf("error creando blog: el autor %d no existe", blog.AuthorID)
+	}
+
+	now := time.Now()
+	blog.CreatedAt = now
+	blog.UpdatedAt = now
+	r.store.lastBlogID++
+	blog.ID = r.store.lastBlogID
+
+	r.store.blogs[blog.ID] = &domain.Blog{
+		ID:                blog.ID,
+		Title:             blog.Title,
+		Content:           blog.Content,
+		AuthorID:          blog.AuthorID,
+		Status:            blog.Status,
+		PublishedAt:       blog.PublishedAt,
+		CommentModeration: blog.CommentModeration,
+		CreatedAt:         blog.CreatedAt,
+		UpdatedAt:         blog.UpdatedAt,
+	}
+	return nil
+}
+
+// FindByID busca un blog por su ID
+func (r *BlogRepository) FindByID(ctx context.Context, id int64) (*domain.Blog, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	blog, ok := r.store.blogs[id]
+	if !ok || blog.DeletedAt != nil {
+		return nil, domain.ErrBlogNotFound
+	}
+	found := r.read(blog)
+	return &found, nil
+}
+
+// FindByIDForUpdate busca un blog por su ID. No necesita bloquearlo: TxManager
+// ejecuta las transacciones de una en una.
+func (r *BlogRepository) FindByIDForUpdate(ctx context.Context, id int64) (*domain.Blog, error) {
+	return r.FindByID(ctx, id)
+}
+
+// read copia un blog guardado junto con su número de comentarios aprobados
+func (r *BlogRepository) read(blog *domain.Blog) domain.Blog {
+	found := *blog
+	found.CommentsCount = r.store.approvedComments(blog.ID)
+	return found
+}
+
+// FindByAuthorID busca una página de blogs de un autor
+func (r *BlogRepository) FindByAuthorID(ctx context.Context, authorID int64, filter domain.BlogFilter, page domain.PageRequest) (*domain.Page[domain.Blog], error) {
+	return r.listPage(filter, page, func(blog *domain.Blog) bool {
+		return blog.DeletedAt == nil && blog.AuthorID == authorID
+	}), nil
+}
+
+// List lista una página de blogs
+func (r *BlogRepository) List(ctx context.Context, filter domain.BlogFilter, page domain.PageRequest) (*domain.Page[domain.Blog], error) {
+	return r.listPage(filter, page, func(blog *domain.Blog) bool {
+		return blog.DeletedAt == nil
+	}), nil
+}
+
+// FindDeleted busca un blog de la papelera por su ID
+func (r *BlogRepository) FindDeleted(ctx context.Context, id int64) (*domain.Blog, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	blog, ok := r.store.blogs[id]
+	if !ok || blog.DeletedAt == nil {
+		return nil, domain.ErrBlogNotFound
+	}
+	found := r.read(blog)
+	return &found, nil
+}
+
+// ListDeleted lista una página de blogs de la papelera
+func (r *BlogRepository) ListDeleted(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.Blog], error) {
+	return r.listPage(domain.BlogFilter{}, page, func(blog *domain.Blog) bool {
+		return blog.DeletedAt != nil
+	}), nil
+}
+
+// listPage obtiene una página de los blogs que cumplen condition y el filtro
+func (r *BlogRepository) listPage(filter domain.BlogFilter, page domain.PageRequest, condition func(blog *domain.Blog) bool) *domain.Page[domain.Blog] {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	now := time.Now()
+	var blogs []domain.Blog
+	for _, blog := range r.store.blogs {
+		if !condition(blog) {
+			continue
+		}
+		if filter.PublishedOnly && !blog.IsPublic(now) {
+			continue
+		}
+		if filter.Tag != "" && !r.hasTag(blog.ID, filter.Tag) {
+			continue
+		}
+		blogs = append(blogs, r.read(blog))
+	}
+
+	cursorOf := func(blog domain.Blog) domain.Cursor {
+		at := blog.SortTime()
+		return domain.Cursor{ID: blog.ID, Count: blog.CommentsCount, At: &at}
+	}
+
+	if page.Sort == domain.SortMostCommented {
+		return newPage(blogs, page,
+			func(a, b domain.Blog) bool {
+				if a.CommentsCount != b.CommentsCount {
+					return a.CommentsCount > b.CommentsCount
+				}
+				return a.ID > b.ID
+			},
+			func(blog domain.Blog) bool {
+				return page.After == nil || blog.CommentsCount < page.After.Count ||
+					(blog.CommentsCount == page.After.Count && blog.ID < page.After.ID)
+			},
+			cursorOf,
+		)
+	}
+
+	if page.Sort != domain.SortOldest {
+		return newPage(blogs, page,
+			func(a, b domain.Blog) bool {
+				if at, bt := a.SortTime(), b.SortTime(); !at.Equal(bt) {
+					return at.After(bt)
+				}
+				return a.ID > b.ID
+			},
+			func(blog domain.Blog) bool {
+				if page.After == nil || page.After.At == nil {
+					return true
+				}
+				at := blog.SortTime()
+				return at.Before(*page.After.At) || (at.Equal(*page.After.At) && blog.ID < page.After.ID)
+			},
+			cursorOf,
+		)
+	}
+
+	less, after := idOrder(page)
+	return newPage(blogs, page,
+		func(a, b domain.Blog) bool { return less(a.ID, b.ID) },
+		func(blog domain.Blog) bool { return after(blog.ID) },
+		cursorOf,
+	)
+}
+
+// ExistsByAuthor indica si un autor tiene algún blog fuera de la papelera
+func (r *BlogRepository) ExistsByAuthor(ctx context.Context, authorID int64) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, blog := range r.store.blogs {
+		if blog.AuthorID == authorID && blog.DeletedAt == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasTag indica si el blog tiene la etiqueta con el slug indicado
+func (r *BlogRepository) hasTag(blogID int64, slug string) bool {
+	for _, tagID := range r.store.blogTags[blogID] {
+		if tag, ok := r.store.tags[tagID]; ok && tag.Slug == slug {
+			return true
+		}
+	}
+	return false
+}
+
+// Update actualiza un blog existente
+func (r *BlogRepository) Update(ctx context.Context, blog *domain.Blog) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.blogs[blog.ID]
+	if !ok || stored.DeletedAt != nil {
+		return domain.ErrBlogNotFound
+	}
+
+	blog.UpdatedAt = time.Now()
+	stored.Title = blog.Title
+	stored.Content = blog.Content
+	stored.Status = blog.Status
+	stored.PublishedAt = blog.PublishedAt
+	stored.CommentModeration = blog.CommentModeration
+	stored.UpdatedAt = blog.UpdatedAt
+	return nil
+}
+
+// ReassignAuthor pasa todos los blogs de un autor, incluidos los de la papelera, a otro
+func (r *BlogRepository) ReassignAuthor(ctx context.Context, fromID, toID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[toID]; !ok {
+		return fmt.Errorf("error reasignando blogs: el autor %d no existe", toID)
+	}
+
+	for _, blog := range r.store.blogs {
+		if blog.AuthorID == fromID {
+			blog.AuthorID = toID
+		}
+	}
+	return nil
+}
+
+// Delete mueve un blog a la papelera
+func (r *BlogRepository) Delete(ctx context.Context, id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.blogs[id]
+	if !ok || stored.DeletedAt != nil {
+		return domain.ErrBlogNotFound
+	}
+
+	now := time.Now()
+	stored.DeletedAt = &now
+	return nil
+}
+
+// PublishDue publica los blogs programados cuya fecha de publicación ya pasó
+func (r *BlogRepository) PublishDue(ctx context.Context, now time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var published int64
+	for _, blog := range r.store.blogs {
+		if blog.Status == domain.BlogStatusScheduled && blog.PublishedAt != nil && !blog.PublishedAt.After(now) && blog.DeletedAt == nil {
+			blog.Status = domain.BlogStatusPublished
+			blog.UpdatedAt = now
+			published++
+		}
+	}
+	return published, nil
+}
+
+// Restore saca un blog de la papelera
+func (r *BlogRepository) Restore(ctx context.Context, id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.blogs[id]
+	if !ok || stored.DeletedAt == nil {
+		return domain.ErrBlogNotFound
+	}
+
+	stored.DeletedAt = nil
+	return nil
+}
+
+// PurgeDeleted elimina definitivamente los blogs que están en la papelera desde antes de before
+func (r *BlogRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var purged int64
+	for id, blog := range r.store.blogs {
+		if blog.DeletedAt != nil && blog.DeletedAt.Before(before) {
+			r.store.deleteBlog(id)
+			purged++
+		}
+	}
+	return purged, nil
+}
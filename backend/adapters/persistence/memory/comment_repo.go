@@ -0,0 +1,397 @@
+package memory
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// CommentRepository implementa la interfaz CommentRepository en memoria
+type CommentRepository struct {
+	store *Store
+}
+
+// NewCommentRepository crea un repositorio de comentarios sobre el almacén indicado
+func NewCommentRepository(store *Store) *CommentRepository {
+	return &CommentRepository{store: store}
+}
+
+var _ ports.CommentRepository = (*CommentRepository)(nil)
+
+// isVisible indica si el comentario está activo o es un nodo eliminado que conserva respuestas
+func isVisible(comment *domain.Comment) bool {
+	return comment.DeletedAt == nil || comment.Removed
+}
+
+// hasVisibleReply indica si el hilo de rootID tiene alguna respuesta que
+// visibility permite ver. Requiere el bloqueo del almacén.
+func (r *CommentRepository) hasVisibleReply(rootID int64, visibility domain.CommentVisibility) bool {
+	for _, comment := range r.store.comments {
+		if comment.RootID != nil && *comment.RootID == rootID && isVisible(comment) && visibility.Allows(comment) {
+			return true
+		}
+	}
+	return false
+}
+
+// Create crea un nuevo comentario. El blog, el autor y el comentario padre
+// deben existir, como exigen las claves foráneas.
+func (r *CommentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.blogs[comment.BlogID]; !ok {
+		return fmt.Errorf("error creando comentario: el blog %d no existe", comment.BlogID)
+	}
+	if _, ok := r.store.users[comment.UserID]; !ok {
+		return fmt.Errorf("error creando comentario: el usuario %d no existe", comment.UserID)
+	}
+	if comment.ParentID != nil {
+		if _, ok := r.store.comments[*comment.ParentID]; !ok {
+			return fmt.Errorf("error creando comentario: el comentario %d no existe", *comment.ParentID)
+		}
+	}
+
+	now := time.Now()
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+	r.store.lastCommentID++
+	comment.ID = r.store.lastCommentID
+
+	r.store.comments[comment.ID] = &domain.Comment{
+		ID:            comment.ID,
+		BlogID:        comment.BlogID,
+		UserID:        comment.UserID,
+		ParentID:      comment.ParentID,
+		RootID:        comment.RootID,
+		Depth:         comment.Depth,
+		Content:       comment.Content,
+		Status:        comment.Status,
+		FilterVerdict: comment.FilterVerdict,
+		CreatedAt:     comment.CreatedAt,
+		UpdatedAt:     comment.UpdatedAt,
+	}
+	return nil
+}
+
+// FindByID busca un comentario por su ID. Los comentarios eliminados que
+// conservan respuestas se devuelven con Removed; el resto de la papelera no.
+func (r *CommentRepository) FindByID(ctx context.Context, id int64) (*domain.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comment, ok := r.store.comments[id]
+	if !ok || !isVisible(comment) {
+		return nil, domain.ErrCommentNotFound
+	}
+	found := *comment
+	return &found, nil
+}
+
+// FindByIDForUpdate busca un comentario por su ID. No necesita bloquearlo:
+// TxManager ejecuta las transacciones de una en una.
+func (r *CommentRepository) FindByIDForUpdate(ctx context.Context, id int64) (*domain.Comment, error) {
+	return r.FindByID(ctx, id)
+}
+
+// FindByBlogID busca una página de comentarios raíz de un blog
+func (r *CommentRepository) FindByBlogID(ctx context.Context, blogID int64, page domain.PageRequest, visibility domain.CommentVisibility) (*domain.Page[domain.Comment], error) {
+	return r.listPage(page, func(comment *domain.Comment) bool {
+		return comment.BlogID == blogID && comment.ParentID == nil && isVisible(comment) &&
+			(visibility.Allows(comment) || r.hasVisibleReply(comment.ID, visibility))
+	}), nil
+}
+
+// FindByUserID busca una página de comentarios aprobados de un usuario
+func (r *CommentRepository) FindByUserID(ctx context.Context, userID int64, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	return r.listPage(page, func(comment *domain.Comment) bool {
+		return comment.UserID == userID && comment.DeletedAt == nil && comment.Status == domain.CommentStatusApproved
+	}), nil
+}
+
+// listPage obtiene una página de los comentarios que cumplen condition
+func (r *CommentRepository) listPage(page domain.PageRequest, condition func(comment *domain.Comment) bool) *domain.Page[domain.Comment] {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	less, after := idOrder(page)
+	return newPage(r.filter(condition), page,
+		func(a, b domain.Comment) bool { return less(a.ID, b.ID) },
+		func(comment domain.Comment) bool { return after(comment.ID) },
+		func(comment domain.Comment) domain.Cursor { return domain.Cursor{ID: comment.ID} },
+	)
+}
+
+// filter copia los comentarios que cumplen condition ordenados por ID
+func (r *CommentRepository) filter(condition func(comment *domain.Comment) bool) []domain.Comment {
+	var comments []domain.Comment
+	for _, comment := range r.store.comments {
+		if condition(comment) {
+			comments = append(comments, *comment)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+	return comments
+}
+
+// FindByRootIDs busca todas las respuestas de los hilos indicados, sea cual sea
+// su estado de moderación, ordenadas por ID
+func (r *CommentRepository) FindByRootIDs(ctx context.Context, rootIDs []int64) ([]domain.Comment, error) {
+	if len(rootIDs) == 0 {
+		return []domain.Comment{}, nil
+	}
+
+	roots := make(map[int64]bool, len(rootIDs))
+	for _, id := range rootIDs {
+		roots[id] = true
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.filter(func(comment *domain.Comment) bool {
+		return comment.RootID != nil && roots[*comment.RootID] && isVisible(comment)
+	}), nil
+}
+
+// CountReplies cuenta las respuestas directas de un comentario
+func (r *CommentRepository) CountReplies(ctx context.Context, id int64) (int64, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var count int64
+	for _, comment := range r.store.comments {
+		if comment.ParentID != nil && *comment.ParentID == id && isVisible(comment) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// FindRecentByUser busca los comentarios de un usuario creados desde since,
+// sea cual sea su estado de moderación
+func (r *CommentRepository) FindRecentByUser(ctx context.Context, userID int64, since time.Time) ([]domain.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.filter(func(comment *domain.Comment) bool {
+		return comment.UserID == userID && !comment.CreatedAt.Before(since) && comment.DeletedAt == nil
+	}), nil
+}
+
+// ExistsByUser indica si un usuario tiene algún comentario fuera de la papelera
+func (r *CommentRepository) ExistsByUser(ctx context.Context, userID int64) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, comment := range r.store.comments {
+		if comment.UserID == userID && comment.DeletedAt == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListForModeration lista una página de la cola de moderación
+func (r *CommentRepository) ListForModeration(ctx context.Context, filter domain.ModerationFilter, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	return r.listPage(page, func(comment *domain.Comment) bool {
+		return comment.Status == filter.Status && comment.DeletedAt == nil && (filter.BlogID == 0 || comment.BlogID == filter.BlogID)
+	}), nil
+}
+
+// Update actualiza un comentario existente
+func (r *CommentRepository) Update(ctx context.Context, comment *domain.Comment) error {
+	return r.update(comment.ID, func(stored *domain.Comment) {
+		comment.UpdatedAt = time.Now()
+		stored.Content = comment.Content
+		stored.Status = comment.Status
+		stored.FilterVerdict = comment.FilterVerdict
+		stored.UpdatedAt = comment.UpdatedAt
+	})
+}
+
+// UpdateModeration guarda la decisión de moderación de un comentario
+func (r *CommentRepository) UpdateModeration(ctx context.Context, comment *domain.Comment) error {
+	return r.update(comment.ID, func(stored *domain.Comment) {
+		stored.Status = comment.Status
+		stored.ModerationReason = comment.ModerationReason
+		stored.ModeratedBy = comment.ModeratedBy
+		stored.ModeratedAt = comment.ModeratedAt
+	})
+}
+
+// update aplica fn a un comentario activo o retorna ErrCommentNotFound
+func (r *CommentRepository) update(id int64, fn func(stored *domain.Comment)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.comments[id]
+	if !ok || stored.DeletedAt != nil {
+		return domain.ErrCommentNotFound
+	}
+	fn(stored)
+	return nil
+}
+
+// MarkRemoved convierte un comentario en un nodo eliminado que conserva sus
+// respuestas. El contenido se guarda en la papelera para poder restaurarlo.
+func (r *CommentRepository) MarkRemoved(ctx context.Context, id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.comments[id]
+	if !ok {
+		return domain.ErrCommentNotFound
+	}
+
+	stored.Removed = true
+	if stored.DeletedAt == nil {
+		now := time.Now()
+		stored.DeletedAt = &now
+	}
+	return nil
+}
+
+// Delete mueve un comentario a la papelera. Un nodo eliminado que ya no
+// conserva respuestas pasa a ser un comentario normal de la papelera.
+func (r *CommentRepository) Delete(ctx context.Context, id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.comments[id]
+	if !ok || !isVisible(stored) {
+		return domain.ErrCommentNotFound
+	}
+
+	stored.Removed = false
+	if stored.DeletedAt == nil {
+		now := time.Now()
+		stored.DeletedAt = &now
+	}
+	return nil
+}
+
+// ExistsByBlog indica si un blog tiene algún comentario fuera de la papelera,
+// sea cual sea su estado de moderación
+func (r *CommentRepository) ExistsByBlog(ctx context.Context, blogID int64) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, comment := range r.store.comments {
+		if comment.BlogID == blogID && comment.DeletedAt == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteByBlog mueve a la papelera los comentarios de un blog que ya está en
+// ella, con la misma fecha de eliminación que el blog
+func (r *CommentRepository) DeleteByBlog(ctx context.Context, blogID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	blog, ok := r.store.blogs[blogID]
+	if !ok || blog.DeletedAt == nil {
+		return nil
+	}
+
+	for _, comment := range r.store.comments {
+		if comment.BlogID == blogID && comment.DeletedAt == nil {
+			deletedAt := *blog.DeletedAt
+			comment.DeletedAt = &deletedAt
+		}
+	}
+	return nil
+}
+
+// ReassignUser pasa todos los comentarios de un usuario, incluidos los de la papelera, a otro
+func (r *CommentRepository) ReassignUser(ctx context.Context, fromID, toID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.users[toID]; !ok {
+		return fmt.Errorf("error reasignando comentarios: el usuario %d no existe", toID)
+	}
+
+	for _, comment := range r.store.comments {
+		if comment.UserID == fromID {
+			comment.UserID = toID
+		}
+	}
+	return nil
+}
+
+// FindDeleted busca un comentario de la papelera por su ID
+func (r *CommentRepository) FindDeleted(ctx context.Context, id int64) (*domain.Comment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	comment, ok := r.store.comments[id]
+	if !ok || comment.DeletedAt == nil {
+		return nil, domain.ErrCommentNotFound
+	}
+	found := *comment
+	return &found, nil
+}
+
+// ListDeleted lista una página de comentarios de la papelera
+func (r *CommentRepository) ListDeleted(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	return r.listPage(page, func(comment *domain.Comment) bool {
+		return comment.DeletedAt != nil
+	}), nil
+}
+
+// Restore saca un comentario de la papelera
+func (r *CommentRepository) Restore(ctx context.Context, id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.comments[id]
+	if !ok || stored.DeletedAt == nil {
+		return domain.ErrCommentNotFound
+	}
+
+	stored.Removed = false
+	stored.DeletedAt = nil
+	return nil
+}
+
+// RestoreByBlog saca de la papelera los comentarios que se eliminaron junto con
+// un blog, es decir, los que tienen su misma fecha de eliminación. Debe
+// llamarse antes de restaurar el blog.
+func (r *CommentRepository) RestoreByBlog(ctx context.Context, blogID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	blog, ok := r.store.blogs[blogID]
+	if !ok || blog.DeletedAt == nil {
+		return nil
+	}
+
+	for _, comment := range r.store.comments {
+		if comment.BlogID == blogID && comment.DeletedAt != nil && comment.DeletedAt.Equal(*blog.DeletedAt) {
+			comment.DeletedAt = nil
+		}
+	}
+	return nil
+}
+
+// PurgeDeleted elimina definitivamente los comentarios que están en la papelera
+// desde antes de before. Los nodos eliminados que conservan respuestas se mantienen.
+func (r *CommentRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var purged int64
+	for id, comment := range r.store.comments {
+		if comment.DeletedAt != nil && comment.DeletedAt.Before(before) && !comment.Removed {
+			r.store.deleteComment(id)
+			purged++
+		}
+	}
+	return purged, nil
+}
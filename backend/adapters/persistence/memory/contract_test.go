@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"blog-backend/adapters/persistence/repotest"
+	"testing"
+	"time"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := NewStore()
+		return repotest.Repositories{
+			Users:    NewUserRepository(store),
+			Blogs:    NewBlogRepository(store),
+			Comments: NewCommentRepository(store),
+			Tags:     NewTagRepository(store),
+			Tx:       NewTxManager(store),
+			BackdateComment: func(id int64, deletedAt time.Time) error {
+				store.mu.Lock()
+				defer store.mu.Unlock()
+				if comment, ok := store.comments[id]; ok && comment.DeletedAt != nil {
+					comment.DeletedAt = &deletedAt
+				}
+				return nil
+			},
+		}
+	})
+}
@@ -0,0 +1,130 @@
+// Package memory contiene implementaciones en memoria de los puertos de
+// persistencia, pensadas para pruebas y entornos sin base de datos.
+package memory
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SearchRepository implementa la interfaz SearchRepository en memoria.
+// Los documentos se registran explícitamente con Index.
+type SearchRepository struct {
+	mu        sync.RWMutex
+	documents map[searchKey]domain.SearchResult
+}
+
+type searchKey struct {
+	kind domain.SearchKind
+	id   int64
+}
+
+// NewSearchRepository crea un índice de búsqueda vacío
+func NewSearchRepository() *SearchRepository {
+	return &SearchRepository{documents: make(map[searchKey]domain.SearchResult)}
+}
+
+var _ ports.SearchRepository = (*SearchRepository)(nil)
+
+// Index agrega o reemplaza un documento en el índice
+func (r *SearchRepository) Index(ctx context.Context, document domain.SearchResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.documents[documentKey(document)] = document
+}
+
+// Remove elimina un documento del índice
+func (r *SearchRepository) Remove(ctx context.Context, kind domain.SearchKind, id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.documents, searchKey{kind: kind, id: id})
+}
+
+// Search busca documentos que contengan todos los términos, ordenados por relevancia
+func (r *SearchRepository) Search(ctx context.Context, query domain.SearchQuery) ([]domain.SearchResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []domain.SearchResult
+	for _, document := range r.documents {
+		if !matchesFilters(document, query) {
+			continue
+		}
+
+		score, ok := scoreDocument(document, query.Terms)
+		if !ok {
+			continue
+		}
+
+		document.Score = score
+		results = append(results, document)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+
+	if query.Offset >= len(results) {
+		return []domain.SearchResult{}, nil
+	}
+	results = results[query.Offset:]
+	if len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+// documentKey identifica un documento por su tipo e ID
+func documentKey(document domain.SearchResult) searchKey {
+	if document.Kind == domain.SearchKindComment {
+		return searchKey{kind: document.Kind, id: document.CommentID}
+	}
+	return searchKey{kind: document.Kind, id: document.BlogID}
+}
+
+// matchesFilters aplica los filtros de tipo, autor y fecha
+func matchesFilters(document domain.SearchResult, query domain.SearchQuery) bool {
+	if query.Kind != "" && document.Kind != query.Kind {
+		return false
+	}
+	if query.AuthorID != 0 && document.AuthorID != query.AuthorID {
+		return false
+	}
+	if query.From != nil && document.CreatedAt.Before(*query.From) {
+		return false
+	}
+	if query.To != nil && !document.CreatedAt.Before(*query.To) {
+		return false
+	}
+	return true
+}
+
+// scoreDocument calcula la relevancia de un documento. Todos los términos deben
+// aparecer; las coincidencias en el título de un blog valen el doble.
+func scoreDocument(document domain.SearchResult, terms []domain.SearchTerm) (float64, bool) {
+	content := strings.ToLower(document.Content)
+	title := ""
+	if document.Kind == domain.SearchKindBlog {
+		title = strings.ToLower(document.Title)
+	}
+
+	var score float64
+	for _, term := range terms {
+		value := strings.ToLower(term.Value)
+		matches := strings.Count(content, value) + 2*strings.Count(title, value)
+		if matches == 0 {
+			return 0, false
+		}
+		score += float64(matches)
+	}
+
+	return score, true
+}
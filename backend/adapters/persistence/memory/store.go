@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"blog-backend/internal/domain"
+	"sort"
+	"sync"
+)
+
+// Store guarda en memoria los usuarios, blogs, comentarios y etiquetas. Sus
+// repositorios comparten el estado como las tablas de una misma base de datos,
+// de modo que el número de comentarios de un blog, el filtro por etiqueta y el
+// borrado en cascada al purgar la papelera se comportan igual que con SQL.
+// Es seguro usarlo desde varias goroutines.
+type Store struct {
+	mu sync.RWMutex
+	// txMu hace que TxManager ejecute las transacciones de una en una
+	txMu sync.Mutex
+
+	users    map[int64]*domain.User
+	blogs    map[int64]*domain.Blog
+	comments map[int64]*domain.Comment
+	tags     map[int64]*domain.Tag
+	// blogTags son los IDs de las etiquetas de cada blog
+	blogTags map[int64][]int64
+
+	lastUserID    int64
+	lastBlogID    int64
+	lastCommentID int64
+	lastTagID     int64
+}
+
+// NewStore crea un almacén vacío
+func NewStore() *Store {
+	return &Store{
+		users:    make(map[int64]*domain.User),
+		blogs:    make(map[int64]*domain.Blog),
+		comments: make(map[int64]*domain.Comment),
+		tags:     make(map[int64]*domain.Tag),
+		blogTags: make(map[int64][]int64),
+	}
+}
+
+// snapshot copia el contenido del almacén para poder deshacer una transacción
+func (s *Store) snapshot() *Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &Store{
+		users:         copyMap(s.users),
+		blogs:         copyMap(s.blogs),
+		comments:      copyMap(s.comments),
+		tags:          copyMap(s.tags),
+		blogTags:      copyBlogTags(s.blogTags),
+		lastUserID:    s.lastUserID,
+		lastBlogID:    s.lastBlogID,
+		lastCommentID: s.lastCommentID,
+		lastTagID:     s.lastTagID,
+	}
+}
+
+// restore devuelve el almacén al contenido de snapshot
+func (s *Store) restore(snapshot *Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users = snapshot.users
+	s.blogs = snapshot.blogs
+	s.comments = snapshot.comments
+	s.tags = snapshot.tags
+	s.blogTags = snapshot.blogTags
+	s.lastUserID = snapshot.lastUserID
+	s.lastBlogID = snapshot.lastBlogID
+	s.lastCommentID = snapshot.lastCommentID
+	s.lastTagID = snapshot.lastTagID
+}
+
+// copyMap copia un mapa y los elementos a los que apunta
+func copyMap[T any](items map[int64]*T) map[int64]*T {
+	copied := make(map[int64]*T, len(items))
+	for id, item := range items {
+		value := *item
+		copied[id] = &value
+	}
+	return copied
+}
+
+// copyBlogTags copia las etiquetas asignadas a cada blog
+func copyBlogTags(blogTags map[int64][]int64) map[int64][]int64 {
+	copied := make(map[int64][]int64, len(blogTags))
+	for blogID, tagIDs := range blogTags {
+		copied[blogID] = append([]int64(nil), tagIDs...)
+	}
+	return copied
+}
+
+// deleteBlog elimina definitivamente un blog con sus comentarios y etiquetas,
+// como hace el ON DELETE CASCADE de la base de datos
+func (s *Store) deleteBlog(id int64) {
+	delete(s.blogs, id)
+	delete(s.blogTags, id)
+	for commentID, comment := range s.comments {
+		if comment.BlogID == id {
+			delete(s.comments, commentID)
+		}
+	}
+}
+
+// deleteComment elimina definitivamente un comentario y, en cascada, sus respuestas
+func (s *Store) deleteComment(id int64) {
+	delete(s.comments, id)
+	for replyID, reply := range s.comments {
+		if reply.ParentID != nil && *reply.ParentID == id {
+			s.deleteComment(replyID)
+		}
+	}
+}
+
+// approvedComments cuenta los comentarios aprobados y activos de un blog
+func (s *Store) approvedComments(blogID int64) int64 {
+	var count int64
+	for _, comment := range s.comments {
+		if comment.BlogID == blogID && comment.DeletedAt == nil && comment.Status == domain.CommentStatusApproved {
+			count++
+		}
+	}
+	return count
+}
+
+// idOrder retorna el orden por ID de un listado paginado y la condición que
+// cumplen los elementos posteriores al cursor. SortOldest ordena de menor a
+// mayor; el resto de órdenes, de mayor a menor.
+func idOrder(page domain.PageRequest) (less func(a, b int64) bool, after func(id int64) bool) {
+	if page.Sort == domain.SortOldest {
+		less = func(a, b int64) bool { return a < b }
+	} else {
+		less = func(a, b int64) bool { return a > b }
+	}
+
+	after = func(id int64) bool {
+		return page.After == nil || less(page.After.ID, id)
+	}
+	return less, after
+}
+
+// newPage ordena items con less, descarta los que no cumplen after y construye
+// la página a partir de los Limit+1 primeros, como las consultas SQL paginadas
+// por clave
+func newPage[T any](items []T, page domain.PageRequest, less func(a, b T) bool, after func(T) bool, cursorOf func(T) domain.Cursor) *domain.Page[T] {
+	sort.Slice(items, func(i, j int) bool { return less(items[i], items[j]) })
+
+	var selected []T
+	for _, item := range items {
+		if len(selected) > page.Limit {
+			break
+		}
+		if after(item) {
+			selected = append(selected, item)
+		}
+	}
+
+	return domain.NewPage(selected, page, cursorOf)
+}
@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TagRepository implementa la interfaz TagRepository en memoria
+type TagRepository struct {
+	store *Store
+}
+
+// NewTagRepository crea un repositorio de etiquetas sobre el almacén indicado
+func NewTagRepository(store *Store) *TagRepository {
+	return &TagRepository{store: store}
+}
+
+var _ ports.TagRepository = (*TagRepository)(nil)
+
+// FindByID busca una etiqueta por su ID
+func (r *TagRepository) FindByID(ctx context.Context, id int64) (*domain.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tag, ok := r.store.tags[id]
+	if !ok {
+		return nil, domain.ErrTagNotFound
+	}
+	return &domain.Tag{ID: tag.ID, Name: tag.Name, Slug: tag.Slug}, nil
+}
+
+// FindBySlug busca una etiqueta por su slug
+func (r *TagRepository) FindBySlug(ctx context.Context, slug string) (*domain.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tag := r.findBySlug(slug)
+	if tag == nil {
+		return nil, domain.ErrTagNotFound
+	}
+	return &domain.Tag{ID: tag.ID, Name: tag.Name, Slug: tag.Slug}, nil
+}
+
+// findBySlug busca una etiqueta guardada por su slug
+func (r *TagRepository) findBySlug(slug string) *domain.Tag {
+	for _, tag := range r.store.tags {
+		if tag.Slug == slug {
+			return tag
+		}
+	}
+	return nil
+}
+
+// List lista todas las etiquetas con el número de blogs publicados que las usan
+func (r *TagRepository) List(ctx context.Context) ([]domain.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[int64]int64, len(r.store.tags))
+	now := time.Now()
+	for blogID, tagIDs := range r.store.blogTags {
+		blog, ok := r.store.blogs[blogID]
+		if !ok || blog.DeletedAt != nil || !blog.IsPublic(now) {
+			continue
+		}
+		for _, tagID := range tagIDs {
+			counts[tagID]++
+		}
+	}
+
+	tags := make([]domain.Tag, 0, len(r.store.tags))
+	for _, tag := range r.store.tags {
+		tags = append(tags, domain.Tag{ID: tag.ID, Name: tag.Name, Slug: tag.Slug, PostCount: counts[tag.ID]})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].PostCount != tags[j].PostCount {
+			return tags[i].PostCount > tags[j].PostCount
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+// FindByBlogIDs obtiene las etiquetas de varios blogs, agrupadas por ID de blog
+func (r *TagRepository) FindByBlogIDs(ctx context.Context, blogIDs []int64) (map[int64][]domain.Tag, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	tags := make(map[int64][]domain.Tag, len(blogIDs))
+	for _, blogID := range blogIDs {
+		for _, tagID := range r.store.blogTags[blogID] {
+			tag := r.store.tags[tagID]
+			tags[blogID] = append(tags[blogID], domain.Tag{ID: tag.ID, Name: tag.Name, Slug: tag.Slug})
+		}
+		blogTags := tags[blogID]
+		sort.Slice(blogTags, func(i, j int) bool { return blogTags[i].Name < blogTags[j].Name })
+	}
+	return tags, nil
+}
+
+// SetBlogTags reemplaza las etiquetas de un blog. Las etiquetas que no existen
+// se crean; las existentes se reutilizan por slug conservando su nombre.
+func (r *TagRepository) SetBlogTags(ctx context.Context, blogID int64, tags []domain.Tag) ([]domain.Tag, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.blogs[blogID]; !ok {
+		return nil, fmt.Errorf("error asignando etiquetas al blog: el blog %d no existe", blogID)
+	}
+
+	saved := make([]domain.Tag, 0, len(tags))
+	tagIDs := make([]int64, 0, len(tags))
+	for _, tag := range tags {
+		stored := r.findBySlug(tag.Slug)
+		if stored == nil {
+			r.store.lastTagID++
+			stored = &domain.Tag{ID: r.store.lastTagID, Name: tag.Name, Slug: tag.Slug}
+			r.store.tags[stored.ID] = stored
+		}
+		saved = append(saved, domain.Tag{ID: stored.ID, Name: stored.Name, Slug: stored.Slug})
+		tagIDs = append(tagIDs, stored.ID)
+	}
+
+	r.store.blogTags[blogID] = tagIDs
+	return saved, nil
+}
+
+// Rename cambia el nombre y el slug de una etiqueta. El slug es único, como en la tabla.
+func (r *TagRepository) Rename(ctx context.Context, tag *domain.Tag) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.tags[tag.ID]
+	if !ok {
+		return domain.ErrTagNotFound
+	}
+	if existing := r.findBySlug(tag.Slug); existing != nil && existing.ID != tag.ID {
+		return fmt.Errorf("error renombrando etiqueta: el slug %q ya existe", tag.Slug)
+	}
+
+	stored.Name = tag.Name
+	stored.Slug = tag.Slug
+	return nil
+}
+
+// Merge reasigna los blogs de la etiqueta origen a la de destino y elimina la de origen
+func (r *TagRepository) Merge(ctx context.Context, sourceID, targetID int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.tags[sourceID]; !ok {
+		return domain.ErrTagNotFound
+	}
+
+	for blogID, tagIDs := range r.store.blogTags {
+		merged := make([]int64, 0, len(tagIDs))
+		hasTarget := false
+		for _, tagID := range tagIDs {
+			if tagID == targetID {
+				hasTarget = true
+			}
+			if tagID != sourceID {
+				merged = append(merged, tagID)
+			}
+		}
+		// Los blogs que ya tienen ambas etiquetas conservan una sola asignación
+		if len(merged) < len(tagIDs) && !hasTarget {
+			merged = append(merged, targetID)
+		}
+		r.store.blogTags[blogID] = merged
+	}
+
+	delete(r.store.tags, sourceID)
+	return nil
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"blog-backend/internal/ports"
+	"context"
+)
+
+// txKey es la clave del contexto que marca una transacción en curso
+type txKey struct{}
+
+// TxManager implementa la interfaz TxManager sobre un Store. Las transacciones
+// se ejecutan de una en una y, si fallan, el almacén vuelve al contenido que
+// tenía al empezar. Las escrituras hechas fuera de una transacción mientras
+// otra está en curso también se deshacen si esta falla.
+type TxManager struct {
+	store *Store
+}
+
+// NewTxManager crea un gestor de transacciones sobre el almacén indicado
+func NewTxManager(store *Store) *TxManager {
+	return &TxManager{store: store}
+}
+
+var _ ports.TxManager = (*TxManager)(nil)
+
+// WithinTx ejecuta fn en una transacción. Se confirma si fn retorna nil y se
+// revierte si retorna un error o entra en pánico.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) == m.store {
+		return fn(ctx)
+	}
+
+	m.store.txMu.Lock()
+	defer m.store.txMu.Unlock()
+
+	snapshot := m.store.snapshot()
+	committed := false
+	defer func() {
+		if !committed {
+			m.store.restore(snapshot)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, m.store)); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
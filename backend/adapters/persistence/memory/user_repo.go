@@ -0,0 +1,323 @@
+package memory
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UserRepository implementa la interfaz UserRepository en memoria
+type UserRepository struct {
+	store *Store
+}
+
+// NewUserRepository crea un repositorio de usuarios sobre el almacén indicado
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+var _ ports.UserRepository = (*UserRepository)(nil)
+
+// Create crea un nuevo usuario. Como en la base de datos, el nombre de usuario
+// y el correo son únicos aunque el usuario que los usa esté en la papelera, y
+// el nombre reservado domain.DeletedUsername se rechaza.
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
+	if domain.IsReservedUsername(user.Username) {
+		return domain.ErrUserAlreadyExists
+	}
+	return r.insert(user)
+}
+
+// CreateDeletedUser crea el usuario domain.DeletedUsername que recibe el
+// contenido de los usuarios eliminados, sin contraseña
+func (r *UserRepository) CreateDeletedUser(ctx context.Context) (*domain.User, error) {
+	user := &domain.User{Username: domain.DeletedUsername, Role: domain.RoleUser}
+	if err := r.insert(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// insert guarda un usuario nuevo y le asigna su ID
+func (r *UserRepository) insert(user *domain.User) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := r.checkUnique(user); err != nil {
+		return err
+	}
+
+	user.CreatedAt = time.Now()
+	r.store.lastUserID++
+	user.ID = r.store.lastUserID
+
+	// Solo se guardan las columnas que inserta el repositorio SQL
+	r.store.users[user.ID] = &domain.User{
+		ID:                 user.ID,
+		Username:           user.Username,
+		Email:              user.Email,
+		Password:           user.Password,
+		Role:               user.Role,
+		CreatedAt:          user.CreatedAt,
+		EmailVerifiedAt:    user.EmailVerifiedAt,
+		VerificationSentAt: user.VerificationSentAt,
+	}
+	return nil
+}
+
+// checkUnique verifica que ningún otro usuario use el nombre o el correo de user.
+// Los nombres se comparan sin distinguir mayúsculas, como la colación de la tabla.
+// Como el repositorio SQL, no traduce la violación a un error de dominio: los
+// servicios comprueban antes que el nombre y el correo estén libres.
+func (r *UserRepository) checkUnique(user *domain.User) error {
+	for _, existing := range r.store.users {
+		if existing.ID == user.ID {
+			continue
+		}
+		if strings.EqualFold(existing.Username, user.Username) {
+			return fmt.Errorf("error guardando usuario: el nombre de usuario %q ya existe", user.Username)
+		}
+		if user.Email != "" && existing.Email == user.Email {
+			return fmt.Errorf("error guardando usuario: el correo %q ya existe", user.Email)
+		}
+	}
+	return nil
+}
+
+// FindByUsername busca un usuario por su nombre de usuario. También devuelve
+// los usuarios de la papelera (con DeletedAt) porque su nombre sigue reservado.
+func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if strings.EqualFold(user.Username, username) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+// FindByEmail busca un usuario por su correo electrónico. Como FindByUsername,
+// también devuelve los usuarios de la papelera.
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if email == "" {
+		return nil, domain.ErrUserNotFound
+	}
+	for _, user := range r.store.users {
+		if user.Email == email {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+// FindByID busca un usuario por su ID
+func (r *UserRepository) FindByID(ctx context.Context, id int64) (*domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	user, ok := r.store.users[id]
+	if !ok || user.IsDeleted() {
+		return nil, domain.ErrUserNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+// List lista todos los usuarios
+func (r *UserRepository) List(ctx context.Context) ([]domain.User, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var users []domain.User
+	for _, user := range r.store.users {
+		if !user.IsDeleted() {
+			users = append(users, *user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// ExistsByRole indica si existe al menos un usuario con el rol indicado
+func (r *UserRepository) ExistsByRole(ctx context.Context, role domain.Role) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, user := range r.store.users {
+		if user.Role == role && !user.IsDeleted() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Update actualiza un usuario existente, incluido el estado de verificación de su correo
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) error {
+	return r.update(user.ID, func(stored *domain.User) error {
+		if err := r.checkUnique(user); err != nil {
+			return err
+		}
+		stored.Username = user.Username
+		stored.Email = user.Email
+		stored.Password = user.Password
+		stored.Role = user.Role
+		stored.EmailVerifiedAt = user.EmailVerifiedAt
+		stored.VerificationSentAt = user.VerificationSentAt
+		return nil
+	})
+}
+
+// UpdateLoginState guarda los inicios de sesión fallidos y el bloqueo de un usuario
+func (r *UserRepository) UpdateLoginState(ctx context.Context, user *domain.User) error {
+	return r.update(user.ID, func(stored *domain.User) error {
+		stored.FailedLogins = user.FailedLogins
+		stored.LastFailedLoginAt = user.LastFailedLoginAt
+		stored.LockedUntil = user.LockedUntil
+		return nil
+	})
+}
+
+// RegisterLoginFailure cuenta de forma atómica un inicio de sesión fallido y
+// retorna el usuario con el estado resultante
+func (r *UserRepository) RegisterLoginFailure(ctx context.Context, userID int64, now time.Time, policy domain.LockoutPolicy) (*domain.User, error) {
+	var updated domain.User
+	err := r.update(userID, func(stored *domain.User) error {
+		stored.RegisterLoginFailure(now, policy)
+		updated = *stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// UpdateEmailVerification guarda el estado de verificación del correo de un usuario
+func (r *UserRepository) UpdateEmailVerification(ctx context.Context, user *domain.User) error {
+	return r.update(user.ID, func(stored *domain.User) error {
+		stored.EmailVerifiedAt = user.EmailVerifiedAt
+		stored.VerificationSentAt = user.VerificationSentAt
+		return nil
+	})
+}
+
+// UpdateTwoFactor guarda el secreto TOTP, su activación y el último código aceptado de un usuario
+func (r *UserRepository) UpdateTwoFactor(ctx context.Context, user *domain.User) error {
+	return r.update(user.ID, func(stored *domain.User) error {
+		stored.TOTPSecret = user.TOTPSecret
+		stored.TOTPEnabledAt = user.TOTPEnabledAt
+		stored.TOTPLastStep = user.TOTPLastStep
+		return nil
+	})
+}
+
+// update aplica fn a un usuario activo o retorna ErrUserNotFound
+func (r *UserRepository) update(id int64, fn func(stored *domain.User) error) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.users[id]
+	if !ok || stored.IsDeleted() {
+		return domain.ErrUserNotFound
+	}
+	return fn(stored)
+}
+
+// AdvanceTOTPStep registra el intervalo del último código TOTP aceptado. Si ya
+// se aceptó un código de ese intervalo o de uno posterior retorna
+// ErrInvalidTOTPCode.
+func (r *UserRepository) AdvanceTOTPStep(ctx context.Context, userID, step int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.users[userID]
+	if !ok || stored.TOTPLastStep >= step {
+		return domain.ErrInvalidTOTPCode
+	}
+	stored.TOTPLastStep = step
+	return nil
+}
+
+// Delete mueve un usuario a la papelera
+func (r *UserRepository) Delete(ctx context.Context, id int64) error {
+	return r.update(id, func(stored *domain.User) error {
+		now := time.Now()
+		stored.DeletedAt = &now
+		return nil
+	})
+}
+
+// ListDeleted lista una página de usuarios de la papelera (paginación por clave)
+func (r *UserRepository) ListDeleted(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.User], error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var users []domain.User
+	for _, user := range r.store.users {
+		if user.IsDeleted() {
+			users = append(users, *user)
+		}
+	}
+
+	less, after := idOrder(page)
+	return newPage(users, page,
+		func(a, b domain.User) bool { return less(a.ID, b.ID) },
+		func(user domain.User) bool { return after(user.ID) },
+		func(user domain.User) domain.Cursor { return domain.Cursor{ID: user.ID} },
+	), nil
+}
+
+// Restore saca un usuario de la papelera
+func (r *UserRepository) Restore(ctx context.Context, id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.users[id]
+	if !ok || !stored.IsDeleted() {
+		return domain.ErrUserNotFound
+	}
+	stored.DeletedAt = nil
+	return nil
+}
+
+// PurgeDeleted elimina definitivamente los usuarios que están en la papelera desde
+// antes de before. Sus blogs y comentarios se eliminan en cascada.
+func (r *UserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var purged int64
+	for id, user := range r.store.users {
+		if user.DeletedAt == nil || !user.DeletedAt.Before(before) {
+			continue
+		}
+
+		delete(r.store.users, id)
+		for blogID, blog := range r.store.blogs {
+			if blog.AuthorID == id {
+				r.store.deleteBlog(blogID)
+			}
+		}
+		for commentID, comment := range r.store.comments {
+			switch {
+			case comment.UserID == id:
+				r.store.deleteComment(commentID)
+			case comment.ModeratedBy != nil && *comment.ModeratedBy == id:
+				comment.ModeratedBy = nil
+			}
+		}
+		purged++
+	}
+	return purged, nil
+}
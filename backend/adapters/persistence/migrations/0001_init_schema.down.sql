@@ -0,0 +1,5 @@
+-- Revierte el esquema inicial (el orden respeta las claves foráneas)
+
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS blogs;
+DROP TABLE IF EXISTS users;
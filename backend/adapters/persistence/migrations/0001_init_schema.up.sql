@@ -1,9 +1,4 @@
--- Esquema de la base de datos para el blog con roles de usuario y comentarios
-
--- Crear base de datos si no existe
-CREATE DATABASE IF NOT EXISTS blog_db CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
-
-USE blog_db;
+-- Esquema inicial del blog: usuarios, blogs y comentarios
 
 -- Tabla de usuarios
 CREATE TABLE IF NOT EXISTS users (
@@ -13,7 +8,7 @@ CREATE TABLE IF NOT EXISTS users (
     role ENUM('Administrador', 'Usuario') NOT NULL DEFAULT 'Usuario',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
-);
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
 
 -- Tabla de blogs
 CREATE TABLE IF NOT EXISTS blogs (
@@ -23,8 +18,9 @@ CREATE TABLE IF NOT EXISTS blogs (
     author_id BIGINT NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_blogs_author_id (author_id),
     FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
-);
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
 
 -- Tabla de comentarios
 CREATE TABLE IF NOT EXISTS comments (
@@ -34,24 +30,8 @@ CREATE TABLE IF NOT EXISTS comments (
     content TEXT NOT NULL,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_comments_blog_id (blog_id),
+    INDEX idx_comments_user_id (user_id),
     FOREIGN KEY (blog_id) REFERENCES blogs(id) ON DELETE CASCADE,
     FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-);
-
--- Índices para mejorar el rendimiento
-CREATE INDEX idx_users_username ON users(username);
-CREATE INDEX idx_blogs_author_id ON blogs(author_id);
-CREATE INDEX idx_comments_blog_id ON comments(blog_id);
-CREATE INDEX idx_comments_user_id ON comments(user_id);
-
--- Insertar usuario administrador por defecto (password: admin123)
--- Nota: En producción, cambiar esta contraseña
-INSERT INTO users (username, password, role) VALUES 
-('admin', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'Administrador')
-ON DUPLICATE KEY UPDATE username=username;
-
--- Insertar algunos usuarios de ejemplo (password: user123)
-INSERT INTO users (username, password, role) VALUES 
-('usuario1', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'Usuario'),
-('usuario2', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'Usuario')
-ON DUPLICATE KEY UPDATE username=username;
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
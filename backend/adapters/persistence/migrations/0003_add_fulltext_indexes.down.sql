@@ -0,0 +1,2 @@
+ALTER TABLE comments DROP INDEX ft_comments_content;
+ALTER TABLE blogs DROP INDEX ft_blogs_title_content;
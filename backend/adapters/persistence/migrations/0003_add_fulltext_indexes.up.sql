@@ -0,0 +1,4 @@
+-- Índices FULLTEXT para la búsqueda de blogs y comentarios
+
+ALTER TABLE blogs ADD FULLTEXT INDEX ft_blogs_title_content (title, content);
+ALTER TABLE comments ADD FULLTEXT INDEX ft_comments_content (content);
@@ -0,0 +1,9 @@
+ALTER TABLE comments DROP FOREIGN KEY fk_comments_parent;
+
+ALTER TABLE comments
+    DROP INDEX idx_comments_parent_id,
+    DROP INDEX idx_comments_root_id,
+    DROP COLUMN removed,
+    DROP COLUMN depth,
+    DROP COLUMN root_id,
+    DROP COLUMN parent_id;
@@ -0,0 +1,12 @@
+-- Respuestas anidadas: cada comentario puede tener un padre dentro del mismo blog.
+-- root_id apunta al comentario raíz del hilo para cargar hilos completos en una consulta
+-- y removed marca los comentarios eliminados que conservan respuestas.
+
+ALTER TABLE comments
+    ADD COLUMN parent_id BIGINT NULL DEFAULT NULL AFTER user_id,
+    ADD COLUMN root_id BIGINT NULL DEFAULT NULL AFTER parent_id,
+    ADD COLUMN depth INT NOT NULL DEFAULT 0 AFTER root_id,
+    ADD COLUMN removed BOOLEAN NOT NULL DEFAULT FALSE AFTER content,
+    ADD INDEX idx_comments_parent_id (parent_id),
+    ADD INDEX idx_comments_root_id (root_id),
+    ADD CONSTRAINT fk_comments_parent FOREIGN KEY (parent_id) REFERENCES comments(id) ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+ALTER TABLE blogs
+    DROP INDEX idx_blogs_status_published_at,
+    DROP COLUMN published_at,
+    DROP COLUMN status;
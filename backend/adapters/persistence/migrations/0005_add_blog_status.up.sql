@@ -0,0 +1,9 @@
+-- Ciclo de vida de los blogs: borrador, programado, publicado y archivado.
+-- Los blogs existentes se consideran publicados en su fecha de creación.
+
+ALTER TABLE blogs
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'published' AFTER author_id,
+    ADD COLUMN published_at TIMESTAMP NULL DEFAULT NULL AFTER status,
+    ADD INDEX idx_blogs_status_published_at (status, published_at);
+
+UPDATE blogs SET published_at = created_at WHERE published_at IS NULL;
@@ -0,0 +1,9 @@
+-- Los elementos que siguen en la papelera se eliminan definitivamente,
+-- salvo los comentarios eliminados que conservan respuestas
+DELETE FROM comments WHERE deleted_at IS NOT NULL AND removed = FALSE;
+DELETE FROM blogs WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE comments DROP INDEX idx_comments_deleted_at, DROP COLUMN deleted_at;
+ALTER TABLE blogs DROP INDEX idx_blogs_deleted_at, DROP COLUMN deleted_at;
+ALTER TABLE users DROP INDEX idx_users_deleted_at, DROP COLUMN deleted_at;
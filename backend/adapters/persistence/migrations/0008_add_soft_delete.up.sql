@@ -0,0 +1,17 @@
+-- Borrado lógico: las filas con deleted_at quedan en la papelera hasta que
+-- el proceso de retención las elimina definitivamente.
+
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_users_deleted_at (deleted_at);
+
+ALTER TABLE blogs
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_blogs_deleted_at (deleted_at);
+
+-- Los comentarios eliminados que conservan respuestas (removed) también pasan a la papelera
+ALTER TABLE comments
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_comments_deleted_at (deleted_at);
+
+UPDATE comments SET deleted_at = updated_at WHERE removed = TRUE;
@@ -0,0 +1,8 @@
+ALTER TABLE users DROP FOREIGN KEY fk_users_role;
+
+-- Los usuarios con roles que no existían antes vuelven a ser usuarios normales
+UPDATE users SET role = 'Usuario' WHERE role NOT IN ('Administrador', 'Usuario');
+ALTER TABLE users MODIFY role ENUM('Administrador', 'Usuario') NOT NULL DEFAULT 'Usuario';
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
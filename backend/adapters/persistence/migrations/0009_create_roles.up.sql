@@ -0,0 +1,53 @@
+-- Roles almacenados con sus permisos. Los roles predefinidos se crean aquí;
+-- los administradores pueden crear roles nuevos y asignarlos a usuarios.
+
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    built_in BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_name VARCHAR(50) NOT NULL,
+    permission VARCHAR(50) NOT NULL,
+    PRIMARY KEY (role_name, permission),
+    FOREIGN KEY (role_name) REFERENCES roles(name) ON DELETE CASCADE
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
+
+INSERT INTO roles (name, description, built_in) VALUES
+    ('Administrador', 'Acceso completo a la administración del sitio', TRUE),
+    ('Usuario', 'Escribe sus propios blogs y comenta', TRUE),
+    ('Editor', 'Edita, publica y elimina blogs de cualquier autor y gestiona etiquetas', TRUE),
+    ('Moderador', 'Modera los comentarios de cualquier usuario', TRUE);
+
+INSERT INTO role_permissions (role_name, permission) VALUES
+    ('Administrador', 'blog:create'),
+    ('Administrador', 'blog:publish'),
+    ('Administrador', 'blog:edit'),
+    ('Administrador', 'blog:delete'),
+    ('Administrador', 'blog:read_unpublished'),
+    ('Administrador', 'comment:create'),
+    ('Administrador', 'comment:moderate'),
+    ('Administrador', 'tag:manage'),
+    ('Administrador', 'user:manage'),
+    ('Administrador', 'role:manage'),
+    ('Administrador', 'trash:manage'),
+    ('Usuario', 'blog:create'),
+    ('Usuario', 'blog:publish'),
+    ('Usuario', 'comment:create'),
+    ('Editor', 'blog:create'),
+    ('Editor', 'blog:publish'),
+    ('Editor', 'blog:edit'),
+    ('Editor', 'blog:delete'),
+    ('Editor', 'blog:read_unpublished'),
+    ('Editor', 'comment:create'),
+    ('Editor', 'tag:manage'),
+    ('Moderador', 'blog:create'),
+    ('Moderador', 'blog:publish'),
+    ('Moderador', 'comment:create'),
+    ('Moderador', 'comment:moderate');
+
+-- El rol de los usuarios deja de ser un ENUM y pasa a referenciar la tabla de roles
+ALTER TABLE users MODIFY role VARCHAR(50) NOT NULL DEFAULT 'Usuario';
+ALTER TABLE users ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name);
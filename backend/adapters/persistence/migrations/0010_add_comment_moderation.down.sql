@@ -0,0 +1,12 @@
+-- Los comentarios que no llegaron a aprobarse se descartan
+DELETE FROM comments WHERE status <> 'approved';
+
+ALTER TABLE blogs DROP COLUMN comment_moderation;
+
+ALTER TABLE comments
+    DROP FOREIGN KEY fk_comments_moderated_by,
+    DROP INDEX idx_comments_status,
+    DROP COLUMN moderated_at,
+    DROP COLUMN moderated_by,
+    DROP COLUMN moderation_reason,
+    DROP COLUMN status;
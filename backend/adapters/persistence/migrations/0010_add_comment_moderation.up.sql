@@ -0,0 +1,14 @@
+-- Moderación de comentarios: los comentarios nuevos pueden quedar pendientes
+-- de revisión. Los comentarios existentes se consideran aprobados.
+
+ALTER TABLE comments
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'approved' AFTER removed,
+    ADD COLUMN moderation_reason VARCHAR(500) NULL DEFAULT NULL AFTER status,
+    ADD COLUMN moderated_by BIGINT NULL DEFAULT NULL AFTER moderation_reason,
+    ADD COLUMN moderated_at TIMESTAMP NULL DEFAULT NULL AFTER moderated_by,
+    ADD INDEX idx_comments_status (status, id),
+    ADD CONSTRAINT fk_comments_moderated_by FOREIGN KEY (moderated_by) REFERENCES users(id) ON DELETE SET NULL;
+
+-- Los autores pueden exigir moderación previa en sus blogs
+ALTER TABLE blogs
+    ADD COLUMN comment_moderation BOOLEAN NOT NULL DEFAULT FALSE AFTER published_at;
@@ -0,0 +1,5 @@
+ALTER TABLE comments
+    DROP INDEX idx_comments_user_created_at,
+    DROP COLUMN filter_reason,
+    DROP COLUMN filter_name,
+    DROP COLUMN filter_action;
@@ -0,0 +1,8 @@
+-- Veredicto del filtro de contenido con el que se guardó o editó cada comentario.
+-- Los comentarios que pasaron todos los filtros no guardan veredicto.
+
+ALTER TABLE comments
+    ADD COLUMN filter_action VARCHAR(20) NULL DEFAULT NULL AFTER moderated_at,
+    ADD COLUMN filter_name VARCHAR(50) NULL DEFAULT NULL AFTER filter_action,
+    ADD COLUMN filter_reason VARCHAR(255) NULL DEFAULT NULL AFTER filter_name,
+    ADD INDEX idx_comments_user_created_at (user_id, created_at);
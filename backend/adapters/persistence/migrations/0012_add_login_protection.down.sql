@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS login_attempts;
+
+ALTER TABLE users
+    DROP COLUMN locked_until,
+    DROP COLUMN last_failed_login_at,
+    DROP COLUMN failed_logins;
@@ -0,0 +1,22 @@
+-- Protección contra fuerza bruta: fallos seguidos y bloqueo temporal por
+-- cuenta, y auditoría de todos los intentos de inicio de sesión.
+
+ALTER TABLE users
+    ADD COLUMN failed_logins INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_failed_login_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN locked_until TIMESTAMP NULL DEFAULT NULL;
+
+-- user_id es NULL cuando el nombre de usuario no corresponde a ninguna cuenta
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NULL,
+    username VARCHAR(50) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    reason VARCHAR(30) NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_login_attempts_user_id (user_id, id),
+    INDEX idx_login_attempts_ip (ip, created_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
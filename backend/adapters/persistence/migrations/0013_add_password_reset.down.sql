@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS password_reset_tokens;
+
+ALTER TABLE users
+    DROP INDEX idx_users_email,
+    DROP COLUMN email;
@@ -0,0 +1,17 @@
+-- Correo electrónico opcional de los usuarios y tokens de un solo uso para
+-- restablecer la contraseña: solo se guarda el hash del token
+
+ALTER TABLE users
+    ADD COLUMN email VARCHAR(255) NULL DEFAULT NULL AFTER username,
+    ADD UNIQUE INDEX idx_users_email (email);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_password_reset_tokens_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,8 @@
+-- Verificación del correo electrónico. Las cuentas existentes se dan por
+-- verificadas para que activar REQUIRE_EMAIL_VERIFICATION no las bloquee.
+
+ALTER TABLE users
+    ADD COLUMN email_verified_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN email_verification_sent_at TIMESTAMP NULL DEFAULT NULL;
+
+UPDATE users SET email_verified_at = created_at;
@@ -0,0 +1,17 @@
+-- Autenticación en dos pasos con TOTP y códigos de recuperación de un solo
+-- uso (solo se guarda el hash de cada código)
+
+ALTER TABLE users
+    ADD COLUMN totp_secret VARCHAR(64) NULL DEFAULT NULL,
+    ADD COLUMN totp_enabled_at TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE INDEX idx_recovery_codes_user_code (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,12 @@
+-- Claves asimétricas de firma de los tokens de acceso. Las claves retiradas
+-- siguen verificando tokens hasta expires_at.
+
+CREATE TABLE IF NOT EXISTS signing_keys (
+    id VARCHAR(64) PRIMARY KEY,
+    algorithm VARCHAR(16) NOT NULL,
+    private_key TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    retired_at TIMESTAMP NULL DEFAULT NULL,
+    expires_at TIMESTAMP NULL DEFAULT NULL,
+    INDEX idx_signing_keys_expires_at (expires_at)
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
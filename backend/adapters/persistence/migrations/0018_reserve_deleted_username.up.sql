@@ -0,0 +1,8 @@
+-- El nombre usuario-eliminado está reservado para el usuario que recibe el
+-- contenido de los usuarios eliminados con USER_DELETION_POLICY=reassign, que
+-- se crea sin contraseña. Una cuenta con contraseña que lo haya tomado antes de
+-- la reserva se renombra a usuario-renombrado-<id> para que no reciba ese
+-- contenido; el contenido que ya haya recibido hay que revisarlo a mano.
+
+UPDATE users SET username = CONCAT('usuario-renombrado-', id)
+WHERE username = 'usuario-eliminado' AND password <> '';
@@ -0,0 +1,10 @@
+-- Los secretos cifrados no caben en la columna anterior: se descartan y los
+-- usuarios tienen que activar de nuevo la autenticación en dos pasos
+
+UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0
+WHERE totp_secret LIKE 'v1:%';
+
+DELETE FROM recovery_codes
+WHERE user_id IN (SELECT id FROM users WHERE totp_secret IS NULL);
+
+ALTER TABLE users MODIFY COLUMN totp_secret VARCHAR(64) NULL DEFAULT NULL;
@@ -0,0 +1,4 @@
+-- Los secretos TOTP se guardan cifrados con AES-GCM, que ocupan más que el
+-- secreto en base32
+
+ALTER TABLE users MODIFY COLUMN totp_secret VARCHAR(255) NULL DEFAULT NULL;
@@ -0,0 +1,33 @@
+// Package migrations contiene los scripts SQL versionados del esquema.
+//
+// Cada migración se compone de dos archivos con el formato
+// NNNN_descripcion.up.sql y NNNN_descripcion.down.sql, que se embeben en el
+// binario para que el servidor pueda aplicarlos sin depender del sistema de
+// archivos. El directorio sqlite contiene las mismas migraciones, con las
+// mismas versiones y nombres, escritas para SQLite.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS contiene todos los archivos de migración embebidos
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
+// SQLiteFS contiene las migraciones equivalentes para SQLite
+var SQLiteFS = mustSub(sqliteFiles, "sqlite")
+
+// mustSub retorna el subdirectorio dir de fsys
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1 @@
+-- Sin cambios: la migración no crea ningún índice en SQLite
@@ -0,0 +1,2 @@
+-- SQLite no tiene índices FULLTEXT: la búsqueda usa LIKE sobre las columnas.
+-- La migración se conserva para mantener la misma numeración que en MySQL.
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_comments_parent_id;
+DROP INDEX IF EXISTS idx_comments_root_id;
+
+ALTER TABLE comments DROP COLUMN removed;
+ALTER TABLE comments DROP COLUMN depth;
+ALTER TABLE comments DROP COLUMN root_id;
+ALTER TABLE comments DROP COLUMN parent_id;
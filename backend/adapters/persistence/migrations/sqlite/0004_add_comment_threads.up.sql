@@ -0,0 +1,11 @@
+-- Respuestas anidadas: cada comentario puede tener un padre dentro del mismo blog.
+-- root_id apunta al comentario raíz del hilo para cargar hilos completos en una consulta
+-- y removed marca los comentarios eliminados que conservan respuestas.
+
+ALTER TABLE comments ADD COLUMN parent_id BIGINT NULL DEFAULT NULL REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN root_id BIGINT NULL DEFAULT NULL;
+ALTER TABLE comments ADD COLUMN depth INT NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN removed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+CREATE INDEX IF NOT EXISTS idx_comments_root_id ON comments (root_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_blogs_status_published_at;
+
+ALTER TABLE blogs DROP COLUMN published_at;
+ALTER TABLE blogs DROP COLUMN status;
@@ -0,0 +1,9 @@
+-- Ciclo de vida de los blogs: borrador, programado, publicado y archivado.
+-- Los blogs existentes se consideran publicados en su fecha de creación.
+
+ALTER TABLE blogs ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'published';
+ALTER TABLE blogs ADD COLUMN published_at TIMESTAMP NULL DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_blogs_status_published_at ON blogs (status, published_at);
+
+UPDATE blogs SET published_at = created_at WHERE published_at IS NULL;
@@ -0,0 +1,21 @@
+-- Historial de ediciones de blogs y comentarios.
+-- Cada fila es una instantánea del contenido tras una creación o edición.
+
+CREATE TABLE IF NOT EXISTS revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id BIGINT NOT NULL,
+    editor_id BIGINT NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_revisions_entity ON revisions (entity_type, entity_id);
+
+-- La versión actual del contenido existente es su primera revisión
+INSERT INTO revisions (entity_type, entity_id, editor_id, title, content, created_at)
+SELECT 'blog', id, author_id, title, content, updated_at FROM blogs;
+
+INSERT INTO revisions (entity_type, entity_id, editor_id, title, content, created_at)
+SELECT 'comment', id, user_id, '', content, updated_at FROM comments WHERE removed = FALSE;
@@ -0,0 +1,17 @@
+-- Etiquetas de los blogs (relación muchos a muchos).
+-- El slug es la forma normalizada del nombre y es único.
+
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(50) NOT NULL COLLATE NOCASE,
+    slug VARCHAR(100) NOT NULL COLLATE NOCASE UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS blog_tags (
+    blog_id BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (blog_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_blog_tags_tag_id ON blog_tags (tag_id);
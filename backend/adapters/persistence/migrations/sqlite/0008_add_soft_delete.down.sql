@@ -0,0 +1,14 @@
+-- Los elementos que siguen en la papelera se eliminan definitivamente,
+-- salvo los comentarios eliminados que conservan respuestas
+DELETE FROM comments WHERE deleted_at IS NOT NULL AND removed = FALSE;
+DELETE FROM blogs WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_comments_deleted_at;
+ALTER TABLE comments DROP COLUMN deleted_at;
+
+DROP INDEX IF EXISTS idx_blogs_deleted_at;
+ALTER TABLE blogs DROP COLUMN deleted_at;
+
+DROP INDEX IF EXISTS idx_users_deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
@@ -0,0 +1,5 @@
+-- Los usuarios con roles que no existían antes vuelven a ser usuarios normales
+UPDATE users SET role = 'Usuario' WHERE role NOT IN ('Administrador', 'Usuario');
+
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
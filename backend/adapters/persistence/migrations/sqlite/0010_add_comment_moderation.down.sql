@@ -0,0 +1,10 @@
+-- Los comentarios que no llegaron a aprobarse se descartan
+DELETE FROM comments WHERE status <> 'approved';
+
+ALTER TABLE blogs DROP COLUMN comment_moderation;
+
+DROP INDEX IF EXISTS idx_comments_status;
+ALTER TABLE comments DROP COLUMN moderated_at;
+ALTER TABLE comments DROP COLUMN moderated_by;
+ALTER TABLE comments DROP COLUMN moderation_reason;
+ALTER TABLE comments DROP COLUMN status;
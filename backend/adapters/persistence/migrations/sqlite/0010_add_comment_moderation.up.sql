@@ -0,0 +1,12 @@
+-- Moderación de comentarios: los comentarios nuevos pueden quedar pendientes
+-- de revisión. Los comentarios existentes se consideran aprobados.
+
+ALTER TABLE comments ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'approved';
+ALTER TABLE comments ADD COLUMN moderation_reason VARCHAR(500) NULL DEFAULT NULL;
+ALTER TABLE comments ADD COLUMN moderated_by BIGINT NULL DEFAULT NULL REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE comments ADD COLUMN moderated_at TIMESTAMP NULL DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_status ON comments (status, id);
+
+-- Los autores pueden exigir moderación previa en sus blogs
+ALTER TABLE blogs ADD COLUMN comment_moderation BOOLEAN NOT NULL DEFAULT FALSE;
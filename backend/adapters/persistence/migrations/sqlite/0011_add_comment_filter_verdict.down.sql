@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_comments_user_created_at;
+
+ALTER TABLE comments DROP COLUMN filter_reason;
+ALTER TABLE comments DROP COLUMN filter_name;
+ALTER TABLE comments DROP COLUMN filter_action;
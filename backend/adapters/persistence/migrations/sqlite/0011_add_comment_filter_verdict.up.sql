@@ -0,0 +1,8 @@
+-- Veredicto del filtro de contenido con el que se guardó o editó cada comentario.
+-- Los comentarios que pasaron todos los filtros no guardan veredicto.
+
+ALTER TABLE comments ADD COLUMN filter_action VARCHAR(20) NULL DEFAULT NULL;
+ALTER TABLE comments ADD COLUMN filter_name VARCHAR(50) NULL DEFAULT NULL;
+ALTER TABLE comments ADD COLUMN filter_reason VARCHAR(255) NULL DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_comments_user_created_at ON comments (user_id, created_at);
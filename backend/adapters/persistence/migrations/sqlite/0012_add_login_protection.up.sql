@@ -0,0 +1,21 @@
+-- Protección contra fuerza bruta: fallos seguidos y bloqueo temporal por
+-- cuenta, y auditoría de todos los intentos de inicio de sesión.
+
+ALTER TABLE users ADD COLUMN failed_logins INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN last_failed_login_at TIMESTAMP NULL DEFAULT NULL;
+ALTER TABLE users ADD COLUMN locked_until TIMESTAMP NULL DEFAULT NULL;
+
+-- user_id es NULL cuando el nombre de usuario no corresponde a ninguna cuenta
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id BIGINT NULL REFERENCES users(id) ON DELETE CASCADE,
+    username VARCHAR(50) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL,
+    reason VARCHAR(30) NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user_id ON login_attempts (user_id, id);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts (ip, created_at);
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN email_verification_sent_at;
+ALTER TABLE users DROP COLUMN email_verified_at;
@@ -0,0 +1,2 @@
+-- El renombrado no se revierte: devolver el nombre reservado a una cuenta con
+-- contraseña reabriría el problema que corrige la migración.
@@ -0,0 +1 @@
+-- SQLite no limita la longitud de VARCHAR: no hay nada que revertir.
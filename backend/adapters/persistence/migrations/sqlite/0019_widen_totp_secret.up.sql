@@ -0,0 +1,3 @@
+-- Los secretos TOTP se guardan cifrados con AES-GCM, que ocupan más que el
+-- secreto en base32. SQLite no limita la longitud de VARCHAR: no hay nada
+-- que cambiar.
@@ -0,0 +1,326 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationLockName es el nombre del bloqueo consultivo que comparten todas
+// las réplicas para no ejecutar migraciones al mismo tiempo
+const migrationLockName = "blog_backend_schema_migrations"
+
+// migrationFilePattern reconoce archivos con el formato NNNN_nombre.up.sql / .down.sql
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-zA-Z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration representa una migración versionada del esquema
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus describe el estado de una migración en la base de datos
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// Migrator aplica y revierte migraciones registrándolas en schema_migrations
+type Migrator struct {
+	db          *sql.DB
+	dialect     Dialect
+	migrations  []Migration
+	lockTimeout time.Duration
+}
+
+// NewMigrator crea un migrador a partir de los archivos .sql contenidos en fsys,
+// que deben estar escritos para el dialecto indicado
+func NewMigrator(db *sql.DB, dialect Dialect, fsys fs.FS, lockTimeout time.Duration) (*Migrator, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:          db,
+		dialect:     dialect,
+		migrations:  migrations,
+		lockTimeout: lockTimeout,
+	}, nil
+}
+
+// Up aplica todas las migraciones pendientes en orden y retorna las aplicadas
+func (m *Migrator) Up() ([]Migration, error) {
+	var applied []Migration
+
+	err := m.withLock(func(conn *sql.Conn) error {
+		versions, err := m.appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+
+			if err := execScript(conn, migration.Up); err != nil {
+				return fmt.Errorf("error aplicando migración %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			query := `INSERT INTO schema_migrations (version, name) VALUES (?, ?)`
+			if _, err := conn.ExecContext(context.Background(), query, migration.Version, migration.Name); err != nil {
+				return fmt.Errorf("error registrando migración %04d: %w", migration.Version, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down revierte las últimas steps migraciones aplicadas y retorna las revertidas
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := m.withLock(func(conn *sql.Conn) error {
+		versions, err := m.appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+
+			if err := execScript(conn, migration.Down); err != nil {
+				return fmt.Errorf("error revirtiendo migración %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			query := `DELETE FROM schema_migrations WHERE version = ?`
+			if _, err := conn.ExecContext(context.Background(), query, migration.Version); err != nil {
+				return fmt.Errorf("error eliminando registro de migración %04d: %w", migration.Version, err)
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status retorna el estado de cada migración conocida
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := m.withLock(func(conn *sql.Conn) error {
+		versions, err := m.appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if appliedAt, ok := versions[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withLock ejecuta fn en una conexión dedicada que mantiene el bloqueo consultivo
+func (m *Migrator) withLock(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error obteniendo conexión para migraciones: %w", err)
+	}
+	defer conn.Close()
+
+	// SQLite no tiene bloqueos consultivos, pero solo admite un escritor a la vez
+	// y su archivo no se comparte entre réplicas
+	if m.dialect == DialectSQLite {
+		if err := ensureMigrationsTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	}
+
+	// GET_LOCK es por sesión, por eso se usa siempre la misma conexión
+	var acquired sql.NullInt64
+	query := `SELECT GET_LOCK(?, ?)`
+	if err := conn.QueryRowContext(ctx, query, migrationLockName, int(m.lockTimeout.Seconds())).Scan(&acquired); err != nil {
+		return fmt.Errorf("error adquiriendo bloqueo de migraciones: %w", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("no se pudo adquirir el bloqueo de migraciones en %s", m.lockTimeout)
+	}
+	defer conn.ExecContext(ctx, `SELECT RELEASE_LOCK(?)`, migrationLockName)
+
+	if err := ensureMigrationsTable(conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// appliedVersions retorna las versiones aplicadas junto con su fecha de aplicación
+func (m *Migrator) appliedVersions(conn *sql.Conn) (map[int64]time.Time, error) {
+	query := `SELECT version, applied_at FROM schema_migrations`
+	rows, err := conn.QueryContext(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("error consultando migraciones aplicadas: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("error escaneando migración aplicada: %w", err)
+		}
+		versions[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando migraciones aplicadas: %w", err)
+	}
+
+	return versions, nil
+}
+
+// ensureMigrationsTable crea la tabla de control de migraciones si no existe
+func ensureMigrationsTable(conn *sql.Conn) error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`
+	if _, err := conn.ExecContext(context.Background(), query); err != nil {
+		return fmt.Errorf("error creando tabla schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// execScript ejecuta una a una las sentencias de un script SQL
+func execScript(conn *sql.Conn, script string) error {
+	for _, statement := range splitStatements(script) {
+		if _, err := conn.ExecContext(context.Background(), statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements divide un script en sentencias separadas por ';' al final de línea,
+// descartando las líneas de comentario
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statement := strings.TrimSuffix(strings.TrimSpace(current.String()), ";")
+			statements = append(statements, statement)
+			current.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
+
+// loadMigrations lee y valida los archivos de migración de fsys
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo migraciones: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		matches := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("versión de migración inválida en %s: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo migración %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("versión de migración duplicada: %04d", version)
+		}
+
+		switch matches[3] {
+		case "up":
+			migration.Up = string(content)
+		case "down":
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("la migración %04d_%s debe tener archivos up y down", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// OIDCStateRepositorySQL implementa la interfaz OIDCStateRepository usando SQL
+type OIDCStateRepositorySQL struct {
+	db *sql.DB
+}
+
+// NewOIDCStateRepositorySQL crea una nueva instancia del repositorio SQL de logins con OpenID Connect en curso
+func NewOIDCStateRepositorySQL(db *sql.DB) ports.OIDCStateRepository {
+	return &OIDCStateRepositorySQL{db: db}
+}
+
+// Create guarda un login con OpenID Connect en curso
+func (r *OIDCStateRepositorySQL) Create(ctx context.Context, state *domain.OIDCLoginState) error {
+	var userID sql.NullInt64
+	if state.UserID != 0 {
+		userID = sql.NullInt64{Int64: state.UserID, Valid: true}
+	}
+
+	query := `INSERT INTO oidc_login_states (state_hash, nonce, code_verifier, user_id, expires_at) VALUES (?, ?, ?, ?, ?)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, state.StateHash, state.Nonce, state.CodeVerifier, userID, state.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error guardando login con OpenID Connect: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error obteniendo ID del login con OpenID Connect: %w", err)
+	}
+
+	state.ID = id
+	return nil
+}
+
+// Take busca un login en curso por el hash de su state y lo elimina. Si no
+// existe, o si otra petición lo eliminó antes, retorna ErrInvalidToken.
+func (r *OIDCStateRepositorySQL) Take(ctx context.Context, stateHash string) (*domain.OIDCLoginState, error) {
+	query := `SELECT id, state_hash, nonce, code_verifier, user_id, expires_at FROM oidc_login_states WHERE state_hash = ?`
+	state := &domain.OIDCLoginState{}
+	var userID sql.NullInt64
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, stateHash).Scan(&state.ID, &state.StateHash, &state.Nonce, &state.CodeVerifier, &userID, &state.ExpiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("error buscando login con OpenID Connect: %w", err)
+	}
+	state.UserID = userID.Int64
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM oidc_login_states WHERE id = ?`, state.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error eliminando login con OpenID Connect: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return state, nil
+}
+
+// DeleteExpired elimina los logins en curso que caducaron antes de now
+func (r *OIDCStateRepositorySQL) DeleteExpired(ctx context.Context, now time.Time) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at <= ?`, now); err != nil {
+		return fmt.Errorf("error eliminando logins con OpenID Connect caducados: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PasswordResetTokenRepositorySQL implementa la interfaz PasswordResetTokenRepository usando SQL
+type PasswordResetTokenRepositorySQL struct {
+	db *sql.DB
+}
+
+// NewPasswordResetTokenRepositorySQL crea una nueva instancia del repositorio SQL de tokens de restablecimiento
+func NewPasswordResetTokenRepositorySQL(db *sql.DB) ports.PasswordResetTokenRepository {
+	return &PasswordResetTokenRepositorySQL{db: db}
+}
+
+// Create guarda un nuevo token de restablecimiento de contraseña
+func (r *PasswordResetTokenRepositorySQL) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	query := `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES (?, ?, ?)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, token.UserID, token.TokenHash, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error creando token de restablecimiento: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error obteniendo ID del token de restablecimiento: %w", err)
+	}
+
+	token.ID = id
+	return nil
+}
+
+// FindByHash busca un token de restablecimiento por el hash del token
+func (r *PasswordResetTokenRepositorySQL) FindByHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	query := `SELECT id, user_id, token_hash, expires_at, used_at FROM password_reset_tokens WHERE token_hash = ?`
+	token := &domain.PasswordResetToken{}
+	var usedAt sql.NullTime
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &usedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("error buscando token de restablecimiento: %w", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return token, nil
+}
+
+// MarkUsed marca un token como usado. Si ya estaba usado retorna ErrInvalidToken,
+// lo que impide usar dos veces el mismo token en peticiones concurrentes.
+func (r *PasswordResetTokenRepositorySQL) MarkUsed(ctx context.Context, id int64) error {
+	query := `UPDATE password_reset_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("error marcando token de restablecimiento como usado: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrInvalidToken
+	}
+
+	return nil
+}
+
+// DeleteForUser elimina todos los tokens de restablecimiento de un usuario
+func (r *PasswordResetTokenRepositorySQL) DeleteForUser(ctx context.Context, userID int64) error {
+	query := `DELETE FROM password_reset_tokens WHERE user_id = ?`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("error eliminando tokens de restablecimiento: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RecoveryCodeRepositorySQL implementa la interfaz RecoveryCodeRepository usando SQL
+type RecoveryCodeRepositorySQL struct {
+	db *sql.DB
+}
+
+// NewRecoveryCodeRepositorySQL crea una nueva instancia del repositorio SQL de códigos de recuperación
+func NewRecoveryCodeRepositorySQL(db *sql.DB) ports.RecoveryCodeRepository {
+	return &RecoveryCodeRepositorySQL{db: db}
+}
+
+// ReplaceForUser reemplaza todos los códigos de recuperación de un usuario
+func (r *RecoveryCodeRepositorySQL) ReplaceForUser(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("error eliminando códigos de recuperación: %w", err)
+	}
+
+	for _, codeHash := range codeHashes {
+		query := `INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)`
+		if _, err := tx.ExecContext(ctx, query, userID, codeHash); err != nil {
+			return fmt.Errorf("error guardando código de recuperación: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando códigos de recuperación: %w", err)
+	}
+
+	return nil
+}
+
+// Use marca como usado un código de recuperación. Si no existe o ya se usó
+// retorna ErrInvalidTOTPCode.
+func (r *RecoveryCodeRepositorySQL) Use(ctx context.Context, userID int64, codeHash string) error {
+	query := `UPDATE recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("error usando código de recuperación: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrInvalidTOTPCode
+	}
+
+	return nil
+}
+
+// CountUnused cuenta los códigos de recuperación sin usar de un usuario
+func (r *RecoveryCodeRepositorySQL) CountUnused(ctx context.Context, userID int64) (int, error) {
+	query := `SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL`
+	var count int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error contando códigos de recuperación: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteForUser elimina todos los códigos de recuperación de un usuario
+func (r *RecoveryCodeRepositorySQL) DeleteForUser(ctx context.Context, userID int64) error {
+	if _, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("error eliminando códigos de recuperación: %w", err)
+	}
+	return nil
+}
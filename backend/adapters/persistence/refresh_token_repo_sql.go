@@ -0,0 +1,107 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RefreshTokenRepositorySQL implementa la interfaz RefreshTokenRepository usando SQL
+type RefreshTokenRepositorySQL struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepositorySQL crea una nueva instancia del repositorio SQL de tokens de refresco
+func NewRefreshTokenRepositorySQL(db *sql.DB) ports.RefreshTokenRepository {
+	return &RefreshTokenRepositorySQL{db: db}
+}
+
+// Create guarda un nuevo token de refresco
+func (r *RefreshTokenRepositorySQL) Create(ctx context.Context, token *domain.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at) VALUES (?, ?, ?, ?)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error creando token de refresco: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error obteniendo ID del token de refresco: %w", err)
+	}
+
+	token.ID = id
+	return nil
+}
+
+// FindByHash busca un token de refresco por el hash del token
+func (r *RefreshTokenRepositorySQL) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `SELECT id, user_id, family_id, token_hash, expires_at, revoked_at FROM refresh_tokens WHERE token_hash = ?`
+	token := &domain.RefreshToken{}
+	var revokedAt sql.NullTime
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash, &token.ExpiresAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("error buscando token de refresco: %w", err)
+	}
+
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return token, nil
+}
+
+// Revoke revoca un token de refresco. Si ya estaba revocado retorna ErrTokenReused,
+// lo que permite detectar dos rotaciones concurrentes del mismo token.
+func (r *RefreshTokenRepositorySQL) Revoke(ctx context.Context, id int64) error {
+	query := `UPDATE refresh_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("error revocando token de refresco: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrTokenReused
+	}
+
+	return nil
+}
+
+// RevokeFamily revoca todos los tokens de una familia (sesión)
+func (r *RefreshTokenRepositorySQL) RevokeFamily(ctx context.Context, familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), familyID); err != nil {
+		return fmt.Errorf("error revocando familia de tokens: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllForUser revoca todas las sesiones de un usuario
+func (r *RefreshTokenRepositorySQL) RevokeAllForUser(ctx context.Context, userID int64) error {
+	query := `UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), userID); err != nil {
+		return fmt.Errorf("error revocando tokens del usuario: %w", err)
+	}
+	return nil
+}
+
+// IsFamilyActive indica si una sesión conserva algún token de refresco vigente
+func (r *RefreshTokenRepositorySQL) IsFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM refresh_tokens WHERE family_id = ? AND revoked_at IS NULL AND expires_at > ?)`
+	var active bool
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, familyID, time.Now()).Scan(&active); err != nil {
+		return false, fmt.Errorf("error verificando sesión: %w", err)
+	}
+	return active, nil
+}
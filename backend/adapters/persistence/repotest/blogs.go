@@ -0,0 +1,316 @@
+package repotest
+
+import (
+	"blog-backend/internal/domain"
+	"testing"
+	"time"
+)
+
+func testBlogs(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		blog := &domain.Blog{Title: "Título", Content: "Contenido", AuthorID: author.ID, Status: domain.BlogStatusDraft, CommentModeration: true}
+		mustNot(t, repos.Blogs.Create(ctx, blog))
+		if blog.ID == 0 || blog.CreatedAt.IsZero() || blog.UpdatedAt.IsZero() {
+			t.Fatalf("Create no asignó ID ni fechas: %+v", blog)
+		}
+
+		found, err := repos.Blogs.FindByID(ctx, blog.ID)
+		mustNot(t, err)
+		if found.Title != "Título" || found.Content != "Contenido" || found.AuthorID != author.ID ||
+			found.Status != domain.BlogStatusDraft || found.PublishedAt != nil || !found.CommentModeration {
+			t.Fatalf("FindByID = %+v", found)
+		}
+
+		_, err = repos.Blogs.FindByID(ctx, missingID)
+		wantErr(t, err, domain.ErrBlogNotFound)
+	})
+
+	t.Run("CommentsCount", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, author, "con comentarios")
+		createComment(t, repos, blog, author, nil, domain.CommentStatusApproved)
+		createComment(t, repos, blog, author, nil, domain.CommentStatusPending)
+		deleted := createComment(t, repos, blog, author, nil, domain.CommentStatusApproved)
+		mustNot(t, repos.Comments.Delete(ctx, deleted.ID))
+
+		// Solo cuentan los comentarios aprobados que no están en la papelera
+		found, err := repos.Blogs.FindByID(ctx, blog.ID)
+		mustNot(t, err)
+		if found.CommentsCount != 1 {
+			t.Fatalf("CommentsCount = %d, se esperaba 1", found.CommentsCount)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, author, "original")
+		blog.Title = "editado"
+		blog.Content = "nuevo"
+		blog.Status = domain.BlogStatusArchived
+		blog.CommentModeration = true
+		mustNot(t, repos.Blogs.Update(ctx, blog))
+
+		found, err := repos.Blogs.FindByID(ctx, blog.ID)
+		mustNot(t, err)
+		if found.Title != "editado" || found.Content != "nuevo" || found.Status != domain.BlogStatusArchived || !found.CommentModeration {
+			t.Fatalf("Update no guardó los cambios: %+v", found)
+		}
+
+		wantErr(t, repos.Blogs.Update(ctx, &domain.Blog{ID: missingID, Title: "x", Status: domain.BlogStatusDraft}), domain.ErrBlogNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		other := createUser(t, repos, "bob")
+		first := createBlog(t, repos, author, "primero")
+		draft := &domain.Blog{Title: "borrador", Content: "c", AuthorID: author.ID, Status: domain.BlogStatusDraft}
+		mustNot(t, repos.Blogs.Create(ctx, draft))
+		third := createBlog(t, repos, other, "tercero")
+		deleted := createBlog(t, repos, author, "eliminado")
+		mustNot(t, repos.Blogs.Delete(ctx, deleted.ID))
+
+		request := domain.PageRequest{Limit: 10, Sort: domain.SortNewest}
+		page, err := repos.Blogs.List(ctx, domain.BlogFilter{}, request)
+		mustNot(t, err)
+		// El borrador no tiene fecha de publicación y se ordena por la de creación
+		wantIDs(t, ids(page.Items, blogID), []int64{draft.ID, third.ID, first.ID})
+
+		page, err = repos.Blogs.List(ctx, domain.BlogFilter{PublishedOnly: true}, request)
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, blogID), []int64{third.ID, first.ID})
+
+		page, err = repos.Blogs.FindByAuthorID(ctx, author.ID, domain.BlogFilter{}, domain.PageRequest{Limit: 10, Sort: domain.SortOldest})
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, blogID), []int64{first.ID, draft.ID})
+
+		page, err = repos.Blogs.FindByAuthorID(ctx, missingID, domain.BlogFilter{}, request)
+		mustNot(t, err)
+		if page.Items == nil || len(page.Items) != 0 || page.HasMore {
+			t.Fatalf("FindByAuthorID de un autor sin blogs = %+v", page)
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		var blogs []int64
+		for _, title := range []string{"a", "b", "c", "d", "e"} {
+			blogs = append(blogs, createBlog(t, repos, author, title).ID)
+		}
+
+		request := domain.PageRequest{Limit: 2, Sort: domain.SortOldest}
+		var got []int64
+		for {
+			page, err := repos.Blogs.List(ctx, domain.BlogFilter{}, request)
+			mustNot(t, err)
+			got = append(got, ids(page.Items, blogID)...)
+			if !page.HasMore {
+				break
+			}
+			request = nextPage(t, request, page)
+		}
+		wantIDs(t, got, blogs)
+	})
+
+	t.Run("NewestByPublication", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		now := time.Now().Truncate(time.Second)
+		publish := func(title string, publishedAt time.Time) int64 {
+			blog := &domain.Blog{Title: title, Content: "c", AuthorID: author.ID, Status: domain.BlogStatusPublished, PublishedAt: &publishedAt}
+			mustNot(t, repos.Blogs.Create(ctx, blog))
+			return blog.ID
+		}
+		// Un blog escrito antes pero publicado después aparece primero;
+		// los empates de fecha se ordenan por ID descendente
+		late := publish("publicado tarde", now)
+		early := publish("publicado pronto", now.Add(-2*time.Hour))
+		tiedFirst := publish("empate primero", now.Add(-time.Hour))
+		tiedSecond := publish("empate segundo", now.Add(-time.Hour))
+
+		request := domain.PageRequest{Limit: 1, Sort: domain.SortNewest}
+		var got []int64
+		for {
+			page, err := repos.Blogs.List(ctx, domain.BlogFilter{}, request)
+			mustNot(t, err)
+			got = append(got, ids(page.Items, blogID)...)
+			if !page.HasMore {
+				break
+			}
+			request = nextPage(t, request, page)
+		}
+		wantIDs(t, got, []int64{late, tiedSecond, tiedFirst, early})
+	})
+
+	t.Run("MostCommented", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		quiet := createBlog(t, repos, author, "sin comentarios")
+		busy := createBlog(t, repos, author, "dos comentarios")
+		single := createBlog(t, repos, author, "un comentario")
+		createComment(t, repos, busy, author, nil, domain.CommentStatusApproved)
+		createComment(t, repos, busy, author, nil, domain.CommentStatusApproved)
+		createComment(t, repos, single, author, nil, domain.CommentStatusApproved)
+		also := createBlog(t, repos, author, "también sin comentarios")
+
+		request := domain.PageRequest{Limit: 2, Sort: domain.SortMostCommented}
+		page, err := repos.Blogs.List(ctx, domain.BlogFilter{}, request)
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, blogID), []int64{busy.ID, single.ID})
+
+		// Los empates se ordenan por ID descendente
+		page, err = repos.Blogs.List(ctx, domain.BlogFilter{}, nextPage(t, request, page))
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, blogID), []int64{also.ID, quiet.ID})
+	})
+
+	t.Run("TagFilter", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		tagged := createBlog(t, repos, author, "con etiqueta")
+		createBlog(t, repos, author, "sin etiqueta")
+		_, err := repos.Tags.SetBlogTags(ctx, tagged.ID, []domain.Tag{{Name: "Go", Slug: "go"}})
+		mustNot(t, err)
+
+		page, err := repos.Blogs.List(ctx, domain.BlogFilter{Tag: "go"}, domain.PageRequest{Limit: 10, Sort: domain.SortNewest})
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, blogID), []int64{tagged.ID})
+	})
+
+	t.Run("PublishDue", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		past := time.Now().Add(-time.Hour)
+		later := time.Now().Add(time.Hour)
+		due := &domain.Blog{Title: "vencido", Content: "c", AuthorID: author.ID, Status: domain.BlogStatusScheduled, PublishedAt: &past}
+		pending := &domain.Blog{Title: "futuro", Content: "c", AuthorID: author.ID, Status: domain.BlogStatusScheduled, PublishedAt: &later}
+		mustNot(t, repos.Blogs.Create(ctx, due))
+		mustNot(t, repos.Blogs.Create(ctx, pending))
+
+		published, err := repos.Blogs.PublishDue(ctx, time.Now())
+		mustNot(t, err)
+		if published != 1 {
+			t.Fatalf("PublishDue = %d, se esperaba 1", published)
+		}
+
+		found, err := repos.Blogs.FindByID(ctx, due.ID)
+		mustNot(t, err)
+		if found.Status != domain.BlogStatusPublished {
+			t.Fatalf("Status = %s, se esperaba %s", found.Status, domain.BlogStatusPublished)
+		}
+		found, err = repos.Blogs.FindByID(ctx, pending.ID)
+		mustNot(t, err)
+		if found.Status != domain.BlogStatusScheduled {
+			t.Fatalf("Status = %s, se esperaba %s", found.Status, domain.BlogStatusScheduled)
+		}
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, author, "papelera")
+		kept := createBlog(t, repos, author, "activo")
+
+		mustNot(t, repos.Blogs.Delete(ctx, blog.ID))
+		wantErr(t, repos.Blogs.Delete(ctx, blog.ID), domain.ErrBlogNotFound)
+		wantErr(t, repos.Blogs.Update(ctx, blog), domain.ErrBlogNotFound)
+		_, err := repos.Blogs.FindByID(ctx, blog.ID)
+		wantErr(t, err, domain.ErrBlogNotFound)
+		deleted, err := repos.Blogs.FindDeleted(ctx, blog.ID)
+		mustNot(t, err)
+		if deleted.AuthorID != author.ID || deleted.DeletedAt == nil {
+			t.Fatalf("FindDeleted = %+v", deleted)
+		}
+		_, err = repos.Blogs.FindDeleted(ctx, kept.ID)
+		wantErr(t, err, domain.ErrBlogNotFound)
+
+		page, err := repos.Blogs.ListDeleted(ctx, domain.PageRequest{Limit: 10, Sort: domain.SortNewest})
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, blogID), []int64{blog.ID})
+		if page.Items[0].DeletedAt == nil {
+			t.Fatal("ListDeleted no incluye la fecha de borrado")
+		}
+
+		mustNot(t, repos.Blogs.Restore(ctx, blog.ID))
+		wantErr(t, repos.Blogs.Restore(ctx, blog.ID), domain.ErrBlogNotFound)
+		wantErr(t, repos.Blogs.Restore(ctx, kept.ID), domain.ErrBlogNotFound)
+		_, err = repos.Blogs.FindByID(ctx, blog.ID)
+		mustNot(t, err)
+	})
+
+	t.Run("PurgeDeleted", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, author, "purgado")
+		comment := createComment(t, repos, blog, author, nil, domain.CommentStatusApproved)
+		_, err := repos.Tags.SetBlogTags(ctx, blog.ID, []domain.Tag{{Name: "Go", Slug: "go"}})
+		mustNot(t, err)
+		mustNot(t, repos.Blogs.Delete(ctx, blog.ID))
+
+		purged, err := repos.Blogs.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+		mustNot(t, err)
+		if purged != 0 {
+			t.Fatalf("PurgeDeleted purgó %d blogs recientes", purged)
+		}
+
+		purged, err = repos.Blogs.PurgeDeleted(ctx, future())
+		mustNot(t, err)
+		if purged != 1 {
+			t.Fatalf("PurgeDeleted = %d, se esperaba 1", purged)
+		}
+
+		// Sus comentarios y etiquetas se eliminan en cascada; la etiqueta se conserva
+		wantErr(t, repos.Blogs.Restore(ctx, blog.ID), domain.ErrBlogNotFound)
+		_, err = repos.Comments.FindDeleted(ctx, comment.ID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+		_, err = repos.Comments.FindByID(ctx, comment.ID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+		tags, err := repos.Tags.FindByBlogIDs(ctx, []int64{blog.ID})
+		mustNot(t, err)
+		if len(tags[blog.ID]) != 0 {
+			t.Fatalf("FindByBlogIDs = %+v tras purgar el blog", tags)
+		}
+		_, err = repos.Tags.FindBySlug(ctx, "go")
+		mustNot(t, err)
+	})
+
+	t.Run("Author", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		heir := createUser(t, repos, "bea")
+		blog := createBlog(t, repos, author, "activo")
+		trashed := createBlog(t, repos, author, "papelera")
+		mustNot(t, repos.Blogs.Delete(ctx, trashed.ID))
+
+		exists, err := repos.Blogs.ExistsByAuthor(ctx, author.ID)
+		mustNot(t, err)
+		if !exists {
+			t.Fatal("ExistsByAuthor = false, se esperaba true")
+		}
+
+		found, err := repos.Blogs.FindByIDForUpdate(ctx, blog.ID)
+		mustNot(t, err)
+		if found.ID != blog.ID {
+			t.Fatalf("FindByIDForUpdate = %+v", found)
+		}
+		_, err = repos.Blogs.FindByIDForUpdate(ctx, trashed.ID)
+		wantErr(t, err, domain.ErrBlogNotFound)
+
+		// La reasignación incluye los blogs de la papelera
+		mustNot(t, repos.Blogs.ReassignAuthor(ctx, author.ID, heir.ID))
+		exists, err = repos.Blogs.ExistsByAuthor(ctx, author.ID)
+		mustNot(t, err)
+		if exists {
+			t.Fatal("ExistsByAuthor = true tras reasignar los blogs")
+		}
+		mustNot(t, repos.Blogs.Restore(ctx, trashed.ID))
+		page, err := repos.Blogs.FindByAuthorID(ctx, heir.ID, domain.BlogFilter{}, domain.PageRequest{Limit: 10, Sort: domain.SortNewest})
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, blogID), []int64{trashed.ID, blog.ID})
+	})
+}
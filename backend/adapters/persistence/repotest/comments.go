@@ -0,0 +1,374 @@
+package repotest
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+	"testing"
+	"time"
+)
+
+func testComments(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, user, "blog")
+		comment := &domain.Comment{
+			BlogID:        blog.ID,
+			UserID:        user.ID,
+			Content:       "hola",
+			Status:        domain.CommentStatusPending,
+			FilterVerdict: &domain.FilterVerdict{Action: domain.FilterHold, Filter: "links", Reason: "demasiados enlaces"},
+		}
+		mustNot(t, repos.Comments.Create(ctx, comment))
+		if comment.ID == 0 || comment.CreatedAt.IsZero() {
+			t.Fatalf("Create no asignó ID ni fecha: %+v", comment)
+		}
+
+		found, err := repos.Comments.FindByID(ctx, comment.ID)
+		mustNot(t, err)
+		if found.Content != "hola" || found.BlogID != blog.ID || found.UserID != user.ID || found.ParentID != nil ||
+			found.Status != domain.CommentStatusPending || found.FilterVerdict == nil || found.FilterVerdict.Filter != "links" {
+			t.Fatalf("FindByID = %+v", found)
+		}
+
+		reply := createComment(t, repos, blog, user, comment, domain.CommentStatusApproved)
+		found, err = repos.Comments.FindByID(ctx, reply.ID)
+		mustNot(t, err)
+		if found.ParentID == nil || *found.ParentID != comment.ID || found.RootID == nil || *found.RootID != comment.ID || found.Depth != 1 {
+			t.Fatalf("FindByID de la respuesta = %+v", found)
+		}
+
+		_, err = repos.Comments.FindByID(ctx, missingID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+
+		mustNot(t, repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			found, err := repos.Comments.FindByIDForUpdate(ctx, comment.ID)
+			if err == nil && found.Content != "hola" {
+				t.Errorf("FindByIDForUpdate = %+v", found)
+			}
+			return err
+		}))
+		_, err = repos.Comments.FindByIDForUpdate(ctx, missingID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+	})
+
+	t.Run("Threads", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		viewer := createUser(t, repos, "bob")
+		blog := createBlog(t, repos, author, "blog")
+		approved := createComment(t, repos, blog, author, nil, domain.CommentStatusApproved)
+		pendingOwn := createComment(t, repos, blog, viewer, nil, domain.CommentStatusPending)
+		pendingOther := createComment(t, repos, blog, author, nil, domain.CommentStatusPending)
+		spam := createComment(t, repos, blog, author, nil, domain.CommentStatusSpam)
+		reply := createComment(t, repos, blog, viewer, approved, domain.CommentStatusApproved)
+		nested := createComment(t, repos, blog, author, reply, domain.CommentStatusPending)
+		// un comentario rechazado después de recibir respuestas sigue en la
+		// página para que sus respuestas visibles no desaparezcan
+		rejected := createComment(t, repos, blog, author, nil, domain.CommentStatusRejected)
+		createComment(t, repos, blog, viewer, rejected, domain.CommentStatusApproved)
+		// uno con solo respuestas pendientes de otros no aparece
+		hidden := createComment(t, repos, blog, author, nil, domain.CommentStatusSpam)
+		createComment(t, repos, blog, author, hidden, domain.CommentStatusPending)
+
+		request := domain.PageRequest{Limit: 10, Sort: domain.SortOldest}
+		tests := []struct {
+			name       string
+			visibility domain.CommentVisibility
+			roots      []int64
+		}{
+			{"anónimo", domain.CommentVisibility{}, []int64{approved.ID, rejected.ID}},
+			{"autor de pendientes", domain.CommentVisibility{ViewerID: viewer.ID}, []int64{approved.ID, pendingOwn.ID, rejected.ID}},
+			{"moderador", domain.CommentVisibility{Moderator: true}, []int64{approved.ID, pendingOwn.ID, pendingOther.ID, rejected.ID, hidden.ID}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := repos.Comments.FindByBlogID(ctx, blog.ID, request, tt.visibility)
+				mustNot(t, err)
+				wantIDs(t, ids(page.Items, commentID), tt.roots)
+			})
+		}
+
+		// las respuestas se cargan sea cual sea su estado; la visibilidad se aplica al armar el árbol
+		replies, err := repos.Comments.FindByRootIDs(ctx, []int64{approved.ID, spam.ID})
+		mustNot(t, err)
+		wantIDs(t, ids(replies, commentID), []int64{reply.ID, nested.ID})
+
+		replies, err = repos.Comments.FindByRootIDs(ctx, nil)
+		mustNot(t, err)
+		if replies == nil || len(replies) != 0 {
+			t.Fatalf("FindByRootIDs(nil) = %#v, se esperaba un slice vacío", replies)
+		}
+
+		count, err := repos.Comments.CountReplies(ctx, approved.ID)
+		mustNot(t, err)
+		if count != 1 {
+			t.Fatalf("CountReplies = %d, se esperaba 1", count)
+		}
+	})
+
+	t.Run("FindByUserID", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, user, "blog")
+		first := createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+		createComment(t, repos, blog, user, nil, domain.CommentStatusPending)
+		deleted := createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+		second := createComment(t, repos, blog, user, first, domain.CommentStatusApproved)
+		mustNot(t, repos.Comments.Delete(ctx, deleted.ID))
+
+		page, err := repos.Comments.FindByUserID(ctx, user.ID, domain.PageRequest{Limit: 1, Sort: domain.SortNewest})
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, commentID), []int64{second.ID})
+
+		page, err = repos.Comments.FindByUserID(ctx, user.ID, nextPage(t, domain.PageRequest{Limit: 1, Sort: domain.SortNewest}, page))
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, commentID), []int64{first.ID})
+	})
+
+	t.Run("FindRecentByUser", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		other := createUser(t, repos, "bob")
+		blog := createBlog(t, repos, user, "blog")
+		pending := createComment(t, repos, blog, user, nil, domain.CommentStatusPending)
+		spam := createComment(t, repos, blog, user, nil, domain.CommentStatusSpam)
+		deleted := createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+		createComment(t, repos, blog, other, nil, domain.CommentStatusApproved)
+		mustNot(t, repos.Comments.Delete(ctx, deleted.ID))
+
+		recent, err := repos.Comments.FindRecentByUser(ctx, user.ID, time.Now().Add(-time.Minute))
+		mustNot(t, err)
+		wantIDs(t, ids(recent, commentID), []int64{pending.ID, spam.ID})
+
+		recent, err = repos.Comments.FindRecentByUser(ctx, user.ID, future())
+		mustNot(t, err)
+		if len(recent) != 0 {
+			t.Fatalf("FindRecentByUser = %d comentarios futuros", len(recent))
+		}
+	})
+
+	t.Run("Moderation", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		moderator := createUser(t, repos, "mod")
+		blog := createBlog(t, repos, user, "blog")
+		otherBlog := createBlog(t, repos, user, "otro")
+		first := createComment(t, repos, blog, user, nil, domain.CommentStatusPending)
+		second := createComment(t, repos, otherBlog, user, nil, domain.CommentStatusPending)
+		createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+
+		request := domain.PageRequest{Limit: 10, Sort: domain.SortOldest}
+		page, err := repos.Comments.ListForModeration(ctx, domain.ModerationFilter{Status: domain.CommentStatusPending}, request)
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, commentID), []int64{first.ID, second.ID})
+
+		page, err = repos.Comments.ListForModeration(ctx, domain.ModerationFilter{Status: domain.CommentStatusPending, BlogID: otherBlog.ID}, request)
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, commentID), []int64{second.ID})
+
+		mustNot(t, first.Moderate(domain.CommentStatusSpam, "publicidad", moderator.ID, time.Now()))
+		mustNot(t, repos.Comments.UpdateModeration(ctx, first))
+
+		found, err := repos.Comments.FindByID(ctx, first.ID)
+		mustNot(t, err)
+		if found.Status != domain.CommentStatusSpam || found.ModerationReason != "publicidad" ||
+			found.ModeratedBy == nil || *found.ModeratedBy != moderator.ID || found.ModeratedAt == nil {
+			t.Fatalf("UpdateModeration no guardó la decisión: %+v", found)
+		}
+
+		page, err = repos.Comments.ListForModeration(ctx, domain.ModerationFilter{Status: domain.CommentStatusPending}, request)
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, commentID), []int64{second.ID})
+
+		wantErr(t, repos.Comments.UpdateModeration(ctx, &domain.Comment{ID: missingID, Status: domain.CommentStatusSpam}), domain.ErrCommentNotFound)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, user, "blog")
+		comment := createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+
+		comment.Content = "editado"
+		comment.Status = domain.CommentStatusPending
+		mustNot(t, repos.Comments.Update(ctx, comment))
+
+		found, err := repos.Comments.FindByID(ctx, comment.ID)
+		mustNot(t, err)
+		if found.Content != "editado" || found.Status != domain.CommentStatusPending {
+			t.Fatalf("Update no guardó los cambios: %+v", found)
+		}
+
+		mustNot(t, repos.Comments.Delete(ctx, comment.ID))
+		wantErr(t, repos.Comments.Update(ctx, comment), domain.ErrCommentNotFound)
+		wantErr(t, repos.Comments.Update(ctx, &domain.Comment{ID: missingID, Content: "x"}), domain.ErrCommentNotFound)
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, user, "blog")
+		comment := createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+
+		mustNot(t, repos.Comments.Delete(ctx, comment.ID))
+		wantErr(t, repos.Comments.Delete(ctx, comment.ID), domain.ErrCommentNotFound)
+		_, err := repos.Comments.FindByID(ctx, comment.ID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+
+		deleted, err := repos.Comments.FindDeleted(ctx, comment.ID)
+		mustNot(t, err)
+		if deleted.DeletedAt == nil || deleted.Removed {
+			t.Fatalf("FindDeleted = %+v", deleted)
+		}
+
+		page, err := repos.Comments.ListDeleted(ctx, domain.PageRequest{Limit: 10, Sort: domain.SortNewest})
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, commentID), []int64{comment.ID})
+
+		mustNot(t, repos.Comments.Restore(ctx, comment.ID))
+		wantErr(t, repos.Comments.Restore(ctx, comment.ID), domain.ErrCommentNotFound)
+		_, err = repos.Comments.FindDeleted(ctx, comment.ID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+		_, err = repos.Comments.FindByID(ctx, comment.ID)
+		mustNot(t, err)
+	})
+
+	t.Run("MarkRemoved", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, user, "blog")
+		parent := createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+		reply := createComment(t, repos, blog, user, parent, domain.CommentStatusApproved)
+
+		// Un comentario con respuestas se conserva en el hilo como eliminado
+		mustNot(t, repos.Comments.MarkRemoved(ctx, parent.ID))
+		found, err := repos.Comments.FindByID(ctx, parent.ID)
+		mustNot(t, err)
+		if !found.Removed || found.DeletedAt == nil {
+			t.Fatalf("FindByID tras MarkRemoved = %+v", found)
+		}
+		page, err := repos.Comments.FindByBlogID(ctx, blog.ID, domain.PageRequest{Limit: 10, Sort: domain.SortOldest}, domain.CommentVisibility{})
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, commentID), []int64{parent.ID})
+		wantErr(t, repos.Comments.MarkRemoved(ctx, missingID), domain.ErrCommentNotFound)
+
+		// La purga no elimina los nodos que conservan respuestas
+		purged, err := repos.Comments.PurgeDeleted(ctx, future())
+		mustNot(t, err)
+		if purged != 0 {
+			t.Fatalf("PurgeDeleted = %d, se esperaba 0", purged)
+		}
+
+		// Al quedarse sin respuestas pasa a ser un comentario normal de la papelera
+		mustNot(t, repos.Comments.Delete(ctx, reply.ID))
+		mustNot(t, repos.Comments.Delete(ctx, parent.ID))
+		_, err = repos.Comments.FindByID(ctx, parent.ID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+
+		// El número de purgados depende de si la respuesta cae antes por la cascada
+		purged, err = repos.Comments.PurgeDeleted(ctx, future())
+		mustNot(t, err)
+		if purged == 0 {
+			t.Fatal("PurgeDeleted no purgó el comentario")
+		}
+		_, err = repos.Comments.FindDeleted(ctx, parent.ID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+		_, err = repos.Comments.FindDeleted(ctx, reply.ID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+	})
+
+	t.Run("PurgeDeleted", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, user, "blog")
+		parent := createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+		reply := createComment(t, repos, blog, user, parent, domain.CommentStatusApproved)
+		mustNot(t, repos.Comments.Delete(ctx, parent.ID))
+
+		purged, err := repos.Comments.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+		mustNot(t, err)
+		if purged != 0 {
+			t.Fatalf("PurgeDeleted purgó %d comentarios recientes", purged)
+		}
+
+		// Las respuestas se eliminan en cascada, pero no cuentan como purgadas
+		purged, err = repos.Comments.PurgeDeleted(ctx, future())
+		mustNot(t, err)
+		if purged != 1 {
+			t.Fatalf("PurgeDeleted = %d, se esperaba 1", purged)
+		}
+		_, err = repos.Comments.FindByID(ctx, reply.ID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+	})
+
+	t.Run("ByBlog", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, user, "blog")
+		other := createBlog(t, repos, user, "otro")
+		pending := createComment(t, repos, blog, user, nil, domain.CommentStatusPending)
+		earlier := createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+		kept := createComment(t, repos, other, user, nil, domain.CommentStatusApproved)
+
+		exists, err := repos.Comments.ExistsByBlog(ctx, blog.ID)
+		mustNot(t, err)
+		if !exists {
+			t.Fatal("ExistsByBlog = false, se esperaba true")
+		}
+
+		// Un comentario eliminado antes que el blog no se restaura con él
+		mustNot(t, repos.Comments.Delete(ctx, earlier.ID))
+		mustNot(t, repos.BackdateComment(earlier.ID, time.Now().Add(-time.Hour)))
+		mustNot(t, repos.Blogs.Delete(ctx, blog.ID))
+		mustNot(t, repos.Comments.DeleteByBlog(ctx, blog.ID))
+
+		exists, err = repos.Comments.ExistsByBlog(ctx, blog.ID)
+		mustNot(t, err)
+		if exists {
+			t.Fatal("ExistsByBlog = true tras eliminar los comentarios del blog")
+		}
+		_, err = repos.Comments.FindDeleted(ctx, pending.ID)
+		mustNot(t, err)
+		_, err = repos.Comments.FindByID(ctx, kept.ID)
+		mustNot(t, err)
+
+		mustNot(t, repos.Comments.RestoreByBlog(ctx, blog.ID))
+		mustNot(t, repos.Blogs.Restore(ctx, blog.ID))
+		_, err = repos.Comments.FindByID(ctx, pending.ID)
+		mustNot(t, err)
+		_, err = repos.Comments.FindDeleted(ctx, earlier.ID)
+		mustNot(t, err)
+	})
+
+	t.Run("User", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		heir := createUser(t, repos, "bea")
+		blog := createBlog(t, repos, heir, "blog")
+		comment := createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+		trashed := createComment(t, repos, blog, user, nil, domain.CommentStatusApproved)
+		mustNot(t, repos.Comments.Delete(ctx, trashed.ID))
+
+		exists, err := repos.Comments.ExistsByUser(ctx, user.ID)
+		mustNot(t, err)
+		if !exists {
+			t.Fatal("ExistsByUser = false, se esperaba true")
+		}
+
+		// La reasignación incluye los comentarios de la papelera
+		mustNot(t, repos.Comments.ReassignUser(ctx, user.ID, heir.ID))
+		exists, err = repos.Comments.ExistsByUser(ctx, user.ID)
+		mustNot(t, err)
+		if exists {
+			t.Fatal("ExistsByUser = true tras reasignar los comentarios")
+		}
+		found, err := repos.Comments.FindByID(ctx, comment.ID)
+		mustNot(t, err)
+		deleted, err := repos.Comments.FindDeleted(ctx, trashed.ID)
+		mustNot(t, err)
+		if found.UserID != heir.ID || deleted.UserID != heir.ID {
+			t.Fatalf("autores tras ReassignUser = %d y %d, se esperaba %d", found.UserID, deleted.UserID, heir.ID)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+// Package repotest contiene las pruebas de contrato que deben superar todas las
+// implementaciones de los repositorios de usuarios, blogs, comentarios y
+// etiquetas y de su gestor de transacciones. Cada adaptador las ejecuta desde sus propias pruebas con Run para
+// comprobar que respeta los mismos errores y la misma semántica.
+package repotest
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Repositories son los repositorios bajo prueba. Deben compartir el mismo
+// almacenamiento, como las tablas de una misma base de datos.
+type Repositories struct {
+	Users    ports.UserRepository
+	Blogs    ports.BlogRepository
+	Comments ports.CommentRepository
+	Tags     ports.TagRepository
+	Tx       ports.TxManager
+
+	// BackdateComment cambia la fecha de eliminación de un comentario de la
+	// papelera, para que las pruebas fijen las fechas sin esperar al reloj
+	BackdateComment func(id int64, deletedAt time.Time) error
+}
+
+// BackdateCommentSQL implementa Repositories.BackdateComment sobre las tablas de db
+func BackdateCommentSQL(db *sql.DB) func(id int64, deletedAt time.Time) error {
+	return func(id int64, deletedAt time.Time) error {
+		_, err := db.Exec(`UPDATE comments SET deleted_at = ? WHERE id = ? AND deleted_at IS NOT NULL`, deletedAt, id)
+		return err
+	}
+}
+
+// Factory crea repositorios sin datos para una prueba
+type Factory func(t *testing.T) Repositories
+
+// Run ejecuta todas las pruebas de contrato contra los repositorios de newRepos
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Users", func(t *testing.T) { testUsers(t, newRepos) })
+	t.Run("Blogs", func(t *testing.T) { testBlogs(t, newRepos) })
+	t.Run("Comments", func(t *testing.T) { testComments(t, newRepos) })
+	t.Run("Tags", func(t *testing.T) { testTags(t, newRepos) })
+	t.Run("Tx", func(t *testing.T) { testTx(t, newRepos) })
+}
+
+// ctx es el contexto de las operaciones de las pruebas
+var ctx = context.Background()
+
+// missingID es un ID que no usa ningún registro, ni siquiera en una base de
+// datos cuyos autoincrementales avanzan entre pruebas
+const missingID = 1 << 40
+
+// future es una fecha posterior a cualquier marca de borrado de la prueba. Deja
+// margen porque la base de datos puede redondear los segundos.
+func future() time.Time {
+	return time.Now().Add(time.Minute)
+}
+
+// wantErr falla si err no es want
+func wantErr(t *testing.T, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Fatalf("error = %v, se esperaba %v", err, want)
+	}
+}
+
+// mustNot falla si err no es nil
+func mustNot(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+}
+
+// createUser crea un usuario con el rol Usuario
+func createUser(t *testing.T, repos Repositories, username string) *domain.User {
+	t.Helper()
+	user := &domain.User{Username: username, Email: username + "@example.com", Password: "hash", Role: domain.RoleUser}
+	mustNot(t, repos.Users.Create(ctx, user))
+	return user
+}
+
+// createBlog crea un blog publicado de author
+func createBlog(t *testing.T, repos Repositories, author *domain.User, title string) *domain.Blog {
+	t.Helper()
+	publishedAt := time.Now().Add(-time.Hour)
+	blog := &domain.Blog{Title: title, Content: "contenido", AuthorID: author.ID, Status: domain.BlogStatusPublished, PublishedAt: &publishedAt}
+	mustNot(t, repos.Blogs.Create(ctx, blog))
+	return blog
+}
+
+// createComment crea un comentario con el estado indicado; parent es nil para los comentarios raíz
+func createComment(t *testing.T, repos Repositories, blog *domain.Blog, user *domain.User, parent *domain.Comment, status domain.CommentStatus) *domain.Comment {
+	t.Helper()
+	comment := &domain.Comment{BlogID: blog.ID, UserID: user.ID, Content: "comentario", Status: status}
+	if parent != nil {
+		rootID := parent.ID
+		if parent.RootID != nil {
+			rootID = *parent.RootID
+		}
+		comment.ParentID = &parent.ID
+		comment.RootID = &rootID
+		comment.Depth = parent.Depth + 1
+	}
+	mustNot(t, repos.Comments.Create(ctx, comment))
+	return comment
+}
+
+// ids retorna los IDs de los elementos en el orden recibido
+func ids[T any](items []T, id func(T) int64) []int64 {
+	result := make([]int64, 0, len(items))
+	for _, item := range items {
+		result = append(result, id(item))
+	}
+	return result
+}
+
+func blogID(blog domain.Blog) int64          { return blog.ID }
+func commentID(comment domain.Comment) int64 { return comment.ID }
+func userID(user domain.User) int64          { return user.ID }
+
+// wantIDs falla si got no contiene exactamente want en ese orden
+func wantIDs(t *testing.T, got, want []int64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("IDs = %v, se esperaba %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("IDs = %v, se esperaba %v", got, want)
+		}
+	}
+}
+
+// nextPage retorna la petición de la página siguiente a page
+func nextPage[T any](t *testing.T, request domain.PageRequest, page *domain.Page[T]) domain.PageRequest {
+	t.Helper()
+	if !page.HasMore || page.NextCursor == "" {
+		t.Fatalf("se esperaba otra página: %+v", page)
+	}
+	after, err := domain.DecodeCursor(page.NextCursor)
+	mustNot(t, err)
+	request.After = after
+	return request
+}
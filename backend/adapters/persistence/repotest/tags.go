@@ -0,0 +1,143 @@
+package repotest
+
+import (
+	"blog-backend/internal/domain"
+	"testing"
+)
+
+func testTags(t *testing.T, newRepos Factory) {
+	t.Run("SetBlogTags", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, author, "blog")
+		other := createBlog(t, repos, author, "otro")
+
+		saved, err := repos.Tags.SetBlogTags(ctx, blog.ID, []domain.Tag{{Name: "Go", Slug: "go"}, {Name: "Bases de datos", Slug: "bases-de-datos"}})
+		mustNot(t, err)
+		if len(saved) != 2 || saved[0].ID == 0 || saved[1].ID == 0 {
+			t.Fatalf("SetBlogTags = %+v", saved)
+		}
+
+		// Las etiquetas existentes se reutilizan por slug conservando su nombre
+		reused, err := repos.Tags.SetBlogTags(ctx, other.ID, []domain.Tag{{Name: "GO", Slug: "go"}})
+		mustNot(t, err)
+		if len(reused) != 1 || reused[0].ID != saved[0].ID || reused[0].Name != "Go" {
+			t.Fatalf("SetBlogTags = %+v, se esperaba reutilizar %+v", reused, saved[0])
+		}
+
+		// Reemplazar las etiquetas descarta las anteriores
+		_, err = repos.Tags.SetBlogTags(ctx, blog.ID, []domain.Tag{{Name: "Go", Slug: "go"}})
+		mustNot(t, err)
+
+		tags, err := repos.Tags.FindByBlogIDs(ctx, []int64{blog.ID, other.ID, missingID})
+		mustNot(t, err)
+		if len(tags[blog.ID]) != 1 || tags[blog.ID][0].Slug != "go" || len(tags[other.ID]) != 1 || len(tags[missingID]) != 0 {
+			t.Fatalf("FindByBlogIDs = %+v", tags)
+		}
+
+		empty, err := repos.Tags.FindByBlogIDs(ctx, nil)
+		mustNot(t, err)
+		if empty == nil || len(empty) != 0 {
+			t.Fatalf("FindByBlogIDs(nil) = %#v", empty)
+		}
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, author, "blog")
+		saved, err := repos.Tags.SetBlogTags(ctx, blog.ID, []domain.Tag{{Name: "Go", Slug: "go"}})
+		mustNot(t, err)
+
+		byID, err := repos.Tags.FindByID(ctx, saved[0].ID)
+		mustNot(t, err)
+		bySlug, err := repos.Tags.FindBySlug(ctx, "go")
+		mustNot(t, err)
+		if byID.Name != "Go" || bySlug.ID != saved[0].ID {
+			t.Fatalf("FindByID = %+v, FindBySlug = %+v", byID, bySlug)
+		}
+
+		_, err = repos.Tags.FindByID(ctx, missingID)
+		wantErr(t, err, domain.ErrTagNotFound)
+		_, err = repos.Tags.FindBySlug(ctx, "nada")
+		wantErr(t, err, domain.ErrTagNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		first := createBlog(t, repos, author, "primero")
+		second := createBlog(t, repos, author, "segundo")
+		draft := &domain.Blog{Title: "borrador", Content: "c", AuthorID: author.ID, Status: domain.BlogStatusDraft}
+		mustNot(t, repos.Blogs.Create(ctx, draft))
+
+		_, err := repos.Tags.SetBlogTags(ctx, first.ID, []domain.Tag{{Name: "Go", Slug: "go"}, {Name: "SQL", Slug: "sql"}})
+		mustNot(t, err)
+		_, err = repos.Tags.SetBlogTags(ctx, second.ID, []domain.Tag{{Name: "Go", Slug: "go"}})
+		mustNot(t, err)
+		// Los borradores no cuentan
+		_, err = repos.Tags.SetBlogTags(ctx, draft.ID, []domain.Tag{{Name: "SQL", Slug: "sql"}, {Name: "Borrador", Slug: "borrador"}})
+		mustNot(t, err)
+
+		tags, err := repos.Tags.List(ctx)
+		mustNot(t, err)
+		want := []domain.Tag{{Name: "Go", PostCount: 2}, {Name: "SQL", PostCount: 1}, {Name: "Borrador", PostCount: 0}}
+		if len(tags) != len(want) {
+			t.Fatalf("List = %+v", tags)
+		}
+		for i := range want {
+			if tags[i].Name != want[i].Name || tags[i].PostCount != want[i].PostCount {
+				t.Fatalf("List = %+v, se esperaba %+v", tags, want)
+			}
+		}
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, author, "blog")
+		saved, err := repos.Tags.SetBlogTags(ctx, blog.ID, []domain.Tag{{Name: "Go", Slug: "go"}, {Name: "SQL", Slug: "sql"}})
+		mustNot(t, err)
+
+		renamed := &domain.Tag{ID: saved[0].ID, Name: "Golang", Slug: "golang"}
+		mustNot(t, repos.Tags.Rename(ctx, renamed))
+		found, err := repos.Tags.FindBySlug(ctx, "golang")
+		mustNot(t, err)
+		if found.ID != saved[0].ID || found.Name != "Golang" {
+			t.Fatalf("FindBySlug tras Rename = %+v", found)
+		}
+
+		// El slug es único
+		if err := repos.Tags.Rename(ctx, &domain.Tag{ID: saved[0].ID, Name: "SQL", Slug: "sql"}); err == nil {
+			t.Fatal("Rename aceptó un slug repetido")
+		}
+		wantErr(t, repos.Tags.Rename(ctx, &domain.Tag{ID: missingID, Name: "x", Slug: "x"}), domain.ErrTagNotFound)
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		both := createBlog(t, repos, author, "ambas")
+		sourceOnly := createBlog(t, repos, author, "origen")
+		saved, err := repos.Tags.SetBlogTags(ctx, both.ID, []domain.Tag{{Name: "golang", Slug: "golang"}, {Name: "Go", Slug: "go"}})
+		mustNot(t, err)
+		source, target := saved[0], saved[1]
+		_, err = repos.Tags.SetBlogTags(ctx, sourceOnly.ID, []domain.Tag{{Name: "golang", Slug: "golang"}})
+		mustNot(t, err)
+
+		mustNot(t, repos.Tags.Merge(ctx, source.ID, target.ID))
+		_, err = repos.Tags.FindByID(ctx, source.ID)
+		wantErr(t, err, domain.ErrTagNotFound)
+
+		// Los blogs que ya tenían ambas etiquetas conservan una sola asignación
+		tags, err := repos.Tags.FindByBlogIDs(ctx, []int64{both.ID, sourceOnly.ID})
+		mustNot(t, err)
+		for _, id := range []int64{both.ID, sourceOnly.ID} {
+			if len(tags[id]) != 1 || tags[id][0].ID != target.ID {
+				t.Fatalf("FindByBlogIDs tras Merge = %+v", tags)
+			}
+		}
+
+		wantErr(t, repos.Tags.Merge(ctx, source.ID, target.ID), domain.ErrTagNotFound)
+	})
+}
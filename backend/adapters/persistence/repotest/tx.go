@@ -0,0 +1,90 @@
+package repotest
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+	"errors"
+	"testing"
+)
+
+func testTx(t *testing.T, newRepos Factory) {
+	t.Run("Commit", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+
+		var blog *domain.Blog
+		mustNot(t, repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			blog = &domain.Blog{Title: "blog", Content: "contenido", AuthorID: author.ID, Status: domain.BlogStatusDraft}
+			if err := repos.Blogs.Create(ctx, blog); err != nil {
+				return err
+			}
+			// Las lecturas dentro de la transacción ven sus propias escrituras
+			_, err := repos.Blogs.FindByIDForUpdate(ctx, blog.ID)
+			return err
+		}))
+
+		_, err := repos.Blogs.FindByID(ctx, blog.ID)
+		mustNot(t, err)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		kept := createBlog(t, repos, author, "conservado")
+		_, err := repos.Tags.SetBlogTags(ctx, kept.ID, []domain.Tag{{Name: "Go", Slug: "go"}})
+		mustNot(t, err)
+
+		failure := errors.New("fallo")
+		var created *domain.Blog
+		err = repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			created = &domain.Blog{Title: "revertido", Content: "contenido", AuthorID: author.ID, Status: domain.BlogStatusDraft}
+			if err := repos.Blogs.Create(ctx, created); err != nil {
+				return err
+			}
+			if err := repos.Blogs.Delete(ctx, kept.ID); err != nil {
+				return err
+			}
+			// Las operaciones de varias sentencias se unen a la transacción
+			if _, err := repos.Tags.SetBlogTags(ctx, kept.ID, nil); err != nil {
+				return err
+			}
+			return failure
+		})
+		wantErr(t, err, failure)
+
+		_, err = repos.Blogs.FindByID(ctx, created.ID)
+		wantErr(t, err, domain.ErrBlogNotFound)
+		_, err = repos.Blogs.FindByID(ctx, kept.ID)
+		mustNot(t, err)
+		tags, err := repos.Tags.FindByBlogIDs(ctx, []int64{kept.ID})
+		mustNot(t, err)
+		if len(tags[kept.ID]) != 1 {
+			t.Fatalf("FindByBlogIDs = %+v tras revertir la transacción", tags)
+		}
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		repos := newRepos(t)
+		author := createUser(t, repos, "ana")
+		blog := createBlog(t, repos, author, "blog")
+
+		// La transacción anidada se une a la exterior: si esta se revierte,
+		// también se revierte lo que aquella confirmó
+		failure := errors.New("fallo")
+		err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repos.Tx.WithinTx(ctx, func(ctx context.Context) error {
+				return repos.Blogs.Delete(ctx, blog.ID)
+			}); err != nil {
+				return err
+			}
+			if _, err := repos.Blogs.FindByID(ctx, blog.ID); err != domain.ErrBlogNotFound {
+				t.Errorf("FindByID en la transacción exterior = %v, se esperaba %v", err, domain.ErrBlogNotFound)
+			}
+			return failure
+		})
+		wantErr(t, err, failure)
+
+		_, err = repos.Blogs.FindByID(ctx, blog.ID)
+		mustNot(t, err)
+	})
+}
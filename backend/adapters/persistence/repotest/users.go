@@ -0,0 +1,327 @@
+package repotest
+
+import (
+	"blog-backend/internal/domain"
+	"testing"
+	"time"
+)
+
+func testUsers(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndFind", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		if user.ID == 0 || user.CreatedAt.IsZero() {
+			t.Fatalf("Create no asignó ID ni fecha: %+v", user)
+		}
+
+		byID, err := repos.Users.FindByID(ctx, user.ID)
+		mustNot(t, err)
+		if byID.Username != "ana" || byID.Email != "ana@example.com" || byID.Role != domain.RoleUser || byID.Password != "hash" {
+			t.Fatalf("FindByID = %+v", byID)
+		}
+
+		// El nombre de usuario no distingue mayúsculas
+		byName, err := repos.Users.FindByUsername(ctx, "ANA")
+		mustNot(t, err)
+		if byName.ID != user.ID {
+			t.Fatalf("FindByUsername = %d, se esperaba %d", byName.ID, user.ID)
+		}
+
+		byEmail, err := repos.Users.FindByEmail(ctx, "ana@example.com")
+		mustNot(t, err)
+		if byEmail.ID != user.ID {
+			t.Fatalf("FindByEmail = %d, se esperaba %d", byEmail.ID, user.ID)
+		}
+	})
+
+	t.Run("DeletedUser", func(t *testing.T) {
+		repos := newRepos(t)
+
+		// El nombre reservado solo se crea con CreateDeletedUser
+		squatter := &domain.User{Username: "Usuario-Eliminado", Password: "hash", Role: domain.RoleAdmin}
+		wantErr(t, repos.Users.Create(ctx, squatter), domain.ErrUserAlreadyExists)
+
+		heir, err := repos.Users.CreateDeletedUser(ctx)
+		mustNot(t, err)
+		found, err := repos.Users.FindByUsername(ctx, domain.DeletedUsername)
+		mustNot(t, err)
+		if found.ID != heir.ID || found.Password != "" || found.Role != domain.RoleUser {
+			t.Fatalf("FindByUsername = %+v", found)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repos := newRepos(t)
+		noEmail := &domain.User{Username: "sincorreo", Password: "hash", Role: domain.RoleUser}
+		mustNot(t, repos.Users.Create(ctx, noEmail))
+
+		_, err := repos.Users.FindByID(ctx, missingID)
+		wantErr(t, err, domain.ErrUserNotFound)
+		_, err = repos.Users.FindByUsername(ctx, "nadie")
+		wantErr(t, err, domain.ErrUserNotFound)
+		_, err = repos.Users.FindByEmail(ctx, "nadie@example.com")
+		wantErr(t, err, domain.ErrUserNotFound)
+		// Un usuario sin correo no se encuentra buscando el correo vacío
+		_, err = repos.Users.FindByEmail(ctx, "")
+		wantErr(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("Unique", func(t *testing.T) {
+		repos := newRepos(t)
+		createUser(t, repos, "ana")
+
+		if err := repos.Users.Create(ctx, &domain.User{Username: "Ana", Password: "hash", Role: domain.RoleUser}); err == nil {
+			t.Fatal("Create aceptó un nombre de usuario repetido")
+		}
+		if err := repos.Users.Create(ctx, &domain.User{Username: "otra", Email: "ana@example.com", Password: "hash", Role: domain.RoleUser}); err == nil {
+			t.Fatal("Create aceptó un correo repetido")
+		}
+
+		bob := createUser(t, repos, "bob")
+		bob.Username = "ANA"
+		if err := repos.Users.Update(ctx, bob); err == nil {
+			t.Fatal("Update aceptó un nombre de usuario repetido")
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		repos := newRepos(t)
+		users, err := repos.Users.List(ctx)
+		mustNot(t, err)
+		if len(users) != 0 {
+			t.Fatalf("List = %d usuarios, se esperaba 0", len(users))
+		}
+
+		ana := createUser(t, repos, "ana")
+		bob := createUser(t, repos, "bob")
+		carla := createUser(t, repos, "carla")
+		mustNot(t, repos.Users.Delete(ctx, bob.ID))
+
+		users, err = repos.Users.List(ctx)
+		mustNot(t, err)
+		wantIDs(t, ids(users, userID), []int64{ana.ID, carla.ID})
+	})
+
+	t.Run("ExistsByRole", func(t *testing.T) {
+		repos := newRepos(t)
+		admin := &domain.User{Username: "admin", Password: "hash", Role: domain.RoleAdmin}
+		mustNot(t, repos.Users.Create(ctx, admin))
+
+		exists, err := repos.Users.ExistsByRole(ctx, domain.RoleAdmin)
+		mustNot(t, err)
+		if !exists {
+			t.Fatal("ExistsByRole(Administrador) = false")
+		}
+		exists, err = repos.Users.ExistsByRole(ctx, domain.RoleEditor)
+		mustNot(t, err)
+		if exists {
+			t.Fatal("ExistsByRole(Editor) = true")
+		}
+
+		// Los usuarios de la papelera no cuentan
+		mustNot(t, repos.Users.Delete(ctx, admin.ID))
+		exists, err = repos.Users.ExistsByRole(ctx, domain.RoleAdmin)
+		mustNot(t, err)
+		if exists {
+			t.Fatal("ExistsByRole cuenta usuarios eliminados")
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		verifiedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+		user.Username = "ana2"
+		user.Email = "ana2@example.com"
+		user.Password = "otro"
+		user.Role = domain.RoleEditor
+		user.EmailVerifiedAt = &verifiedAt
+		mustNot(t, repos.Users.Update(ctx, user))
+
+		found, err := repos.Users.FindByID(ctx, user.ID)
+		mustNot(t, err)
+		if found.Username != "ana2" || found.Email != "ana2@example.com" || found.Password != "otro" ||
+			found.Role != domain.RoleEditor || found.EmailVerifiedAt == nil {
+			t.Fatalf("Update no guardó los cambios: %+v", found)
+		}
+
+		missing := &domain.User{ID: missingID, Username: "nadie", Role: domain.RoleUser}
+		wantErr(t, repos.Users.Update(ctx, missing), domain.ErrUserNotFound)
+		wantErr(t, repos.Users.UpdateLoginState(ctx, missing), domain.ErrUserNotFound)
+		wantErr(t, repos.Users.UpdateEmailVerification(ctx, missing), domain.ErrUserNotFound)
+		wantErr(t, repos.Users.UpdateTwoFactor(ctx, missing), domain.ErrUserNotFound)
+	})
+
+	t.Run("UpdateState", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		now := time.Now().Truncate(time.Second)
+		lockedUntil := now.Add(time.Hour)
+
+		user.FailedLogins = 3
+		user.LastFailedLoginAt = &now
+		user.LockedUntil = &lockedUntil
+		mustNot(t, repos.Users.UpdateLoginState(ctx, user))
+
+		user.EmailVerifiedAt = &now
+		user.VerificationSentAt = &now
+		mustNot(t, repos.Users.UpdateEmailVerification(ctx, user))
+
+		user.TOTPSecret = "JBSWY3DPEHPK3PXP"
+		user.TOTPEnabledAt = &now
+		mustNot(t, repos.Users.UpdateTwoFactor(ctx, user))
+
+		found, err := repos.Users.FindByID(ctx, user.ID)
+		mustNot(t, err)
+		if found.FailedLogins != 3 || found.LastFailedLoginAt == nil || found.LockedUntil == nil {
+			t.Fatalf("UpdateLoginState no guardó el estado: %+v", found)
+		}
+		if found.EmailVerifiedAt == nil || found.VerificationSentAt == nil {
+			t.Fatalf("UpdateEmailVerification no guardó el estado: %+v", found)
+		}
+		if found.TOTPSecret != "JBSWY3DPEHPK3PXP" || found.TOTPEnabledAt == nil {
+			t.Fatalf("UpdateTwoFactor no guardó el estado: %+v", found)
+		}
+	})
+
+	t.Run("RegisterLoginFailure", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		policy := domain.LockoutPolicy{MaxAccountFailures: 3, FailureWindow: time.Hour, LockoutDuration: time.Hour}
+		now := time.Now().Truncate(time.Second)
+
+		steps := []struct {
+			at       time.Time
+			failures int
+			locked   bool
+		}{
+			{at: now, failures: 1},
+			{at: now.Add(time.Minute), failures: 2},
+			// fuera de la ventana el contador vuelve a empezar
+			{at: now.Add(2 * time.Hour), failures: 1},
+			{at: now.Add(2*time.Hour + time.Minute), failures: 2},
+			// al llegar al máximo se bloquea y el contador vuelve a cero
+			{at: now.Add(2*time.Hour + 2*time.Minute), failures: 0, locked: true},
+		}
+		for i, step := range steps {
+			updated, err := repos.Users.RegisterLoginFailure(ctx, user.ID, step.at, policy)
+			mustNot(t, err)
+			if updated.FailedLogins != step.failures || updated.LastFailedLoginAt == nil || !updated.LastFailedLoginAt.Equal(step.at) {
+				t.Fatalf("paso %d: estado = %d fallos, último %v", i, updated.FailedLogins, updated.LastFailedLoginAt)
+			}
+			if updated.IsLocked(step.at) != step.locked {
+				t.Fatalf("paso %d: bloqueado = %v, se esperaba %v", i, updated.IsLocked(step.at), step.locked)
+			}
+		}
+
+		_, err := repos.Users.RegisterLoginFailure(ctx, missingID, now, policy)
+		wantErr(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("AdvanceTOTPStep", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+
+		mustNot(t, repos.Users.AdvanceTOTPStep(ctx, user.ID, 10))
+		wantErr(t, repos.Users.AdvanceTOTPStep(ctx, user.ID, 10), domain.ErrInvalidTOTPCode)
+		wantErr(t, repos.Users.AdvanceTOTPStep(ctx, user.ID, 9), domain.ErrInvalidTOTPCode)
+		mustNot(t, repos.Users.AdvanceTOTPStep(ctx, user.ID, 11))
+
+		found, err := repos.Users.FindByID(ctx, user.ID)
+		mustNot(t, err)
+		if found.TOTPLastStep != 11 {
+			t.Fatalf("TOTPLastStep = %d, se esperaba 11", found.TOTPLastStep)
+		}
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos, "ana")
+		mustNot(t, repos.Users.Delete(ctx, user.ID))
+		wantErr(t, repos.Users.Delete(ctx, user.ID), domain.ErrUserNotFound)
+		wantErr(t, repos.Users.Update(ctx, user), domain.ErrUserNotFound)
+
+		_, err := repos.Users.FindByID(ctx, user.ID)
+		wantErr(t, err, domain.ErrUserNotFound)
+
+		// El nombre y el correo siguen reservados
+		byName, err := repos.Users.FindByUsername(ctx, "ana")
+		mustNot(t, err)
+		if !byName.IsDeleted() {
+			t.Fatal("FindByUsername no marca el usuario como eliminado")
+		}
+		byEmail, err := repos.Users.FindByEmail(ctx, "ana@example.com")
+		mustNot(t, err)
+		if !byEmail.IsDeleted() {
+			t.Fatal("FindByEmail no marca el usuario como eliminado")
+		}
+
+		mustNot(t, repos.Users.Restore(ctx, user.ID))
+		wantErr(t, repos.Users.Restore(ctx, user.ID), domain.ErrUserNotFound)
+		wantErr(t, repos.Users.Restore(ctx, missingID), domain.ErrUserNotFound)
+		_, err = repos.Users.FindByID(ctx, user.ID)
+		mustNot(t, err)
+	})
+
+	t.Run("ListDeleted", func(t *testing.T) {
+		repos := newRepos(t)
+		var deleted []int64
+		for _, name := range []string{"ana", "bob", "carla"} {
+			user := createUser(t, repos, name)
+			mustNot(t, repos.Users.Delete(ctx, user.ID))
+			deleted = append(deleted, user.ID)
+		}
+		createUser(t, repos, "activo")
+
+		request := domain.PageRequest{Limit: 2, Sort: domain.SortNewest}
+		page, err := repos.Users.ListDeleted(ctx, request)
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, userID), []int64{deleted[2], deleted[1]})
+
+		page, err = repos.Users.ListDeleted(ctx, nextPage(t, request, page))
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, userID), []int64{deleted[0]})
+		if page.HasMore {
+			t.Fatal("la última página indica que hay más")
+		}
+
+		page, err = repos.Users.ListDeleted(ctx, domain.PageRequest{Limit: 10, Sort: domain.SortOldest})
+		mustNot(t, err)
+		wantIDs(t, ids(page.Items, userID), deleted)
+	})
+
+	t.Run("PurgeDeleted", func(t *testing.T) {
+		repos := newRepos(t)
+		ana := createUser(t, repos, "ana")
+		bob := createUser(t, repos, "bob")
+		anaBlog := createBlog(t, repos, ana, "de ana")
+		bobBlog := createBlog(t, repos, bob, "de bob")
+		anaComment := createComment(t, repos, bobBlog, ana, nil, domain.CommentStatusApproved)
+		reply := createComment(t, repos, bobBlog, bob, anaComment, domain.CommentStatusApproved)
+		mustNot(t, repos.Users.Delete(ctx, ana.ID))
+
+		purged, err := repos.Users.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+		mustNot(t, err)
+		if purged != 0 {
+			t.Fatalf("PurgeDeleted purgó %d usuarios recientes", purged)
+		}
+
+		purged, err = repos.Users.PurgeDeleted(ctx, future())
+		mustNot(t, err)
+		if purged != 1 {
+			t.Fatalf("PurgeDeleted = %d, se esperaba 1", purged)
+		}
+
+		// Los blogs y comentarios del usuario, y las respuestas a estos, se eliminan en cascada
+		_, err = repos.Users.FindByUsername(ctx, "ana")
+		wantErr(t, err, domain.ErrUserNotFound)
+		_, err = repos.Blogs.FindByID(ctx, anaBlog.ID)
+		wantErr(t, err, domain.ErrBlogNotFound)
+		_, err = repos.Comments.FindByID(ctx, anaComment.ID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+		_, err = repos.Comments.FindByID(ctx, reply.ID)
+		wantErr(t, err, domain.ErrCommentNotFound)
+		_, err = repos.Blogs.FindByID(ctx, bobBlog.ID)
+		mustNot(t, err)
+	})
+}
@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RevisionRepositorySQL implementa la interfaz RevisionRepository usando SQL
+type RevisionRepositorySQL struct {
+	db *sql.DB
+}
+
+// NewRevisionRepositorySQL crea una nueva instancia del repositorio SQL de revisiones
+func NewRevisionRepositorySQL(db *sql.DB) ports.RevisionRepository {
+	return &RevisionRepositorySQL{db: db}
+}
+
+// Create guarda una nueva revisión
+func (r *RevisionRepositorySQL) Create(ctx context.Context, revision *domain.Revision) error {
+	revision.CreatedAt = time.Now()
+
+	query := `INSERT INTO revisions (entity_type, entity_id, editor_id, title, content, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, revision.EntityType, revision.EntityID, revision.EditorID, revision.Title, revision.Content, revision.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error creando revisión: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error obteniendo ID de la revisión: %w", err)
+	}
+
+	revision.ID = id
+	return nil
+}
+
+// FindByID busca una revisión por su ID
+func (r *RevisionRepositorySQL) FindByID(ctx context.Context, id int64) (*domain.Revision, error) {
+	query := `SELECT id, entity_type, entity_id, editor_id, title, content, created_at FROM revisions WHERE id = ?`
+	revision := &domain.Revision{}
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&revision.ID, &revision.EntityType, &revision.EntityID, &revision.EditorID,
+		&revision.Title, &revision.Content, &revision.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("error buscando revisión por ID: %w", err)
+	}
+
+	return revision, nil
+}
+
+// FindByEntity lista las revisiones de un blog o comentario, de la más reciente a la más antigua
+func (r *RevisionRepositorySQL) FindByEntity(ctx context.Context, entityType domain.RevisionEntity, entityID int64) ([]domain.Revision, error) {
+	query := `SELECT id, entity_type, entity_id, editor_id, title, content, created_at FROM revisions
+		WHERE entity_type = ? AND entity_id = ? ORDER BY id DESC`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando revisiones: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []domain.Revision{}
+	for rows.Next() {
+		var revision domain.Revision
+		if err := rows.Scan(&revision.ID, &revision.EntityType, &revision.EntityID, &revision.EditorID,
+			&revision.Title, &revision.Content, &revision.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error escaneando revisión: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando revisiones: %w", err)
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,211 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// RoleRepositorySQL implementa la interfaz RoleRepository usando SQL
+type RoleRepositorySQL struct {
+	db *sql.DB
+}
+
+// NewRoleRepositorySQL crea una nueva instancia del repositorio SQL de roles
+func NewRoleRepositorySQL(db *sql.DB) ports.RoleRepository {
+	return &RoleRepositorySQL{db: db}
+}
+
+// Create crea un nuevo rol con sus permisos
+func (r *RoleRepositorySQL) Create(ctx context.Context, role *domain.RoleDefinition) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO roles (name, description, built_in) VALUES (?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, query, role.Name, role.Description, role.BuiltIn); err != nil {
+		return fmt.Errorf("error creando rol: %w", err)
+	}
+
+	if err := insertRolePermissions(ctx, tx, role); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando creación del rol: %w", err)
+	}
+
+	return nil
+}
+
+// FindByName busca un rol por su nombre junto con sus permisos
+func (r *RoleRepositorySQL) FindByName(ctx context.Context, name domain.Role) (*domain.RoleDefinition, error) {
+	query := `SELECT name, description, built_in FROM roles WHERE name = ?`
+	role := &domain.RoleDefinition{}
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, name).Scan(&role.Name, &role.Description, &role.BuiltIn)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("error buscando rol por nombre: %w", err)
+	}
+
+	permissions, err := r.findPermissions(ctx, `WHERE role_name = ?`, name)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Permissions = permissions[role.Name]
+	if role.Permissions == nil {
+		role.Permissions = []domain.Permission{}
+	}
+
+	return role, nil
+}
+
+// List lista todos los roles con sus permisos
+func (r *RoleRepositorySQL) List(ctx context.Context) ([]domain.RoleDefinition, error) {
+	query := `SELECT name, description, built_in FROM roles ORDER BY built_in DESC, name`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error listando roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []domain.RoleDefinition
+	for rows.Next() {
+		var role domain.RoleDefinition
+		if err := rows.Scan(&role.Name, &role.Description, &role.BuiltIn); err != nil {
+			return nil, fmt.Errorf("error escaneando rol: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando roles: %w", err)
+	}
+
+	permissions, err := r.findPermissions(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range roles {
+		roles[i].Permissions = permissions[roles[i].Name]
+		if roles[i].Permissions == nil {
+			roles[i].Permissions = []domain.Permission{}
+		}
+	}
+
+	return roles, nil
+}
+
+// findPermissions lee los permisos agrupados por rol. filter es una condición
+// opcional sobre role_permissions.
+func (r *RoleRepositorySQL) findPermissions(ctx context.Context, filter string, args ...interface{}) (map[domain.Role][]domain.Permission, error) {
+	query := `SELECT role_name, permission FROM role_permissions ` + filter + ` ORDER BY permission`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando permisos: %w", err)
+	}
+	defer rows.Close()
+
+	permissions := make(map[domain.Role][]domain.Permission)
+	for rows.Next() {
+		var role domain.Role
+		var permission domain.Permission
+		if err := rows.Scan(&role, &permission); err != nil {
+			return nil, fmt.Errorf("error escaneando permiso: %w", err)
+		}
+		permissions[role] = append(permissions[role], permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando permisos: %w", err)
+	}
+
+	return permissions, nil
+}
+
+// Update actualiza la descripción y reemplaza los permisos de un rol
+func (r *RoleRepositorySQL) Update(ctx context.Context, role *domain.RoleDefinition) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE roles SET description = ? WHERE name = ?`, role.Description, role.Name)
+	if err != nil {
+		return fmt.Errorf("error actualizando rol: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_name = ?`, role.Name); err != nil {
+		return fmt.Errorf("error eliminando permisos del rol: %w", err)
+	}
+
+	if err := insertRolePermissions(ctx, tx, role); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando actualización del rol: %w", err)
+	}
+
+	return nil
+}
+
+// insertRolePermissions guarda los permisos de un rol dentro de una transacción
+func insertRolePermissions(ctx context.Context, tx querier, role *domain.RoleDefinition) error {
+	for _, permission := range role.Permissions {
+		query := `INSERT INTO role_permissions (role_name, permission) VALUES (?, ?)`
+		if _, err := tx.ExecContext(ctx, query, role.Name, permission); err != nil {
+			return fmt.Errorf("error asignando permiso al rol: %w", err)
+		}
+	}
+	return nil
+}
+
+// Delete elimina un rol y sus permisos
+func (r *RoleRepositorySQL) Delete(ctx context.Context, name domain.Role) error {
+	query := `DELETE FROM roles WHERE name = ?`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, name)
+	if err != nil {
+		return fmt.Errorf("error eliminando rol: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+// InUse indica si algún usuario (incluidos los de la papelera) tiene asignado el rol
+func (r *RoleRepositorySQL) InUse(ctx context.Context, name domain.Role) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE role = ?)`
+	var inUse bool
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, name).Scan(&inUse); err != nil {
+		return false, fmt.Errorf("error verificando uso del rol: %w", err)
+	}
+	return inUse, nil
+}
@@ -0,0 +1,206 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SearchRepositorySQL implementa la interfaz SearchRepository usando índices FULLTEXT
+// de MySQL/MariaDB. SQLite no tiene esos índices y busca con LIKE.
+type SearchRepositorySQL struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewSearchRepositorySQL crea una nueva instancia del repositorio SQL de búsqueda
+func NewSearchRepositorySQL(db *sql.DB, dialect Dialect) ports.SearchRepository {
+	return &SearchRepositorySQL{db: db, dialect: dialect}
+}
+
+// publicBlogCondition limita la búsqueda a blogs visibles públicamente (alias b)
+const publicBlogCondition = `b.deleted_at IS NULL AND (b.status = ? OR (b.status = ? AND b.published_at <= ?))`
+
+// Search busca blogs y comentarios ordenados por relevancia
+func (r *SearchRepositorySQL) Search(ctx context.Context, query domain.SearchQuery) ([]domain.SearchResult, error) {
+	var selects []string
+	var args []interface{}
+
+	if query.Kind == "" || query.Kind == domain.SearchKindBlog {
+		score, scoreArgs, match, matchArgs := r.matchTerms(query.Terms, "b.title", "b.content")
+		sel := `SELECT 'blog' AS kind, b.id AS blog_id, 0 AS comment_id, b.title, b.content, b.author_id, b.created_at,
+			` + score + ` AS score
+			FROM blogs b
+			WHERE ` + match + ` AND ` + publicBlogCondition
+		args = append(args, scoreArgs...)
+		args = append(args, matchArgs...)
+		args = append(args, domain.BlogStatusPublished, domain.BlogStatusScheduled, time.Now())
+
+		filters, filterArgs := searchFilters(query, "b.author_id", "b.created_at")
+		selects = append(selects, sel+filters)
+		args = append(args, filterArgs...)
+	}
+
+	if query.Kind == "" || query.Kind == domain.SearchKindComment {
+		score, scoreArgs, match, matchArgs := r.matchTerms(query.Terms, "", "c.content")
+		sel := `SELECT 'comment' AS kind, c.blog_id, c.id AS comment_id, b.title, c.content, c.user_id AS author_id, c.created_at,
+			` + score + ` AS score
+			FROM comments c
+			JOIN blogs b ON b.id = c.blog_id
+			WHERE ` + match + ` AND c.deleted_at IS NULL AND c.status = ? AND ` + publicBlogCondition
+		args = append(args, scoreArgs...)
+		args = append(args, matchArgs...)
+		args = append(args, domain.CommentStatusApproved, domain.BlogStatusPublished, domain.BlogStatusScheduled, time.Now())
+
+		filters, filterArgs := searchFilters(query, "c.user_id", "c.created_at")
+		selects = append(selects, sel+filters)
+		args = append(args, filterArgs...)
+	}
+
+	sqlQuery := `SELECT kind, blog_id, comment_id, title, content, author_id, created_at, score FROM (` +
+		strings.Join(selects, ` UNION ALL `) +
+		`) AS results ORDER BY score DESC, created_at DESC LIMIT ? OFFSET ?`
+	args = append(args, query.Limit, query.Offset)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando contenido: %w", err)
+	}
+	defer rows.Close()
+
+	var results []domain.SearchResult
+	for rows.Next() {
+		var result domain.SearchResult
+		if err := rows.Scan(&result.Kind, &result.BlogID, &result.CommentID, &result.Title, &result.Content,
+			&result.AuthorID, &result.CreatedAt, &result.Score); err != nil {
+			return nil, fmt.Errorf("error escaneando resultado de búsqueda: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando resultados de búsqueda: %w", err)
+	}
+
+	return results, nil
+}
+
+// matchTerms construye la expresión de relevancia y la condición de búsqueda de
+// los términos sobre el contenido y, si se indica, el título, junto con sus argumentos
+func (r *SearchRepositorySQL) matchTerms(terms []domain.SearchTerm, titleColumn, contentColumn string) (string, []interface{}, string, []interface{}) {
+	if r.dialect == DialectSQLite {
+		return likeScore(terms, titleColumn, contentColumn), likeScoreArgs(terms, titleColumn),
+			likeCondition(terms, titleColumn, contentColumn), likeConditionArgs(terms, titleColumn)
+	}
+
+	columns := contentColumn
+	if titleColumn != "" {
+		columns = titleColumn + ", " + contentColumn
+	}
+	match := `MATCH(` + columns + `) AGAINST (? IN BOOLEAN MODE)`
+	against := booleanModeQuery(terms)
+	return match, []interface{}{against}, match, []interface{}{against}
+}
+
+// searchFilters construye los filtros opcionales de autor y fecha
+func searchFilters(query domain.SearchQuery, authorColumn, dateColumn string) (string, []interface{}) {
+	var filters string
+	var args []interface{}
+
+	if query.AuthorID != 0 {
+		filters += ` AND ` + authorColumn + ` = ?`
+		args = append(args, query.AuthorID)
+	}
+	if query.From != nil {
+		filters += ` AND ` + dateColumn + ` >= ?`
+		args = append(args, *query.From)
+	}
+	if query.To != nil {
+		filters += ` AND ` + dateColumn + ` < ?`
+		args = append(args, *query.To)
+	}
+
+	return filters, args
+}
+
+// booleanModeQuery traduce los términos a la sintaxis de MATCH ... IN BOOLEAN MODE.
+// Todos los términos son obligatorios; las palabras admiten prefijos y las frases
+// deben aparecer exactas.
+func booleanModeQuery(terms []domain.SearchTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term.Phrase {
+			parts = append(parts, `+"`+term.Value+`"`)
+		} else {
+			parts = append(parts, `+`+term.Value+`*`)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// likeCondition exige que cada término aparezca en el título o en el contenido.
+// Los términos solo contienen letras, números y espacios, así que no hay
+// comodines de LIKE que escapar.
+func likeCondition(terms []domain.SearchTerm, titleColumn, contentColumn string) string {
+	parts := make([]string, 0, len(terms))
+	for range terms {
+		if titleColumn != "" {
+			parts = append(parts, `(`+titleColumn+` LIKE ? OR `+contentColumn+` LIKE ?)`)
+		} else {
+			parts = append(parts, contentColumn+` LIKE ?`)
+		}
+	}
+	return `(` + strings.Join(parts, ` AND `) + `)`
+}
+
+// likeConditionArgs retorna los argumentos de likeCondition
+func likeConditionArgs(terms []domain.SearchTerm, titleColumn string) []interface{} {
+	var args []interface{}
+	for _, term := range terms {
+		pattern := "%" + term.Value + "%"
+		if titleColumn != "" {
+			args = append(args, pattern)
+		}
+		args = append(args, pattern)
+	}
+	return args
+}
+
+// likeScore puntúa como el repositorio en memoria: cada aparición de un término
+// en el contenido suma uno y en el título suma dos
+func likeScore(terms []domain.SearchTerm, titleColumn, contentColumn string) string {
+	parts := make([]string, 0, len(terms))
+	for range terms {
+		part := occurrences(contentColumn)
+		if titleColumn != "" {
+			part += ` + 2 * ` + occurrences(titleColumn)
+		}
+		parts = append(parts, part)
+	}
+	return `(` + strings.Join(parts, ` + `) + `)`
+}
+
+// likeScoreArgs retorna los argumentos de likeScore
+func likeScoreArgs(terms []domain.SearchTerm, titleColumn string) []interface{} {
+	var args []interface{}
+	for _, term := range terms {
+		value := strings.ToLower(term.Value)
+		length := len([]rune(value))
+		args = append(args, value, length)
+		if titleColumn != "" {
+			args = append(args, value, length)
+		}
+	}
+	return args
+}
+
+// occurrences cuenta las apariciones de un término (primer argumento) en la
+// columna comparando la longitud antes y después de eliminarlo; el segundo
+// argumento es la longitud del término
+func occurrences(column string) string {
+	return `(LENGTH(` + column + `) - LENGTH(REPLACE(LOWER(` + column + `), ?, ''))) / ?`
+}
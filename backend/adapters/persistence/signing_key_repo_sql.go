@@ -0,0 +1,88 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SigningKeyRepositorySQL implementa la interfaz SigningKeyRepository usando SQL
+type SigningKeyRepositorySQL struct {
+	db *sql.DB
+}
+
+// NewSigningKeyRepositorySQL crea una nueva instancia del repositorio SQL de claves de firma
+func NewSigningKeyRepositorySQL(db *sql.DB) ports.SigningKeyRepository {
+	return &SigningKeyRepositorySQL{db: db}
+}
+
+// Create guarda una nueva clave de firma
+func (r *SigningKeyRepositorySQL) Create(ctx context.Context, key *domain.SigningKey) error {
+	key.CreatedAt = time.Now()
+
+	query := `INSERT INTO signing_keys (id, algorithm, private_key, created_at) VALUES (?, ?, ?, ?)`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, key.ID, key.Algorithm, key.PrivateKey, key.CreatedAt); err != nil {
+		return fmt.Errorf("error creando clave de firma: %w", err)
+	}
+	return nil
+}
+
+// ListUsable lista las claves sin caducar, de la más nueva a la más antigua
+func (r *SigningKeyRepositorySQL) ListUsable(ctx context.Context, now time.Time) ([]domain.SigningKey, error) {
+	query := `SELECT id, algorithm, private_key, created_at, retired_at, expires_at FROM signing_keys WHERE expires_at IS NULL OR expires_at > ? ORDER BY created_at DESC, id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("error listando claves de firma: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []domain.SigningKey
+	for rows.Next() {
+		var key domain.SigningKey
+		var retiredAt, expiresAt sql.NullTime
+
+		if err := rows.Scan(&key.ID, &key.Algorithm, &key.PrivateKey, &key.CreatedAt, &retiredAt, &expiresAt); err != nil {
+			return nil, fmt.Errorf("error escaneando clave de firma: %w", err)
+		}
+
+		if retiredAt.Valid {
+			key.RetiredAt = &retiredAt.Time
+		}
+		if expiresAt.Valid {
+			key.ExpiresAt = &expiresAt.Time
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando claves de firma: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Retire retira una clave de firma si todavía estaba activa
+func (r *SigningKeyRepositorySQL) Retire(ctx context.Context, id string, retiredAt, expiresAt time.Time) error {
+	query := `UPDATE signing_keys SET retired_at = ?, expires_at = ? WHERE id = ? AND retired_at IS NULL`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, retiredAt, expiresAt, id); err != nil {
+		return fmt.Errorf("error retirando clave de firma: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired elimina las claves que ya no verifican ningún token
+func (r *SigningKeyRepositorySQL) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM signing_keys WHERE expires_at <= ?`, now)
+	if err != nil {
+		return 0, fmt.Errorf("error eliminando claves de firma caducadas: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+	return deleted, nil
+}
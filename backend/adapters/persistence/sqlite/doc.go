@@ -0,0 +1,9 @@
+// Package sqlite abre bases de datos SQLite para los repositorios SQL de
+// persistence, de modo que el servidor y las pruebas puedan funcionar con un
+// único archivo y sin servicios externos.
+//
+// El driver (github.com/mattn/go-sqlite3) requiere cgo y un compilador de C.
+package sqlite
+
+// MemoryPath es la ruta de una base de datos temporal en memoria
+const MemoryPath = ":memory:"
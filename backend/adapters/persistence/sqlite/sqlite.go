@@ -0,0 +1,84 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Open abre (o crea) la base de datos SQLite de path con las claves foráneas
+// activadas. Las escrituras esperan hasta 5 segundos a que se libere el
+// bloqueo del archivo y las transacciones lo toman al empezar para no fallar
+// al pasar de lectura a escritura.
+func Open(path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("la ruta de la base de datos SQLite está vacía")
+	}
+
+	options := "_foreign_keys=on&_busy_timeout=5000&_txlock=immediate"
+	if path != MemoryPath {
+		// WAL permite leer mientras otra conexión escribe
+		options += "&_journal_mode=WAL"
+	}
+	dsn := "file:" + path + "?" + options
+	if strings.Contains(path, "?") {
+		dsn = "file:" + path + "&" + options
+	}
+
+	db := sql.OpenDB(&connector{dsn: dsn, driver: &sqlite3.SQLiteDriver{}})
+
+	// Cada conexión a :memory: abre una base de datos distinta. Con una sola
+	// conexión, las consultas hechas dentro de una transacción sin su contexto
+	// esperarían para siempre a que esta termine.
+	if path == MemoryPath {
+		db.SetMaxOpenConns(1)
+	}
+
+	return db, nil
+}
+
+// connector crea las conexiones con el driver de SQLite
+type connector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+}
+
+// Connect abre una conexión nueva
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &utcConn{SQLiteConn: conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+// Driver retorna el driver de SQLite
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// utcConn guarda las fechas en UTC. SQLite compara las fechas como texto, así
+// que todas deben tener la misma zona horaria, igual que CURRENT_TIMESTAMP.
+type utcConn struct {
+	*sqlite3.SQLiteConn
+}
+
+// CheckNamedValue convierte los argumentos como database/sql y pasa las fechas a UTC
+func (c *utcConn) CheckNamedValue(nv *driver.NamedValue) error {
+	value, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	if err != nil {
+		return err
+	}
+	if t, ok := value.(time.Time); ok {
+		value = t.UTC()
+	}
+	nv.Value = value
+	return nil
+}
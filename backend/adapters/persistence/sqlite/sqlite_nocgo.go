@@ -0,0 +1,13 @@
+//go:build !cgo
+
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// Open falla siempre: el driver de SQLite necesita cgo
+func Open(path string) (*sql.DB, error) {
+	return nil, errors.New("SQLite no está disponible: el servidor se compiló sin cgo (CGO_ENABLED=0)")
+}
@@ -0,0 +1,204 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"blog-backend/adapters/persistence"
+	"blog-backend/adapters/persistence/migrations"
+	"blog-backend/adapters/persistence/repotest"
+	"blog-backend/internal/domain"
+	"context"
+	"database/sql"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openMigrated abre una base de datos SQLite nueva con todas las migraciones aplicadas
+func openMigrated(t *testing.T) (*sql.DB, *persistence.Migrator) {
+	t.Helper()
+
+	db, err := Open(filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatalf("abriendo la base de datos: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := persistence.NewMigrator(db, persistence.DialectSQLite, migrations.SQLiteFS, time.Minute)
+	if err != nil {
+		t.Fatalf("cargando migraciones: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("aplicando migraciones: %v", err)
+	}
+
+	return db, migrator
+}
+
+func TestRepositoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		db, _ := openMigrated(t)
+		return repotest.Repositories{
+			Users:           persistence.NewUserRepositorySQL(db),
+			Blogs:           persistence.NewBlogRepositorySQL(db, persistence.DialectSQLite),
+			Comments:        persistence.NewCommentRepositorySQL(db, persistence.DialectSQLite),
+			Tags:            persistence.NewTagRepositorySQL(db, persistence.DialectSQLite),
+			Tx:              persistence.NewTxManagerSQL(db),
+			BackdateComment: repotest.BackdateCommentSQL(db),
+		}
+	})
+}
+
+func TestMigrationsMatchMySQL(t *testing.T) {
+	names := func(fsys fs.FS) []string {
+		matches, err := fs.Glob(fsys, "*.sql")
+		if err != nil {
+			t.Fatalf("listando migraciones: %v", err)
+		}
+		return matches
+	}
+
+	mysql, sqlite := names(migrations.FS), names(migrations.SQLiteFS)
+	if len(sqlite) == 0 || !reflect.DeepEqual(mysql, sqlite) {
+		t.Errorf("migraciones de SQLite = %v, se esperaban %v", sqlite, mysql)
+	}
+}
+
+func TestMigrationsDownAndUp(t *testing.T) {
+	_, migrator := openMigrated(t)
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+
+	reverted, err := migrator.Down(len(statuses))
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if len(reverted) != len(statuses) {
+		t.Errorf("revertidas %d migraciones, se esperaban %d", len(reverted), len(statuses))
+	}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if len(applied) != len(statuses) {
+		t.Errorf("aplicadas %d migraciones, se esperaban %d", len(applied), len(statuses))
+	}
+}
+
+func TestMigrationReservesDeletedUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     string
+	}{
+		{name: "cuenta con contraseña", password: "hash", want: "usuario-renombrado-1"},
+		{name: "usuario de reasignación", password: "", want: domain.DeletedUsername},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, migrator := openMigrated(t)
+			// Revertir hasta antes de la migración que reserva el nombre
+			statuses, err := migrator.Status()
+			if err != nil {
+				t.Fatalf("Status: %v", err)
+			}
+			steps := 0
+			for _, status := range statuses {
+				if status.Version >= 18 {
+					steps++
+				}
+			}
+			if _, err := migrator.Down(steps); err != nil {
+				t.Fatalf("Down: %v", err)
+			}
+			if _, err := db.Exec(`INSERT INTO users (id, username, password, role) VALUES (1, 'Usuario-Eliminado', ?, ?)`,
+				tt.password, domain.RoleUser); err != nil {
+				t.Fatalf("insertando usuario: %v", err)
+			}
+			if _, err := migrator.Up(); err != nil {
+				t.Fatalf("Up: %v", err)
+			}
+
+			var username string
+			if err := db.QueryRow(`SELECT username FROM users WHERE id = 1`).Scan(&username); err != nil {
+				t.Fatalf("leyendo usuario: %v", err)
+			}
+			if !strings.EqualFold(username, tt.want) {
+				t.Errorf("username = %q, se esperaba %q", username, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	db, _ := openMigrated(t)
+	users := persistence.NewUserRepositorySQL(db)
+	blogs := persistence.NewBlogRepositorySQL(db, persistence.DialectSQLite)
+	comments := persistence.NewCommentRepositorySQL(db, persistence.DialectSQLite)
+	search := persistence.NewSearchRepositorySQL(db, persistence.DialectSQLite)
+
+	author := &domain.User{Username: "ana", Password: "hash", Role: domain.RoleUser}
+	if err := users.Create(ctx, author); err != nil {
+		t.Fatalf("creando usuario: %v", err)
+	}
+
+	publishedAt := time.Now().Add(-time.Hour)
+	newBlog := func(title, content string, status domain.BlogStatus) *domain.Blog {
+		blog := &domain.Blog{Title: title, Content: content, AuthorID: author.ID, Status: status, PublishedAt: &publishedAt}
+		if err := blogs.Create(ctx, blog); err != nil {
+			t.Fatalf("creando blog: %v", err)
+		}
+		return blog
+	}
+	inTitle := newBlog("Recetas de Cocina", "pan casero de cocina", domain.BlogStatusPublished)
+	inContent := newBlog("Viajes", "cocina local y cocina de mercado", domain.BlogStatusPublished)
+	newBlog("Cocina en borrador", "cocina", domain.BlogStatusDraft)
+	comment := &domain.Comment{BlogID: inContent.ID, UserID: author.ID, Content: "Gran cocina casera", Status: domain.CommentStatusApproved}
+	if err := comments.Create(ctx, comment); err != nil {
+		t.Fatalf("creando comentario: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query domain.SearchQuery
+		want  []int64
+	}{
+		{name: "relevancia", query: domain.SearchQuery{Terms: domain.ParseSearchTerms("COCINA"), Kind: domain.SearchKindBlog},
+			want: []int64{inTitle.ID, inContent.ID}},
+		{name: "todos los términos", query: domain.SearchQuery{Terms: domain.ParseSearchTerms("cocina pan")},
+			want: []int64{inTitle.ID}},
+		{name: "frase", query: domain.SearchQuery{Terms: domain.ParseSearchTerms(`"cocina casera"`), Kind: domain.SearchKindComment},
+			want: []int64{comment.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Limit = 10
+			results, err := search.Search(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+
+			var got []int64
+			for _, result := range results {
+				if result.Kind == domain.SearchKindComment {
+					got = append(got, result.CommentID)
+				} else {
+					got = append(got, result.BlogID)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resultados = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
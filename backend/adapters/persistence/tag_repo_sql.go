@@ -0,0 +1,225 @@
+package persistence
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TagRepositorySQL implementa la interfaz TagRepository usando SQL
+type TagRepositorySQL struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewTagRepositorySQL crea una nueva instancia del repositorio SQL de etiquetas
+func NewTagRepositorySQL(db *sql.DB, dialect Dialect) ports.TagRepository {
+	return &TagRepositorySQL{db: db, dialect: dialect}
+}
+
+// FindByID busca una etiqueta por su ID
+func (r *TagRepositorySQL) FindByID(ctx context.Context, id int64) (*domain.Tag, error) {
+	query := `SELECT id, name, slug FROM tags WHERE id = ?`
+	tag := &domain.Tag{}
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&tag.ID, &tag.Name, &tag.Slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrTagNotFound
+		}
+		return nil, fmt.Errorf("error buscando etiqueta por ID: %w", err)
+	}
+
+	return tag, nil
+}
+
+// FindBySlug busca una etiqueta por su slug
+func (r *TagRepositorySQL) FindBySlug(ctx context.Context, slug string) (*domain.Tag, error) {
+	query := `SELECT id, name, slug FROM tags WHERE slug = ?`
+	tag := &domain.Tag{}
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, slug).Scan(&tag.ID, &tag.Name, &tag.Slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrTagNotFound
+		}
+		return nil, fmt.Errorf("error buscando etiqueta por slug: %w", err)
+	}
+
+	return tag, nil
+}
+
+// List lista todas las etiquetas con el número de blogs publicados que las usan
+func (r *TagRepositorySQL) List(ctx context.Context) ([]domain.Tag, error) {
+	query := `SELECT t.id, t.name, t.slug,
+		(SELECT COUNT(*) FROM blog_tags bt JOIN blogs b ON b.id = bt.blog_id
+			WHERE bt.tag_id = t.id AND ` + publicBlogCondition + `) AS post_count
+		FROM tags t ORDER BY post_count DESC, t.name`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, domain.BlogStatusPublished, domain.BlogStatusScheduled, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("error listando etiquetas: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []domain.Tag{}
+	for rows.Next() {
+		var tag domain.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.PostCount); err != nil {
+			return nil, fmt.Errorf("error escaneando etiqueta: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando etiquetas: %w", err)
+	}
+
+	return tags, nil
+}
+
+// FindByBlogIDs obtiene las etiquetas de varios blogs, agrupadas por ID de blog
+func (r *TagRepositorySQL) FindByBlogIDs(ctx context.Context, blogIDs []int64) (map[int64][]domain.Tag, error) {
+	tags := make(map[int64][]domain.Tag, len(blogIDs))
+	if len(blogIDs) == 0 {
+		return tags, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(blogIDs)), ",")
+	args := make([]interface{}, len(blogIDs))
+	for i, id := range blogIDs {
+		args[i] = id
+	}
+
+	query := `SELECT bt.blog_id, t.id, t.name, t.slug FROM blog_tags bt
+		JOIN tags t ON t.id = bt.tag_id
+		WHERE bt.blog_id IN (` + placeholders + `) ORDER BY t.name`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error buscando etiquetas de blogs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var blogID int64
+		var tag domain.Tag
+		if err := rows.Scan(&blogID, &tag.ID, &tag.Name, &tag.Slug); err != nil {
+			return nil, fmt.Errorf("error escaneando etiqueta: %w", err)
+		}
+		tags[blogID] = append(tags[blogID], tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando etiquetas: %w", err)
+	}
+
+	return tags, nil
+}
+
+// SetBlogTags reemplaza las etiquetas de un blog. Las etiquetas que no existen
+// se crean; las existentes se reutilizan por slug conservando su nombre.
+func (r *TagRepositorySQL) SetBlogTags(ctx context.Context, blogID int64, tags []domain.Tag) ([]domain.Tag, error) {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM blog_tags WHERE blog_id = ?`, blogID); err != nil {
+		return nil, fmt.Errorf("error eliminando etiquetas del blog: %w", err)
+	}
+
+	saved := make([]domain.Tag, 0, len(tags))
+	for _, tag := range tags {
+		_, err := tx.ExecContext(ctx, r.insertTagQuery(), tag.Name, tag.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("error guardando etiqueta: %w", err)
+		}
+
+		if err := tx.QueryRowContext(ctx, `SELECT id, name, slug FROM tags WHERE slug = ?`, tag.Slug).
+			Scan(&tag.ID, &tag.Name, &tag.Slug); err != nil {
+			return nil, fmt.Errorf("error obteniendo etiqueta: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO blog_tags (blog_id, tag_id) VALUES (?, ?)`, blogID, tag.ID); err != nil {
+			return nil, fmt.Errorf("error asignando etiqueta al blog: %w", err)
+		}
+		saved = append(saved, tag)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando etiquetas del blog: %w", err)
+	}
+
+	return saved, nil
+}
+
+// insertTagQuery retorna la sentencia que crea una etiqueta si su slug no está registrado
+func (r *TagRepositorySQL) insertTagQuery() string {
+	if r.dialect == DialectSQLite {
+		return `INSERT INTO tags (name, slug) VALUES (?, ?) ON CONFLICT (slug) DO NOTHING`
+	}
+	// LAST_INSERT_ID(id) devuelve el ID de la etiqueta existente si el slug ya está registrado
+	return `INSERT INTO tags (name, slug) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)`
+}
+
+// Rename cambia el nombre y el slug de una etiqueta
+func (r *TagRepositorySQL) Rename(ctx context.Context, tag *domain.Tag) error {
+	query := `UPDATE tags SET name = ?, slug = ? WHERE id = ?`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, tag.Name, tag.Slug, tag.ID)
+	if err != nil {
+		return fmt.Errorf("error renombrando etiqueta: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	return nil
+}
+
+// Merge reasigna los blogs de la etiqueta origen a la de destino y elimina la de origen
+func (r *TagRepositorySQL) Merge(ctx context.Context, sourceID, targetID int64) error {
+	tx, err := beginTx(ctx, r.db)
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Los blogs que ya tienen ambas etiquetas conservan una sola asignación
+	_, err = tx.ExecContext(ctx, r.dialect.insertIgnore()+` INTO blog_tags (blog_id, tag_id)
+		SELECT blog_id, ? FROM blog_tags WHERE tag_id = ?`, targetID, sourceID)
+	if err != nil {
+		return fmt.Errorf("error reasignando etiquetas: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, sourceID)
+	if err != nil {
+		return fmt.Errorf("error eliminando etiqueta: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrTagNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando fusión de etiquetas: %w", err)
+	}
+
+	return nil
+}
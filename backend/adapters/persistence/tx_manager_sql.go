@@ -0,0 +1,98 @@
+package persistence
+
+import (
+	"blog-backend/internal/ports"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// txKey es la clave del contexto que guarda la transacción en curso
+type txKey struct{}
+
+// querier agrupa los métodos de *sql.DB y *sql.Tx que usan los repositorios
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn retorna la transacción de ctx o, fuera de una transacción, db
+func conn(ctx context.Context, db *sql.DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// repoTx es la transacción de una operación de varias sentencias de un
+// repositorio. Si la operación se ejecuta dentro de la transacción de un
+// TxManager la reutiliza: Commit y Rollback no hacen nada y es el TxManager
+// quien la confirma o la revierte.
+type repoTx struct {
+	*sql.Tx
+	owned bool
+}
+
+// beginTx inicia una transacción o reutiliza la de ctx
+func beginTx(ctx context.Context, db *sql.DB) (*repoTx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return &repoTx{Tx: tx}, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &repoTx{Tx: tx, owned: true}, nil
+}
+
+// Commit confirma la transacción si la inició el repositorio
+func (t *repoTx) Commit() error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+// Rollback revierte la transacción si la inició el repositorio
+func (t *repoTx) Rollback() error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
+
+// TxManagerSQL implementa la interfaz TxManager con transacciones de la base de datos
+type TxManagerSQL struct {
+	db *sql.DB
+}
+
+// NewTxManagerSQL crea una nueva instancia del gestor de transacciones SQL
+func NewTxManagerSQL(db *sql.DB) ports.TxManager {
+	return &TxManagerSQL{db: db}
+}
+
+// WithinTx ejecuta fn en una transacción que viaja en el contexto. Se confirma
+// si fn retorna nil y se revierte si retorna un error o entra en pánico.
+func (m *TxManagerSQL) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando transacción: %w", err)
+	}
+
+	return nil
+}
@@ -3,8 +3,10 @@ package persistence
 import (
 	"blog-backend/internal/domain"
 	"blog-backend/internal/ports"
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 // UserRepositorySQL implementa la interfaz UserRepository usando SQL
@@ -17,10 +19,76 @@ func NewUserRepositorySQL(db *sql.DB) ports.UserRepository {
 	return &UserRepositorySQL{db: db}
 }
 
-// Create crea un nuevo usuario en la base de datos
-func (r *UserRepositorySQL) Create(user *domain.User) error {
-	query := `INSERT INTO users (username, password, role) VALUES (?, ?, ?)`
-	result, err := r.db.Exec(query, user.Username, user.Password, user.Role)
+// userColumns son las columnas que se leen de un usuario
+const userColumns = `id, username, email, password, role, created_at, deleted_at, failed_logins, last_failed_login_at, locked_until,
+	email_verified_at, email_verification_sent_at, totp_secret, totp_enabled_at, totp_last_step`
+
+// scanUser lee un usuario con las columnas de userColumns
+func scanUser(scanner rowScanner, user *domain.User) error {
+	var email, totpSecret sql.NullString
+	var deletedAt, lastFailedLoginAt, lockedUntil, emailVerifiedAt, verificationSentAt, totpEnabledAt sql.NullTime
+	if err := scanner.Scan(&user.ID, &user.Username, &email, &user.Password, &user.Role, &user.CreatedAt, &deletedAt,
+		&user.FailedLogins, &lastFailedLoginAt, &lockedUntil, &emailVerifiedAt, &verificationSentAt,
+		&totpSecret, &totpEnabledAt, &user.TOTPLastStep); err != nil {
+		return err
+	}
+
+	user.Email = email.String
+	if deletedAt.Valid {
+		user.DeletedAt = &deletedAt.Time
+	}
+	if lastFailedLoginAt.Valid {
+		user.LastFailedLoginAt = &lastFailedLoginAt.Time
+	}
+	if lockedUntil.Valid {
+		user.LockedUntil = &lockedUntil.Time
+	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+	if verificationSentAt.Valid {
+		user.VerificationSentAt = &verificationSentAt.Time
+	}
+	user.TOTPSecret = totpSecret.String
+	if totpEnabledAt.Valid {
+		user.TOTPEnabledAt = &totpEnabledAt.Time
+	}
+	return nil
+}
+
+// nullableEmail guarda como NULL el correo vacío para que no choque con el índice único
+func nullableEmail(email string) sql.NullString {
+	return sql.NullString{String: email, Valid: email != ""}
+}
+
+// Create crea un nuevo usuario en la base de datos. El nombre reservado
+// domain.DeletedUsername se rechaza con domain.ErrUserAlreadyExists.
+func (r *UserRepositorySQL) Create(ctx context.Context, user *domain.User) error {
+	if domain.IsReservedUsername(user.Username) {
+		return domain.ErrUserAlreadyExists
+	}
+	return r.insert(ctx, user)
+}
+
+// CreateDeletedUser crea el usuario domain.DeletedUsername que recibe el
+// contenido de los usuarios eliminados. No tiene contraseña, así que no puede
+// iniciar sesión.
+func (r *UserRepositorySQL) CreateDeletedUser(ctx context.Context) (*domain.User, error) {
+	user := &domain.User{Username: domain.DeletedUsername, Role: domain.RoleUser}
+	if err := r.insert(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// insert guarda un usuario nuevo y le asigna su ID
+func (r *UserRepositorySQL) insert(ctx context.Context, user *domain.User) error {
+	user.CreatedAt = time.Now()
+
+	query := `INSERT INTO users (username, email, password, role, created_at, email_verified_at, email_verification_sent_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, user.Username, nullableEmail(user.Email), user.Password, user.Role, user.CreatedAt,
+		user.EmailVerifiedAt, user.VerificationSentAt)
 	if err != nil {
 		return fmt.Errorf("error creando usuario: %w", err)
 	}
@@ -34,12 +102,13 @@ func (r *UserRepositorySQL) Create(user *domain.User) error {
 	return nil
 }
 
-// FindByUsername busca un usuario por su nombre de usuario
-func (r *UserRepositorySQL) FindByUsername(username string) (*domain.User, error) {
-	query := `SELECT id, username, password, role FROM users WHERE username = ?`
+// FindByUsername busca un usuario por su nombre de usuario. También devuelve
+// los usuarios de la papelera (con DeletedAt) porque su nombre sigue reservado.
+func (r *UserRepositorySQL) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = ?`
 	user := &domain.User{}
 	
-	err := r.db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Password, &user.Role)
+	err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, username), user)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrUserNotFound
@@ -50,12 +119,29 @@ func (r *UserRepositorySQL) FindByUsername(username string) (*domain.User, error
 	return user, nil
 }
 
+// FindByEmail busca un usuario por su correo electrónico. Como FindByUsername,
+// también devuelve los usuarios de la papelera.
+func (r *UserRepositorySQL) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
+	user := &domain.User{}
+
+	err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, email), user)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error buscando usuario por email: %w", err)
+	}
+
+	return user, nil
+}
+
 // FindByID busca un usuario por su ID
-func (r *UserRepositorySQL) FindByID(id int64) (*domain.User, error) {
-	query := `SELECT id, username, password, role FROM users WHERE id = ?`
+func (r *UserRepositorySQL) FindByID(ctx context.Context, id int64) (*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ? AND deleted_at IS NULL`
 	user := &domain.User{}
 	
-	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Username, &user.Password, &user.Role)
+	err := scanUser(conn(ctx, r.db).QueryRowContext(ctx, query, id), user)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, domain.ErrUserNotFound
@@ -67,9 +153,9 @@ func (r *UserRepositorySQL) FindByID(id int64) (*domain.User, error) {
 }
 
 // List lista todos los usuarios
-func (r *UserRepositorySQL) List() ([]domain.User, error) {
-	query := `SELECT id, username, password, role FROM users ORDER BY id`
-	rows, err := r.db.Query(query)
+func (r *UserRepositorySQL) List(ctx context.Context) ([]domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE deleted_at IS NULL ORDER BY id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error listando usuarios: %w", err)
 	}
@@ -78,7 +164,7 @@ func (r *UserRepositorySQL) List() ([]domain.User, error) {
 	var users []domain.User
 	for rows.Next() {
 		var user domain.User
-		if err := rows.Scan(&user.ID, &user.Username, &user.Password, &user.Role); err != nil {
+		if err := scanUser(rows, &user); err != nil {
 			return nil, fmt.Errorf("error escaneando usuario: %w", err)
 		}
 		users = append(users, user)
@@ -91,10 +177,22 @@ func (r *UserRepositorySQL) List() ([]domain.User, error) {
 	return users, nil
 }
 
-// Update actualiza un usuario existente
-func (r *UserRepositorySQL) Update(user *domain.User) error {
-	query := `UPDATE users SET username = ?, password = ?, role = ? WHERE id = ?`
-	result, err := r.db.Exec(query, user.Username, user.Password, user.Role, user.ID)
+// ExistsByRole indica si existe al menos un usuario con el rol indicado
+func (r *UserRepositorySQL) ExistsByRole(ctx context.Context, role domain.Role) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE role = ? AND deleted_at IS NULL)`
+	var exists bool
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, role).Scan(&exists); err != nil {
+		return false, fmt.Errorf("error verificando usuarios por rol: %w", err)
+	}
+	return exists, nil
+}
+
+// Update actualiza un usuario existente, incluido el estado de verificación de su correo
+func (r *UserRepositorySQL) Update(ctx context.Context, user *domain.User) error {
+	query := `UPDATE users SET username = ?, email = ?, password = ?, role = ?, email_verified_at = ?, email_verification_sent_at = ?
+		WHERE id = ? AND deleted_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, user.Username, nullableEmail(user.Email), user.Password, user.Role,
+		user.EmailVerifiedAt, user.VerificationSentAt, user.ID)
 	if err != nil {
 		return fmt.Errorf("error actualizando usuario: %w", err)
 	}
@@ -111,10 +209,128 @@ func (r *UserRepositorySQL) Update(user *domain.User) error {
 	return nil
 }
 
-// Delete elimina un usuario por su ID
-func (r *UserRepositorySQL) Delete(id int64) error {
-	query := `DELETE FROM users WHERE id = ?`
-	result, err := r.db.Exec(query, id)
+// UpdateLoginState guarda los inicios de sesión fallidos y el bloqueo de un usuario
+func (r *UserRepositorySQL) UpdateLoginState(ctx context.Context, user *domain.User) error {
+	query := `UPDATE users SET failed_logins = ?, last_failed_login_at = ?, locked_until = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, user.FailedLogins, user.LastFailedLoginAt, user.LockedUntil, user.ID)
+	if err != nil {
+		return fmt.Errorf("error actualizando estado de inicio de sesión: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// RegisterLoginFailure cuenta de forma atómica un inicio de sesión fallido y
+// retorna el usuario con el estado resultante. El contador se incrementa en la
+// propia sentencia para no perder fallos concurrentes; las expresiones solo
+// leen valores previos porque MySQL aplica las asignaciones en orden y SQLite no.
+func (r *UserRepositorySQL) RegisterLoginFailure(ctx context.Context, userID int64, now time.Time, policy domain.LockoutPolicy) (*domain.User, error) {
+	failures := `(CASE WHEN last_failed_login_at IS NULL OR last_failed_login_at < ? THEN 1 ELSE failed_logins + 1 END)`
+	query := `UPDATE users SET
+		locked_until = CASE WHEN ? > 0 AND ` + failures + ` >= ? THEN ? ELSE locked_until END,
+		failed_logins = CASE WHEN ? > 0 AND ` + failures + ` >= ? THEN 0 ELSE ` + failures + ` END,
+		last_failed_login_at = ?
+		WHERE id = ? AND deleted_at IS NULL`
+
+	windowStart := now.Add(-policy.FailureWindow)
+	maxFailures := policy.MaxAccountFailures
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		maxFailures, windowStart, maxFailures, now.Add(policy.LockoutDuration),
+		maxFailures, windowStart, maxFailures, windowStart,
+		now, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error registrando inicio de sesión fallido: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return r.FindByID(ctx, userID)
+}
+
+// UpdateEmailVerification guarda el estado de verificación del correo de un usuario
+func (r *UserRepositorySQL) UpdateEmailVerification(ctx context.Context, user *domain.User) error {
+	query := `UPDATE users SET email_verified_at = ?, email_verification_sent_at = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, user.EmailVerifiedAt, user.VerificationSentAt, user.ID)
+	if err != nil {
+		return fmt.Errorf("error actualizando verificación de correo: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdateTwoFactor guarda el secreto TOTP, su activación y el último código aceptado de un usuario
+func (r *UserRepositorySQL) UpdateTwoFactor(ctx context.Context, user *domain.User) error {
+	query := `UPDATE users SET totp_secret = ?, totp_enabled_at = ?, totp_last_step = ? WHERE id = ? AND deleted_at IS NULL`
+	totpSecret := sql.NullString{String: user.TOTPSecret, Valid: user.TOTPSecret != ""}
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, totpSecret, user.TOTPEnabledAt, user.TOTPLastStep, user.ID)
+	if err != nil {
+		return fmt.Errorf("error actualizando autenticación en dos pasos: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// AdvanceTOTPStep registra el intervalo del último código TOTP aceptado. Si ya
+// se aceptó un código de ese intervalo o de uno posterior retorna
+// ErrInvalidTOTPCode, lo que impide usar dos veces el mismo código en
+// peticiones concurrentes.
+func (r *UserRepositorySQL) AdvanceTOTPStep(ctx context.Context, userID, step int64) error {
+	query := `UPDATE users SET totp_last_step = ? WHERE id = ? AND totp_last_step < ?`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return fmt.Errorf("error actualizando código TOTP usado: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrInvalidTOTPCode
+	}
+
+	return nil
+}
+
+// Delete mueve un usuario a la papelera
+func (r *UserRepositorySQL) Delete(ctx context.Context, id int64) error {
+	query := `UPDATE users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("error eliminando usuario: %w", err)
 	}
@@ -130,3 +346,87 @@ func (r *UserRepositorySQL) Delete(id int64) error {
 
 	return nil
 }
+
+// ListDeleted lista una página de usuarios de la papelera (paginación por clave)
+func (r *UserRepositorySQL) ListDeleted(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.User], error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE deleted_at IS NOT NULL`
+	var args []interface{}
+
+	orderBy := `id DESC`
+	if page.Sort == domain.SortOldest {
+		orderBy = `id ASC`
+		if page.After != nil {
+			query += ` AND id > ?`
+			args = append(args, page.After.ID)
+		}
+	} else if page.After != nil {
+		query += ` AND id < ?`
+		args = append(args, page.After.ID)
+	}
+
+	query += ` ORDER BY ` + orderBy + ` LIMIT ?`
+	args = append(args, page.Limit+1)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listando usuarios eliminados: %w", err)
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var user domain.User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, fmt.Errorf("error escaneando usuario: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterando usuarios: %w", err)
+	}
+
+	return domain.NewPage(users, page, userCursor), nil
+}
+
+// userCursor obtiene la posición de un usuario para la siguiente página
+func userCursor(user domain.User) domain.Cursor {
+	return domain.Cursor{ID: user.ID}
+}
+
+// Restore saca un usuario de la papelera
+func (r *UserRepositorySQL) Restore(ctx context.Context, id int64) error {
+	query := `UPDATE users SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("error restaurando usuario: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeleted elimina definitivamente los usuarios que están en la papelera desde
+// antes de before. Sus blogs y comentarios se eliminan en cascada.
+func (r *UserRepositorySQL) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM users WHERE deleted_at < ?`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("error purgando usuarios eliminados: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error verificando filas afectadas: %w", err)
+	}
+
+	return rowsAffected, nil
+}
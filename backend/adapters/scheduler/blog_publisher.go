@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// ScheduledPublisher publica los blogs programados cuya fecha ya llegó
+type ScheduledPublisher interface {
+	PublishScheduled(ctx context.Context) (int64, error)
+}
+
+// BlogPublisher ejecuta periódicamente la publicación de blogs programados
+type BlogPublisher struct {
+	publisher ScheduledPublisher
+	interval  time.Duration
+}
+
+// NewBlogPublisher crea un planificador que revisa los blogs programados cada interval
+func NewBlogPublisher(publisher ScheduledPublisher, interval time.Duration) *BlogPublisher {
+	return &BlogPublisher{
+		publisher: publisher,
+		interval:  interval,
+	}
+}
+
+// Start lanza el planificador en segundo plano hasta que se cancele ctx. wg
+// permite esperar a que termine la ejecución en curso.
+func (p *BlogPublisher) Start(ctx context.Context, wg *sync.WaitGroup) {
+	start(ctx, wg, p.interval, p.run)
+}
+
+// run publica los blogs pendientes y registra el resultado
+func (p *BlogPublisher) run(ctx context.Context) {
+	published, err := p.publisher.PublishScheduled(ctx)
+	if err != nil {
+		log.Printf("Error publicando blogs programados: %v", err)
+		return
+	}
+	if published > 0 {
+		log.Printf("Blogs programados publicados: %d", published)
+	}
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// KeyRotator rota las claves de firma de los tokens cuando caducan
+type KeyRotator interface {
+	RotateKeys(ctx context.Context) (bool, error)
+}
+
+// SigningKeyRotator ejecuta periódicamente la rotación de las claves de firma
+type SigningKeyRotator struct {
+	rotator  KeyRotator
+	interval time.Duration
+}
+
+// NewSigningKeyRotator crea un planificador que comprueba las claves de firma cada interval
+func NewSigningKeyRotator(rotator KeyRotator, interval time.Duration) *SigningKeyRotator {
+	return &SigningKeyRotator{
+		rotator:  rotator,
+		interval: interval,
+	}
+}
+
+// Start lanza el planificador en segundo plano hasta que se cancele ctx. wg
+// permite esperar a que termine la ejecución en curso.
+func (p *SigningKeyRotator) Start(ctx context.Context, wg *sync.WaitGroup) {
+	start(ctx, wg, p.interval, p.run)
+}
+
+// run rota las claves si corresponde y registra el resultado
+func (p *SigningKeyRotator) run(ctx context.Context) {
+	rotated, err := p.rotator.RotateKeys(ctx)
+	if err != nil {
+		log.Printf("Error rotando las claves de firma: %v", err)
+		return
+	}
+	if rotated {
+		log.Println("Clave de firma de los tokens rotada")
+	}
+}
@@ -0,0 +1,35 @@
+// Package scheduler contiene las tareas periódicas que se ejecutan en segundo
+// plano mientras el servidor está en marcha.
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// start lanza runEvery en segundo plano y lo registra en wg
+func start(ctx context.Context, wg *sync.WaitGroup, interval time.Duration, fn func(ctx context.Context)) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runEvery(ctx, interval, fn)
+	}()
+}
+
+// runEvery ejecuta fn inmediatamente y luego cada interval hasta que se cancele
+// ctx, que se pasa a fn para interrumpir la ejecución en curso
+func runEvery(ctx context.Context, interval time.Duration, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	fn(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn(ctx)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// ExpiredPurger elimina definitivamente los elementos caducados de la papelera
+type ExpiredPurger interface {
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// TrashPurger ejecuta periódicamente el vaciado de la papelera
+type TrashPurger struct {
+	purger   ExpiredPurger
+	interval time.Duration
+}
+
+// NewTrashPurger crea un planificador que vacía la papelera cada interval
+func NewTrashPurger(purger ExpiredPurger, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		purger:   purger,
+		interval: interval,
+	}
+}
+
+// Start lanza el planificador en segundo plano hasta que se cancele ctx. wg
+// permite esperar a que termine la ejecución en curso.
+func (p *TrashPurger) Start(ctx context.Context, wg *sync.WaitGroup) {
+	start(ctx, wg, p.interval, p.run)
+}
+
+// run purga los elementos caducados y registra el resultado
+func (p *TrashPurger) run(ctx context.Context) {
+	purged, err := p.purger.PurgeExpired(ctx)
+	if err != nil {
+		log.Printf("Error vaciando la papelera: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Elementos eliminados definitivamente de la papelera: %d", purged)
+	}
+}
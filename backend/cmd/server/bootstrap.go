@@ -0,0 +1,56 @@
+package main
+
+import (
+	"blog-backend/adapters/config"
+	"blog-backend/internal/domain"
+	"blog-backend/internal/services"
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// runBootstrapAdminCommand ejecuta el subcomando "bootstrap-admin".
+// La contraseña se lee de la entrada estándar para no dejarla en el historial.
+func runBootstrapAdminCommand(ctx context.Context, userService *services.UserService, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("uso: bootstrap-admin <username>")
+	}
+
+	fmt.Print("Contraseña del administrador: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return fmt.Errorf("error leyendo contraseña: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+
+	user, err := userService.BootstrapAdmin(ctx, args[0], password)
+	if err != nil {
+		switch err {
+		case domain.ErrAdminAlreadyExists:
+			return fmt.Errorf("ya existe un administrador, use la API de administración para crear más")
+		case domain.ErrInvalidInput:
+			return fmt.Errorf("la contraseña debe tener al menos 6 caracteres")
+		}
+		return err
+	}
+
+	fmt.Printf("Administrador %q creado con ID %d\n", user.Username, user.ID)
+	return nil
+}
+
+// bootstrapAdminFromEnv crea el primer administrador con las credenciales
+// configuradas si todavía no existe ninguno
+func bootstrapAdminFromEnv(ctx context.Context, userService *services.UserService, cfg config.BootstrapConfig) {
+	user, err := userService.BootstrapAdmin(ctx, cfg.AdminUsername, cfg.AdminPassword)
+	switch err {
+	case nil:
+		log.Printf("Administrador inicial %q creado", user.Username)
+	case domain.ErrAdminAlreadyExists:
+		log.Println("Ya existe un administrador, se omite BOOTSTRAP_ADMIN_USERNAME")
+	default:
+		log.Fatalf("Error creando administrador inicial: %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"blog-backend/adapters/config"
+	"blog-backend/adapters/filter"
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"fmt"
+)
+
+// newContentFilter arma la cadena de filtros de comentarios activados en la configuración
+func newContentFilter(cfg config.CommentsConfig, commentRepo ports.CommentRepository) (ports.ContentFilter, error) {
+	var filters []ports.ContentFilter
+
+	if cfg.MaxLinks >= 0 {
+		filters = append(filters, filter.NewLinkFilter(cfg.MaxLinks))
+	}
+
+	if len(cfg.BannedWords) > 0 {
+		action := domain.FilterAction(cfg.BannedWordsAction)
+		if action != domain.FilterHold && action != domain.FilterReject {
+			return nil, fmt.Errorf("COMMENT_BANNED_WORDS_ACTION inválido: %q (se admite hold o reject)", cfg.BannedWordsAction)
+		}
+		filters = append(filters, filter.NewBannedWordsFilter(cfg.BannedWords, action))
+	}
+
+	if cfg.DuplicateWindow > 0 {
+		filters = append(filters, filter.NewDuplicateFilter(commentRepo, cfg.DuplicateWindow))
+	}
+
+	if cfg.NewAccountAge > 0 {
+		filters = append(filters, filter.NewNewAccountFilter(commentRepo, cfg.NewAccountAge, cfg.NewAccountMaxPerHour))
+	}
+
+	return filter.NewChain(filters...), nil
+}
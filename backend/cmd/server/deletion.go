@@ -0,0 +1,23 @@
+package main
+
+import (
+	"blog-backend/adapters/config"
+	"blog-backend/internal/domain"
+	"fmt"
+)
+
+// deletionPolicies valida las políticas de eliminación de usuarios y de blogs.
+// Los comentarios de un blog no se pueden reasignar.
+func deletionPolicies(cfg config.DeletionConfig) (userPolicy, blogPolicy domain.DeletionPolicy, err error) {
+	userPolicy = domain.DeletionPolicy(cfg.UserPolicy)
+	if !userPolicy.IsValid() {
+		return "", "", fmt.Errorf("USER_DELETION_POLICY inválido: %q (se admite cascade, reassign o block)", cfg.UserPolicy)
+	}
+
+	blogPolicy = domain.DeletionPolicy(cfg.BlogPolicy)
+	if blogPolicy != domain.DeletionCascade && blogPolicy != domain.DeletionBlock {
+		return "", "", fmt.Errorf("BLOG_DELETION_POLICY inválido: %q (se admite cascade o block)", cfg.BlogPolicy)
+	}
+
+	return userPolicy, blogPolicy, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"blog-backend/adapters/api/http/middleware"
+	"blog-backend/adapters/config"
+	"blog-backend/internal/domain"
+)
+
+// lockoutPolicy convierte la configuración en la política de bloqueo del inicio de sesión
+func lockoutPolicy(cfg config.LoginConfig) domain.LockoutPolicy {
+	return domain.LockoutPolicy{
+		MaxAccountFailures: cfg.MaxAccountFailures,
+		MaxIPFailures:      cfg.MaxIPFailures,
+		FailureWindow:      cfg.FailureWindow,
+		LockoutDuration:    cfg.LockoutDuration,
+		DelayAfter:         cfg.DelayAfter,
+		BaseDelay:          cfg.BaseDelay,
+		MaxDelay:           cfg.MaxDelay,
+	}
+}
+
+// authPolicy convierte la configuración en las exigencias de seguridad de las cuentas
+func authPolicy(cfg *config.Config) middleware.AuthPolicy {
+	policy := middleware.AuthPolicy{RequireVerifiedEmail: cfg.EmailVerification.Required}
+	if cfg.TwoFactor.RequireForAdmins {
+		policy.TwoFactorRoles = []domain.Role{domain.RoleAdmin}
+	}
+	return policy
+}
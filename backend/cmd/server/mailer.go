@@ -0,0 +1,28 @@
+package main
+
+import (
+	"blog-backend/adapters/config"
+	"blog-backend/adapters/mail"
+	"blog-backend/internal/ports"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// asyncMailTimeout limita cuánto puede tardar un correo enviado en segundo plano
+const asyncMailTimeout = time.Minute
+
+// newMailer crea el adaptador de correo indicado en MAIL_DRIVER
+func newMailer(cfg config.MailConfig) (ports.Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return mail.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case "file":
+		return mail.NewFileMailer(cfg.FileDir, cfg.From)
+	case "log":
+		return mail.NewLogMailer(log.New(os.Stderr, "[mail] ", log.LstdFlags), cfg.From), nil
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER inválido: %q (se admite smtp, file o log)", cfg.Driver)
+	}
+}
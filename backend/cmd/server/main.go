@@ -2,14 +2,23 @@ package main
 
 import (
 	httprouter "blog-backend/adapters/api/http"
+	"blog-backend/adapters/api/http/handlers"
 	"blog-backend/adapters/api/http/middleware"
 	"blog-backend/adapters/auth"
 	"blog-backend/adapters/config"
+	"blog-backend/adapters/mail"
 	"blog-backend/adapters/persistence"
+	"blog-backend/adapters/persistence/migrations"
+	"blog-backend/adapters/persistence/sqlite"
+	"blog-backend/adapters/scheduler"
 	"blog-backend/internal/services"
+	"context"
 	"database/sql"
 	"fmt"
+	"io/fs"
 	"log"
+	"os"
+	"sync"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
@@ -23,9 +32,12 @@ func main() {
 
 	// Cargar configuración
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Configuración inválida: %v", err)
+	}
 
 	// Conectar a la base de datos
-	db, err := connectDB(cfg.Database)
+	db, dialect, err := connectDB(cfg.Database)
 	if err != nil {
 		log.Fatalf("Error conectando a la base de datos: %v", err)
 	}
@@ -37,42 +49,195 @@ func main() {
 	}
 	log.Println("Conexión a la base de datos establecida exitosamente")
 
+	// Crear migrador con los scripts embebidos en el binario
+	var migrationFiles fs.FS = migrations.FS
+	if dialect == persistence.DialectSQLite {
+		migrationFiles = migrations.SQLiteFS
+	}
+	migrator, err := persistence.NewMigrator(db, dialect, migrationFiles, cfg.Database.MigrationLockTimeout)
+	if err != nil {
+		log.Fatalf("Error cargando migraciones: %v", err)
+	}
+
+	// Subcomando "migrate up|down|status"
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(migrator, os.Args[2:]); err != nil {
+			log.Fatalf("Error ejecutando migraciones: %v", err)
+		}
+		return
+	}
+
+	// Aplicar migraciones pendientes al iniciar
+	if cfg.Database.AutoMigrate {
+		applied, err := migrator.Up()
+		if err != nil {
+			log.Fatalf("Error aplicando migraciones: %v", err)
+		}
+		log.Printf("Migraciones aplicadas: %d", len(applied))
+	}
+
 	// Crear repositorios (adaptadores de infraestructura)
 	userRepo := persistence.NewUserRepositorySQL(db)
-	blogRepo := persistence.NewBlogRepositorySQL(db)
-	commentRepo := persistence.NewCommentRepositorySQL(db)
+	blogRepo := persistence.NewBlogRepositorySQL(db, dialect)
+	commentRepo := persistence.NewCommentRepositorySQL(db, dialect)
+	refreshTokenRepo := persistence.NewRefreshTokenRepositorySQL(db)
+	loginAttemptRepo := persistence.NewLoginAttemptRepositorySQL(db)
+	passwordResetRepo := persistence.NewPasswordResetTokenRepositorySQL(db)
+	recoveryCodeRepo := persistence.NewRecoveryCodeRepositorySQL(db)
+	identityRepo := persistence.NewIdentityRepositorySQL(db)
+	oidcStateRepo := persistence.NewOIDCStateRepositorySQL(db)
+	searchRepo := persistence.NewSearchRepositorySQL(db, dialect)
+	revisionRepo := persistence.NewRevisionRepositorySQL(db)
+	tagRepo := persistence.NewTagRepositorySQL(db, dialect)
+	roleRepo := persistence.NewRoleRepositorySQL(db)
+	signingKeyRepo := persistence.NewSigningKeyRepositorySQL(db)
+	txManager := persistence.NewTxManagerSQL(db)
+
+	// Las operaciones de arranque no tienen plazo
+	ctx := context.Background()
 
 	// Crear servicios de infraestructura
-	jwtService := auth.NewJWTService(cfg.JWT.SecretKey)
+	tokenKeys, keyManager, err := newTokenKeys(ctx, cfg.JWT, signingKeyRepo)
+	if err != nil {
+		log.Fatalf("Error cargando las claves de firma de los tokens: %v", err)
+	}
+	jwtService := auth.NewJWTService(tokenKeys, cfg.JWT.AccessTokenTTL)
+	authorizer := auth.NewRoleAuthorizer(roleRepo)
+	linkSigner := auth.NewHMACLinkSigner(cfg.EmailVerification.Secret)
+	totpService := auth.NewTOTPService(cfg.TwoFactor.Issuer)
+	totpCipher, err := auth.NewAESGCMCipher(cfg.TwoFactor.EncryptionKey)
+	if err != nil {
+		log.Fatalf("Error creando el cifrado de los secretos TOTP: %v", err)
+	}
+	contentFilter, err := newContentFilter(cfg.Comments, commentRepo)
+	if err != nil {
+		log.Fatalf("Error configurando el filtro de comentarios: %v", err)
+	}
+	mailer, err := newMailer(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Error configurando el envío de correos: %v", err)
+	}
+	identityProvider, err := newIdentityProvider(cfg.OIDC)
+	if err != nil {
+		log.Fatalf("Error configurando OpenID Connect: %v", err)
+	}
+	oidcPolicy, err := identityPolicy(ctx, cfg.OIDC, roleRepo)
+	if err != nil {
+		log.Fatalf("Error configurando OpenID Connect: %v", err)
+	}
+	userDeletion, blogDeletion, err := deletionPolicies(cfg.Deletion)
+	if err != nil {
+		log.Fatalf("Error configurando las políticas de eliminación: %v", err)
+	}
 
 	// Crear servicios de aplicación (casos de uso)
-	userService := services.NewUserService(userRepo, jwtService)
-	authService := services.NewAuthService(userRepo, jwtService)
-	blogService := services.NewBlogService(blogRepo, userRepo)
-	commentService := services.NewCommentService(commentRepo, blogRepo, userRepo)
+	emailVerificationService := services.NewEmailVerificationService(userRepo, jwtService, linkSigner, mailer,
+		cfg.EmailVerification.URL, cfg.EmailVerification.LinkTTL, cfg.EmailVerification.ResendCooldown)
+	userService := services.NewUserService(userRepo, roleRepo, refreshTokenRepo, blogRepo, commentRepo, jwtService, emailVerificationService,
+		txManager, userDeletion)
+	twoFactorService := services.NewTwoFactorService(userRepo, roleRepo, recoveryCodeRepo, refreshTokenRepo, jwtService, totpService, totpCipher)
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, loginAttemptRepo, jwtService, linkSigner, twoFactorService,
+		cfg.JWT.RefreshTokenTTL, cfg.TwoFactor.ChallengeTTL, lockoutPolicy(cfg.Login))
+	oidcService := services.NewOIDCService(identityProvider, identityRepo, oidcStateRepo, userRepo, jwtService, authService,
+		oidcPolicy, cfg.OIDC.StateTTL)
+	// Los correos de restablecimiento se envían en segundo plano para que la
+	// respuesta no revele si el correo está registrado
+	resetMailer := mail.NewAsyncMailer(mailer, asyncMailTimeout)
+	passwordResetService := services.NewPasswordResetService(userRepo, passwordResetRepo, refreshTokenRepo, jwtService, resetMailer, txManager,
+		cfg.Mail.PasswordResetURL, cfg.Mail.PasswordResetTTL)
+	blogService := services.NewBlogService(blogRepo, userRepo, revisionRepo, tagRepo, commentRepo, authorizer, txManager, blogDeletion)
+	commentService := services.NewCommentService(commentRepo, blogRepo, userRepo, revisionRepo, authorizer, contentFilter, txManager,
+		cfg.Comments.ModerateAll)
+	searchService := services.NewSearchService(searchRepo)
+	tagService := services.NewTagService(tagRepo)
+	trashService := services.NewTrashService(blogRepo, commentRepo, userRepo, txManager, cfg.Scheduler.TrashRetention)
+	roleService := services.NewRoleService(roleRepo)
+
+	// Subcomando "bootstrap-admin <username>"
+	if len(os.Args) > 1 && os.Args[1] == "bootstrap-admin" {
+		if err := runBootstrapAdminCommand(ctx, userService, os.Args[2:]); err != nil {
+			log.Fatalf("Error creando administrador inicial: %v", err)
+		}
+		return
+	}
+
+	// Crear el primer administrador a partir de variables de entorno
+	if cfg.Bootstrap.AdminUsername != "" {
+		bootstrapAdminFromEnv(ctx, userService, cfg.Bootstrap)
+	}
+
+	// Publicar en segundo plano los blogs programados, vaciar la papelera caducada
+	// y rotar las claves de firma
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	defer stopScheduler()
+	var jobs sync.WaitGroup
+	scheduler.NewBlogPublisher(blogService, cfg.Scheduler.PublishInterval).Start(schedulerCtx, &jobs)
+	scheduler.NewTrashPurger(trashService, cfg.Scheduler.PurgeInterval).Start(schedulerCtx, &jobs)
+	if keyManager != nil {
+		scheduler.NewSigningKeyRotator(keyManager, cfg.JWT.KeyCheckInterval).Start(schedulerCtx, &jobs)
+	}
 
 	// Crear middleware de autenticación
-	authMiddleware := middleware.NewAuthMiddleware(jwtService)
+	authMiddleware := middleware.NewAuthMiddleware(authService, authorizer, authPolicy(cfg))
+
+	// Crear limitador de peticiones (en memoria: válido con una sola réplica)
+	rateLimiter := middleware.NewRateLimiter(middleware.NewMemoryRateLimitStore())
+
+	// Sondas de vida y de disponibilidad
+	healthHandler := handlers.NewHealthHandler(db, cfg.Database.PingTimeout)
 
 	// Configurar las rutas usando el router
-	router := httprouter.NewRouter(userService, authService, blogService, commentService, authMiddleware)
+	router := httprouter.NewRouter(userService, authService, passwordResetService, emailVerificationService, twoFactorService, oidcService, blogService, commentService, searchService, tagService, trashService, roleService,
+		tokenKeys, healthHandler, authMiddleware, rateLimiter, rateLimits(cfg.RateLimit), cfg.Database.QueryTimeout)
 	ginEngine := router.SetupRoutes()
+	if err := ginEngine.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatalf("Error configurando los proxies de confianza: %v", err)
+	}
 
-	serverAddr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
+	server := newHTTPServer(cfg.Server, ginEngine)
 
-	log.Printf("Servidor iniciando en %s", serverAddr)
-	log.Printf("API disponible en http://%s/api", serverAddr)
-	log.Printf("Endpoint de salud en http://%s/health", serverAddr)
+	log.Printf("Servidor iniciando en %s", server.Addr)
+	log.Printf("API disponible en http://%s/api", server.Addr)
+	log.Printf("Sondas de salud en http://%s/livez y http://%s/readyz", server.Addr, server.Addr)
 
-	// Iniciar servidor usando Gin directamente
-	if err := ginEngine.Run(serverAddr); err != nil {
-		log.Fatalf("Error iniciando servidor: %v", err)
+	if err := runServer(server, healthHandler, cfg.Server); err != nil {
+		log.Fatalf("Error en el servidor: %v", err)
 	}
+
+	// Esperar a que terminen las tareas en segundo plano antes de cerrar la base de datos
+	stopScheduler()
+	jobs.Wait()
+	resetMailer.Wait()
+	log.Println("Servidor detenido")
 }
 
-// connectDB establece la conexión a la base de datos
-func connectDB(dbConfig config.DatabaseConfig) (*sql.DB, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4&collation=utf8mb4_unicode_ci",
+// connectDB establece la conexión a la base de datos del motor configurado y
+// retorna el dialecto SQL que deben usar los repositorios
+func connectDB(dbConfig config.DatabaseConfig) (*sql.DB, persistence.Dialect, error) {
+	switch dbConfig.Driver {
+	case "mysql":
+		db, err := connectMySQL(dbConfig)
+		return db, persistence.DialectMySQL, err
+	case "sqlite":
+		db, err := sqlite.Open(dbConfig.Path)
+		if err != nil {
+			return nil, "", fmt.Errorf("error abriendo la base de datos SQLite: %w", err)
+		}
+		// :memory: necesita su única conexión abierta para no perder los datos
+		if dbConfig.Path != sqlite.MemoryPath {
+			configurePool(db, dbConfig)
+		}
+		return db, persistence.DialectSQLite, nil
+	default:
+		return nil, "", fmt.Errorf("DB_DRIVER inválido: %q (se admite mysql o sqlite)", dbConfig.Driver)
+	}
+}
+
+// connectMySQL establece la conexión a MySQL/MariaDB
+func connectMySQL(dbConfig config.DatabaseConfig) (*sql.DB, error) {
+	// clientFoundRows hace que RowsAffected cuente las filas encontradas y no solo las
+	// modificadas, para que un UPDATE sin cambios no se interprete como "no encontrado"
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&clientFoundRows=true&charset=utf8mb4&collation=utf8mb4_unicode_ci",
 		dbConfig.User,
 		dbConfig.Password,
 		dbConfig.Host,
@@ -85,9 +250,15 @@ func connectDB(dbConfig config.DatabaseConfig) (*sql.DB, error) {
 		return nil, fmt.Errorf("error abriendo conexión a la base de datos: %w", err)
 	}
 
-	// Configurar pool de conexiones
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
+	configurePool(db, dbConfig)
 
 	return db, nil
 }
+
+// configurePool aplica al pool de conexiones los límites de la configuración
+func configurePool(db *sql.DB, dbConfig config.DatabaseConfig) {
+	db.SetMaxOpenConns(dbConfig.MaxOpenConns)
+	db.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	db.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(dbConfig.ConnMaxIdleTime)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"blog-backend/adapters/persistence"
+	"fmt"
+	"strconv"
+)
+
+// runMigrateCommand ejecuta el subcomando "migrate" con sus argumentos
+func runMigrateCommand(migrator *persistence.Migrator, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("uso: migrate up|down [pasos]|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("Aplicada  %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("No hay migraciones pendientes")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("número de pasos inválido: %s", args[1])
+			}
+			steps = n
+		}
+
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("Revertida %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Println("No hay migraciones para revertir")
+		}
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pendiente"
+			if status.Applied {
+				state = "aplicada " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", status.Version, status.Name, state)
+		}
+
+	default:
+		return fmt.Errorf("subcomando desconocido %q, uso: migrate up|down [pasos]|status", args[0])
+	}
+
+	return nil
+}
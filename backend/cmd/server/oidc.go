@@ -0,0 +1,49 @@
+package main
+
+import (
+	"blog-backend/adapters/auth"
+	"blog-backend/adapters/config"
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+)
+
+// newIdentityProvider crea el proveedor OpenID Connect configurado, o nil si
+// OIDC_ISSUER_URL está vacío
+func newIdentityProvider(cfg config.OIDCConfig) (ports.IdentityProvider, error) {
+	if cfg.IssuerURL == "" {
+		return nil, nil
+	}
+	if cfg.ClientID == "" {
+		return nil, fmt.Errorf("OIDC_CLIENT_ID es obligatorio con OIDC_ISSUER_URL")
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	return auth.NewOIDCProvider(cfg.ProviderName, cfg.IssuerURL, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL, scopes, cfg.RoleClaim), nil
+}
+
+// identityPolicy convierte la configuración en la política de identidades
+// externas y verifica que los roles de la asignación existan
+func identityPolicy(ctx context.Context, cfg config.OIDCConfig, roleRepo ports.RoleRepository) (domain.IdentityPolicy, error) {
+	mapping, err := domain.ParseRoleMapping(cfg.RoleMapping)
+	if err != nil {
+		return domain.IdentityPolicy{}, fmt.Errorf("OIDC_ROLE_MAPPING inválido: %w", err)
+	}
+
+	for _, rule := range mapping {
+		if _, err := roleRepo.FindByName(ctx, rule.Role); err != nil {
+			return domain.IdentityPolicy{}, fmt.Errorf("OIDC_ROLE_MAPPING usa el rol %q: %w", rule.Role, err)
+		}
+	}
+
+	return domain.IdentityPolicy{
+		RoleMapping:     mapping,
+		AutoCreateUsers: cfg.AutoCreateUsers,
+		LinkByEmail:     cfg.LinkByEmail,
+	}, nil
+}
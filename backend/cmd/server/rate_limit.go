@@ -0,0 +1,35 @@
+package main
+
+import (
+	httprouter "blog-backend/adapters/api/http"
+	"blog-backend/adapters/api/http/middleware"
+	"blog-backend/adapters/config"
+)
+
+// rateLimits convierte la configuración en las políticas de limitación del router.
+// Con la limitación desactivada todas las políticas quedan vacías.
+func rateLimits(cfg config.RateLimitConfig) httprouter.RateLimits {
+	if !cfg.Enabled {
+		return httprouter.RateLimits{}
+	}
+
+	return httprouter.RateLimits{
+		Login:         rateLimitPolicy("login", cfg.Login),
+		Register:      rateLimitPolicy("register", cfg.Register),
+		PasswordReset: rateLimitPolicy("password_reset", cfg.PasswordReset),
+		VerifyEmail:   rateLimitPolicy("verify_email", cfg.VerifyEmail),
+		Refresh:       rateLimitPolicy("refresh", cfg.Refresh),
+		Search:        rateLimitPolicy("search", cfg.Search),
+		Write:         rateLimitPolicy("write", cfg.Write),
+		Comment:       rateLimitPolicy("comment", cfg.Comment),
+	}
+}
+
+// rateLimitPolicy crea la política con nombre a partir de su configuración
+func rateLimitPolicy(name string, policy config.RateLimitPolicy) middleware.RateLimitPolicy {
+	return middleware.RateLimitPolicy{
+		Name:     name,
+		Requests: policy.Requests,
+		Period:   policy.Period,
+	}
+}
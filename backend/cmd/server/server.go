@@ -0,0 +1,63 @@
+package main
+
+import (
+	"blog-backend/adapters/api/http/handlers"
+	"blog-backend/adapters/config"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// newHTTPServer crea el servidor HTTP con los tiempos máximos de la configuración
+func newHTTPServer(cfg config.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// runServer atiende peticiones hasta recibir SIGINT o SIGTERM. Entonces marca
+// el servidor como no disponible, espera ShutdownDelay, deja de aceptar
+// conexiones y espera hasta ShutdownTimeout a que terminen las peticiones en
+// curso. Una segunda señal detiene el proceso de inmediato.
+func runServer(server *http.Server, health *handlers.HealthHandler, cfg config.ServerConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Println("Señal de parada recibida, cerrando el servidor")
+	health.StartDraining()
+	time.Sleep(cfg.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("error esperando las peticiones en curso: %w", err)
+	}
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
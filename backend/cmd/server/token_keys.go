@@ -0,0 +1,27 @@
+package main
+
+import (
+	"blog-backend/adapters/auth"
+	"blog-backend/adapters/config"
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+)
+
+// newTokenKeys crea las claves de firma de los tokens de acceso. Con RS256 o
+// EdDSA retorna también el gestor de claves, que debe rotarse periódicamente;
+// con HS256 el gestor es nil.
+func newTokenKeys(ctx context.Context, cfg config.JWTConfig, repo ports.SigningKeyRepository) (auth.TokenKeys, *auth.KeyManager, error) {
+	if cfg.SigningAlgorithm == domain.SigningAlgorithmHS256 {
+		return auth.NewHMACKeys(cfg.SecretKey), nil, nil
+	}
+
+	// Una clave retirada debe verificar los tokens que firmó hasta que expiren,
+	// incluidos los que otras réplicas firmaron antes de enterarse de la rotación
+	retention := cfg.AccessTokenTTL + cfg.KeyCheckInterval
+	manager, err := auth.NewKeyManager(ctx, repo, cfg.SigningAlgorithm, cfg.KeyRotationInterval, retention)
+	if err != nil {
+		return nil, nil, err
+	}
+	return manager, manager, nil
+}
@@ -1,8 +1,96 @@
 package domain
 
+import "time"
+
+// BlogStatus representa el estado de publicación de un blog
+type BlogStatus string
+
+const (
+	BlogStatusDraft     BlogStatus = "draft"
+	BlogStatusScheduled BlogStatus = "scheduled"
+	BlogStatusPublished BlogStatus = "published"
+	BlogStatusArchived  BlogStatus = "archived"
+)
+
+// IsValid indica si el estado es uno de los estados conocidos
+func (s BlogStatus) IsValid() bool {
+	switch s {
+	case BlogStatusDraft, BlogStatusScheduled, BlogStatusPublished, BlogStatusArchived:
+		return true
+	}
+	return false
+}
+
 type Blog struct {
-	ID       int64  `json:"id"`
-	Title    string `json:"title"`
-	Content  string `json:"content"`
-	AuthorID int64  `json:"author_id"`
+	ID            int64      `json:"id"`
+	Title         string     `json:"title"`
+	Content       string     `json:"content"`
+	AuthorID      int64      `json:"author_id"`
+	Status        BlogStatus `json:"status"`
+	PublishedAt   *time.Time `json:"published_at"`
+	CommentsCount int64      `json:"comments_count"`
+	Tags          []Tag      `json:"tags"`
+	// CommentModeration exige aprobar los comentarios nuevos antes de mostrarlos
+	CommentModeration bool       `json:"comment_moderation"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	DeletedAt         *time.Time `json:"deleted_at,omitempty"`
+}
+
+// BlogFilter restringe los blogs devueltos por un listado
+type BlogFilter struct {
+	// PublishedOnly limita el listado a los blogs visibles públicamente
+	PublishedOnly bool
+	// Tag limita el listado a los blogs con la etiqueta de este slug
+	Tag string
+}
+
+// SetStatus cambia el estado del blog validando la fecha de publicación.
+// Un blog programado requiere publishAt en el futuro; al publicar sin fecha
+// previa se usa now.
+func (b *Blog) SetStatus(status BlogStatus, publishAt *time.Time, now time.Time) error {
+	switch status {
+	case BlogStatusDraft:
+		b.PublishedAt = nil
+	case BlogStatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return ErrInvalidSchedule
+		}
+		at := *publishAt
+		b.PublishedAt = &at
+	case BlogStatusPublished:
+		if b.PublishedAt == nil || b.Status == BlogStatusScheduled || b.Status == BlogStatusDraft {
+			at := now
+			b.PublishedAt = &at
+		}
+	case BlogStatusArchived:
+		// Conserva la fecha de publicación original
+	default:
+		return ErrInvalidStatus
+	}
+
+	b.Status = status
+	return nil
+}
+
+// IsPublic indica si el blog es visible para cualquier visitante.
+// Un blog programado cuya fecha ya pasó se considera publicado aunque el
+// planificador todavía no haya actualizado su estado.
+func (b *Blog) IsPublic(now time.Time) bool {
+	switch b.Status {
+	case BlogStatusPublished:
+		return true
+	case BlogStatusScheduled:
+		return b.PublishedAt != nil && !b.PublishedAt.After(now)
+	}
+	return false
+}
+
+// SortTime es la fecha por la que se ordenan los blogs más recientes: la de
+// publicación o, en los borradores, la de creación
+func (b *Blog) SortTime() time.Time {
+	if b.PublishedAt != nil {
+		return *b.PublishedAt
+	}
+	return b.CreatedAt
 }
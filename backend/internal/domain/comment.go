@@ -1,8 +1,187 @@
 package domain
 
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// MaxCommentDepth es la profundidad máxima de una respuesta (los comentarios raíz tienen profundidad 0)
+const MaxCommentDepth = 5
+
+// RemovedCommentContent es el texto que muestra un comentario eliminado que conserva respuestas
+const RemovedCommentContent = "comentario eliminado"
+
+// HiddenCommentContent es el texto que muestra un comentario no visible para
+// quien consulta el hilo que conserva respuestas visibles
+const HiddenCommentContent = "comentario oculto"
+
+// MaxModerationReasonLength es la longitud máxima del motivo de una decisión de moderación
+const MaxModerationReasonLength = 500
+
+// CommentStatus representa el estado de moderación de un comentario
+type CommentStatus string
+
+const (
+	CommentStatusApproved CommentStatus = "approved"
+	CommentStatusPending  CommentStatus = "pending"
+	CommentStatusRejected CommentStatus = "rejected"
+	CommentStatusSpam     CommentStatus = "spam"
+)
+
+// IsValid indica si el estado es uno de los estados conocidos
+func (s CommentStatus) IsValid() bool {
+	switch s {
+	case CommentStatusApproved, CommentStatusPending, CommentStatusRejected, CommentStatusSpam:
+		return true
+	}
+	return false
+}
+
 type Comment struct {
-	ID      int64  `json:"id"`
-	BlogID  int64  `json:"blog_id"`
-	UserID  int64  `json:"user_id"`
-	Content string `json:"content"`
+	ID               int64          `json:"id"`
+	BlogID           int64          `json:"blog_id"`
+	UserID           int64          `json:"user_id"`
+	ParentID         *int64         `json:"parent_id"`
+	RootID           *int64         `json:"-"`
+	Depth            int            `json:"depth"`
+	Content          string         `json:"content"`
+	Removed          bool           `json:"removed"`
+	Status           CommentStatus  `json:"status"`
+	ModerationReason string         `json:"moderation_reason,omitempty"`
+	ModeratedBy      *int64         `json:"moderated_by,omitempty"`
+	ModeratedAt      *time.Time     `json:"moderated_at,omitempty"`
+	FilterVerdict    *FilterVerdict `json:"filter_verdict,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        *time.Time     `json:"deleted_at,omitempty"`
+}
+
+// Moderate registra la decisión de un moderador sobre el comentario. Un
+// comentario no puede volver a quedar pendiente ni recibir la misma decisión dos veces.
+func (c *Comment) Moderate(status CommentStatus, reason string, moderatorID int64, now time.Time) error {
+	if !status.IsValid() || status == CommentStatusPending || status == c.Status {
+		return ErrInvalidModeration
+	}
+	if utf8.RuneCountInString(reason) > MaxModerationReasonLength {
+		return ErrInvalidInput
+	}
+
+	c.Status = status
+	c.ModerationReason = reason
+	c.ModeratedBy = &moderatorID
+	c.ModeratedAt = &now
+	return nil
+}
+
+// ApplyVerdict registra el veredicto del filtro de contenido: "hold" deja el
+// comentario pendiente de moderación y "reject" lo rechaza. Un veredicto
+// "allow" conserva el estado y borra el veredicto anterior, que ya no
+// corresponde al contenido actual.
+func (c *Comment) ApplyVerdict(verdict FilterVerdict) {
+	switch verdict.Action {
+	case FilterHold:
+		if c.Status == CommentStatusApproved {
+			c.Status = CommentStatusPending
+		}
+	case FilterReject:
+		c.Status = CommentStatusRejected
+	default:
+		c.FilterVerdict = nil
+		return
+	}
+	c.FilterVerdict = &verdict
+}
+
+// CommentVisibility indica qué comentarios no aprobados ve quien consulta un hilo
+type CommentVisibility struct {
+	// ViewerID ve sus propios comentarios pendientes (0 para visitantes anónimos)
+	ViewerID int64
+	// Moderator ve todos los comentarios pendientes
+	Moderator bool
+}
+
+// Allows indica si quien consulta un hilo ve el comentario: los aprobados los
+// ve cualquiera y los pendientes, su autor y los moderadores
+func (v CommentVisibility) Allows(comment *Comment) bool {
+	switch comment.Status {
+	case CommentStatusApproved:
+		return true
+	case CommentStatusPending:
+		return v.Moderator || (v.ViewerID != 0 && comment.UserID == v.ViewerID)
+	}
+	return false
+}
+
+// ModerationFilter restringe los comentarios de la cola de moderación
+type ModerationFilter struct {
+	Status CommentStatus
+	// BlogID limita la cola a un blog (0 para todos)
+	BlogID int64
+}
+
+// CommentNode es un comentario junto con sus respuestas
+type CommentNode struct {
+	Comment
+	Replies []*CommentNode `json:"replies"`
+}
+
+// BuildCommentTree arma los hilos de los comentarios raíz con sus respuestas.
+// Las respuestas deben venir ordenadas por ID ascendente para que cada padre
+// aparezca antes que sus hijos. Los comentarios eliminados se muestran como
+// nodos sin autor ni contenido, igual que los que visibility no permite ver
+// pero tienen respuestas visibles; los demás no visibles se descartan.
+func BuildCommentTree(roots []Comment, replies []Comment, visibility CommentVisibility) []*CommentNode {
+	nodes := make(map[int64]*CommentNode, len(roots)+len(replies))
+	tree := make([]*CommentNode, 0, len(roots))
+
+	for _, root := range roots {
+		node := newCommentNode(root)
+		nodes[root.ID] = node
+		tree = append(tree, node)
+	}
+
+	for _, reply := range replies {
+		if reply.ParentID == nil {
+			continue
+		}
+		parent, ok := nodes[*reply.ParentID]
+		if !ok {
+			continue
+		}
+		node := newCommentNode(reply)
+		nodes[reply.ID] = node
+		parent.Replies = append(parent.Replies, node)
+	}
+
+	return pruneHiddenComments(tree, visibility)
+}
+
+// pruneHiddenComments descarta los nodos que visibility no permite ver y
+// convierte en lápidas los que conservan respuestas visibles
+func pruneHiddenComments(nodes []*CommentNode, visibility CommentVisibility) []*CommentNode {
+	kept := nodes[:0]
+	for _, node := range nodes {
+		node.Replies = pruneHiddenComments(node.Replies, visibility)
+		if !visibility.Allows(&node.Comment) {
+			if len(node.Replies) == 0 {
+				continue
+			}
+			node.UserID = 0
+			node.Content = HiddenCommentContent
+			node.ModerationReason = ""
+			node.ModeratedBy = nil
+			node.ModeratedAt = nil
+			node.FilterVerdict = nil
+		}
+		kept = append(kept, node)
+	}
+	return kept
+}
+
+func newCommentNode(comment Comment) *CommentNode {
+	if comment.Removed {
+		comment.UserID = 0
+		comment.Content = RemovedCommentContent
+	}
+	return &CommentNode{Comment: comment, Replies: []*CommentNode{}}
 }
@@ -0,0 +1,55 @@
+package domain
+
+// FilterAction es la decisión de un filtro de contenido sobre un comentario
+type FilterAction string
+
+const (
+	FilterAllow  FilterAction = "allow"
+	FilterHold   FilterAction = "hold"
+	FilterReject FilterAction = "reject"
+)
+
+// IsValid indica si la acción es una de las acciones conocidas
+func (a FilterAction) IsValid() bool {
+	switch a {
+	case FilterAllow, FilterHold, FilterReject:
+		return true
+	}
+	return false
+}
+
+// severity ordena las acciones de menos a más restrictiva
+func (a FilterAction) severity() int {
+	switch a {
+	case FilterHold:
+		return 1
+	case FilterReject:
+		return 2
+	}
+	return 0
+}
+
+// FilterVerdict es el resultado de evaluar el contenido de un comentario
+type FilterVerdict struct {
+	Action FilterAction `json:"action"`
+	// Filter es el nombre del filtro que tomó la decisión
+	Filter string `json:"filter,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// AllowVerdict es el veredicto de un contenido que no infringe ningún filtro
+var AllowVerdict = FilterVerdict{Action: FilterAllow}
+
+// StricterThan indica si el veredicto es más restrictivo que other
+func (v FilterVerdict) StricterThan(other FilterVerdict) bool {
+	return v.Action.severity() > other.Action.severity()
+}
+
+// FilterInput es el comentario que se evalúa junto con su contexto
+type FilterInput struct {
+	// CommentID es el comentario que se edita (0 al crear uno nuevo)
+	CommentID int64
+	BlogID    int64
+	Author    *User
+	Content   string
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "strings"
+
+// DeletionPolicy indica qué ocurre con el contenido de un usuario o de un blog
+// cuando se elimina
+type DeletionPolicy string
+
+const (
+	// DeletionCascade mueve el contenido a la papelera junto con su dueño
+	DeletionCascade DeletionPolicy = "cascade"
+	// DeletionReassign conserva el contenido del usuario y lo pasa al usuario DeletedUsername
+	DeletionReassign DeletionPolicy = "reassign"
+	// DeletionBlock impide la eliminación mientras quede contenido
+	DeletionBlock DeletionPolicy = "block"
+)
+
+// DeletedUsername es el usuario que recibe el contenido de los usuarios
+// eliminados con la política DeletionReassign. Se crea la primera vez que hace
+// falta y no puede iniciar sesión.
+const DeletedUsername = "usuario-eliminado"
+
+// IsValid indica si la política es una de las conocidas
+func (p DeletionPolicy) IsValid() bool {
+	switch p {
+	case DeletionCascade, DeletionReassign, DeletionBlock:
+		return true
+	}
+	return false
+}
+
+// IsReservedUsername indica si username es el de DeletedUsername, sin
+// distinguir mayúsculas, por lo que nadie más puede usarlo
+func IsReservedUsername(username string) bool {
+	return strings.EqualFold(username, DeletedUsername)
+}
@@ -3,12 +3,59 @@ package domain
 import "errors"
 
 var (
-	ErrUserNotFound       = errors.New("usuario no encontrado")
-	ErrUserAlreadyExists  = errors.New("el usuario ya existe")
-	ErrInvalidCredentials = errors.New("credenciales inválidas")
-	ErrUnauthorized       = errors.New("no autorizado")
-	ErrForbidden          = errors.New("acceso prohibido")
-	ErrBlogNotFound       = errors.New("blog no encontrado")
-	ErrCommentNotFound    = errors.New("comentario no encontrado")
-	ErrInvalidInput       = errors.New("entrada inválida")
+	ErrUserNotFound         = errors.New("usuario no encontrado")
+	ErrUserAlreadyExists    = errors.New("el usuario ya existe")
+	ErrInvalidCredentials   = errors.New("credenciales inválidas")
+	ErrUnauthorized         = errors.New("no autorizado")
+	ErrForbidden            = errors.New("acceso prohibido")
+	ErrBlogNotFound         = errors.New("blog no encontrado")
+	ErrCommentNotFound      = errors.New("comentario no encontrado")
+	ErrInvalidInput         = errors.New("entrada inválida")
+	ErrRevisionNotFound     = errors.New("revisión no encontrada")
+	ErrInvalidStatus        = errors.New("estado de blog inválido")
+	ErrInvalidSchedule      = errors.New("la fecha de publicación programada debe estar en el futuro")
+	ErrInvalidParentComment = errors.New("el comentario padre no pertenece al blog")
+	ErrCommentDepthExceeded = errors.New("se alcanzó la profundidad máxima de respuestas")
+	ErrInvalidRole          = errors.New("rol inválido")
+	ErrAdminAlreadyExists   = errors.New("ya existe un administrador")
+	ErrInvalidToken         = errors.New("token inválido")
+	ErrTokenReused          = errors.New("reutilización de token de refresco detectada")
+	ErrInvalidCursor        = errors.New("cursor de paginación inválido")
+	ErrInvalidSort          = errors.New("orden de listado inválido")
+	ErrTagNotFound          = errors.New("etiqueta no encontrada")
+	ErrTagAlreadyExists     = errors.New("la etiqueta ya existe")
+	ErrInvalidTag           = errors.New("etiqueta inválida")
+	ErrTooManyTags          = errors.New("demasiadas etiquetas")
+	ErrRoleNotFound         = errors.New("rol no encontrado")
+	ErrRoleAlreadyExists    = errors.New("el rol ya existe")
+	ErrRoleInUse            = errors.New("el rol está asignado a usuarios")
+	ErrBuiltInRole          = errors.New("los roles predefinidos no se pueden eliminar")
+	ErrInvalidPermission    = errors.New("permiso inválido")
+	ErrAdminRoleImmutable   = errors.New("los permisos del rol de administrador no se pueden modificar")
+	ErrInvalidCommentStatus = errors.New("estado de comentario inválido")
+	ErrInvalidModeration    = errors.New("acción de moderación inválida")
+	ErrCommentRejected      = errors.New("el comentario fue rechazado por el filtro de contenido")
+	ErrAccountLocked        = errors.New("cuenta bloqueada temporalmente por demasiados intentos fallidos")
+	ErrLoginThrottled       = errors.New("demasiados intentos de inicio de sesión")
+	ErrInvalidEmail         = errors.New("correo electrónico inválido")
+	ErrEmailAlreadyExists   = errors.New("el correo electrónico ya está registrado")
+	ErrEmailRequired        = errors.New("se requiere un correo electrónico")
+	ErrEmailNotVerified     = errors.New("el correo electrónico no está verificado")
+	ErrEmailAlreadyVerified = errors.New("el correo electrónico ya está verificado")
+	ErrResendThrottled      = errors.New("el enlace de verificación se envió hace poco")
+	ErrInvalidTOTPCode      = errors.New("código de verificación inválido")
+	ErrTwoFactorEnabled     = errors.New("la autenticación en dos pasos ya está activada")
+	ErrTwoFactorDisabled    = errors.New("la autenticación en dos pasos no está activada")
+	ErrNoTOTPEnrollment     = errors.New("no hay una activación de la autenticación en dos pasos pendiente")
+	ErrTwoFactorRequired    = errors.New("se requiere activar la autenticación en dos pasos")
+	ErrOIDCDisabled         = errors.New("el inicio de sesión con OpenID Connect no está configurado")
+	ErrIdentityProvider     = errors.New("error comunicándose con el proveedor de identidad")
+	ErrIdentityNotFound     = errors.New("identidad externa no encontrada")
+	ErrIdentityLinked       = errors.New("la identidad externa ya está vinculada a otro usuario")
+	ErrAccountNotLinked     = errors.New("la identidad externa no está vinculada a ningún usuario")
+	ErrInvalidRoleMapping   = errors.New("asignación de roles inválida")
+	ErrUserHasContent       = errors.New("el usuario tiene blogs o comentarios")
+	ErrBlogHasComments      = errors.New("el blog tiene comentarios")
+	ErrBlogDeleted          = errors.New("el blog está en la papelera")
+	ErrAuthorDeleted        = errors.New("el autor está en la papelera")
 )
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ExternalIdentity vincula un usuario con su cuenta en un proveedor de identidad
+// OpenID Connect. El proveedor identifica a la cuenta con Subject, que no cambia
+// aunque cambie el correo.
+type ExternalIdentity struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// IdentityClaims son los datos del usuario que el proveedor de identidad
+// certifica en el ID token
+type IdentityClaims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	// Roles son los valores del claim configurado para asignar roles (por ejemplo "groups")
+	Roles []string
+}
+
+// OIDCLoginState guarda entre la redirección al proveedor y la vuelta los datos
+// que no deben viajar por el navegador. Solo se guarda el hash del parámetro
+// state; UserID es el usuario que vincula su cuenta, o 0 en un login.
+type OIDCLoginState struct {
+	ID           int64
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	UserID       int64
+	ExpiresAt    time.Time
+}
+
+// IsExpired indica si el login con el proveedor ha caducado en now
+func (s *OIDCLoginState) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// RoleMappingRule asigna Role a los usuarios cuyo claim de roles contiene Value
+type RoleMappingRule struct {
+	Value string
+	Role  Role
+}
+
+// RoleMapping traduce los valores del claim de roles del proveedor de identidad
+// a roles locales. Las reglas se evalúan en orden y gana la primera que coincide,
+// por lo que las de mayor privilegio deben ir primero.
+type RoleMapping []RoleMappingRule
+
+// Resolve retorna el rol de la primera regla cuyo valor aparece en values
+func (m RoleMapping) Resolve(values []string) (Role, bool) {
+	for _, rule := range m {
+		for _, value := range values {
+			if value == rule.Value {
+				return rule.Role, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ParseRoleMapping interpreta una lista de reglas "valor=Rol" separadas por comas
+func ParseRoleMapping(spec string) (RoleMapping, error) {
+	var mapping RoleMapping
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		value, role, ok := strings.Cut(entry, "=")
+		value, role = strings.TrimSpace(value), strings.TrimSpace(role)
+		if !ok || value == "" || role == "" {
+			return nil, ErrInvalidRoleMapping
+		}
+		mapping = append(mapping, RoleMappingRule{Value: value, Role: Role(role)})
+	}
+	return mapping, nil
+}
+
+// IdentityPolicy define cómo se asocian las identidades externas a los usuarios
+type IdentityPolicy struct {
+	// RoleMapping asigna el rol de los usuarios en cada login con el proveedor.
+	// Si ninguna regla coincide se conserva el rol actual.
+	RoleMapping RoleMapping
+	// AutoCreateUsers crea un usuario con el rol de usuario (o el de RoleMapping)
+	// para las identidades que no están vinculadas
+	AutoCreateUsers bool
+	// LinkByEmail vincula una identidad nueva con el usuario que tiene el mismo
+	// correo si ambos lados lo verificaron
+	LinkByEmail bool
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestRoleMappingResolve(t *testing.T) {
+	mapping, err := ParseRoleMapping("blog-admins=Administrador, editors=Editor")
+	if err != nil {
+		t.Fatalf("ParseRoleMapping: %v", err)
+	}
+
+	tests := []struct {
+		values []string
+		role   Role
+		ok     bool
+	}{
+		{values: []string{"staff", "editors", "blog-admins"}, role: RoleAdmin, ok: true},
+		{values: []string{"editors"}, role: RoleEditor, ok: true},
+		{values: []string{"staff"}, ok: false},
+		{values: nil, ok: false},
+	}
+
+	for _, tt := range tests {
+		role, ok := mapping.Resolve(tt.values)
+		if role != tt.role || ok != tt.ok {
+			t.Errorf("Resolve(%v) = %q, %v; se esperaba %q, %v", tt.values, role, ok, tt.role, tt.ok)
+		}
+	}
+
+	if _, err := ParseRoleMapping("sin-rol"); err != ErrInvalidRoleMapping {
+		t.Errorf("ParseRoleMapping(\"sin-rol\") = %v, se esperaba ErrInvalidRoleMapping", err)
+	}
+}
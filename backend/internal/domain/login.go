@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+)
+
+// Motivos por los que se rechaza un intento de inicio de sesión
+const (
+	LoginReasonInvalidCredentials = "invalid_credentials"
+	LoginReasonLocked             = "locked"
+	LoginReasonThrottled          = "throttled"
+	LoginReasonIPBlocked          = "ip_blocked"
+	LoginReasonInvalidTwoFactor   = "invalid_2fa"
+)
+
+// Longitudes máximas que se guardan en la auditoría de inicios de sesión
+const (
+	maxAuditUsernameLength  = 50
+	maxAuditUserAgentLength = 255
+)
+
+// LoginClient identifica al cliente que intenta iniciar sesión
+type LoginClient struct {
+	IP        string
+	UserAgent string
+}
+
+// LoginAttempt es el registro de auditoría de un intento de inicio de sesión
+type LoginAttempt struct {
+	ID int64 `json:"id"`
+	// UserID es nil si el nombre de usuario no corresponde a ninguna cuenta
+	UserID    *int64    `json:"-"`
+	Username  string    `json:"username"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewLoginAttempt crea el registro de un intento recortando los datos que envía el cliente
+func NewLoginAttempt(user *User, username string, client LoginClient, reason string) *LoginAttempt {
+	attempt := &LoginAttempt{
+		Username:  truncateRunes(username, maxAuditUsernameLength),
+		IP:        client.IP,
+		UserAgent: truncateRunes(client.UserAgent, maxAuditUserAgentLength),
+		Success:   reason == "",
+		Reason:    reason,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	return attempt
+}
+
+// truncateRunes recorta s a como máximo max caracteres
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+// LockoutPolicy define la protección contra ataques de fuerza bruta en el inicio de sesión
+type LockoutPolicy struct {
+	// MaxAccountFailures es el número de fallos seguidos que bloquea la cuenta (0 lo desactiva)
+	MaxAccountFailures int
+	// MaxIPFailures es el número de fallos desde una IP que la bloquea (0 lo desactiva)
+	MaxIPFailures int
+	// FailureWindow es el periodo en el que se acumulan los fallos
+	FailureWindow time.Duration
+	// LockoutDuration es cuánto dura el bloqueo de una cuenta
+	LockoutDuration time.Duration
+	// DelayAfter es el número de fallos a partir del cual se exige esperar entre intentos
+	DelayAfter int
+	// BaseDelay es la primera espera; se duplica con cada fallo adicional hasta MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Delay calcula la espera exigida tras failures fallos seguidos
+func (p LockoutPolicy) Delay(failures int) time.Duration {
+	if p.BaseDelay <= 0 || failures < p.DelayAfter {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.DelayAfter; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// RetryAfterError acompaña un error con el tiempo que hay que esperar antes de reintentar
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter obtiene el tiempo de espera de un error, o 0 si no lo tiene
+func RetryAfter(err error) time.Duration {
+	var retryErr *RetryAfterError
+	if errors.As(err, &retryErr) {
+		return retryErr.RetryAfter
+	}
+	return 0
+}
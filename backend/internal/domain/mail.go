@@ -0,0 +1,8 @@
+package domain
+
+// EmailMessage es un correo de texto plano que la aplicación envía a un usuario
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
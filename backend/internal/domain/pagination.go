@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// SortOrder define el orden de un listado paginado
+type SortOrder string
+
+const (
+	SortNewest        SortOrder = "newest"
+	SortOldest        SortOrder = "oldest"
+	SortMostCommented SortOrder = "most_commented"
+)
+
+var (
+	// BlogSortOrders son los órdenes admitidos para blogs (el primero es el predeterminado)
+	BlogSortOrders = []SortOrder{SortNewest, SortOldest, SortMostCommented}
+	// CommentSortOrders son los órdenes admitidos para comentarios (el primero es el predeterminado)
+	CommentSortOrders = []SortOrder{SortOldest, SortNewest}
+	// TrashSortOrders son los órdenes admitidos para la papelera (el primero es el predeterminado)
+	TrashSortOrders = []SortOrder{SortNewest, SortOldest}
+	// LoginAttemptSortOrders son los órdenes admitidos para el historial de inicios de sesión (el primero es el predeterminado)
+	LoginAttemptSortOrders = []SortOrder{SortNewest, SortOldest}
+)
+
+const (
+	// DefaultPageLimit es el tamaño de página cuando no se indica limit
+	DefaultPageLimit = 20
+	// MaxPageLimit es el tamaño máximo de página permitido
+	MaxPageLimit = 100
+)
+
+// Cursor identifica la posición del último elemento devuelto en una página.
+// Se serializa de forma opaca para los clientes.
+type Cursor struct {
+	Sort  SortOrder  `json:"s"`
+	ID    int64      `json:"id"`
+	Count int64      `json:"n,omitempty"`
+	At    *time.Time `json:"t,omitempty"`
+}
+
+// PageRequest contiene los parámetros de una consulta paginada por cursor
+type PageRequest struct {
+	Limit int
+	Sort  SortOrder
+	After *Cursor
+}
+
+// Page es el sobre de respuesta de un listado paginado
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// NewPageRequest valida los parámetros de paginación recibidos del cliente.
+// El primer valor de allowed es el orden por defecto.
+func NewPageRequest(limit int, cursor string, sort SortOrder, allowed ...SortOrder) (PageRequest, error) {
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	if limit < 0 || limit > MaxPageLimit {
+		return PageRequest{}, ErrInvalidInput
+	}
+
+	if sort == "" && len(allowed) > 0 {
+		sort = allowed[0]
+	}
+	if !containsSort(allowed, sort) {
+		return PageRequest{}, ErrInvalidSort
+	}
+
+	page := PageRequest{Limit: limit, Sort: sort}
+	if cursor != "" {
+		after, err := DecodeCursor(cursor)
+		if err != nil || after.Sort != sort {
+			return PageRequest{}, ErrInvalidCursor
+		}
+		page.After = after
+	}
+
+	return page, nil
+}
+
+// NewPage construye una página a partir de hasta Limit+1 elementos consultados.
+// cursorOf obtiene la posición de un elemento para generar next_cursor.
+func NewPage[T any](items []T, page PageRequest, cursorOf func(T) Cursor) *Page[T] {
+	result := &Page[T]{Items: items}
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+
+	if len(result.Items) > page.Limit {
+		result.Items = result.Items[:page.Limit]
+		result.HasMore = true
+
+		next := cursorOf(result.Items[len(result.Items)-1])
+		next.Sort = page.Sort
+		result.NextCursor = EncodeCursor(next)
+	}
+
+	return result
+}
+
+// EncodeCursor serializa un cursor como texto opaco
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor deserializa un cursor generado por EncodeCursor
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+func containsSort(allowed []SortOrder, sort SortOrder) bool {
+	for _, s := range allowed {
+		if s == sort {
+			return true
+		}
+	}
+	return false
+}
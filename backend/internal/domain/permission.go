@@ -0,0 +1,116 @@
+package domain
+
+// Permission es una acción que un rol puede tener concedida
+type Permission string
+
+const (
+	// PermBlogCreate permite crear blogs propios
+	PermBlogCreate Permission = "blog:create"
+	// PermBlogPublish permite publicar o programar blogs (los demás solo pueden guardar borradores)
+	PermBlogPublish Permission = "blog:publish"
+	// PermBlogEdit permite editar y cambiar el estado de blogs de otros autores
+	PermBlogEdit Permission = "blog:edit"
+	// PermBlogDelete permite eliminar blogs de otros autores
+	PermBlogDelete Permission = "blog:delete"
+	// PermBlogReadUnpublished permite ver borradores, programados y archivados de otros autores
+	PermBlogReadUnpublished Permission = "blog:read_unpublished"
+	// PermCommentCreate permite comentar y responder
+	PermCommentCreate Permission = "comment:create"
+	// PermCommentModerate permite editar y eliminar comentarios de otros usuarios
+	PermCommentModerate Permission = "comment:moderate"
+	// PermTagManage permite renombrar y fusionar etiquetas
+	PermTagManage Permission = "tag:manage"
+	// PermUserManage permite crear, editar, eliminar y desbloquear usuarios
+	PermUserManage Permission = "user:manage"
+	// PermRoleManage permite crear y modificar roles y sus permisos
+	PermRoleManage Permission = "role:manage"
+	// PermTrashManage permite consultar y restaurar la papelera
+	PermTrashManage Permission = "trash:manage"
+)
+
+// Permissions son todos los permisos conocidos
+var Permissions = []Permission{
+	PermBlogCreate,
+	PermBlogPublish,
+	PermBlogEdit,
+	PermBlogDelete,
+	PermBlogReadUnpublished,
+	PermCommentCreate,
+	PermCommentModerate,
+	PermTagManage,
+	PermUserManage,
+	PermRoleManage,
+	PermTrashManage,
+}
+
+// IsValid indica si el permiso es uno de los permisos conocidos
+func (p Permission) IsValid() bool {
+	for _, known := range Permissions {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleDefinition es un rol almacenado con los permisos que concede
+type RoleDefinition struct {
+	Name        Role         `json:"name"`
+	Description string       `json:"description"`
+	BuiltIn     bool         `json:"built_in"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Has indica si el rol concede el permiso
+func (r *RoleDefinition) Has(permission Permission) bool {
+	return containsPermission(r.Permissions, permission)
+}
+
+// Covers indica si el rol concede todos los permisos de other
+func (r *RoleDefinition) Covers(other *RoleDefinition) bool {
+	for _, permission := range other.Permissions {
+		if !r.Has(permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRoleDefinition valida el nombre y los permisos de un rol y elimina los permisos repetidos
+func NewRoleDefinition(name Role, description string, permissions []Permission) (*RoleDefinition, error) {
+	if name == "" || len(name) > 50 {
+		return nil, ErrInvalidRole
+	}
+
+	role := &RoleDefinition{Name: name, Description: description, Permissions: []Permission{}}
+	if err := role.SetPermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// SetPermissions reemplaza los permisos del rol validando que sean conocidos
+func (r *RoleDefinition) SetPermissions(permissions []Permission) error {
+	unique := make([]Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			return ErrInvalidPermission
+		}
+		if !containsPermission(unique, permission) {
+			unique = append(unique, permission)
+		}
+	}
+
+	r.Permissions = unique
+	return nil
+}
+
+func containsPermission(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
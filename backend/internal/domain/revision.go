@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// RevisionEntity identifica el tipo de contenido versionado
+type RevisionEntity string
+
+const (
+	RevisionEntityBlog    RevisionEntity = "blog"
+	RevisionEntityComment RevisionEntity = "comment"
+)
+
+// Revision es una instantánea del contenido de un blog o comentario tras una edición
+type Revision struct {
+	ID         int64          `json:"id"`
+	EntityType RevisionEntity `json:"entity_type"`
+	EntityID   int64          `json:"entity_id"`
+	EditorID   int64          `json:"editor_id"`
+	Title      string         `json:"title,omitempty"`
+	Content    string         `json:"content"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// DiffOp es el tipo de cambio de una línea en un diff
+type DiffOp string
+
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// DiffLine es una línea de un diff entre dos textos
+type DiffLine struct {
+	Op   DiffOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// RevisionDiff describe las diferencias entre dos revisiones
+type RevisionDiff struct {
+	From         *Revision  `json:"from"`
+	To           *Revision  `json:"to"`
+	TitleChanged bool       `json:"title_changed"`
+	Content      []DiffLine `json:"content"`
+}
+
+// NewRevisionDiff compara dos revisiones línea a línea
+func NewRevisionDiff(from, to *Revision) *RevisionDiff {
+	return &RevisionDiff{
+		From:         from,
+		To:           to,
+		TitleChanged: from.Title != to.Title,
+		Content:      DiffLines(from.Content, to.Content),
+	}
+}
+
+// maxDiffCells limita el trabajo de DiffLines: si las partes distintas de los
+// dos textos superan este producto de líneas se muestran como un bloque
+// eliminado seguido de uno insertado en lugar de buscar las líneas comunes
+const maxDiffCells = 16 << 20
+
+// DiffLines calcula un diff por líneas usando la subsecuencia común más larga.
+// Usa el algoritmo de Hirschberg, que necesita memoria lineal en el número de
+// líneas en lugar de una tabla con una celda por cada par de líneas.
+func DiffLines(a, b string) []DiffLine {
+	left := strings.Split(a, "\n")
+	right := strings.Split(b, "\n")
+	return diffRange(left, right, make([]DiffLine, 0, len(left)+len(right)))
+}
+
+// diffRange añade a diff las líneas del diff entre left y right
+func diffRange(left, right []string, diff []DiffLine) []DiffLine {
+	// Las líneas iniciales y finales comunes no necesitan buscarse
+	for len(left) > 0 && len(right) > 0 && left[0] == right[0] {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: left[0]})
+		left, right = left[1:], right[1:]
+	}
+	suffix := 0
+	for suffix < len(left) && suffix < len(right) && left[len(left)-1-suffix] == right[len(right)-1-suffix] {
+		suffix++
+	}
+	common := left[len(left)-suffix:]
+	left, right = left[:len(left)-suffix], right[:len(right)-suffix]
+
+	switch {
+	case len(left) == 0 || len(right) == 0 || len(left)*len(right) > maxDiffCells:
+		diff = appendLines(diff, DiffDelete, left)
+		diff = appendLines(diff, DiffInsert, right)
+	case len(left) == 1:
+		// Una sola línea: o aparece en right o se reemplaza
+		at := -1
+		for j, line := range right {
+			if line == left[0] {
+				at = j
+				break
+			}
+		}
+		if at < 0 {
+			diff = appendLines(diff, DiffDelete, left)
+			diff = appendLines(diff, DiffInsert, right)
+		} else {
+			diff = appendLines(diff, DiffInsert, right[:at])
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: left[0]})
+			diff = appendLines(diff, DiffInsert, right[at+1:])
+		}
+	default:
+		// Se parte left por la mitad y right por el punto en el que la suma de
+		// las subsecuencias comunes de ambas mitades es máxima
+		mid := len(left) / 2
+		forward := lcsLengths(left[:mid], right, false)
+		backward := lcsLengths(left[mid:], right, true)
+		split := 0
+		for j := range forward {
+			if forward[j]+backward[j] > forward[split]+backward[split] {
+				split = j
+			}
+		}
+		diff = diffRange(left[:mid], right[:split], diff)
+		diff = diffRange(left[mid:], right[split:], diff)
+	}
+
+	return appendLines(diff, DiffEqual, common)
+}
+
+// lcsLengths retorna en la posición j la longitud de la subsecuencia común más
+// larga de left y right[:j] o, con reverse, de left y right[j:]. Solo guarda
+// dos filas de la tabla de programación dinámica.
+func lcsLengths(left, right []string, reverse bool) []int {
+	prev := make([]int, len(right)+1)
+	curr := make([]int, len(right)+1)
+	for i := range left {
+		line := left[i]
+		if reverse {
+			line = left[len(left)-1-i]
+		}
+		for k := 1; k <= len(right); k++ {
+			other := right[k-1]
+			if reverse {
+				other = right[len(right)-k]
+			}
+			if line == other {
+				curr[k] = prev[k-1] + 1
+			} else {
+				curr[k] = max(prev[k], curr[k-1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+
+	if reverse {
+		// prev[k] corresponde a las últimas k líneas de right
+		lengths := make([]int, len(right)+1)
+		for j := range lengths {
+			lengths[j] = prev[len(right)-j]
+		}
+		return lengths
+	}
+	return prev
+}
+
+// appendLines añade a diff las líneas indicadas con la operación op
+func appendLines(diff []DiffLine, op DiffOp, lines []string) []DiffLine {
+	for _, line := range lines {
+		diff = append(diff, DiffLine{Op: op, Text: line})
+	}
+	return diff
+}
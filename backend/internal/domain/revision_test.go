@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// diffString resume un diff con un prefijo por línea: " " igual, "+" insertada y "-" eliminada
+func diffString(diff []DiffLine) string {
+	prefixes := map[DiffOp]string{DiffEqual: " ", DiffInsert: "+", DiffDelete: "-"}
+	lines := make([]string, len(diff))
+	for i, line := range diff {
+		lines[i] = prefixes[line.Op] + line.Text
+	}
+	return strings.Join(lines, "|")
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "iguales", a: "a\nb", b: "a\nb", want: " a| b"},
+		{name: "vacíos", a: "", b: "", want: " "},
+		{name: "desde vacío", a: "", b: "a", want: "-|+a"},
+		{name: "línea añadida al final", a: "a\nb", b: "a\nb\nc", want: " a| b|+c"},
+		{name: "línea eliminada al principio", a: "a\nb\nc", b: "b\nc", want: "-a| b| c"},
+		{name: "línea reemplazada", a: "a\nb\nc", b: "a\nx\nc", want: " a|-b|+x| c"},
+		{name: "línea movida", a: "a\nb\nc", b: "b\nc\na", want: "-a| b| c|+a"},
+		{name: "intercaladas", a: "a\nb\nc\nd", b: "x\nb\ny\nd", want: "-a|+x| b|-c|+y| d"},
+		{name: "sin líneas comunes", a: "a\nb", b: "c\nd", want: "-a|-b|+c|+d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffString(DiffLines(tt.a, tt.b)); got != tt.want {
+				t.Errorf("DiffLines = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// quadraticLCS calcula la longitud de la subsecuencia común más larga con la tabla completa
+func quadraticLCS(left, right []string) int {
+	lcs := make([][]int, len(left)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(right)+1)
+	}
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return lcs[0][0]
+}
+
+func TestDiffLinesIsMinimal(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		lines := make([]string, random.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + random.Intn(4)))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := randomText(), randomText()
+		diff := DiffLines(a, b)
+
+		// El diff reconstruye ambos textos y conserva todas las líneas comunes posibles
+		var left, right []string
+		equal := 0
+		for _, line := range diff {
+			if line.Op != DiffInsert {
+				left = append(left, line.Text)
+			}
+			if line.Op != DiffDelete {
+				right = append(right, line.Text)
+			}
+			if line.Op == DiffEqual {
+				equal++
+			}
+		}
+		wantLeft, wantRight := strings.Split(a, "\n"), strings.Split(b, "\n")
+		if !reflect.DeepEqual(left, wantLeft) || !reflect.DeepEqual(right, wantRight) {
+			t.Fatalf("DiffLines(%q, %q) no reconstruye los textos: %q", a, b, diffString(diff))
+		}
+		if lcs := quadraticLCS(wantLeft, wantRight); equal != lcs {
+			t.Fatalf("DiffLines(%q, %q) conserva %d líneas, se esperaban %d", a, b, equal, lcs)
+		}
+	}
+}
+
+func TestDiffLinesLargeInput(t *testing.T) {
+	lines := make([]string, 50000)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("línea %d", i)
+	}
+	a := strings.Join(lines, "\n")
+	lines[100] = "cambiada"
+	lines = append(lines[:150], lines[151:]...)
+	b := strings.Join(lines, "\n")
+
+	counts := map[DiffOp]int{}
+	for _, line := range DiffLines(a, b) {
+		counts[line.Op]++
+	}
+	if counts[DiffEqual] != 49998 || counts[DiffDelete] != 2 || counts[DiffInsert] != 1 {
+		t.Errorf("DiffLines = %v", counts)
+	}
+
+	// Sin líneas comunes por encima del límite se muestran como dos bloques
+	other := strings.Repeat("otra\n", 50000)
+	counts = map[DiffOp]int{}
+	for _, line := range DiffLines(a, other) {
+		counts[line.Op]++
+	}
+	if counts[DiffDelete] != 50000 || counts[DiffInsert] != 50001 || counts[DiffEqual] != 0 {
+		t.Errorf("DiffLines sin líneas comunes = %v", counts)
+	}
+}
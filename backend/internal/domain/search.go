@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SearchKind identifica el tipo de elemento encontrado en una búsqueda
+type SearchKind string
+
+const (
+	SearchKindBlog    SearchKind = "blog"
+	SearchKindComment SearchKind = "comment"
+)
+
+// SearchTerm es un término de búsqueda: una palabra o una frase exacta entre comillas
+type SearchTerm struct {
+	Value  string
+	Phrase bool
+}
+
+// SearchQuery contiene los criterios de una búsqueda de texto completo
+type SearchQuery struct {
+	Terms    []SearchTerm
+	Kind     SearchKind
+	AuthorID int64
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
+
+// SearchResult es un blog o comentario que coincide con una búsqueda
+type SearchResult struct {
+	Kind      SearchKind `json:"type"`
+	BlogID    int64      `json:"blog_id"`
+	CommentID int64      `json:"comment_id,omitempty"`
+	Title     string     `json:"title"`
+	AuthorID  int64      `json:"author_id"`
+	Snippet   string     `json:"snippet"`
+	Score     float64    `json:"score"`
+	CreatedAt time.Time  `json:"created_at"`
+	Content   string     `json:"-"`
+}
+
+// SearchResults es la respuesta paginada de una búsqueda
+type SearchResults struct {
+	Items   []SearchResult `json:"items"`
+	HasMore bool           `json:"has_more"`
+}
+
+// ParseSearchTerms separa el texto de búsqueda en palabras y frases entre comillas.
+// Los caracteres que no son letras ni dígitos se tratan como separadores.
+func ParseSearchTerms(text string) []SearchTerm {
+	var terms []SearchTerm
+
+	parts := strings.Split(text, `"`)
+	for i, part := range parts {
+		words := strings.FieldsFunc(part, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+
+		// Las partes impares están entre comillas (una comilla sin cerrar se ignora)
+		if i%2 == 1 && i < len(parts)-1 {
+			if len(words) > 0 {
+				terms = append(terms, SearchTerm{Value: strings.Join(words, " "), Phrase: true})
+			}
+			continue
+		}
+
+		for _, word := range words {
+			terms = append(terms, SearchTerm{Value: word})
+		}
+	}
+
+	return terms
+}
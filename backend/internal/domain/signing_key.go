@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// Algoritmos de firma de los tokens de acceso
+const (
+	SigningAlgorithmHS256 = "HS256"
+	SigningAlgorithmRS256 = "RS256"
+	SigningAlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey es una clave asimétrica de firma de los tokens de acceso. Los
+// tokens indican en su cabecera "kid" el ID de la clave que los firmó. Una
+// clave retirada deja de firmar pero sigue verificando hasta ExpiresAt, cuando
+// ya expiraron todos los tokens que firmó.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	// PrivateKey es la clave privada en PEM (PKCS #8)
+	PrivateKey string
+	CreatedAt  time.Time
+	RetiredAt  *time.Time
+	ExpiresAt  *time.Time
+}
+
+// IsRetired indica si la clave ya no firma tokens nuevos
+func (k *SigningKey) IsRetired() bool {
+	return k.RetiredAt != nil
+}
+
+// JSONWebKey es una clave pública de verificación en formato JWK (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// Claves RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Claves Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet es el conjunto de claves públicas que se publica en /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
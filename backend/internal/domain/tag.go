@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxTagsPerBlog es el número máximo de etiquetas de un blog
+	MaxTagsPerBlog = 10
+	// MaxTagNameLength es la longitud máxima (en caracteres) del nombre de una etiqueta
+	MaxTagNameLength = 50
+)
+
+// Tag es una etiqueta que agrupa blogs por tema
+type Tag struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	PostCount int64  `json:"post_count"`
+}
+
+// NewTag crea una etiqueta a partir de su nombre, normalizando el slug
+func NewTag(name string) (Tag, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || len([]rune(name)) > MaxTagNameLength {
+		return Tag{}, ErrInvalidTag
+	}
+
+	slug := TagSlug(name)
+	if slug == "" {
+		return Tag{}, ErrInvalidTag
+	}
+
+	return Tag{Name: name, Slug: slug}, nil
+}
+
+// NewTags crea las etiquetas de un blog descartando las que tienen el mismo slug
+func NewTags(names []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		tag, err := NewTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[tag.Slug] {
+			continue
+		}
+		seen[tag.Slug] = true
+		tags = append(tags, tag)
+	}
+
+	if len(tags) > MaxTagsPerBlog {
+		return nil, ErrTooManyTags
+	}
+
+	return tags, nil
+}
+
+// TagSlug normaliza un nombre de etiqueta: minúsculas, sin tildes y con guiones
+// en lugar de espacios y signos ("UI/UX" -> "ui-ux", "Retención" -> "retencion")
+func TagSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		r = foldAccent(r)
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// foldAccent sustituye las vocales acentuadas y la eñe por su letra base
+func foldAccent(r rune) rune {
+	switch r {
+	case 'á', 'à', 'ä', 'â':
+		return 'a'
+	case 'é', 'è', 'ë', 'ê':
+		return 'e'
+	case 'í', 'ì', 'ï', 'î':
+		return 'i'
+	case 'ó', 'ò', 'ö', 'ô':
+		return 'o'
+	case 'ú', 'ù', 'ü', 'û':
+		return 'u'
+	case 'ñ':
+		return 'n'
+	case 'ç':
+		return 'c'
+	}
+	return r
+}
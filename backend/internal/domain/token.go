@@ -0,0 +1,77 @@
+package domain
+
+import "time"
+
+// TokenClaims contiene la información extraída de un token de acceso
+type TokenClaims struct {
+	UserID    int64
+	Username  string
+	Role      Role
+	SessionID string
+}
+
+// TokenPair agrupa el token de acceso y el token de refresco emitidos en un login
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// RefreshToken representa un token de refresco almacenado.
+// Todos los tokens emitidos a partir del mismo login comparten FamilyID,
+// que además identifica la sesión en los tokens de acceso.
+type RefreshToken struct {
+	ID        int64
+	UserID    int64
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// IsExpired indica si el token de refresco ya expiró
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// Propósitos de los tokens firmados
+const (
+	LinkPurposeEmailVerification = "email_verification"
+	LinkPurposeLoginChallenge    = "login_challenge"
+)
+
+// SignedLink son los datos de un token firmado: los enlaces enviados por correo
+// y los desafíos del login en dos pasos. La firma permite verificarlos sin
+// guardarlos en la base de datos; incluir el correo invalida los enlaces de
+// verificación si el usuario lo cambia.
+type SignedLink struct {
+	Purpose   string
+	UserID    int64
+	Email     string
+	ExpiresAt time.Time
+}
+
+// LoginResult es el resultado de un login. Si el usuario tiene
+// activa la autenticación en dos pasos se emite un desafío en lugar de tokens.
+type LoginResult struct {
+	Tokens *TokenPair
+	User   *User
+
+	ChallengeToken     string
+	ChallengeExpiresIn int64
+}
+
+// PasswordResetToken representa un token de restablecimiento de contraseña
+// almacenado. Como con los tokens de refresco solo se guarda su hash.
+type PasswordResetToken struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// IsUsable indica si el token todavía se puede usar: no expiró ni se usó antes
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
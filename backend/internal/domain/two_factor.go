@@ -0,0 +1,46 @@
+package domain
+
+import "strings"
+
+// RecoveryCodeCount es el número de códigos de recuperación que se generan al
+// activar la autenticación en dos pasos
+const RecoveryCodeCount = 10
+
+// TwoFactorEnrollment es el secreto TOTP pendiente de confirmar. ProvisioningURI
+// es la URI otpauth:// que las aplicaciones de autenticación leen como código QR.
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TwoFactorStatus resume la autenticación en dos pasos de un usuario
+type TwoFactorStatus struct {
+	Enabled           bool `json:"enabled"`
+	Pending           bool `json:"pending"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// IsTOTPCode indica si un código tiene la forma de un código TOTP (seis dígitos)
+// y no la de un código de recuperación
+func IsTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeRecoveryCode quita espacios y guiones y pasa a minúsculas un código
+// de recuperación para que se pueda escribir con cualquier formato
+func NormalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+}
@@ -1,15 +1,150 @@
 package domain
 
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
+
 type Role string
 
+// Roles predefinidos. Los permisos de cada rol se almacenan en la base de datos
+// y los administradores pueden crear roles nuevos.
 const (
-	RoleAdmin Role = "Administrador"
-	RoleUser  Role = "Usuario"
+	RoleAdmin     Role = "Administrador"
+	RoleUser      Role = "Usuario"
+	RoleEditor    Role = "Editor"
+	RoleModerator Role = "Moderador"
 )
 
 type User struct {
-	ID       int64  `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"-"`
-	Role     Role   `json:"role"`
+	ID        int64      `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email,omitempty"`
+	Password  string     `json:"-"`
+	Role      Role       `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// EmailVerifiedAt es cuándo se verificó el correo actual; nil si no está verificado
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// VerificationSentAt es cuándo se envió el último enlace de verificación
+	VerificationSentAt *time.Time `json:"-"`
+
+	// TOTPSecret es el secreto TOTP en base32; sin TOTPEnabledAt la activación está pendiente
+	TOTPSecret    string     `json:"-"`
+	TOTPEnabledAt *time.Time `json:"two_factor_enabled_at,omitempty"`
+	// TOTPLastStep es el intervalo del último código aceptado, para no aceptarlo dos veces
+	TOTPLastStep int64 `json:"-"`
+
+	// FailedLogins es el número de inicios de sesión fallidos seguidos
+	FailedLogins      int        `json:"-"`
+	LastFailedLoginAt *time.Time `json:"-"`
+	// LockedUntil es el final del bloqueo por demasiados intentos fallidos
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+}
+
+// MaxEmailLength es la longitud máxima de una dirección de correo
+const MaxEmailLength = 255
+
+// NormalizeEmail valida una dirección de correo y la retorna sin espacios y en
+// minúsculas. Una dirección vacía es válida: el correo es opcional.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", nil
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || len(email) > MaxEmailLength {
+		return "", ErrInvalidEmail
+	}
+	return email, nil
+}
+
+// IsDeleted indica si el usuario está en la papelera
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// IsEmailVerified indica si el usuario verificó su correo
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// ChangeEmail cambia el correo del usuario. Un correo distinto del actual
+// tiene que verificarse de nuevo.
+func (u *User) ChangeEmail(email string) {
+	if email == u.Email {
+		return
+	}
+	u.Email = email
+	u.EmailVerifiedAt = nil
+	u.VerificationSentAt = nil
+}
+
+// VerificationResendDelay es lo que falta para poder reenviar el enlace de verificación
+func (u *User) VerificationResendDelay(now time.Time, cooldown time.Duration) time.Duration {
+	if u.VerificationSentAt == nil {
+		return 0
+	}
+	wait := u.VerificationSentAt.Add(cooldown).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// TwoFactorEnabled indica si el usuario tiene activa la autenticación en dos pasos
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// DisableTwoFactor desactiva la autenticación en dos pasos y descarta el secreto
+func (u *User) DisableTwoFactor() {
+	u.TOTPSecret = ""
+	u.TOTPEnabledAt = nil
+	u.TOTPLastStep = 0
+}
+
+// IsLocked indica si la cuenta está bloqueada por demasiados intentos fallidos
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// LoginDelay es lo que falta para que el usuario pueda volver a intentar
+// iniciar sesión tras varios fallos seguidos
+func (u *User) LoginDelay(now time.Time, policy LockoutPolicy) time.Duration {
+	if u.LastFailedLoginAt == nil {
+		return 0
+	}
+	wait := u.LastFailedLoginAt.Add(policy.Delay(u.FailedLogins)).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// RegisterLoginFailure cuenta un inicio de sesión fallido. Los fallos más
+// antiguos que la ventana de la política no se acumulan; al llegar al máximo
+// la cuenta se bloquea y el contador vuelve a empezar.
+func (u *User) RegisterLoginFailure(now time.Time, policy LockoutPolicy) {
+	if u.LastFailedLoginAt == nil || now.Sub(*u.LastFailedLoginAt) > policy.FailureWindow {
+		u.FailedLogins = 0
+	}
+	u.FailedLogins++
+	u.LastFailedLoginAt = &now
+
+	if policy.MaxAccountFailures > 0 && u.FailedLogins >= policy.MaxAccountFailures {
+		until := now.Add(policy.LockoutDuration)
+		u.LockedUntil = &until
+		u.FailedLogins = 0
+	}
+}
+
+// ResetLoginFailures borra los fallos acumulados y el bloqueo de la cuenta
+func (u *User) ResetLoginFailures() {
+	u.FailedLogins = 0
+	u.LastFailedLoginAt = nil
+	u.LockedUntil = nil
 }
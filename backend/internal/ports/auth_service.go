@@ -1,11 +1,17 @@
 package ports
 
-import "blog-backend/internal/domain"
+import (
+	"blog-backend/internal/domain"
+	"time"
+)
 
 // AuthService define las operaciones de autenticación
 type AuthService interface {
-	GenerateToken(user *domain.User) (string, error)
-	ValidateToken(token string) (*domain.User, error)
+	GenerateToken(user *domain.User, sessionID string) (string, error)
+	AccessTokenTTL() time.Duration
+	ValidateToken(token string) (*domain.TokenClaims, error)
 	HashPassword(password string) (string, error)
 	CheckPassword(password, hash string) bool
+	GenerateOpaqueToken() (string, error)
+	HashToken(token string) string
 }
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+)
+
+// Authorizer decide si un usuario puede realizar una acción según los permisos de su rol
+type Authorizer interface {
+	// Authorize retorna domain.ErrForbidden si el rol no concede el permiso
+	Authorize(ctx context.Context, role domain.Role, permission domain.Permission) error
+	// AuthorizeOwner permite la acción al propietario del recurso o a quien tenga el permiso
+	AuthorizeOwner(ctx context.Context, userID int64, role domain.Role, ownerID int64, permission domain.Permission) error
+}
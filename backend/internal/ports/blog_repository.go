@@ -1,13 +1,25 @@
 package ports
 
-import "blog-backend/internal/domain"
+import (
+	"blog-backend/internal/domain"
+	"context"
+	"time"
+)
 
 // BlogRepository define las operaciones de persistencia para blogs
 type BlogRepository interface {
-	Create(blog *domain.Blog) error
-	FindByID(id int64) (*domain.Blog, error)
-	FindByAuthorID(authorID int64) ([]domain.Blog, error)
-	List() ([]domain.Blog, error)
-	Update(blog *domain.Blog) error
-	Delete(id int64) error
+	Create(ctx context.Context, blog *domain.Blog) error
+	FindByID(ctx context.Context, id int64) (*domain.Blog, error)
+	FindByIDForUpdate(ctx context.Context, id int64) (*domain.Blog, error)
+	FindByAuthorID(ctx context.Context, authorID int64, filter domain.BlogFilter, page domain.PageRequest) (*domain.Page[domain.Blog], error)
+	List(ctx context.Context, filter domain.BlogFilter, page domain.PageRequest) (*domain.Page[domain.Blog], error)
+	ExistsByAuthor(ctx context.Context, authorID int64) (bool, error)
+	Update(ctx context.Context, blog *domain.Blog) error
+	ReassignAuthor(ctx context.Context, fromID, toID int64) error
+	Delete(ctx context.Context, id int64) error
+	FindDeleted(ctx context.Context, id int64) (*domain.Blog, error)
+	ListDeleted(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.Blog], error)
+	Restore(ctx context.Context, id int64) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	PublishDue(ctx context.Context, now time.Time) (int64, error)
 }
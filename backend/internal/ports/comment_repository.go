@@ -1,13 +1,33 @@
 package ports
 
-import "blog-backend/internal/domain"
+import (
+	"blog-backend/internal/domain"
+	"context"
+	"time"
+)
 
 // CommentRepository define las operaciones de persistencia para comentarios
 type CommentRepository interface {
-	Create(comment *domain.Comment) error
-	FindByID(id int64) (*domain.Comment, error)
-	FindByBlogID(blogID int64) ([]domain.Comment, error)
-	FindByUserID(userID int64) ([]domain.Comment, error)
-	Update(comment *domain.Comment) error
-	Delete(id int64) error
+	Create(ctx context.Context, comment *domain.Comment) error
+	FindByID(ctx context.Context, id int64) (*domain.Comment, error)
+	FindByIDForUpdate(ctx context.Context, id int64) (*domain.Comment, error)
+	FindByBlogID(ctx context.Context, blogID int64, page domain.PageRequest, visibility domain.CommentVisibility) (*domain.Page[domain.Comment], error)
+	FindByUserID(ctx context.Context, userID int64, page domain.PageRequest) (*domain.Page[domain.Comment], error)
+	FindByRootIDs(ctx context.Context, rootIDs []int64) ([]domain.Comment, error)
+	FindRecentByUser(ctx context.Context, userID int64, since time.Time) ([]domain.Comment, error)
+	ExistsByUser(ctx context.Context, userID int64) (bool, error)
+	ExistsByBlog(ctx context.Context, blogID int64) (bool, error)
+	ListForModeration(ctx context.Context, filter domain.ModerationFilter, page domain.PageRequest) (*domain.Page[domain.Comment], error)
+	CountReplies(ctx context.Context, id int64) (int64, error)
+	Update(ctx context.Context, comment *domain.Comment) error
+	UpdateModeration(ctx context.Context, comment *domain.Comment) error
+	MarkRemoved(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64) error
+	DeleteByBlog(ctx context.Context, blogID int64) error
+	ReassignUser(ctx context.Context, fromID, toID int64) error
+	FindDeleted(ctx context.Context, id int64) (*domain.Comment, error)
+	ListDeleted(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.Comment], error)
+	Restore(ctx context.Context, id int64) error
+	RestoreByBlog(ctx context.Context, blogID int64) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+)
+
+// ContentFilter evalúa el contenido de un comentario antes de guardarlo
+type ContentFilter interface {
+	// Check retorna si el comentario se publica, queda pendiente de moderación o se rechaza
+	Check(ctx context.Context, input domain.FilterInput) (domain.FilterVerdict, error)
+}
@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+)
+
+// IdentityProvider es un proveedor de identidad externo con el flujo de código
+// de autorización de OpenID Connect y PKCE
+type IdentityProvider interface {
+	// Name identifica al proveedor en las identidades vinculadas
+	Name() string
+	// AuthCodeURL construye la URL de autorización a la que se redirige al usuario
+	AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error)
+	// Exchange canjea el código de autorización y retorna los claims del ID token
+	// verificado. Los códigos y tokens inválidos retornan ErrInvalidToken.
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*domain.IdentityClaims, error)
+}
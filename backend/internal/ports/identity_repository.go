@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+	"time"
+)
+
+// IdentityRepository define las operaciones de persistencia para las identidades externas vinculadas
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *domain.ExternalIdentity) error
+	FindBySubject(ctx context.Context, provider, subject string) (*domain.ExternalIdentity, error)
+	ListByUser(ctx context.Context, userID int64) ([]domain.ExternalIdentity, error)
+	UpdateLastLogin(ctx context.Context, id int64, at time.Time) error
+	// Delete elimina la identidad id solo si pertenece a userID
+	Delete(ctx context.Context, id, userID int64) error
+}
+
+// OIDCStateRepository define las operaciones de persistencia para los logins
+// con OpenID Connect en curso
+type OIDCStateRepository interface {
+	Create(ctx context.Context, state *domain.OIDCLoginState) error
+	// Take busca y elimina un login en curso por el hash de su state, de modo
+	// que cada state solo se pueda usar una vez
+	Take(ctx context.Context, stateHash string) (*domain.OIDCLoginState, error)
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
@@ -0,0 +1,11 @@
+package ports
+
+import "blog-backend/internal/domain"
+
+// LinkSigner firma y verifica los datos de los enlaces enviados por correo
+type LinkSigner interface {
+	Sign(link domain.SignedLink) (string, error)
+	// Verify retorna domain.ErrInvalidToken si la firma no es válida, el
+	// enlace expiró o su propósito no es purpose
+	Verify(purpose, token string) (*domain.SignedLink, error)
+}
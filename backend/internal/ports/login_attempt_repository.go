@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+	"time"
+)
+
+// LoginAttemptRepository define las operaciones de persistencia de la auditoría de inicios de sesión
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *domain.LoginAttempt) error
+	// CountFailuresByIP cuenta los fallos por credenciales inválidas desde una IP
+	// desde since y retorna también la fecha del más antiguo
+	CountFailuresByIP(ctx context.Context, ip string, since time.Time) (int64, time.Time, error)
+	ListByUser(ctx context.Context, userID int64, page domain.PageRequest) (*domain.Page[domain.LoginAttempt], error)
+}
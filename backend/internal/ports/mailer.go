@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+)
+
+// Mailer define el envío de correos electrónicos
+type Mailer interface {
+	Send(ctx context.Context, message domain.EmailMessage) error
+}
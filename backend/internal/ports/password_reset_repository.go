@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+)
+
+// PasswordResetTokenRepository define las operaciones de persistencia para tokens de restablecimiento de contraseña
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *domain.PasswordResetToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id int64) error
+	DeleteForUser(ctx context.Context, userID int64) error
+}
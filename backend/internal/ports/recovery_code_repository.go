@@ -0,0 +1,12 @@
+package ports
+
+import "context"
+
+// RecoveryCodeRepository define las operaciones de persistencia para los
+// códigos de recuperación de la autenticación en dos pasos (solo sus hashes)
+type RecoveryCodeRepository interface {
+	ReplaceForUser(ctx context.Context, userID int64, codeHashes []string) error
+	Use(ctx context.Context, userID int64, codeHash string) error
+	CountUnused(ctx context.Context, userID int64) (int, error)
+	DeleteForUser(ctx context.Context, userID int64) error
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+)
+
+// RefreshTokenRepository define las operaciones de persistencia para tokens de refresco
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *domain.RefreshToken) error
+	FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	Revoke(ctx context.Context, id int64) error
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int64) error
+	IsFamilyActive(ctx context.Context, familyID string) (bool, error)
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+)
+
+// RevisionRepository define las operaciones de persistencia para el historial de ediciones
+type RevisionRepository interface {
+	Create(ctx context.Context, revision *domain.Revision) error
+	FindByID(ctx context.Context, id int64) (*domain.Revision, error)
+	FindByEntity(ctx context.Context, entityType domain.RevisionEntity, entityID int64) ([]domain.Revision, error)
+}
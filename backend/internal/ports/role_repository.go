@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+)
+
+// RoleRepository define las operaciones de persistencia para roles y sus permisos
+type RoleRepository interface {
+	Create(ctx context.Context, role *domain.RoleDefinition) error
+	FindByName(ctx context.Context, name domain.Role) (*domain.RoleDefinition, error)
+	List(ctx context.Context) ([]domain.RoleDefinition, error)
+	Update(ctx context.Context, role *domain.RoleDefinition) error
+	Delete(ctx context.Context, name domain.Role) error
+	InUse(ctx context.Context, name domain.Role) (bool, error)
+}
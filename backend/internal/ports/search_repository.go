@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+)
+
+// SearchRepository define las operaciones de búsqueda de texto completo
+type SearchRepository interface {
+	Search(ctx context.Context, query domain.SearchQuery) ([]domain.SearchResult, error)
+}
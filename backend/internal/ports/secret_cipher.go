@@ -0,0 +1,10 @@
+package ports
+
+// SecretCipher cifra los secretos que el servidor tiene que volver a leer,
+// como los secretos TOTP, para que no se guarden en claro en la base de datos
+type SecretCipher interface {
+	Encrypt(plaintext string) (string, error)
+	// Decrypt retorna el secreto original. Los valores manipulados o cifrados
+	// con otra clave retornan error.
+	Decrypt(ciphertext string) (string, error)
+}
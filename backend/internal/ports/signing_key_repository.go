@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+	"time"
+)
+
+// SigningKeyRepository define las operaciones de persistencia para las claves de firma de los tokens
+type SigningKeyRepository interface {
+	Create(ctx context.Context, key *domain.SigningKey) error
+	// ListUsable lista las claves que todavía verifican tokens en now, de la más nueva a la más antigua
+	ListUsable(ctx context.Context, now time.Time) ([]domain.SigningKey, error)
+	// Retire deja de usar una clave para firmar; sigue verificando hasta expiresAt
+	Retire(ctx context.Context, id string, retiredAt, expiresAt time.Time) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"blog-backend/internal/domain"
+	"context"
+)
+
+// TagRepository define las operaciones de persistencia para etiquetas
+type TagRepository interface {
+	FindByID(ctx context.Context, id int64) (*domain.Tag, error)
+	FindBySlug(ctx context.Context, slug string) (*domain.Tag, error)
+	List(ctx context.Context) ([]domain.Tag, error)
+	FindByBlogIDs(ctx context.Context, blogIDs []int64) (map[int64][]domain.Tag, error)
+	SetBlogTags(ctx context.Context, blogID int64, tags []domain.Tag) ([]domain.Tag, error)
+	Rename(ctx context.Context, tag *domain.Tag) error
+	Merge(ctx context.Context, sourceID, targetID int64) error
+}
@@ -0,0 +1,13 @@
+package ports
+
+import "time"
+
+// TOTPProvider genera y valida los códigos de un solo uso basados en tiempo
+// (RFC 6238) de la autenticación en dos pasos
+type TOTPProvider interface {
+	GenerateSecret() (string, error)
+	ProvisioningURI(secret, accountName string) string
+	// Validate retorna el intervalo de tiempo al que corresponde el código si es válido en now
+	Validate(secret, code string, now time.Time) (int64, bool)
+	GenerateRecoveryCode() (string, error)
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+)
+
+// TxManager ejecuta de forma atómica operaciones que abarcan varios repositorios
+type TxManager interface {
+	// WithinTx ejecuta fn en una transacción. Los repositorios que reciben el
+	// contexto de fn participan en ella. Si fn retorna un error la transacción
+	// se revierte y se retorna ese mismo error; si no, se confirma. Dentro de
+	// otra transacción fn se une a la existente.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -1,13 +1,32 @@
 package ports
 
-import "blog-backend/internal/domain"
+import (
+	"blog-backend/internal/domain"
+	"context"
+	"time"
+)
 
 // UserRepository define las operaciones de persistencia para usuarios
 type UserRepository interface {
-	Create(user *domain.User) error
-	FindByUsername(username string) (*domain.User, error)
-	FindByID(id int64) (*domain.User, error)
-	List() ([]domain.User, error)
-	Update(user *domain.User) error
-	Delete(id int64) error
+	// Create retorna domain.ErrUserAlreadyExists con el nombre reservado
+	// domain.DeletedUsername, que solo se crea con CreateDeletedUser
+	Create(ctx context.Context, user *domain.User) error
+	CreateDeletedUser(ctx context.Context) (*domain.User, error)
+	FindByUsername(ctx context.Context, username string) (*domain.User, error)
+	FindByEmail(ctx context.Context, email string) (*domain.User, error)
+	FindByID(ctx context.Context, id int64) (*domain.User, error)
+	List(ctx context.Context) ([]domain.User, error)
+	ExistsByRole(ctx context.Context, role domain.Role) (bool, error)
+	Update(ctx context.Context, user *domain.User) error
+	UpdateLoginState(ctx context.Context, user *domain.User) error
+	// RegisterLoginFailure cuenta de forma atómica un inicio de sesión fallido
+	// según policy y retorna el usuario con el estado resultante
+	RegisterLoginFailure(ctx context.Context, userID int64, now time.Time, policy domain.LockoutPolicy) (*domain.User, error)
+	UpdateEmailVerification(ctx context.Context, user *domain.User) error
+	UpdateTwoFactor(ctx context.Context, user *domain.User) error
+	AdvanceTOTPStep(ctx context.Context, userID, step int64) error
+	Delete(ctx context.Context, id int64) error
+	ListDeleted(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.User], error)
+	Restore(ctx context.Context, id int64) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
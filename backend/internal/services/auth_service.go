@@ -3,55 +3,329 @@ package services
 import (
 	"blog-backend/internal/domain"
 	"blog-backend/internal/ports"
+	"context"
+	"errors"
+	"sync"
+	"time"
 )
 
 // AuthService implementa los casos de uso para autenticación
 type AuthService struct {
-	userRepo   ports.UserRepository
-	authService ports.AuthService
+	userRepo         ports.UserRepository
+	refreshTokenRepo ports.RefreshTokenRepository
+	loginAttemptRepo ports.LoginAttemptRepository
+	authService      ports.AuthService
+	linkSigner       ports.LinkSigner
+	twoFactor        *TwoFactorService
+	refreshTTL       time.Duration
+	challengeTTL     time.Duration
+	lockout          domain.LockoutPolicy
+
+	// dummyHash se compara con las contraseñas de usuarios inexistentes o
+	// bloqueados para que el tiempo de respuesta no revele si existen
+	dummyHashOnce sync.Once
+	dummyHash     string
 }
 
-// NewAuthService crea una nueva instancia del servicio de autenticación
-func NewAuthService(userRepo ports.UserRepository, authService ports.AuthService) *AuthService {
+// NewAuthService crea una nueva instancia del servicio de autenticación.
+// challengeTTL es la validez del desafío del login en dos pasos.
+func NewAuthService(userRepo ports.UserRepository, refreshTokenRepo ports.RefreshTokenRepository, loginAttemptRepo ports.LoginAttemptRepository, authService ports.AuthService, linkSigner ports.LinkSigner, twoFactor *TwoFactorService, refreshTTL, challengeTTL time.Duration, lockout domain.LockoutPolicy) *AuthService {
 	return &AuthService{
-		userRepo:    userRepo,
-		authService: authService,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		authService:      authService,
+		linkSigner:       linkSigner,
+		twoFactor:        twoFactor,
+		refreshTTL:       refreshTTL,
+		challengeTTL:     challengeTTL,
+		lockout:          lockout,
 	}
 }
 
-// Login autentica un usuario y retorna un token JWT
-func (s *AuthService) Login(username, password string) (string, *domain.User, error) {
+// Login autentica un usuario e inicia una nueva sesión. Si el usuario tiene
+// activa la autenticación en dos pasos retorna un desafío que se completa con
+// CompleteTwoFactorLogin. Cada intento queda registrado en la auditoría; tras
+// varios fallos se exige esperar entre intentos y se bloquea temporalmente la
+// cuenta o la IP. Los bloqueos de la IP se retornan como *domain.RetryAfterError;
+// los de la cuenta, como los usuarios inexistentes, se retornan como
+// domain.ErrInvalidCredentials para no revelar qué usuarios existen.
+func (s *AuthService) Login(ctx context.Context, username, password string, client domain.LoginClient) (*domain.LoginResult, error) {
+	now := time.Now()
+
 	// Buscar usuario por username
-	user, err := s.userRepo.FindByUsername(username)
+	user, err := s.checkLoginAllowed(ctx, username, client, now, true, func() (*domain.User, error) {
+		user, err := s.userRepo.FindByUsername(ctx, username)
+		if err != nil || user.IsDeleted() {
+			return nil, domain.ErrInvalidCredentials
+		}
+		return user, nil
+	})
 	if err != nil {
-		return "", nil, domain.ErrInvalidCredentials
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			s.authService.CheckPassword(password, s.loadDummyHash())
+		}
+		return nil, err
 	}
 
 	// Verificar contraseña
 	if !s.authService.CheckPassword(password, user.Password) {
-		return "", nil, domain.ErrInvalidCredentials
+		return nil, s.registerLoginFailure(ctx, user, username, client, now, domain.LoginReasonInvalidCredentials, domain.ErrInvalidCredentials)
+	}
+
+	return s.startSession(ctx, user, username, client, now)
+}
+
+// loadDummyHash calcula una sola vez el hash con el que se igualan los tiempos
+// de respuesta de Login
+func (s *AuthService) loadDummyHash() string {
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = s.authService.HashPassword("contraseña-inexistente")
+	})
+	return s.dummyHash
+}
+
+// LoginWithIdentity inicia una sesión para un usuario autenticado por un
+// proveedor de identidad externo. Como con la contraseña, las cuentas
+// bloqueadas se rechazan y la autenticación en dos pasos exige su desafío.
+func (s *AuthService) LoginWithIdentity(ctx context.Context, user *domain.User, client domain.LoginClient) (*domain.LoginResult, error) {
+	now := time.Now()
+
+	if user.IsLocked(now) {
+		err := &domain.RetryAfterError{Err: domain.ErrAccountLocked, RetryAfter: user.LockedUntil.Sub(now)}
+		return nil, s.rejectLogin(ctx, user, user.Username, client, domain.LoginReasonLocked, err)
+	}
+
+	return s.startSession(ctx, user, user.Username, client, now)
+}
+
+// startSession abre la sesión de un usuario ya autenticado o, si tiene activa
+// la autenticación en dos pasos, emite el desafío del segundo paso
+func (s *AuthService) startSession(ctx context.Context, user *domain.User, username string, client domain.LoginClient, now time.Time) (*domain.LoginResult, error) {
+	if user.TwoFactorEnabled() {
+		challenge, err := s.linkSigner.Sign(domain.SignedLink{
+			Purpose:   domain.LinkPurposeLoginChallenge,
+			UserID:    user.ID,
+			ExpiresAt: now.Add(s.challengeTTL),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &domain.LoginResult{
+			ChallengeToken:     challenge,
+			ChallengeExpiresIn: int64(s.challengeTTL.Seconds()),
+		}, nil
+	}
+
+	return s.completeLogin(ctx, user, username, client)
+}
+
+// CompleteTwoFactorLogin completa el login de un usuario con la autenticación
+// en dos pasos activa con el desafío de Login y un código TOTP o de
+// recuperación. Los códigos erróneos cuentan como fallos de inicio de sesión.
+func (s *AuthService) CompleteTwoFactorLogin(ctx context.Context, challenge, code string, client domain.LoginClient) (*domain.LoginResult, error) {
+	now := time.Now()
+
+	link, err := s.linkSigner.Verify(domain.LinkPurposeLoginChallenge, challenge)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := s.checkLoginAllowed(ctx, "", client, now, false, func() (*domain.User, error) {
+		user, err := s.userRepo.FindByID(ctx, link.UserID)
+		if err != nil || user.IsDeleted() || !user.TwoFactorEnabled() {
+			return nil, domain.ErrInvalidToken
+		}
+		return user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	username := user.Username
+
+	if err := s.twoFactor.VerifyCode(ctx, user, code); err != nil {
+		if err != domain.ErrInvalidTOTPCode {
+			return nil, err
+		}
+		return nil, s.registerLoginFailure(ctx, user, username, client, now, domain.LoginReasonInvalidTwoFactor, err)
+	}
+
+	return s.completeLogin(ctx, user, username, client)
+}
+
+// checkLoginAllowed aplica los bloqueos por IP y por cuenta antes de verificar
+// las credenciales. findUser busca al usuario; si falla, su error se registra
+// como credenciales inválidas. Con conceal los bloqueos de la cuenta se
+// registran con su motivo pero se retornan como domain.ErrInvalidCredentials.
+func (s *AuthService) checkLoginAllowed(ctx context.Context, username string, client domain.LoginClient, now time.Time, conceal bool, findUser func() (*domain.User, error)) (*domain.User, error) {
+	// Bloqueo por IP
+	if err := s.checkIPFailures(ctx, client.IP, now); err != nil {
+		if errors.Is(err, domain.ErrLoginThrottled) {
+			return nil, s.rejectLogin(ctx, nil, username, client, domain.LoginReasonIPBlocked, err)
+		}
+		return nil, err
+	}
+
+	user, err := findUser()
+	if err != nil {
+		return nil, s.rejectLogin(ctx, nil, username, client, domain.LoginReasonInvalidCredentials, err)
+	}
+	if username == "" {
+		username = user.Username
+	}
+
+	// Cuenta bloqueada o fallos recientes que exigen esperar
+	var reason string
+	var accountErr error
+	if user.IsLocked(now) {
+		reason = domain.LoginReasonLocked
+		accountErr = &domain.RetryAfterError{Err: domain.ErrAccountLocked, RetryAfter: user.LockedUntil.Sub(now)}
+	} else if delay := user.LoginDelay(now, s.lockout); delay > 0 {
+		reason = domain.LoginReasonThrottled
+		accountErr = &domain.RetryAfterError{Err: domain.ErrLoginThrottled, RetryAfter: delay}
+	}
+	if accountErr != nil {
+		if conceal {
+			accountErr = domain.ErrInvalidCredentials
+		}
+		return nil, s.rejectLogin(ctx, user, username, client, reason, accountErr)
+	}
+
+	return user, nil
+}
+
+// registerLoginFailure cuenta un fallo de la cuenta, lo registra en la auditoría y retorna err
+func (s *AuthService) registerLoginFailure(ctx context.Context, user *domain.User, username string, client domain.LoginClient, now time.Time, reason string, err error) error {
+	updated, updateErr := s.userRepo.RegisterLoginFailure(ctx, user.ID, now, s.lockout)
+	if updateErr != nil {
+		return updateErr
+	}
+	return s.rejectLogin(ctx, updated, username, client, reason, err)
+}
+
+// completeLogin reinicia los fallos de la cuenta, registra el inicio de sesión
+// correcto y abre una nueva sesión
+func (s *AuthService) completeLogin(ctx context.Context, user *domain.User, username string, client domain.LoginClient) (*domain.LoginResult, error) {
+	if user.FailedLogins > 0 || user.LastFailedLoginAt != nil || user.LockedUntil != nil {
+		user.ResetLoginFailures()
+		if err := s.userRepo.UpdateLoginState(ctx, user); err != nil {
+			return nil, err
+		}
 	}
 
-	// Generar token JWT
-	token, err := s.authService.GenerateToken(user)
+	if err := s.loginAttemptRepo.Create(ctx, domain.NewLoginAttempt(user, username, client, "")); err != nil {
+		return nil, err
+	}
+
+	// Cada login abre una nueva familia de tokens de refresco
+	familyID, err := s.authService.GenerateOpaqueToken()
 	if err != nil {
-		return "", nil, err
+		return nil, err
+	}
+
+	tokens, err := s.issueTokens(ctx, user, familyID)
+	if err != nil {
+		return nil, err
 	}
 
 	// No retornar la contraseña
 	user.Password = ""
-	return token, user, nil
+	return &domain.LoginResult{Tokens: tokens, User: user}, nil
+}
+
+// checkIPFailures retorna domain.ErrLoginThrottled (con el tiempo de espera)
+// si desde la IP se han acumulado demasiados fallos en la ventana de la política
+func (s *AuthService) checkIPFailures(ctx context.Context, ip string, now time.Time) error {
+	if s.lockout.MaxIPFailures <= 0 {
+		return nil
+	}
+
+	failures, oldest, err := s.loginAttemptRepo.CountFailuresByIP(ctx, ip, now.Add(-s.lockout.FailureWindow))
+	if err != nil {
+		return err
+	}
+	if failures < int64(s.lockout.MaxIPFailures) {
+		return nil
+	}
+
+	// La IP se desbloquea cuando el fallo más antiguo sale de la ventana
+	return &domain.RetryAfterError{Err: domain.ErrLoginThrottled, RetryAfter: oldest.Add(s.lockout.FailureWindow).Sub(now)}
+}
+
+// rejectLogin registra un intento de inicio de sesión fallido y retorna err
+func (s *AuthService) rejectLogin(ctx context.Context, user *domain.User, username string, client domain.LoginClient, reason string, err error) error {
+	if recordErr := s.loginAttemptRepo.Create(ctx, domain.NewLoginAttempt(user, username, client, reason)); recordErr != nil {
+		return recordErr
+	}
+	return err
+}
+
+// LoginHistory lista una página del historial de inicios de sesión de un usuario
+func (s *AuthService) LoginHistory(ctx context.Context, userID int64, page domain.PageRequest) (*domain.Page[domain.LoginAttempt], error) {
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return s.loginAttemptRepo.ListByUser(ctx, userID, page)
+}
+
+// Refresh rota un token de refresco y emite un nuevo par de tokens.
+// Si se presenta un token ya rotado se revoca toda la familia.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	stored, err := s.refreshTokenRepo.FindByHash(ctx, s.authService.HashToken(refreshToken))
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, stored.FamilyID)
+	}
+
+	if stored.IsExpired(time.Now()) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if err := s.refreshTokenRepo.Revoke(ctx, stored.ID); err != nil {
+		if err == domain.ErrTokenReused {
+			return nil, s.revokeReusedFamily(ctx, stored.FamilyID)
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	return s.issueTokens(ctx, user, stored.FamilyID)
+}
+
+// Logout revoca la sesión a la que pertenece el token de refresco
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := s.refreshTokenRepo.FindByHash(ctx, s.authService.HashToken(refreshToken))
+	if err != nil {
+		return domain.ErrInvalidToken
+	}
+
+	return s.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID)
 }
 
 // ValidateToken valida un token JWT y retorna el usuario
-func (s *AuthService) ValidateToken(token string) (*domain.User, error) {
-	user, err := s.authService.ValidateToken(token)
+func (s *AuthService) ValidateToken(ctx context.Context, token string) (*domain.User, error) {
+	claims, err := s.authService.ValidateToken(token)
 	if err != nil {
 		return nil, domain.ErrUnauthorized
 	}
 
+	// La sesión debe seguir activa (no revocada por logout o cambio de contraseña)
+	active, err := s.refreshTokenRepo.IsFamilyActive(ctx, claims.SessionID)
+	if err != nil || !active {
+		return nil, domain.ErrUnauthorized
+	}
+
 	// Obtener usuario actualizado de la base de datos
-	freshUser, err := s.userRepo.FindByID(user.ID)
+	freshUser, err := s.userRepo.FindByID(ctx, claims.UserID)
 	if err != nil {
 		return nil, domain.ErrUnauthorized
 	}
@@ -60,9 +334,9 @@ func (s *AuthService) ValidateToken(token string) (*domain.User, error) {
 	return freshUser, nil
 }
 
-// ChangePassword cambia la contraseña de un usuario
-func (s *AuthService) ChangePassword(userID int64, oldPassword, newPassword string) error {
-	user, err := s.userRepo.FindByID(userID)
+// ChangePassword cambia la contraseña de un usuario y cierra todas sus sesiones
+func (s *AuthService) ChangePassword(ctx context.Context, userID int64, oldPassword, newPassword string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return domain.ErrUserNotFound
 	}
@@ -79,5 +353,46 @@ func (s *AuthService) ChangePassword(userID int64, oldPassword, newPassword stri
 	}
 
 	user.Password = hashedPassword
-	return s.userRepo.Update(user)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeAllForUser(ctx, userID)
+}
+
+// issueTokens genera un token de acceso y un token de refresco dentro de la familia indicada
+func (s *AuthService) issueTokens(ctx context.Context, user *domain.User, familyID string) (*domain.TokenPair, error) {
+	refreshToken, err := s.authService.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &domain.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: s.authService.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+	if err := s.refreshTokenRepo.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.authService.GenerateToken(user, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.authService.AccessTokenTTL().Seconds()),
+	}, nil
+}
+
+// revokeReusedFamily revoca la familia de un token reutilizado y retorna ErrTokenReused
+func (s *AuthService) revokeReusedFamily(ctx context.Context, familyID string) error {
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return domain.ErrTokenReused
 }
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// enableTwoFactor activa la autenticación en dos pasos de user con el código
+// TOTP 000001 y retorna sus códigos de recuperación
+func (env *testEnv) enableTwoFactor(t *testing.T, user *domain.User) []string {
+	t.Helper()
+	if _, err := env.twoFactor.Enroll(ctx, user.ID); err != nil {
+		t.Fatalf("Enroll: %v", err)
+	}
+	codes, err := env.twoFactor.Confirm(ctx, user.ID, "000001")
+	if err != nil {
+		t.Fatalf("Confirm: %v", err)
+	}
+	return codes
+}
+
+// login inicia sesión con la contraseña de createUser y retorna los tokens
+func (env *testEnv) login(t *testing.T, username string) *domain.TokenPair {
+	t.Helper()
+	result, err := env.authService.Login(ctx, username, "secreto", domain.LoginClient{IP: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("Login(%q): %v", username, err)
+	}
+	return result.Tokens
+}
+
+func TestAuthServiceLogin(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	deleted := env.createUser(t, "bob", domain.RoleUser)
+	checkErr(t, env.users.Delete(ctx, deleted.ID), nil)
+	env.enableTwoFactor(t, env.createUser(t, "eva", domain.RoleUser))
+
+	tests := []struct {
+		name      string
+		username  string
+		password  string
+		challenge bool
+		reason    string
+		err       error
+	}{
+		{name: "correcto", username: "ana", password: "secreto"},
+		{name: "sin distinguir mayúsculas", username: "ANA", password: "secreto"},
+		{name: "contraseña incorrecta", username: "ana", password: "otra", reason: domain.LoginReasonInvalidCredentials, err: domain.ErrInvalidCredentials},
+		{name: "usuario inexistente", username: "nadie", password: "secreto", reason: domain.LoginReasonInvalidCredentials, err: domain.ErrInvalidCredentials},
+		{name: "usuario en la papelera", username: "bob", password: "secreto", reason: domain.LoginReasonInvalidCredentials, err: domain.ErrInvalidCredentials},
+		{name: "dos pasos", username: "eva", password: "secreto", challenge: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Cada caso usa su propia IP para no acumular fallos
+			client := domain.LoginClient{IP: "10.0.1." + strconv.Itoa(i), UserAgent: "pruebas"}
+			attempts := len(env.loginAttempts.attempts)
+			result, err := env.authService.Login(ctx, tt.username, tt.password, client)
+			checkErr(t, err, tt.err)
+
+			switch {
+			case err != nil:
+				if reasons := env.loginAttempts.reasons(); len(reasons) != attempts+1 || reasons[attempts] != tt.reason {
+					t.Errorf("auditoría = %v, se esperaba registrar %q", reasons, tt.reason)
+				}
+			case tt.challenge:
+				if result.Tokens != nil || result.ChallengeToken == "" || len(env.loginAttempts.attempts) != attempts {
+					t.Errorf("Login = %+v, se esperaba solo un desafío", result)
+				}
+			default:
+				if result.Tokens == nil || result.User.ID != user.ID || result.User.Password != "" {
+					t.Fatalf("Login = %+v", result)
+				}
+				if _, err := env.authService.ValidateToken(ctx, result.Tokens.AccessToken); err != nil {
+					t.Errorf("ValidateToken del nuevo token: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthServiceLoginLockout(t *testing.T) {
+	t.Run("cuenta", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.createUser(t, "ana", domain.RoleUser)
+		client := domain.LoginClient{IP: "10.0.0.1"}
+
+		for i := 0; i < testLockout.MaxAccountFailures; i++ {
+			_, err := env.authService.Login(ctx, "ana", "otra", client)
+			checkErr(t, err, domain.ErrInvalidCredentials)
+		}
+
+		// Con la cuenta bloqueada ni la contraseña correcta sirve, y la
+		// respuesta es la misma que para un usuario inexistente
+		checks := env.auth.checks
+		_, err := env.authService.Login(ctx, "ana", "secreto", client)
+		checkErr(t, err, domain.ErrInvalidCredentials)
+		if domain.RetryAfter(err) != 0 {
+			t.Errorf("RetryAfter = %s, revela el bloqueo", domain.RetryAfter(err))
+		}
+		if env.auth.checks != checks+1 {
+			t.Errorf("se compararon %d contraseñas, se esperaba 1", env.auth.checks-checks)
+		}
+		if reasons := env.loginAttempts.reasons(); reasons[len(reasons)-1] != domain.LoginReasonLocked {
+			t.Errorf("auditoría = %v", reasons)
+		}
+	})
+
+	t.Run("usuario inexistente", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		checks := env.auth.checks
+		_, err := env.authService.Login(ctx, "nadie", "secreto", domain.LoginClient{IP: "10.0.0.1"})
+		checkErr(t, err, domain.ErrInvalidCredentials)
+		if env.auth.checks != checks+1 {
+			t.Errorf("se compararon %d contraseñas, se esperaba 1", env.auth.checks-checks)
+		}
+	})
+
+	t.Run("IP", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.createUser(t, "ana", domain.RoleUser)
+		client := domain.LoginClient{IP: "10.0.0.2"}
+
+		for i := 0; i < testLockout.MaxIPFailures; i++ {
+			_, err := env.authService.Login(ctx, "nadie", "secreto", client)
+			checkErr(t, err, domain.ErrInvalidCredentials)
+		}
+
+		_, err := env.authService.Login(ctx, "ana", "secreto", client)
+		checkErr(t, err, domain.ErrLoginThrottled)
+		if domain.RetryAfter(err) <= 0 {
+			t.Errorf("RetryAfter = %s", domain.RetryAfter(err))
+		}
+		if reasons := env.loginAttempts.reasons(); reasons[len(reasons)-1] != domain.LoginReasonIPBlocked {
+			t.Errorf("auditoría = %v", reasons)
+		}
+
+		// Otras IP no están bloqueadas
+		_, err = env.authService.Login(ctx, "ana", "secreto", domain.LoginClient{IP: "10.0.0.3"})
+		checkErr(t, err, nil)
+	})
+}
+
+func TestAuthServiceLoginWithIdentity(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	twoFactor := env.createUser(t, "eva", domain.RoleUser)
+	env.enableTwoFactor(t, twoFactor)
+	twoFactor, err := env.users.FindByID(ctx, twoFactor.ID)
+	checkErr(t, err, nil)
+	locked := env.createUser(t, "bob", domain.RoleUser)
+	until := time.Now().Add(time.Hour)
+	locked.LockedUntil = &until
+
+	tests := []struct {
+		name      string
+		user      *domain.User
+		challenge bool
+		err       error
+	}{
+		{name: "correcto", user: user},
+		{name: "dos pasos", user: twoFactor, challenge: true},
+		{name: "cuenta bloqueada", user: locked, err: domain.ErrAccountLocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := env.authService.LoginWithIdentity(ctx, tt.user, domain.LoginClient{IP: "10.0.0.1"})
+			checkErr(t, err, tt.err)
+			if err == nil && (result.Tokens == nil) != tt.challenge {
+				t.Errorf("LoginWithIdentity = %+v", result)
+			}
+		})
+	}
+}
+
+func TestAuthServiceCompleteTwoFactorLogin(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	recoveryCodes := env.enableTwoFactor(t, user)
+	plain := env.createUser(t, "bob", domain.RoleUser)
+	client := domain.LoginClient{IP: "10.0.0.1"}
+
+	challenge := func(t *testing.T) string {
+		result, err := env.authService.Login(ctx, "ana", "secreto", client)
+		checkErr(t, err, nil)
+		return result.ChallengeToken
+	}
+	plainChallenge, err := env.links.Sign(domain.SignedLink{Purpose: domain.LinkPurposeLoginChallenge, UserID: plain.ID, ExpiresAt: time.Now().Add(time.Minute)})
+	checkErr(t, err, nil)
+
+	tests := []struct {
+		name      string
+		challenge string
+		code      string
+		err       error
+	}{
+		{name: "código TOTP", code: "000002"},
+		{name: "código TOTP ya usado", code: "000002", err: domain.ErrInvalidTOTPCode},
+		{name: "código de recuperación", code: recoveryCodes[0]},
+		{name: "código de recuperación ya usado", code: recoveryCodes[0], err: domain.ErrInvalidTOTPCode},
+		{name: "código incorrecto", code: "no-existe", err: domain.ErrInvalidTOTPCode},
+		{name: "desafío inválido", challenge: "link-999", code: "000003", err: domain.ErrInvalidToken},
+		{name: "usuario sin dos pasos", challenge: plainChallenge, code: "000003", err: domain.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.challenge == "" {
+				tt.challenge = challenge(t)
+			}
+			result, err := env.authService.CompleteTwoFactorLogin(ctx, tt.challenge, tt.code, client)
+			checkErr(t, err, tt.err)
+			if err == nil && (result.Tokens == nil || result.User.ID != user.ID) {
+				t.Errorf("CompleteTwoFactorLogin = %+v", result)
+			}
+		})
+	}
+
+	// Los códigos incorrectos cuentan como fallos de la cuenta desde el último
+	// inicio de sesión correcto
+	stored, err := env.users.FindByID(ctx, user.ID)
+	checkErr(t, err, nil)
+	if stored.FailedLogins != 2 {
+		t.Errorf("FailedLogins = %d, se esperaban 2", stored.FailedLogins)
+	}
+}
+
+func TestAuthServiceLoginHistory(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	env.createUser(t, "bob", domain.RoleUser)
+	_, err := env.authService.Login(ctx, "ana", "otra", domain.LoginClient{IP: "10.0.0.1"})
+	checkErr(t, err, domain.ErrInvalidCredentials)
+	env.login(t, "ana")
+	env.login(t, "bob")
+
+	page, err := env.authService.LoginHistory(ctx, user.ID, firstPage(domain.SortNewest))
+	checkErr(t, err, nil)
+	if len(page.Items) != 2 || !page.Items[0].Success || page.Items[1].Reason != domain.LoginReasonInvalidCredentials {
+		t.Errorf("LoginHistory = %+v", page.Items)
+	}
+
+	_, err = env.authService.LoginHistory(ctx, 999, firstPage(domain.SortNewest))
+	checkErr(t, err, domain.ErrUserNotFound)
+}
+
+func TestAuthServiceRefresh(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	tokens := env.login(t, "ana")
+
+	rotated, err := env.authService.Refresh(ctx, tokens.RefreshToken)
+	checkErr(t, err, nil)
+	if rotated.RefreshToken == tokens.RefreshToken || rotated.AccessToken == "" || rotated.ExpiresIn != 900 {
+		t.Fatalf("Refresh = %+v", rotated)
+	}
+
+	// Reutilizar un token ya rotado revoca toda la sesión
+	_, err = env.authService.Refresh(ctx, tokens.RefreshToken)
+	checkErr(t, err, domain.ErrTokenReused)
+	_, err = env.authService.Refresh(ctx, rotated.RefreshToken)
+	checkErr(t, err, domain.ErrTokenReused)
+	_, err = env.authService.ValidateToken(ctx, rotated.AccessToken)
+	checkErr(t, err, domain.ErrUnauthorized)
+
+	expired := &domain.RefreshToken{UserID: user.ID, FamilyID: "caducada", TokenHash: env.auth.HashToken("caducado"), ExpiresAt: time.Now().Add(-time.Minute)}
+	checkErr(t, env.refreshTokens.Create(ctx, expired), nil)
+
+	for _, token := range []string{"caducado", "desconocido"} {
+		_, err := env.authService.Refresh(ctx, token)
+		checkErr(t, err, domain.ErrInvalidToken)
+	}
+}
+
+func TestAuthServiceLogout(t *testing.T) {
+	env := newTestEnv(t)
+	env.createUser(t, "ana", domain.RoleUser)
+	session := env.login(t, "ana")
+	other := env.login(t, "ana")
+
+	checkErr(t, env.authService.Logout(ctx, session.RefreshToken), nil)
+	_, err := env.authService.ValidateToken(ctx, session.AccessToken)
+	checkErr(t, err, domain.ErrUnauthorized)
+
+	// Las demás sesiones siguen abiertas
+	_, err = env.authService.ValidateToken(ctx, other.AccessToken)
+	checkErr(t, err, nil)
+
+	checkErr(t, env.authService.Logout(ctx, "desconocido"), domain.ErrInvalidToken)
+}
+
+func TestAuthServiceValidateToken(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	deleted := env.createUser(t, "bob", domain.RoleUser)
+	session := env.login(t, "ana")
+	deletedSession := env.login(t, "bob")
+	checkErr(t, env.users.Delete(ctx, deleted.ID), nil)
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{name: "válido", token: session.AccessToken},
+		{name: "malformado", token: "basura", err: domain.ErrUnauthorized},
+		{name: "sesión desconocida", token: "access:1:otra", err: domain.ErrUnauthorized},
+		{name: "usuario eliminado", token: deletedSession.AccessToken, err: domain.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := env.authService.ValidateToken(ctx, tt.token)
+			checkErr(t, err, tt.err)
+			if err == nil && (found.ID != user.ID || found.Password != "") {
+				t.Errorf("ValidateToken = %+v", found)
+			}
+		})
+	}
+}
+
+func TestAuthServiceChangePassword(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	session := env.login(t, "ana")
+
+	tests := []struct {
+		name   string
+		userID int64
+		old    string
+		err    error
+	}{
+		{name: "contraseña actual incorrecta", userID: user.ID, old: "otra", err: domain.ErrInvalidCredentials},
+		{name: "usuario inexistente", userID: 999, old: "secreto", err: domain.ErrUserNotFound},
+		{name: "correcto", userID: user.ID, old: "secreto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErr(t, env.authService.ChangePassword(ctx, tt.userID, tt.old, "nueva"), tt.err)
+		})
+	}
+
+	// Cambiar la contraseña cierra todas las sesiones
+	_, err := env.authService.ValidateToken(ctx, session.AccessToken)
+	checkErr(t, err, domain.ErrUnauthorized)
+	_, err = env.authService.Login(ctx, "ana", "secreto", domain.LoginClient{IP: "10.0.0.1"})
+	checkErr(t, err, domain.ErrInvalidCredentials)
+	_, err = env.authService.Login(ctx, "ana", "nueva", domain.LoginClient{IP: "10.0.0.1"})
+	checkErr(t, err, nil)
+}
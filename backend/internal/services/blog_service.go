@@ -3,101 +3,484 @@ package services
 import (
 	"blog-backend/internal/domain"
 	"blog-backend/internal/ports"
+	"context"
+	"time"
 )
 
 // BlogService implementa los casos de uso para gestión de blogs
 type BlogService struct {
-	blogRepo   ports.BlogRepository
-	userRepo   ports.UserRepository
+	blogRepo     ports.BlogRepository
+	userRepo     ports.UserRepository
+	revisionRepo ports.RevisionRepository
+	tagRepo      ports.TagRepository
+	commentRepo  ports.CommentRepository
+	authorizer   ports.Authorizer
+	txManager    ports.TxManager
+	// deletionPolicy indica qué ocurre con los comentarios al eliminar un blog
+	deletionPolicy domain.DeletionPolicy
 }
 
 // NewBlogService crea una nueva instancia del servicio de blog
-func NewBlogService(blogRepo ports.BlogRepository, userRepo ports.UserRepository) *BlogService {
+func NewBlogService(blogRepo ports.BlogRepository, userRepo ports.UserRepository, revisionRepo ports.RevisionRepository, tagRepo ports.TagRepository, commentRepo ports.CommentRepository, authorizer ports.Authorizer, txManager ports.TxManager, deletionPolicy domain.DeletionPolicy) *BlogService {
 	return &BlogService{
-		blogRepo: blogRepo,
-		userRepo: userRepo,
+		blogRepo:       blogRepo,
+		userRepo:       userRepo,
+		revisionRepo:   revisionRepo,
+		tagRepo:        tagRepo,
+		commentRepo:    commentRepo,
+		authorizer:     authorizer,
+		txManager:      txManager,
+		deletionPolicy: deletionPolicy,
 	}
 }
 
-// CreateBlog crea un nuevo blog
-func (s *BlogService) CreateBlog(title, content string, authorID int64) (*domain.Blog, error) {
+// CreateBlog crea un nuevo blog con el estado indicado. Si no se indica se
+// publica cuando el autor tiene permiso para publicar y si no queda como borrador.
+// El blog, sus etiquetas y su primera revisión se guardan en una transacción.
+func (s *BlogService) CreateBlog(ctx context.Context, title, content string, authorID int64, status domain.BlogStatus, publishAt *time.Time, tagNames []string) (*domain.Blog, error) {
 	// Verificar que el autor existe
-	_, err := s.userRepo.FindByID(authorID)
+	author, err := s.userRepo.FindByID(ctx, authorID)
 	if err != nil {
 		return nil, domain.ErrUserNotFound
 	}
 
+	if err := s.authorizer.Authorize(ctx, author.Role, domain.PermBlogCreate); err != nil {
+		return nil, err
+	}
+
+	if status == "" {
+		status = domain.BlogStatusPublished
+		if err := s.authorizer.Authorize(ctx, author.Role, domain.PermBlogPublish); err == domain.ErrForbidden {
+			status = domain.BlogStatusDraft
+		}
+	}
+	if err := s.authorizeStatus(ctx, status, author.Role); err != nil {
+		return nil, err
+	}
+
+	tags, err := domain.NewTags(tagNames)
+	if err != nil {
+		return nil, err
+	}
+
 	blog := &domain.Blog{
 		Title:    title,
 		Content:  content,
 		AuthorID: authorID,
 	}
 
-	if err := s.blogRepo.Create(blog); err != nil {
+	if err := blog.SetStatus(status, publishAt, time.Now()); err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.blogRepo.Create(ctx, blog); err != nil {
+			return err
+		}
+
+		var err error
+		if blog.Tags, err = s.tagRepo.SetBlogTags(ctx, blog.ID, tags); err != nil {
+			return err
+		}
+
+		return s.recordRevision(ctx, blog, authorID)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return blog, nil
 }
 
-// GetBlogByID obtiene un blog por su ID
-func (s *BlogService) GetBlogByID(id int64) (*domain.Blog, error) {
-	blog, err := s.blogRepo.FindByID(id)
+// GetBlogByID obtiene un blog por su ID. Los blogs no publicados solo son visibles
+// para su autor y quien tenga el permiso blog:read_unpublished (userID 0 para
+// visitantes anónimos).
+func (s *BlogService) GetBlogByID(ctx context.Context, id int64, userID int64, userRole domain.Role) (*domain.Blog, error) {
+	blog, err := s.blogRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, domain.ErrBlogNotFound
 	}
+
+	if err := authorizeBlogView(ctx, s.authorizer, blog, userID, userRole); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachTags(ctx, blog); err != nil {
+		return nil, err
+	}
+
 	return blog, nil
 }
 
-// GetBlogsByAuthor obtiene todos los blogs de un autor
-func (s *BlogService) GetBlogsByAuthor(authorID int64) ([]domain.Blog, error) {
+// GetBlogsByAuthor obtiene una página de blogs de un autor. El propio autor y quien
+// tenga el permiso blog:read_unpublished también ven los borradores, programados y archivados.
+func (s *BlogService) GetBlogsByAuthor(ctx context.Context, authorID int64, page domain.PageRequest, userID int64, userRole domain.Role) (*domain.Page[domain.Blog], error) {
 	// Verificar que el autor existe
-	_, err := s.userRepo.FindByID(authorID)
+	_, err := s.userRepo.FindByID(ctx, authorID)
 	if err != nil {
 		return nil, domain.ErrUserNotFound
 	}
 
-	return s.blogRepo.FindByAuthorID(authorID)
-}
+	filter := domain.BlogFilter{PublishedOnly: true}
+	switch err := s.authorizer.AuthorizeOwner(ctx, userID, userRole, authorID, domain.PermBlogReadUnpublished); err {
+	case nil:
+		filter.PublishedOnly = false
+	case domain.ErrForbidden:
+	default:
+		return nil, err
+	}
+
+	blogs, err := s.blogRepo.FindByAuthorID(ctx, authorID, filter, page)
+	if err != nil {
+		return nil, err
+	}
 
-// ListBlogs lista todos los blogs
-func (s *BlogService) ListBlogs() ([]domain.Blog, error) {
-	return s.blogRepo.List()
+	if err := s.attachPageTags(ctx, blogs); err != nil {
+		return nil, err
+	}
+
+	return blogs, nil
 }
 
-// UpdateBlog actualiza un blog existente
-func (s *BlogService) UpdateBlog(id int64, title, content string, userID int64, userRole domain.Role) (*domain.Blog, error) {
-	blog, err := s.blogRepo.FindByID(id)
+// ListBlogs lista una página de blogs publicados, opcionalmente con una etiqueta
+func (s *BlogService) ListBlogs(ctx context.Context, page domain.PageRequest, tag string) (*domain.Page[domain.Blog], error) {
+	filter := domain.BlogFilter{PublishedOnly: true}
+	if tag != "" {
+		filter.Tag = domain.TagSlug(tag)
+		if filter.Tag == "" {
+			return nil, domain.ErrInvalidTag
+		}
+	}
+
+	blogs, err := s.blogRepo.List(ctx, filter, page)
 	if err != nil {
-		return nil, domain.ErrBlogNotFound
+		return nil, err
 	}
 
-	// Verificar permisos: solo el autor o un administrador puede editar
-	if blog.AuthorID != userID && userRole != domain.RoleAdmin {
-		return nil, domain.ErrForbidden
+	if err := s.attachPageTags(ctx, blogs); err != nil {
+		return nil, err
 	}
 
+	return blogs, nil
+}
+
+// UpdateBlog actualiza un blog existente. Si tagNames es nil se conservan las
+// etiquetas actuales; una lista vacía las elimina todas. El blog se bloquea y
+// las etiquetas, el contenido y la revisión se guardan en una transacción.
+func (s *BlogService) UpdateBlog(ctx context.Context, id int64, title, content string, tagNames []string, userID int64, userRole domain.Role) (*domain.Blog, error) {
+	var updated *domain.Blog
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		blog, err := s.lockEditableBlog(ctx, id, userID, userRole)
+		if err != nil {
+			return err
+		}
+
+		if tagNames != nil {
+			tags, err := domain.NewTags(tagNames)
+			if err != nil {
+				return err
+			}
+			if _, err := s.tagRepo.SetBlogTags(ctx, blog.ID, tags); err != nil {
+				return err
+			}
+		}
+
+		updated, err = s.applyEdit(ctx, blog, title, content, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// applyEdit guarda el nuevo título y contenido y registra la revisión
+// correspondiente. Debe llamarse dentro de una transacción con el blog bloqueado.
+func (s *BlogService) applyEdit(ctx context.Context, blog *domain.Blog, title, content string, editorID int64) (*domain.Blog, error) {
 	blog.Title = title
 	blog.Content = content
 
-	if err := s.blogRepo.Update(blog); err != nil {
+	if err := s.blogRepo.Update(ctx, blog); err != nil {
+		return nil, err
+	}
+	if err := s.recordRevision(ctx, blog, editorID); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachTags(ctx, blog); err != nil {
 		return nil, err
 	}
 
 	return blog, nil
 }
 
-// DeleteBlog elimina un blog
-func (s *BlogService) DeleteBlog(id int64, userID int64, userRole domain.Role) error {
-	blog, err := s.blogRepo.FindByID(id)
+// attachTags carga las etiquetas de los blogs indicados
+func (s *BlogService) attachTags(ctx context.Context, blogs ...*domain.Blog) error {
+	ids := make([]int64, len(blogs))
+	for i, blog := range blogs {
+		ids[i] = blog.ID
+	}
+
+	tags, err := s.tagRepo.FindByBlogIDs(ctx, ids)
 	if err != nil {
-		return domain.ErrBlogNotFound
+		return err
+	}
+
+	for _, blog := range blogs {
+		blog.Tags = tags[blog.ID]
+		if blog.Tags == nil {
+			blog.Tags = []domain.Tag{}
+		}
+	}
+	return nil
+}
+
+// attachPageTags carga las etiquetas de una página de blogs
+func (s *BlogService) attachPageTags(ctx context.Context, page *domain.Page[domain.Blog]) error {
+	blogs := make([]*domain.Blog, len(page.Items))
+	for i := range page.Items {
+		blogs[i] = &page.Items[i]
+	}
+	return s.attachTags(ctx, blogs...)
+}
+
+// recordRevision guarda una instantánea del contenido actual del blog
+func (s *BlogService) recordRevision(ctx context.Context, blog *domain.Blog, editorID int64) error {
+	return s.revisionRepo.Create(ctx, &domain.Revision{
+		EntityType: domain.RevisionEntityBlog,
+		EntityID:   blog.ID,
+		EditorID:   editorID,
+		Title:      blog.Title,
+		Content:    blog.Content,
+	})
+}
+
+// ListRevisions obtiene el historial de ediciones de un blog, de la más reciente
+// a la más antigua (el autor o quien tenga el permiso blog:edit)
+func (s *BlogService) ListRevisions(ctx context.Context, blogID int64, userID int64, userRole domain.Role) ([]domain.Revision, error) {
+	if _, err := s.editableBlog(ctx, blogID, userID, userRole); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.revisionRepo.FindByEntity(ctx, domain.RevisionEntityBlog, blogID)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		revisions = []domain.Revision{}
 	}
 
-	// Verificar permisos: solo el autor o un administrador puede eliminar
-	if blog.AuthorID != userID && userRole != domain.RoleAdmin {
-		return domain.ErrForbidden
+	return revisions, nil
+}
+
+// DiffRevisions compara dos revisiones de un blog
+func (s *BlogService) DiffRevisions(ctx context.Context, blogID, fromID, toID int64, userID int64, userRole domain.Role) (*domain.RevisionDiff, error) {
+	if _, err := s.editableBlog(ctx, blogID, userID, userRole); err != nil {
+		return nil, err
 	}
 
-	return s.blogRepo.Delete(id)
+	from, err := s.blogRevision(ctx, blogID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.blogRevision(ctx, blogID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewRevisionDiff(from, to), nil
+}
+
+// RestoreRevision restaura el título y contenido de una revisión anterior.
+// La restauración se registra como una nueva revisión.
+func (s *BlogService) RestoreRevision(ctx context.Context, blogID, revisionID int64, userID int64, userRole domain.Role) (*domain.Blog, error) {
+	var restored *domain.Blog
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		blog, err := s.lockEditableBlog(ctx, blogID, userID, userRole)
+		if err != nil {
+			return err
+		}
+
+		revision, err := s.blogRevision(ctx, blogID, revisionID)
+		if err != nil {
+			return err
+		}
+
+		restored, err = s.applyEdit(ctx, blog, revision.Title, revision.Content, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// editableBlog obtiene un blog verificando que el usuario sea su autor o tenga el permiso blog:edit
+func (s *BlogService) editableBlog(ctx context.Context, blogID int64, userID int64, userRole domain.Role) (*domain.Blog, error) {
+	blog, err := s.blogRepo.FindByID(ctx, blogID)
+	if err != nil {
+		return nil, domain.ErrBlogNotFound
+	}
+
+	if err := s.authorizer.AuthorizeOwner(ctx, userID, userRole, blog.AuthorID, domain.PermBlogEdit); err != nil {
+		return nil, err
+	}
+
+	return blog, nil
+}
+
+// lockEditableBlog es como editableBlog, pero bloquea el blog hasta el final de
+// la transacción para que otra escritura no se pierda al guardarlo entero
+func (s *BlogService) lockEditableBlog(ctx context.Context, blogID int64, userID int64, userRole domain.Role) (*domain.Blog, error) {
+	blog, err := s.blogRepo.FindByIDForUpdate(ctx, blogID)
+	if err != nil {
+		return nil, domain.ErrBlogNotFound
+	}
+
+	if err := s.authorizer.AuthorizeOwner(ctx, userID, userRole, blog.AuthorID, domain.PermBlogEdit); err != nil {
+		return nil, err
+	}
+
+	return blog, nil
+}
+
+// blogRevision obtiene una revisión verificando que pertenezca al blog indicado
+func (s *BlogService) blogRevision(ctx context.Context, blogID, revisionID int64) (*domain.Revision, error) {
+	revision, err := s.revisionRepo.FindByID(ctx, revisionID)
+	if err != nil {
+		return nil, domain.ErrRevisionNotFound
+	}
+
+	if revision.EntityType != domain.RevisionEntityBlog || revision.EntityID != blogID {
+		return nil, domain.ErrRevisionNotFound
+	}
+
+	return revision, nil
+}
+
+// ChangeStatus cambia el estado de publicación de un blog. El blog se bloquea
+// mientras cambia para no deshacer una edición simultánea.
+func (s *BlogService) ChangeStatus(ctx context.Context, id int64, status domain.BlogStatus, publishAt *time.Time, userID int64, userRole domain.Role) (*domain.Blog, error) {
+	var blog *domain.Blog
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Verificar permisos: el autor o quien tenga el permiso blog:edit; para
+		// publicar o programar además hace falta blog:publish
+		var err error
+		blog, err = s.lockEditableBlog(ctx, id, userID, userRole)
+		if err != nil {
+			return err
+		}
+		if err := s.authorizeStatus(ctx, status, userRole); err != nil {
+			return err
+		}
+
+		if err := blog.SetStatus(status, publishAt, time.Now()); err != nil {
+			return err
+		}
+
+		return s.blogRepo.Update(ctx, blog)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachTags(ctx, blog); err != nil {
+		return nil, err
+	}
+
+	return blog, nil
+}
+
+// SetCommentModeration activa o desactiva la moderación previa de los comentarios de un blog
+func (s *BlogService) SetCommentModeration(ctx context.Context, id int64, enabled bool, userID int64, userRole domain.Role) (*domain.Blog, error) {
+	var blog *domain.Blog
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		blog, err = s.lockEditableBlog(ctx, id, userID, userRole)
+		if err != nil {
+			return err
+		}
+
+		blog.CommentModeration = enabled
+		return s.blogRepo.Update(ctx, blog)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachTags(ctx, blog); err != nil {
+		return nil, err
+	}
+
+	return blog, nil
+}
+
+// PublishScheduled publica los blogs programados cuya fecha ya llegó
+func (s *BlogService) PublishScheduled(ctx context.Context) (int64, error) {
+	return s.blogRepo.PublishDue(ctx, time.Now())
+}
+
+// DeleteBlog mueve un blog a la papelera. Con la política DeletionCascade sus
+// comentarios lo acompañan y se restauran con él; con DeletionBlock no se
+// elimina mientras tenga comentarios.
+func (s *BlogService) DeleteBlog(ctx context.Context, id int64, userID int64, userRole domain.Role) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// El bloqueo impide que se comente el blog mientras se elimina
+		blog, err := s.blogRepo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return domain.ErrBlogNotFound
+		}
+
+		// Verificar permisos: el autor o quien tenga el permiso blog:delete
+		if err := s.authorizer.AuthorizeOwner(ctx, userID, userRole, blog.AuthorID, domain.PermBlogDelete); err != nil {
+			return err
+		}
+
+		return deleteBlog(ctx, s.blogRepo, s.commentRepo, id, s.deletionPolicy)
+	})
+}
+
+// deleteBlog mueve un blog y sus comentarios a la papelera. Con DeletionBlock
+// retorna domain.ErrBlogHasComments si el blog tiene comentarios.
+func deleteBlog(ctx context.Context, blogRepo ports.BlogRepository, commentRepo ports.CommentRepository, id int64, policy domain.DeletionPolicy) error {
+	if policy == domain.DeletionBlock {
+		exists, err := commentRepo.ExistsByBlog(ctx, id)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrBlogHasComments
+		}
+	}
+
+	if err := blogRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return commentRepo.DeleteByBlog(ctx, id)
+}
+
+// authorizeStatus verifica que el rol pueda dejar un blog en el estado indicado.
+// Publicar o programar requiere el permiso blog:publish.
+func (s *BlogService) authorizeStatus(ctx context.Context, status domain.BlogStatus, role domain.Role) error {
+	if status != domain.BlogStatusPublished && status != domain.BlogStatusScheduled {
+		return nil
+	}
+	return s.authorizer.Authorize(ctx, role, domain.PermBlogPublish)
+}
+
+// authorizeBlogView verifica que un usuario pueda ver un blog. Los blogs no públicos
+// solo los ven su autor y quien tenga el permiso blog:read_unpublished; para el
+// resto se responde como si el blog no existiera.
+func authorizeBlogView(ctx context.Context, authorizer ports.Authorizer, blog *domain.Blog, userID int64, userRole domain.Role) error {
+	if blog.IsPublic(time.Now()) {
+		return nil
+	}
+
+	err := authorizer.AuthorizeOwner(ctx, userID, userRole, blog.AuthorID, domain.PermBlogReadUnpublished)
+	if err == domain.ErrForbidden {
+		return domain.ErrBlogNotFound
+	}
+	return err
 }
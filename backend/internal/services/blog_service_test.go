@@ -0,0 +1,556 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlogServiceCreateBlog(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	reader := env.createUser(t, "lector", roleLector)
+	later := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		authorID  int64
+		status    domain.BlogStatus
+		publishAt *time.Time
+		tags      []string
+		want      domain.BlogStatus
+		err       error
+	}{
+		{name: "publicado por defecto", authorID: author.ID, want: domain.BlogStatusPublished},
+		{name: "borrador", authorID: author.ID, status: domain.BlogStatusDraft, want: domain.BlogStatusDraft},
+		{name: "programado", authorID: author.ID, status: domain.BlogStatusScheduled, publishAt: &later, want: domain.BlogStatusScheduled},
+		{name: "programado en el pasado", authorID: author.ID, status: domain.BlogStatusScheduled, publishAt: &past, err: domain.ErrInvalidSchedule},
+		{name: "estado inválido", authorID: author.ID, status: "oculto", err: domain.ErrInvalidStatus},
+		{name: "con etiquetas", authorID: author.ID, tags: []string{"Go", "go", "Bases de datos"}, want: domain.BlogStatusPublished},
+		{name: "etiqueta inválida", authorID: author.ID, tags: []string{"  "}, err: domain.ErrInvalidTag},
+		{name: "autor inexistente", authorID: 999, err: domain.ErrUserNotFound},
+		{name: "sin permiso", authorID: reader.ID, err: domain.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blog, err := env.blogService.CreateBlog(ctx, "Título", "Contenido", tt.authorID, tt.status, tt.publishAt, tt.tags)
+			checkErr(t, err, tt.err)
+			if tt.err != nil {
+				return
+			}
+			if blog.Status != tt.want {
+				t.Errorf("Status = %s, se esperaba %s", blog.Status, tt.want)
+			}
+			if tags, _ := domain.NewTags(tt.tags); len(blog.Tags) != len(tags) {
+				t.Errorf("Tags = %+v, se esperaban %v", blog.Tags, tt.tags)
+			}
+			if revisions, _ := env.revisions.FindByEntity(ctx, domain.RevisionEntityBlog, blog.ID); len(revisions) != 1 {
+				t.Errorf("se registraron %d revisiones, se esperaba 1", len(revisions))
+			}
+		})
+	}
+}
+
+func TestBlogServiceCreateBlogDraftWithoutPublishPermission(t *testing.T) {
+	env := newTestEnv(t)
+	roles := env.userService.roleRepo
+	if err := roles.Create(ctx, &domain.RoleDefinition{Name: "Redactor", Permissions: []domain.Permission{domain.PermBlogCreate}}); err != nil {
+		t.Fatal(err)
+	}
+	writer := env.createUser(t, "redactor", "Redactor")
+
+	blog, err := env.blogService.CreateBlog(ctx, "Título", "Contenido", writer.ID, "", nil, nil)
+	checkErr(t, err, nil)
+	if blog.Status != domain.BlogStatusDraft {
+		t.Errorf("Status = %s, se esperaba %s", blog.Status, domain.BlogStatusDraft)
+	}
+
+	_, err = env.blogService.CreateBlog(ctx, "Título", "Contenido", writer.ID, domain.BlogStatusPublished, nil, nil)
+	checkErr(t, err, domain.ErrForbidden)
+}
+
+func TestBlogServiceGetBlogByID(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	other := env.createUser(t, "bob", domain.RoleUser)
+	published := env.createBlog(t, author, domain.BlogStatusPublished)
+	draft := env.createBlog(t, author, domain.BlogStatusDraft)
+
+	tests := []struct {
+		name   string
+		blogID int64
+		userID int64
+		role   domain.Role
+		err    error
+	}{
+		{name: "publicado anónimo", blogID: published.ID},
+		{name: "borrador del autor", blogID: draft.ID, userID: author.ID, role: domain.RoleUser},
+		{name: "borrador para un editor", blogID: draft.ID, userID: 999, role: domain.RoleEditor},
+		{name: "borrador anónimo", blogID: draft.ID, err: domain.ErrBlogNotFound},
+		{name: "borrador ajeno", blogID: draft.ID, userID: other.ID, role: domain.RoleUser, err: domain.ErrBlogNotFound},
+		{name: "inexistente", blogID: 999, err: domain.ErrBlogNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blog, err := env.blogService.GetBlogByID(ctx, tt.blogID, tt.userID, tt.role)
+			checkErr(t, err, tt.err)
+			if err == nil && (blog.ID != tt.blogID || blog.Tags == nil) {
+				t.Errorf("GetBlogByID = %+v", blog)
+			}
+		})
+	}
+}
+
+func TestBlogServiceGetBlogsByAuthor(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	other := env.createUser(t, "bob", domain.RoleUser)
+	env.createBlog(t, author, domain.BlogStatusPublished)
+	env.createBlog(t, author, domain.BlogStatusDraft)
+	env.createBlog(t, author, domain.BlogStatusScheduled)
+
+	tests := []struct {
+		name     string
+		authorID int64
+		userID   int64
+		role     domain.Role
+		want     int
+		err      error
+	}{
+		{name: "anónimo", authorID: author.ID, want: 1},
+		{name: "otro usuario", authorID: author.ID, userID: other.ID, role: domain.RoleUser, want: 1},
+		{name: "el autor", authorID: author.ID, userID: author.ID, role: domain.RoleUser, want: 3},
+		{name: "editor", authorID: author.ID, userID: other.ID, role: domain.RoleEditor, want: 3},
+		{name: "autor sin blogs", authorID: other.ID, want: 0},
+		{name: "autor inexistente", authorID: 999, err: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := env.blogService.GetBlogsByAuthor(ctx, tt.authorID, firstPage(domain.SortNewest), tt.userID, tt.role)
+			checkErr(t, err, tt.err)
+			if err == nil && len(page.Items) != tt.want {
+				t.Errorf("se obtuvieron %d blogs, se esperaban %d", len(page.Items), tt.want)
+			}
+		})
+	}
+}
+
+func TestBlogServiceListBlogs(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	tagged, err := env.blogService.CreateBlog(ctx, "Título", "Contenido", author.ID, "", nil, []string{"Programación"})
+	checkErr(t, err, nil)
+	env.createBlog(t, author, domain.BlogStatusPublished)
+	env.createBlog(t, author, domain.BlogStatusDraft)
+
+	tests := []struct {
+		name string
+		tag  string
+		want int
+		err  error
+	}{
+		{name: "todos los publicados", want: 2},
+		{name: "por etiqueta", tag: "programacion", want: 1},
+		{name: "etiqueta por nombre", tag: "Programación", want: 1},
+		{name: "etiqueta sin blogs", tag: "rust", want: 0},
+		{name: "etiqueta inválida", tag: "¡¿", err: domain.ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := env.blogService.ListBlogs(ctx, firstPage(domain.SortNewest), tt.tag)
+			checkErr(t, err, tt.err)
+			if err != nil {
+				return
+			}
+			if len(page.Items) != tt.want {
+				t.Fatalf("se obtuvieron %d blogs, se esperaban %d", len(page.Items), tt.want)
+			}
+			if tt.tag != "" && tt.want == 1 && (page.Items[0].ID != tagged.ID || len(page.Items[0].Tags) != 1) {
+				t.Errorf("ListBlogs = %+v", page.Items)
+			}
+		})
+	}
+}
+
+func TestBlogServiceUpdateBlog(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	other := env.createUser(t, "bob", domain.RoleUser)
+	blog, err := env.blogService.CreateBlog(ctx, "Título", "Contenido", author.ID, "", nil, []string{"Go"})
+	checkErr(t, err, nil)
+
+	tests := []struct {
+		name   string
+		blogID int64
+		tags   []string
+		userID int64
+		role   domain.Role
+		want   int
+		err    error
+	}{
+		{name: "el autor conserva las etiquetas", blogID: blog.ID, userID: author.ID, role: domain.RoleUser, want: 1},
+		{name: "el autor cambia las etiquetas", blogID: blog.ID, tags: []string{"Go", "SQL"}, userID: author.ID, role: domain.RoleUser, want: 2},
+		{name: "un editor quita las etiquetas", blogID: blog.ID, tags: []string{}, userID: other.ID, role: domain.RoleEditor, want: 0},
+		{name: "etiqueta inválida", blogID: blog.ID, tags: []string{""}, userID: author.ID, role: domain.RoleUser, err: domain.ErrInvalidTag},
+		{name: "otro usuario", blogID: blog.ID, userID: other.ID, role: domain.RoleUser, err: domain.ErrForbidden},
+		{name: "inexistente", blogID: 999, userID: author.ID, role: domain.RoleUser, err: domain.ErrBlogNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := env.blogService.UpdateBlog(ctx, tt.blogID, "Nuevo", tt.name, tt.tags, tt.userID, tt.role)
+			checkErr(t, err, tt.err)
+			if err != nil {
+				return
+			}
+			if updated.Title != "Nuevo" || updated.Content != tt.name || len(updated.Tags) != tt.want {
+				t.Errorf("UpdateBlog = %+v", updated)
+			}
+		})
+	}
+
+	revisions, err := env.blogService.ListRevisions(ctx, blog.ID, author.ID, domain.RoleUser)
+	checkErr(t, err, nil)
+	if len(revisions) != 4 {
+		t.Errorf("se registraron %d revisiones, se esperaban 4", len(revisions))
+	}
+}
+
+func TestBlogServiceWritesRollback(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	blog, err := env.blogService.CreateBlog(ctx, "Título", "Contenido", author.ID, "", nil, []string{"Go"})
+	checkErr(t, err, nil)
+
+	// Si falla la revisión no se guarda nada de la escritura
+	failure := errors.New("fallo")
+	env.revisions.err = failure
+
+	_, err = env.blogService.CreateBlog(ctx, "Otro", "Contenido", author.ID, "", nil, []string{"SQL"})
+	checkErr(t, err, failure)
+	page, err := env.blogService.GetBlogsByAuthor(ctx, author.ID, firstPage(domain.SortNewest), author.ID, domain.RoleUser)
+	checkErr(t, err, nil)
+	if len(page.Items) != 1 {
+		t.Errorf("hay %d blogs, se esperaba 1", len(page.Items))
+	}
+
+	_, err = env.blogService.UpdateBlog(ctx, blog.ID, "Nuevo", "Nuevo", []string{"SQL"}, author.ID, domain.RoleUser)
+	checkErr(t, err, failure)
+	env.revisions.err = nil
+	found, err := env.blogService.GetBlogByID(ctx, blog.ID, author.ID, domain.RoleUser)
+	checkErr(t, err, nil)
+	if found.Title != "Título" || len(found.Tags) != 1 || found.Tags[0].Name != "Go" {
+		t.Errorf("el blog cambió aunque la transacción falló: %+v", found)
+	}
+}
+
+// slowBlogRepo tarda en retornar los blogs leídos para que las escrituras
+// simultáneas se intercalen entre la lectura y el guardado
+type slowBlogRepo struct {
+	ports.BlogRepository
+}
+
+func (r slowBlogRepo) FindByID(ctx context.Context, id int64) (*domain.Blog, error) {
+	blog, err := r.BlogRepository.FindByID(ctx, id)
+	time.Sleep(time.Millisecond)
+	return blog, err
+}
+
+func (r slowBlogRepo) FindByIDForUpdate(ctx context.Context, id int64) (*domain.Blog, error) {
+	blog, err := r.BlogRepository.FindByIDForUpdate(ctx, id)
+	time.Sleep(time.Millisecond)
+	return blog, err
+}
+
+func TestBlogServiceConcurrentWrites(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	env.blogService.blogRepo = slowBlogRepo{BlogRepository: env.blogs}
+
+	// Una edición y un cambio de estado simultáneos no se pisan
+	for i := 0; i < 5; i++ {
+		blog := env.createBlog(t, author, domain.BlogStatusDraft)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 3)
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			_, errs[0] = env.blogService.UpdateBlog(ctx, blog.ID, "Editado", "Nuevo", nil, author.ID, domain.RoleUser)
+		}()
+		go func() {
+			defer wg.Done()
+			_, errs[1] = env.blogService.ChangeStatus(ctx, blog.ID, domain.BlogStatusPublished, nil, author.ID, domain.RoleUser)
+		}()
+		go func() {
+			defer wg.Done()
+			_, errs[2] = env.blogService.SetCommentModeration(ctx, blog.ID, true, author.ID, domain.RoleUser)
+		}()
+		wg.Wait()
+		for _, err := range errs {
+			checkErr(t, err, nil)
+		}
+
+		found, err := env.blogs.FindByID(ctx, blog.ID)
+		checkErr(t, err, nil)
+		if found.Title != "Editado" || found.Status != domain.BlogStatusPublished || !found.CommentModeration {
+			t.Fatalf("blog = %+v, se esperaban los tres cambios", found)
+		}
+	}
+}
+
+func TestBlogServiceRevisions(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	other := env.createUser(t, "bob", domain.RoleUser)
+	blog, err := env.blogService.CreateBlog(ctx, "Primero", "uno\ndos", author.ID, "", nil, nil)
+	checkErr(t, err, nil)
+	_, err = env.blogService.UpdateBlog(ctx, blog.ID, "Segundo", "uno\ntres", nil, author.ID, domain.RoleUser)
+	checkErr(t, err, nil)
+	otherBlog := env.createBlog(t, author, domain.BlogStatusPublished)
+	foreign := &domain.Revision{EntityType: domain.RevisionEntityBlog, EntityID: otherBlog.ID, Title: "ajeno"}
+	checkErr(t, env.revisions.Create(ctx, foreign), nil)
+
+	revisions, err := env.blogService.ListRevisions(ctx, blog.ID, author.ID, domain.RoleUser)
+	checkErr(t, err, nil)
+	if len(revisions) != 2 || revisions[0].Title != "Segundo" || revisions[1].Title != "Primero" {
+		t.Fatalf("ListRevisions = %+v", revisions)
+	}
+	first, second := revisions[1], revisions[0]
+
+	t.Run("ListRevisions", func(t *testing.T) {
+		_, err := env.blogService.ListRevisions(ctx, blog.ID, other.ID, domain.RoleUser)
+		checkErr(t, err, domain.ErrForbidden)
+		_, err = env.blogService.ListRevisions(ctx, 999, author.ID, domain.RoleUser)
+		checkErr(t, err, domain.ErrBlogNotFound)
+
+		empty, err := env.blogService.ListRevisions(ctx, otherBlog.ID, other.ID, domain.RoleEditor)
+		checkErr(t, err, nil)
+		if len(empty) != 1 {
+			t.Errorf("ListRevisions = %+v", empty)
+		}
+	})
+
+	t.Run("DiffRevisions", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			from, to int64
+			userID   int64
+			role     domain.Role
+			err      error
+		}{
+			{name: "el autor", from: first.ID, to: second.ID, userID: author.ID, role: domain.RoleUser},
+			{name: "un editor", from: first.ID, to: second.ID, userID: other.ID, role: domain.RoleEditor},
+			{name: "otro usuario", from: first.ID, to: second.ID, userID: other.ID, role: domain.RoleUser, err: domain.ErrForbidden},
+			{name: "revisión de otro blog", from: first.ID, to: foreign.ID, userID: author.ID, role: domain.RoleUser, err: domain.ErrRevisionNotFound},
+			{name: "revisión inexistente", from: 999, to: second.ID, userID: author.ID, role: domain.RoleUser, err: domain.ErrRevisionNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				diff, err := env.blogService.DiffRevisions(ctx, blog.ID, tt.from, tt.to, tt.userID, tt.role)
+				checkErr(t, err, tt.err)
+				if err == nil && (!diff.TitleChanged || len(diff.Content) != 3) {
+					t.Errorf("DiffRevisions = %+v", diff)
+				}
+			})
+		}
+	})
+
+	t.Run("RestoreRevision", func(t *testing.T) {
+		_, err := env.blogService.RestoreRevision(ctx, blog.ID, first.ID, other.ID, domain.RoleUser)
+		checkErr(t, err, domain.ErrForbidden)
+		_, err = env.blogService.RestoreRevision(ctx, blog.ID, foreign.ID, author.ID, domain.RoleUser)
+		checkErr(t, err, domain.ErrRevisionNotFound)
+
+		restored, err := env.blogService.RestoreRevision(ctx, blog.ID, first.ID, author.ID, domain.RoleUser)
+		checkErr(t, err, nil)
+		if restored.Title != "Primero" || restored.Content != "uno\ndos" {
+			t.Errorf("RestoreRevision = %+v", restored)
+		}
+
+		// La restauración queda registrada como una revisión nueva
+		revisions, err := env.blogService.ListRevisions(ctx, blog.ID, author.ID, domain.RoleUser)
+		checkErr(t, err, nil)
+		if len(revisions) != 3 || revisions[0].Title != "Primero" {
+			t.Errorf("ListRevisions = %+v", revisions)
+		}
+	})
+}
+
+func TestBlogServiceChangeStatus(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	other := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusDraft)
+	later := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		blogID    int64
+		status    domain.BlogStatus
+		publishAt *time.Time
+		userID    int64
+		role      domain.Role
+		err       error
+	}{
+		{name: "publicar", blogID: blog.ID, status: domain.BlogStatusPublished, userID: author.ID, role: domain.RoleUser},
+		{name: "archivar", blogID: blog.ID, status: domain.BlogStatusArchived, userID: author.ID, role: domain.RoleUser},
+		{name: "programar", blogID: blog.ID, status: domain.BlogStatusScheduled, publishAt: &later, userID: other.ID, role: domain.RoleEditor},
+		{name: "programar sin fecha", blogID: blog.ID, status: domain.BlogStatusScheduled, userID: author.ID, role: domain.RoleUser, err: domain.ErrInvalidSchedule},
+		{name: "estado inválido", blogID: blog.ID, status: "oculto", userID: author.ID, role: domain.RoleUser, err: domain.ErrInvalidStatus},
+		{name: "otro usuario", blogID: blog.ID, status: domain.BlogStatusDraft, userID: other.ID, role: domain.RoleUser, err: domain.ErrForbidden},
+		{name: "publicar sin permiso", blogID: blog.ID, status: domain.BlogStatusPublished, userID: author.ID, role: roleLector, err: domain.ErrForbidden},
+		{name: "inexistente", blogID: 999, status: domain.BlogStatusDraft, userID: author.ID, role: domain.RoleUser, err: domain.ErrBlogNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, err := env.blogService.ChangeStatus(ctx, tt.blogID, tt.status, tt.publishAt, tt.userID, tt.role)
+			checkErr(t, err, tt.err)
+			if err != nil {
+				return
+			}
+			found, err := env.blogs.FindByID(ctx, tt.blogID)
+			checkErr(t, err, nil)
+			if changed.Status != tt.status || found.Status != tt.status {
+				t.Errorf("Status = %s (guardado %s), se esperaba %s", changed.Status, found.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestBlogServiceSetCommentModeration(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	other := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+
+	tests := []struct {
+		name    string
+		blogID  int64
+		enabled bool
+		userID  int64
+		role    domain.Role
+		err     error
+	}{
+		{name: "activar", blogID: blog.ID, enabled: true, userID: author.ID, role: domain.RoleUser},
+		{name: "desactivar un editor", blogID: blog.ID, enabled: false, userID: other.ID, role: domain.RoleEditor},
+		{name: "otro usuario", blogID: blog.ID, enabled: true, userID: other.ID, role: domain.RoleModerator, err: domain.ErrForbidden},
+		{name: "inexistente", blogID: 999, enabled: true, userID: author.ID, role: domain.RoleUser, err: domain.ErrBlogNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := env.blogService.SetCommentModeration(ctx, tt.blogID, tt.enabled, tt.userID, tt.role)
+			checkErr(t, err, tt.err)
+			if err == nil && updated.CommentModeration != tt.enabled {
+				t.Errorf("CommentModeration = %v, se esperaba %v", updated.CommentModeration, tt.enabled)
+			}
+		})
+	}
+}
+
+func TestBlogServicePublishScheduled(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	scheduled := env.createBlog(t, author, domain.BlogStatusScheduled)
+	past := time.Now().Add(-time.Minute)
+	due := &domain.Blog{Title: "vencido", AuthorID: author.ID, Status: domain.BlogStatusScheduled, PublishedAt: &past}
+	checkErr(t, env.blogs.Create(ctx, due), nil)
+
+	published, err := env.blogService.PublishScheduled(ctx)
+	checkErr(t, err, nil)
+	if published != 1 {
+		t.Fatalf("PublishScheduled = %d, se esperaba 1", published)
+	}
+
+	for blogID, want := range map[int64]domain.BlogStatus{due.ID: domain.BlogStatusPublished, scheduled.ID: domain.BlogStatusScheduled} {
+		found, err := env.blogs.FindByID(ctx, blogID)
+		checkErr(t, err, nil)
+		if found.Status != want {
+			t.Errorf("Status del blog %d = %s, se esperaba %s", blogID, found.Status, want)
+		}
+	}
+}
+
+func TestBlogServiceDeleteBlog(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	other := env.createUser(t, "bob", domain.RoleUser)
+	first := env.createBlog(t, author, domain.BlogStatusPublished)
+	second := env.createBlog(t, author, domain.BlogStatusPublished)
+
+	tests := []struct {
+		name   string
+		blogID int64
+		userID int64
+		role   domain.Role
+		err    error
+	}{
+		{name: "otro usuario", blogID: first.ID, userID: other.ID, role: domain.RoleUser, err: domain.ErrForbidden},
+		{name: "el autor", blogID: first.ID, userID: author.ID, role: domain.RoleUser},
+		{name: "ya eliminado", blogID: first.ID, userID: author.ID, role: domain.RoleUser, err: domain.ErrBlogNotFound},
+		{name: "un editor", blogID: second.ID, userID: other.ID, role: domain.RoleEditor},
+		{name: "inexistente", blogID: 999, userID: author.ID, role: domain.RoleAdmin, err: domain.ErrBlogNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErr(t, env.blogService.DeleteBlog(ctx, tt.blogID, tt.userID, tt.role), tt.err)
+			if tt.err == nil {
+				_, err := env.blogs.FindByID(ctx, tt.blogID)
+				checkErr(t, err, domain.ErrBlogNotFound)
+			}
+		})
+	}
+}
+
+func TestBlogServiceDeleteBlogCascade(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	comment := env.createComment(t, blog, commenter, nil)
+	trashed := env.createComment(t, blog, commenter, nil)
+	checkErr(t, env.commentService.DeleteComment(ctx, trashed.ID, commenter.ID, domain.RoleUser), nil)
+
+	// Los comentarios acompañan al blog a la papelera
+	checkErr(t, env.blogService.DeleteBlog(ctx, blog.ID, author.ID, domain.RoleUser), nil)
+	_, err := env.comments.FindDeleted(ctx, comment.ID)
+	checkErr(t, err, nil)
+
+	// y vuelven con él, salvo los que ya estaban en la papelera
+	checkErr(t, env.trashService.RestoreBlog(ctx, blog.ID), nil)
+	_, err = env.comments.FindByID(ctx, comment.ID)
+	checkErr(t, err, nil)
+	_, err = env.comments.FindDeleted(ctx, trashed.ID)
+	checkErr(t, err, nil)
+}
+
+func TestBlogServiceDeleteBlogBlock(t *testing.T) {
+	env := newTestEnvWithPolicies(t, domain.DeletionCascade, domain.DeletionBlock)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commented := env.createBlog(t, author, domain.BlogStatusPublished)
+	comment := env.createComment(t, commented, author, nil)
+	empty := env.createBlog(t, author, domain.BlogStatusPublished)
+
+	checkErr(t, env.blogService.DeleteBlog(ctx, commented.ID, author.ID, domain.RoleUser), domain.ErrBlogHasComments)
+	_, err := env.blogs.FindByID(ctx, commented.ID)
+	checkErr(t, err, nil)
+
+	checkErr(t, env.blogService.DeleteBlog(ctx, empty.ID, author.ID, domain.RoleUser), nil)
+
+	// Sin comentarios el blog ya se puede eliminar
+	checkErr(t, env.commentService.DeleteComment(ctx, comment.ID, author.ID, domain.RoleUser), nil)
+	checkErr(t, env.blogService.DeleteBlog(ctx, commented.ID, author.ID, domain.RoleUser), nil)
+}
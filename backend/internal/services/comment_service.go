@@ -3,45 +3,134 @@ package services
 import (
 	"blog-backend/internal/domain"
 	"blog-backend/internal/ports"
+	"context"
+	"time"
 )
 
 // CommentService implementa los casos de uso para gestión de comentarios
 type CommentService struct {
-	commentRepo ports.CommentRepository
-	blogRepo    ports.BlogRepository
-	userRepo    ports.UserRepository
+	commentRepo   ports.CommentRepository
+	blogRepo      ports.BlogRepository
+	userRepo      ports.UserRepository
+	revisionRepo  ports.RevisionRepository
+	authorizer    ports.Authorizer
+	contentFilter ports.ContentFilter
+	txManager     ports.TxManager
+	// moderateAll deja pendientes los comentarios nuevos de todos los blogs
+	moderateAll bool
 }
 
 // NewCommentService crea una nueva instancia del servicio de comentarios
-func NewCommentService(commentRepo ports.CommentRepository, blogRepo ports.BlogRepository, userRepo ports.UserRepository) *CommentService {
+func NewCommentService(commentRepo ports.CommentRepository, blogRepo ports.BlogRepository, userRepo ports.UserRepository, revisionRepo ports.RevisionRepository, authorizer ports.Authorizer, contentFilter ports.ContentFilter, txManager ports.TxManager, moderateAll bool) *CommentService {
 	return &CommentService{
-		commentRepo: commentRepo,
-		blogRepo:    blogRepo,
-		userRepo:    userRepo,
+		commentRepo:   commentRepo,
+		blogRepo:      blogRepo,
+		userRepo:      userRepo,
+		revisionRepo:  revisionRepo,
+		authorizer:    authorizer,
+		contentFilter: contentFilter,
+		txManager:     txManager,
+		moderateAll:   moderateAll,
 	}
 }
 
-// CreateComment crea un nuevo comentario
-func (s *CommentService) CreateComment(blogID, userID int64, content string) (*domain.Comment, error) {
-	// Verificar que el blog existe
-	_, err := s.blogRepo.FindByID(blogID)
+// CreateComment crea un nuevo comentario o, si parentID no es nil, una respuesta.
+// Si el blog exige moderación el comentario queda pendiente, salvo que lo
+// escriba alguien que puede moderarlo. El filtro de contenido puede dejarlo
+// pendiente o rechazarlo; los comentarios rechazados se guardan para que los
+// moderadores puedan revisarlos y se retorna domain.ErrCommentRejected.
+func (s *CommentService) CreateComment(ctx context.Context, blogID, userID int64, content string, parentID *int64) (*domain.Comment, error) {
+	var comment *domain.Comment
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		comment, err = s.createComment(ctx, blogID, userID, content, parentID)
+		return err
+	})
 	if err != nil {
-		return nil, domain.ErrBlogNotFound
+		return nil, err
+	}
+
+	if comment.Status == domain.CommentStatusRejected {
+		return nil, domain.ErrCommentRejected
 	}
 
+	return comment, nil
+}
+
+// createComment valida y guarda un comentario con su primera revisión. El blog
+// queda bloqueado hasta el final de la transacción para que no se elimine
+// mientras tanto.
+func (s *CommentService) createComment(ctx context.Context, blogID, userID int64, content string, parentID *int64) (*domain.Comment, error) {
 	// Verificar que el usuario existe
-	_, err = s.userRepo.FindByID(userID)
+	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, domain.ErrUserNotFound
 	}
 
+	if err := s.authorizer.Authorize(ctx, user.Role, domain.PermCommentCreate); err != nil {
+		return nil, err
+	}
+
+	// Verificar que el blog existe y es visible para el usuario
+	blog, err := s.blogRepo.FindByIDForUpdate(ctx, blogID)
+	if err != nil {
+		return nil, domain.ErrBlogNotFound
+	}
+	if err := authorizeBlogView(ctx, s.authorizer, blog, userID, user.Role); err != nil {
+		return nil, err
+	}
+
+	moderator, err := s.canModerate(ctx, blog, userID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
 	comment := &domain.Comment{
 		BlogID:  blogID,
 		UserID:  userID,
 		Content: content,
+		Status:  domain.CommentStatusApproved,
 	}
+	if (s.moderateAll || blog.CommentModeration) && !moderator {
+		comment.Status = domain.CommentStatusPending
+	}
+
+	// Validar la respuesta: el padre debe existir en el mismo blog y no superar la profundidad máxima
+	if parentID != nil {
+		parent, err := s.commentRepo.FindByID(ctx, *parentID)
+		if err != nil {
+			return nil, domain.ErrInvalidParentComment
+		}
+		if parent.BlogID != blogID || parent.Removed || parent.Status != domain.CommentStatusApproved {
+			return nil, domain.ErrInvalidParentComment
+		}
+		if parent.Depth+1 > domain.MaxCommentDepth {
+			return nil, domain.ErrCommentDepthExceeded
+		}
+
+		rootID := parent.ID
+		if parent.RootID != nil {
+			rootID = *parent.RootID
+		}
 
-	if err := s.commentRepo.Create(comment); err != nil {
+		comment.ParentID = &parent.ID
+		comment.RootID = &rootID
+		comment.Depth = parent.Depth + 1
+	}
+
+	if !moderator {
+		verdict, err := s.contentFilter.Check(ctx, domain.FilterInput{BlogID: blogID, Author: user, Content: content})
+		if err != nil {
+			return nil, err
+		}
+		comment.ApplyVerdict(verdict)
+	}
+
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordRevision(ctx, comment, userID); err != nil {
 		return nil, err
 	}
 
@@ -49,68 +138,285 @@ func (s *CommentService) CreateComment(blogID, userID int64, content string) (*d
 }
 
 // GetCommentByID obtiene un comentario por su ID
-func (s *CommentService) GetCommentByID(id int64) (*domain.Comment, error) {
-	comment, err := s.commentRepo.FindByID(id)
+func (s *CommentService) GetCommentByID(ctx context.Context, id int64) (*domain.Comment, error) {
+	comment, err := s.commentRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, domain.ErrCommentNotFound
 	}
 	return comment, nil
 }
 
-// GetCommentsByBlog obtiene todos los comentarios de un blog
-func (s *CommentService) GetCommentsByBlog(blogID int64) ([]domain.Comment, error) {
-	// Verificar que el blog existe
-	_, err := s.blogRepo.FindByID(blogID)
+// GetCommentsByBlog obtiene una página de hilos de comentarios de un blog.
+// La paginación se aplica a los comentarios raíz; cada uno incluye todas sus respuestas.
+// Los comentarios pendientes solo los ven su autor y quien puede moderarlos; los
+// no visibles con respuestas visibles se muestran como lápidas.
+func (s *CommentService) GetCommentsByBlog(ctx context.Context, blogID int64, page domain.PageRequest, userID int64, userRole domain.Role) (*domain.Page[*domain.CommentNode], error) {
+	// Verificar que el blog existe y es visible para el usuario
+	blog, err := s.blogRepo.FindByID(ctx, blogID)
 	if err != nil {
 		return nil, domain.ErrBlogNotFound
 	}
+	if err := authorizeBlogView(ctx, s.authorizer, blog, userID, userRole); err != nil {
+		return nil, err
+	}
 
-	return s.commentRepo.FindByBlogID(blogID)
+	moderator, err := s.canModerate(ctx, blog, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+	visibility := domain.CommentVisibility{ViewerID: userID, Moderator: moderator}
+
+	roots, err := s.commentRepo.FindByBlogID(ctx, blogID, page, visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	rootIDs := make([]int64, len(roots.Items))
+	for i, root := range roots.Items {
+		rootIDs[i] = root.ID
+	}
+
+	replies, err := s.commentRepo.FindByRootIDs(ctx, rootIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Page[*domain.CommentNode]{
+		Items:      domain.BuildCommentTree(roots.Items, replies, visibility),
+		NextCursor: roots.NextCursor,
+		HasMore:    roots.HasMore,
+	}, nil
 }
 
-// GetCommentsByUser obtiene todos los comentarios de un usuario
-func (s *CommentService) GetCommentsByUser(userID int64) ([]domain.Comment, error) {
+// GetCommentsByUser obtiene una página de comentarios de un usuario
+func (s *CommentService) GetCommentsByUser(ctx context.Context, userID int64, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
 	// Verificar que el usuario existe
-	_, err := s.userRepo.FindByID(userID)
+	_, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return nil, domain.ErrUserNotFound
 	}
 
-	return s.commentRepo.FindByUserID(userID)
+	return s.commentRepo.FindByUserID(ctx, userID, page)
+}
+
+// UpdateComment actualiza un comentario existente. El comentario se bloquea y
+// el contenido y la revisión se guardan en una transacción.
+func (s *CommentService) UpdateComment(ctx context.Context, id int64, content string, userID int64, userRole domain.Role) (*domain.Comment, error) {
+	var comment *domain.Comment
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		comment, err = s.commentRepo.FindByIDForUpdate(ctx, id)
+		if err != nil || comment.Removed {
+			return domain.ErrCommentNotFound
+		}
+
+		// Verificar permisos: el autor o quien tenga el permiso comment:moderate
+		if err := s.authorizer.AuthorizeOwner(ctx, userID, userRole, comment.UserID, domain.PermCommentModerate); err != nil {
+			return err
+		}
+
+		if err := s.filterEdit(ctx, comment, content, userID, userRole); err != nil {
+			return err
+		}
+
+		comment.Content = content
+
+		if err := s.commentRepo.Update(ctx, comment); err != nil {
+			return err
+		}
+		return s.recordRevision(ctx, comment, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// ListModerationQueue lista una página de comentarios de la cola de moderación.
+// Sin blog la cola es global y requiere el permiso comment:moderate; la cola de
+// un blog también la puede consultar su autor.
+func (s *CommentService) ListModerationQueue(ctx context.Context, filter domain.ModerationFilter, page domain.PageRequest, userID int64, userRole domain.Role) (*domain.Page[domain.Comment], error) {
+	if filter.Status == "" {
+		filter.Status = domain.CommentStatusPending
+	}
+	if !filter.Status.IsValid() || filter.Status == domain.CommentStatusApproved {
+		return nil, domain.ErrInvalidCommentStatus
+	}
+
+	if filter.BlogID == 0 {
+		if err := s.authorizer.Authorize(ctx, userRole, domain.PermCommentModerate); err != nil {
+			return nil, err
+		}
+	} else {
+		blog, err := s.blogRepo.FindByID(ctx, filter.BlogID)
+		if err != nil {
+			return nil, domain.ErrBlogNotFound
+		}
+		if err := s.authorizer.AuthorizeOwner(ctx, userID, userRole, blog.AuthorID, domain.PermCommentModerate); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.commentRepo.ListForModeration(ctx, filter, page)
 }
 
-// UpdateComment actualiza un comentario existente
-func (s *CommentService) UpdateComment(id int64, content string, userID int64, userRole domain.Role) (*domain.Comment, error) {
-	comment, err := s.commentRepo.FindByID(id)
+// ModerateComment aprueba, rechaza o marca como spam un comentario. Pueden
+// hacerlo el autor del blog y quien tenga el permiso comment:moderate.
+func (s *CommentService) ModerateComment(ctx context.Context, id int64, status domain.CommentStatus, reason string, userID int64, userRole domain.Role) (*domain.Comment, error) {
+	comment, err := s.commentRepo.FindByID(ctx, id)
+	if err != nil || comment.Removed {
+		return nil, domain.ErrCommentNotFound
+	}
+
+	blog, err := s.blogRepo.FindByID(ctx, comment.BlogID)
 	if err != nil {
 		return nil, domain.ErrCommentNotFound
 	}
 
-	// Verificar permisos: solo el autor o un administrador puede editar
-	if comment.UserID != userID && userRole != domain.RoleAdmin {
-		return nil, domain.ErrForbidden
+	if err := s.authorizer.AuthorizeOwner(ctx, userID, userRole, blog.AuthorID, domain.PermCommentModerate); err != nil {
+		return nil, err
 	}
 
-	comment.Content = content
+	if err := comment.Moderate(status, reason, userID, time.Now()); err != nil {
+		return nil, err
+	}
 
-	if err := s.commentRepo.Update(comment); err != nil {
+	if err := s.commentRepo.UpdateModeration(ctx, comment); err != nil {
 		return nil, err
 	}
 
 	return comment, nil
 }
 
-// DeleteComment elimina un comentario
-func (s *CommentService) DeleteComment(id int64, userID int64, userRole domain.Role) error {
-	comment, err := s.commentRepo.FindByID(id)
+// canModerate indica si el usuario puede moderar los comentarios de un blog:
+// su autor o quien tenga el permiso comment:moderate
+func (s *CommentService) canModerate(ctx context.Context, blog *domain.Blog, userID int64, userRole domain.Role) (bool, error) {
+	switch err := s.authorizer.AuthorizeOwner(ctx, userID, userRole, blog.AuthorID, domain.PermCommentModerate); err {
+	case nil:
+		return true, nil
+	case domain.ErrForbidden:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// filterEdit evalúa el nuevo contenido de un comentario con el filtro de
+// contenido. Una edición rechazada no se guarda; si el filtro la retiene o el
+// blog modera sus comentarios, el comentario vuelve a quedar pendiente de
+// moderación. Las ediciones de quien puede moderarlo no se filtran.
+func (s *CommentService) filterEdit(ctx context.Context, comment *domain.Comment, content string, userID int64, userRole domain.Role) error {
+	blog, err := s.blogRepo.FindByID(ctx, comment.BlogID)
 	if err != nil {
 		return domain.ErrCommentNotFound
 	}
 
-	// Verificar permisos: solo el autor o un administrador puede eliminar
-	if comment.UserID != userID && userRole != domain.RoleAdmin {
-		return domain.ErrForbidden
+	moderator, err := s.canModerate(ctx, blog, userID, userRole)
+	if err != nil || moderator {
+		return err
+	}
+
+	author, err := s.userRepo.FindByID(ctx, comment.UserID)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	verdict, err := s.contentFilter.Check(ctx, domain.FilterInput{
+		CommentID: comment.ID,
+		BlogID:    comment.BlogID,
+		Author:    author,
+		Content:   content,
+	})
+	if err != nil {
+		return err
+	}
+	if verdict.Action == domain.FilterReject {
+		return domain.ErrCommentRejected
+	}
+
+	// Con moderación activa la edición vuelve a la cola como un comentario nuevo
+	if (s.moderateAll || blog.CommentModeration) && comment.Status == domain.CommentStatusApproved {
+		comment.Status = domain.CommentStatusPending
+	}
+
+	comment.ApplyVerdict(verdict)
+	return nil
+}
+
+// recordRevision guarda una instantánea del contenido actual del comentario
+func (s *CommentService) recordRevision(ctx context.Context, comment *domain.Comment, editorID int64) error {
+	return s.revisionRepo.Create(ctx, &domain.Revision{
+		EntityType: domain.RevisionEntityComment,
+		EntityID:   comment.ID,
+		EditorID:   editorID,
+		Content:    comment.Content,
+	})
+}
+
+// DeleteComment elimina un comentario. Si tiene respuestas se conserva como
+// nodo "comentario eliminado" para no dejar huérfanas las respuestas.
+func (s *CommentService) DeleteComment(ctx context.Context, id int64, userID int64, userRole domain.Role) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		comment, err := s.commentRepo.FindByID(ctx, id)
+		if err != nil || comment.Removed {
+			return domain.ErrCommentNotFound
+		}
+
+		// Verificar permisos: el autor o quien tenga el permiso comment:moderate
+		if err := s.authorizer.AuthorizeOwner(ctx, userID, userRole, comment.UserID, domain.PermCommentModerate); err != nil {
+			return err
+		}
+
+		return removeComment(ctx, s.commentRepo, comment)
+	})
+}
+
+// removeComment mueve un comentario a la papelera o, si tiene respuestas, lo
+// marca como "comentario eliminado"
+func removeComment(ctx context.Context, commentRepo ports.CommentRepository, comment *domain.Comment) error {
+	replies, err := commentRepo.CountReplies(ctx, comment.ID)
+	if err != nil {
+		return err
+	}
+	if replies > 0 {
+		return commentRepo.MarkRemoved(ctx, comment.ID)
+	}
+
+	if err := commentRepo.Delete(ctx, comment.ID); err != nil {
+		return err
+	}
+
+	return pruneRemovedAncestors(ctx, commentRepo, comment.ParentID)
+}
+
+// pruneRemovedAncestors elimina los nodos "comentario eliminado" que se quedaron sin respuestas
+func pruneRemovedAncestors(ctx context.Context, commentRepo ports.CommentRepository, parentID *int64) error {
+	for parentID != nil {
+		parent, err := commentRepo.FindByID(ctx, *parentID)
+		if err != nil {
+			if err == domain.ErrCommentNotFound {
+				return nil
+			}
+			return err
+		}
+		if !parent.Removed {
+			return nil
+		}
+
+		replies, err := commentRepo.CountReplies(ctx, parent.ID)
+		if err != nil {
+			return err
+		}
+		if replies > 0 {
+			return nil
+		}
+
+		if err := commentRepo.Delete(ctx, parent.ID); err != nil {
+			return err
+		}
+		parentID = parent.ParentID
 	}
 
-	return s.commentRepo.Delete(id)
+	return nil
 }
@@ -0,0 +1,470 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"errors"
+	"testing"
+)
+
+func TestCommentServiceCreateComment(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	moderator := env.createUser(t, "mod", domain.RoleModerator)
+	reader := env.createUser(t, "lector", roleLector)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	moderated := env.createBlog(t, author, domain.BlogStatusPublished)
+	_, err := env.blogService.SetCommentModeration(ctx, moderated.ID, true, author.ID, domain.RoleUser)
+	checkErr(t, err, nil)
+	draft := env.createBlog(t, author, domain.BlogStatusDraft)
+	otherBlog := env.createBlog(t, author, domain.BlogStatusPublished)
+
+	root := env.createComment(t, blog, commenter, nil)
+	foreign := env.createComment(t, otherBlog, commenter, nil)
+	pending, err := env.commentService.CreateComment(ctx, blog.ID, commenter.ID, "[hold] pendiente", nil)
+	checkErr(t, err, nil)
+	deepest := root
+	for deepest.Depth < domain.MaxCommentDepth {
+		deepest = env.createComment(t, blog, commenter, deepest)
+	}
+
+	tests := []struct {
+		name     string
+		blogID   int64
+		userID   int64
+		content  string
+		parentID *int64
+		status   domain.CommentStatus
+		err      error
+	}{
+		{name: "aprobado", blogID: blog.ID, userID: commenter.ID, content: "hola", status: domain.CommentStatusApproved},
+		{name: "respuesta", blogID: blog.ID, userID: commenter.ID, content: "hola", parentID: &root.ID, status: domain.CommentStatusApproved},
+		{name: "blog con moderación", blogID: moderated.ID, userID: commenter.ID, content: "hola", status: domain.CommentStatusPending},
+		{name: "moderación omitida por el autor", blogID: moderated.ID, userID: author.ID, content: "hola", status: domain.CommentStatusApproved},
+		{name: "moderación omitida por un moderador", blogID: moderated.ID, userID: moderator.ID, content: "[hold] hola", status: domain.CommentStatusApproved},
+		{name: "retenido por el filtro", blogID: blog.ID, userID: commenter.ID, content: "[hold] hola", status: domain.CommentStatusPending},
+		{name: "rechazado por el filtro", blogID: blog.ID, userID: commenter.ID, content: "[reject] hola", err: domain.ErrCommentRejected},
+		{name: "borrador del autor", blogID: draft.ID, userID: author.ID, content: "hola", status: domain.CommentStatusApproved},
+		{name: "borrador ajeno", blogID: draft.ID, userID: commenter.ID, content: "hola", err: domain.ErrBlogNotFound},
+		{name: "padre de otro blog", blogID: blog.ID, userID: commenter.ID, content: "hola", parentID: &foreign.ID, err: domain.ErrInvalidParentComment},
+		{name: "padre pendiente", blogID: blog.ID, userID: commenter.ID, content: "hola", parentID: &pending.ID, err: domain.ErrInvalidParentComment},
+		{name: "profundidad máxima", blogID: blog.ID, userID: commenter.ID, content: "hola", parentID: &deepest.ID, err: domain.ErrCommentDepthExceeded},
+		{name: "blog inexistente", blogID: 999, userID: commenter.ID, content: "hola", err: domain.ErrBlogNotFound},
+		{name: "usuario inexistente", blogID: blog.ID, userID: 999, content: "hola", err: domain.ErrUserNotFound},
+		{name: "sin permiso", blogID: blog.ID, userID: reader.ID, content: "hola", err: domain.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comment, err := env.commentService.CreateComment(ctx, tt.blogID, tt.userID, tt.content, tt.parentID)
+			checkErr(t, err, tt.err)
+			if err != nil {
+				return
+			}
+			if comment.Status != tt.status {
+				t.Errorf("Status = %s, se esperaba %s", comment.Status, tt.status)
+			}
+			if tt.parentID != nil && (comment.ParentID == nil || *comment.RootID != root.ID || comment.Depth != 1) {
+				t.Errorf("respuesta mal enlazada: %+v", comment)
+			}
+		})
+	}
+
+	// Los comentarios rechazados se guardan para revisarlos en la cola de moderación
+	rejected, err := env.commentService.ListModerationQueue(ctx, domain.ModerationFilter{Status: domain.CommentStatusRejected},
+		firstPage(domain.SortNewest), moderator.ID, domain.RoleModerator)
+	checkErr(t, err, nil)
+	if len(rejected.Items) != 1 || rejected.Items[0].FilterVerdict == nil {
+		t.Errorf("cola de rechazados = %+v", rejected.Items)
+	}
+}
+
+func TestCommentServiceCreateCommentRollback(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+
+	// Si falla la revisión tampoco se guarda el comentario
+	failure := errors.New("fallo")
+	env.revisions.err = failure
+	_, err := env.commentService.CreateComment(ctx, blog.ID, author.ID, "hola", nil)
+	checkErr(t, err, failure)
+
+	exists, err := env.comments.ExistsByBlog(ctx, blog.ID)
+	checkErr(t, err, nil)
+	if exists {
+		t.Error("el comentario se guardó aunque la transacción falló")
+	}
+}
+
+func TestCommentServiceUpdateCommentRollback(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	comment := env.createComment(t, blog, author, nil)
+
+	// Si falla la revisión el contenido no cambia
+	failure := errors.New("fallo")
+	env.revisions.err = failure
+	_, err := env.commentService.UpdateComment(ctx, comment.ID, "editado", author.ID, domain.RoleUser)
+	checkErr(t, err, failure)
+
+	found, err := env.comments.FindByID(ctx, comment.ID)
+	checkErr(t, err, nil)
+	if found.Content != comment.Content {
+		t.Errorf("contenido = %q, se esperaba %q porque la transacción falló", found.Content, comment.Content)
+	}
+}
+
+func TestCommentServiceCreateCommentModerateAll(t *testing.T) {
+	env := newTestEnv(t)
+	env.commentService.moderateAll = true
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+
+	for userID, want := range map[int64]domain.CommentStatus{commenter.ID: domain.CommentStatusPending, author.ID: domain.CommentStatusApproved} {
+		comment, err := env.commentService.CreateComment(ctx, blog.ID, userID, "hola", nil)
+		checkErr(t, err, nil)
+		if comment.Status != want {
+			t.Errorf("Status del comentario de %d = %s, se esperaba %s", userID, comment.Status, want)
+		}
+	}
+}
+
+func TestCommentServiceUpdateCommentModerated(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	comment := env.createComment(t, blog, commenter, nil)
+	env.commentService.moderateAll = true
+
+	// Una edición del autor vuelve a la cola
+	updated, err := env.commentService.UpdateComment(ctx, comment.ID, "editado", commenter.ID, domain.RoleUser)
+	checkErr(t, err, nil)
+	found, err := env.comments.FindByID(ctx, comment.ID)
+	checkErr(t, err, nil)
+	if updated.Status != domain.CommentStatusPending || found.Status != domain.CommentStatusPending {
+		t.Errorf("Status tras editar = %s, se esperaba %s", found.Status, domain.CommentStatusPending)
+	}
+
+	// La del autor del blog, que puede moderarlo, no
+	_, err = env.commentService.ModerateComment(ctx, comment.ID, domain.CommentStatusApproved, "", author.ID, domain.RoleUser)
+	checkErr(t, err, nil)
+	updated, err = env.commentService.UpdateComment(ctx, comment.ID, "corregido", author.ID, domain.RoleModerator)
+	checkErr(t, err, nil)
+	if updated.Status != domain.CommentStatusApproved {
+		t.Errorf("Status tras editar como moderador = %s, se esperaba %s", updated.Status, domain.CommentStatusApproved)
+	}
+}
+
+func TestCommentServiceGetCommentByID(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	comment := env.createComment(t, env.createBlog(t, author, domain.BlogStatusPublished), author, nil)
+
+	found, err := env.commentService.GetCommentByID(ctx, comment.ID)
+	checkErr(t, err, nil)
+	if found.Content != comment.Content {
+		t.Errorf("GetCommentByID = %+v", found)
+	}
+
+	_, err = env.commentService.GetCommentByID(ctx, 999)
+	checkErr(t, err, domain.ErrCommentNotFound)
+}
+
+func TestCommentServiceGetCommentsByBlog(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	other := env.createUser(t, "eva", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	draft := env.createBlog(t, author, domain.BlogStatusDraft)
+
+	root := env.createComment(t, blog, commenter, nil)
+	env.createComment(t, blog, other, root)
+	_, err := env.commentService.CreateComment(ctx, blog.ID, commenter.ID, "[hold] pendiente", &root.ID)
+	checkErr(t, err, nil)
+	env.createComment(t, blog, other, nil)
+
+	tests := []struct {
+		name    string
+		blogID  int64
+		userID  int64
+		role    domain.Role
+		roots   int
+		replies int
+		err     error
+	}{
+		{name: "anónimo", blogID: blog.ID, roots: 2, replies: 1},
+		{name: "autor del pendiente", blogID: blog.ID, userID: commenter.ID, role: domain.RoleUser, roots: 2, replies: 2},
+		{name: "otro usuario", blogID: blog.ID, userID: other.ID, role: domain.RoleUser, roots: 2, replies: 1},
+		{name: "autor del blog", blogID: blog.ID, userID: author.ID, role: domain.RoleUser, roots: 2, replies: 2},
+		{name: "moderador", blogID: blog.ID, userID: other.ID, role: domain.RoleModerator, roots: 2, replies: 2},
+		{name: "borrador ajeno", blogID: draft.ID, userID: other.ID, role: domain.RoleUser, err: domain.ErrBlogNotFound},
+		{name: "borrador del autor", blogID: draft.ID, userID: author.ID, role: domain.RoleUser},
+		{name: "inexistente", blogID: 999, err: domain.ErrBlogNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := env.commentService.GetCommentsByBlog(ctx, tt.blogID, firstPage(domain.SortOldest), tt.userID, tt.role)
+			checkErr(t, err, tt.err)
+			if err != nil {
+				return
+			}
+			if len(page.Items) != tt.roots {
+				t.Fatalf("se obtuvieron %d hilos, se esperaban %d", len(page.Items), tt.roots)
+			}
+			if tt.roots > 0 && (page.Items[0].ID != root.ID || len(page.Items[0].Replies) != tt.replies) {
+				t.Errorf("el primer hilo tiene %d respuestas, se esperaban %d", len(page.Items[0].Replies), tt.replies)
+			}
+		})
+	}
+}
+
+func TestCommentServiceGetCommentsByBlogHiddenParent(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	root := env.createComment(t, blog, commenter, nil)
+	reply := env.createComment(t, blog, author, root)
+	nested := env.createComment(t, blog, commenter, reply)
+
+	// Rechazar la raíz y la respuesta intermedia no oculta la respuesta aprobada
+	for _, id := range []int64{root.ID, reply.ID} {
+		_, err := env.commentService.ModerateComment(ctx, id, domain.CommentStatusRejected, "motivo", author.ID, domain.RoleUser)
+		checkErr(t, err, nil)
+	}
+
+	page, err := env.commentService.GetCommentsByBlog(ctx, blog.ID, firstPage(domain.SortOldest), 0, "")
+	checkErr(t, err, nil)
+	if len(page.Items) != 1 {
+		t.Fatalf("se obtuvieron %d hilos, se esperaba 1", len(page.Items))
+	}
+	for _, node := range []*domain.CommentNode{page.Items[0], page.Items[0].Replies[0]} {
+		if node.Content != domain.HiddenCommentContent || node.UserID != 0 || node.ModerationReason != "" {
+			t.Errorf("el comentario oculto se muestra como %+v", node.Comment)
+		}
+	}
+	if shown := page.Items[0].Replies[0].Replies; len(shown) != 1 || shown[0].ID != nested.ID || shown[0].Content != nested.Content {
+		t.Errorf("respuestas visibles = %+v", shown)
+	}
+
+	// Sin respuestas visibles el hilo desaparece
+	_, err = env.commentService.ModerateComment(ctx, nested.ID, domain.CommentStatusSpam, "", author.ID, domain.RoleUser)
+	checkErr(t, err, nil)
+	page, err = env.commentService.GetCommentsByBlog(ctx, blog.ID, firstPage(domain.SortOldest), 0, "")
+	checkErr(t, err, nil)
+	if len(page.Items) != 0 {
+		t.Errorf("se obtuvieron %d hilos, se esperaba ninguno", len(page.Items))
+	}
+}
+
+func TestCommentServiceGetCommentsByUser(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	env.createComment(t, blog, commenter, nil)
+	env.createComment(t, blog, commenter, nil)
+	env.createComment(t, blog, author, nil)
+
+	tests := []struct {
+		name   string
+		userID int64
+		want   int
+		err    error
+	}{
+		{name: "con comentarios", userID: commenter.ID, want: 2},
+		{name: "inexistente", userID: 999, err: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := env.commentService.GetCommentsByUser(ctx, tt.userID, firstPage(domain.SortNewest))
+			checkErr(t, err, tt.err)
+			if err == nil && len(page.Items) != tt.want {
+				t.Errorf("se obtuvieron %d comentarios, se esperaban %d", len(page.Items), tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentServiceUpdateComment(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	other := env.createUser(t, "eva", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	comment := env.createComment(t, blog, commenter, nil)
+
+	tests := []struct {
+		name    string
+		id      int64
+		content string
+		userID  int64
+		role    domain.Role
+		status  domain.CommentStatus
+		// held indica si el comentario queda con el veredicto del filtro
+		held bool
+		err  error
+	}{
+		{name: "el autor", id: comment.ID, content: "editado", userID: commenter.ID, role: domain.RoleUser, status: domain.CommentStatusApproved},
+		{name: "edición rechazada", id: comment.ID, content: "[reject] spam", userID: commenter.ID, role: domain.RoleUser, err: domain.ErrCommentRejected},
+		{name: "un moderador sin filtro", id: comment.ID, content: "[hold] moderado", userID: other.ID, role: domain.RoleModerator, status: domain.CommentStatusApproved},
+		{name: "edición retenida", id: comment.ID, content: "[hold] otra vez", userID: commenter.ID, role: domain.RoleUser, status: domain.CommentStatusPending, held: true},
+		{name: "edición corregida", id: comment.ID, content: "corregido", userID: commenter.ID, role: domain.RoleUser, status: domain.CommentStatusPending},
+		{name: "otro usuario", id: comment.ID, content: "ajeno", userID: other.ID, role: domain.RoleUser, err: domain.ErrForbidden},
+		{name: "inexistente", id: 999, content: "x", userID: commenter.ID, role: domain.RoleUser, err: domain.ErrCommentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := env.commentService.UpdateComment(ctx, tt.id, tt.content, tt.userID, tt.role)
+			checkErr(t, err, tt.err)
+			if err != nil {
+				return
+			}
+			if updated.Content != tt.content || updated.Status != tt.status {
+				t.Errorf("UpdateComment = %+v", updated)
+			}
+			stored, err := env.comments.FindByID(ctx, tt.id)
+			checkErr(t, err, nil)
+			if (stored.FilterVerdict != nil) != tt.held {
+				t.Errorf("veredicto guardado = %+v", stored.FilterVerdict)
+			}
+		})
+	}
+
+	// Solo las ediciones guardadas quedan en el historial
+	revisions, err := env.revisions.FindByEntity(ctx, domain.RevisionEntityComment, comment.ID)
+	checkErr(t, err, nil)
+	if len(revisions) != 5 {
+		t.Errorf("se registraron %d revisiones, se esperaban 5", len(revisions))
+	}
+}
+
+func TestCommentServiceModeration(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	moderator := env.createUser(t, "mod", domain.RoleModerator)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	otherBlog := env.createBlog(t, commenter, domain.BlogStatusPublished)
+	pending, err := env.commentService.CreateComment(ctx, blog.ID, commenter.ID, "[hold] uno", nil)
+	checkErr(t, err, nil)
+	_, err = env.commentService.CreateComment(ctx, otherBlog.ID, author.ID, "[hold] dos", nil)
+	checkErr(t, err, nil)
+
+	t.Run("ListModerationQueue", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			filter domain.ModerationFilter
+			userID int64
+			role   domain.Role
+			want   int
+			err    error
+		}{
+			{name: "cola global", userID: moderator.ID, role: domain.RoleModerator, want: 2},
+			{name: "cola de un blog para su autor", filter: domain.ModerationFilter{BlogID: blog.ID}, userID: author.ID, role: domain.RoleUser, want: 1},
+			{name: "spam", filter: domain.ModerationFilter{Status: domain.CommentStatusSpam}, userID: moderator.ID, role: domain.RoleModerator, want: 0},
+			{name: "cola global sin permiso", userID: author.ID, role: domain.RoleUser, err: domain.ErrForbidden},
+			{name: "cola de un blog ajeno", filter: domain.ModerationFilter{BlogID: otherBlog.ID}, userID: author.ID, role: domain.RoleUser, err: domain.ErrForbidden},
+			{name: "blog inexistente", filter: domain.ModerationFilter{BlogID: 999}, userID: moderator.ID, role: domain.RoleModerator, err: domain.ErrBlogNotFound},
+			{name: "aprobados", filter: domain.ModerationFilter{Status: domain.CommentStatusApproved}, userID: moderator.ID, role: domain.RoleModerator, err: domain.ErrInvalidCommentStatus},
+			{name: "estado inválido", filter: domain.ModerationFilter{Status: "borrado"}, userID: moderator.ID, role: domain.RoleModerator, err: domain.ErrInvalidCommentStatus},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				page, err := env.commentService.ListModerationQueue(ctx, tt.filter, firstPage(domain.SortOldest), tt.userID, tt.role)
+				checkErr(t, err, tt.err)
+				if err == nil && len(page.Items) != tt.want {
+					t.Errorf("se obtuvieron %d comentarios, se esperaban %d", len(page.Items), tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("ModerateComment", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			id     int64
+			status domain.CommentStatus
+			userID int64
+			role   domain.Role
+			err    error
+		}{
+			{name: "otro usuario", id: pending.ID, status: domain.CommentStatusApproved, userID: commenter.ID, role: domain.RoleUser, err: domain.ErrForbidden},
+			{name: "volver a pendiente", id: pending.ID, status: domain.CommentStatusPending, userID: author.ID, role: domain.RoleUser, err: domain.ErrInvalidModeration},
+			{name: "el autor del blog aprueba", id: pending.ID, status: domain.CommentStatusApproved, userID: author.ID, role: domain.RoleUser},
+			{name: "mismo estado", id: pending.ID, status: domain.CommentStatusApproved, userID: author.ID, role: domain.RoleUser, err: domain.ErrInvalidModeration},
+			{name: "un moderador marca spam", id: pending.ID, status: domain.CommentStatusSpam, userID: moderator.ID, role: domain.RoleModerator},
+			{name: "inexistente", id: 999, status: domain.CommentStatusApproved, userID: moderator.ID, role: domain.RoleModerator, err: domain.ErrCommentNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				moderated, err := env.commentService.ModerateComment(ctx, tt.id, tt.status, "motivo", tt.userID, tt.role)
+				checkErr(t, err, tt.err)
+				if err != nil {
+					return
+				}
+				found, err := env.comments.FindByID(ctx, tt.id)
+				checkErr(t, err, nil)
+				if moderated.Status != tt.status || found.Status != tt.status || found.ModeratedBy == nil || *found.ModeratedBy != tt.userID {
+					t.Errorf("comentario moderado = %+v", found)
+				}
+			})
+		}
+	})
+}
+
+func TestCommentServiceDeleteComment(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	other := env.createUser(t, "eva", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+
+	root := env.createComment(t, blog, commenter, nil)
+	reply := env.createComment(t, blog, other, root)
+	single := env.createComment(t, blog, commenter, nil)
+
+	tests := []struct {
+		name    string
+		id      int64
+		userID  int64
+		role    domain.Role
+		removed []int64
+		deleted []int64
+		err     error
+	}{
+		{name: "otro usuario", id: root.ID, userID: other.ID, role: domain.RoleUser, err: domain.ErrForbidden},
+		{name: "sin respuestas", id: single.ID, userID: commenter.ID, role: domain.RoleUser, deleted: []int64{single.ID}},
+		{name: "con respuestas", id: root.ID, userID: commenter.ID, role: domain.RoleUser, removed: []int64{root.ID}},
+		{name: "ya eliminado", id: root.ID, userID: commenter.ID, role: domain.RoleUser, err: domain.ErrCommentNotFound},
+		{name: "la última respuesta poda el padre", id: reply.ID, userID: other.ID, role: domain.RoleModerator, deleted: []int64{reply.ID, root.ID}},
+		{name: "inexistente", id: 999, userID: commenter.ID, role: domain.RoleAdmin, err: domain.ErrCommentNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkErr(t, env.commentService.DeleteComment(ctx, tt.id, tt.userID, tt.role), tt.err)
+			for _, id := range tt.removed {
+				found, err := env.comments.FindByID(ctx, id)
+				checkErr(t, err, nil)
+				if !found.Removed {
+					t.Errorf("el comentario %d no quedó como eliminado", id)
+				}
+			}
+			for _, id := range tt.deleted {
+				_, err := env.comments.FindByID(ctx, id)
+				checkErr(t, err, domain.ErrCommentNotFound)
+			}
+		})
+	}
+}
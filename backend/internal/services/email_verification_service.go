@@ -0,0 +1,183 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// EmailVerificationService implementa la verificación del correo de los
+// usuarios mediante enlaces firmados enviados por correo
+type EmailVerificationService struct {
+	userRepo       ports.UserRepository
+	authService    ports.AuthService
+	linkSigner     ports.LinkSigner
+	mailer         ports.Mailer
+	verifyURL      string
+	linkTTL        time.Duration
+	resendCooldown time.Duration
+}
+
+// NewEmailVerificationService crea una nueva instancia del servicio de verificación.
+// verifyURL es la página del frontend que recibe el token en el parámetro "token".
+func NewEmailVerificationService(userRepo ports.UserRepository, authService ports.AuthService, linkSigner ports.LinkSigner, mailer ports.Mailer, verifyURL string, linkTTL, resendCooldown time.Duration) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:       userRepo,
+		authService:    authService,
+		linkSigner:     linkSigner,
+		mailer:         mailer,
+		verifyURL:      verifyURL,
+		linkTTL:        linkTTL,
+		resendCooldown: resendCooldown,
+	}
+}
+
+// SendVerification envía al usuario un enlace para verificar su correo actual
+func (s *EmailVerificationService) SendVerification(ctx context.Context, user *domain.User) error {
+	if user.Email == "" {
+		return domain.ErrEmailRequired
+	}
+
+	now := time.Now()
+	token, err := s.linkSigner.Sign(domain.SignedLink{
+		Purpose:   domain.LinkPurposeEmailVerification,
+		UserID:    user.ID,
+		Email:     user.Email,
+		ExpiresAt: now.Add(s.linkTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	link, err := s.verifyLink(token)
+	if err != nil {
+		return err
+	}
+
+	// Se guarda antes de enviar para que los reenvíos queden limitados aunque el envío falle
+	user.VerificationSentAt = &now
+	if err := s.userRepo.UpdateEmailVerification(ctx, user); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, domain.EmailMessage{
+		To:      user.Email,
+		Subject: "Verifica tu correo electrónico",
+		Body: fmt.Sprintf("Hola %s:\n\n"+
+			"Para confirmar que esta dirección es tuya abre este enlace antes de %d horas:\n\n%s\n\n"+
+			"Si no creaste una cuenta puedes ignorar este correo.\n",
+			user.Username, int(s.linkTTL.Hours()), link),
+	})
+}
+
+// verifyLink añade el token a la URL de verificación
+func (s *EmailVerificationService) verifyLink(token string) (string, error) {
+	link, err := url.Parse(s.verifyURL)
+	if err != nil {
+		return "", fmt.Errorf("URL de verificación inválida: %w", err)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+// ResendVerification vuelve a enviar el enlace de verificación. Entre dos
+// envíos hay que esperar el tiempo configurado.
+func (s *EmailVerificationService) ResendVerification(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	if user.IsEmailVerified() {
+		return domain.ErrEmailAlreadyVerified
+	}
+
+	if wait := user.VerificationResendDelay(time.Now(), s.resendCooldown); wait > 0 {
+		return &domain.RetryAfterError{Err: domain.ErrResendThrottled, RetryAfter: wait}
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// VerifyEmail marca como verificado el correo del enlace. El enlace deja de
+// valer si el usuario cambió de correo después de recibirlo.
+func (s *EmailVerificationService) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	link, err := s.linkSigner.Verify(domain.LinkPurposeEmailVerification, token)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, link.UserID)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if user.Email == "" || user.Email != link.Email {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := s.userRepo.UpdateEmailVerification(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// ChangeEmail cambia el correo de un usuario tras verificar su contraseña y le
+// envía un enlace para verificar el nuevo. Con un correo vacío el usuario deja
+// de poder restablecer su contraseña.
+func (s *EmailVerificationService) ChangeEmail(ctx context.Context, userID int64, password, email string) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	if !s.authService.CheckPassword(password, user.Password) {
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	email, err = domain.NormalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if email == user.Email {
+		user.Password = ""
+		return user, nil
+	}
+
+	if email != "" {
+		existingUser, err := s.userRepo.FindByEmail(ctx, email)
+		if err != nil && err != domain.ErrUserNotFound {
+			return nil, err
+		}
+		if existingUser != nil {
+			return nil, domain.ErrEmailAlreadyExists
+		}
+	}
+
+	user.ChangeEmail(email)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if email != "" {
+		if err := s.SendVerification(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Password = ""
+	return user, nil
+}
@@ -0,0 +1,696 @@
+package services
+
+import (
+	"blog-backend/adapters/persistence/memory"
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ctx es el contexto de las llamadas de los tests del paquete
+var ctx = context.Background()
+
+// builtInRoles son los permisos de los roles predefinidos, como los crea la
+// migración de roles
+var builtInRoles = map[domain.Role][]domain.Permission{
+	domain.RoleAdmin: domain.Permissions,
+	domain.RoleUser:  {domain.PermBlogCreate, domain.PermBlogPublish, domain.PermCommentCreate},
+	domain.RoleEditor: {domain.PermBlogCreate, domain.PermBlogPublish, domain.PermBlogEdit, domain.PermBlogDelete,
+		domain.PermBlogReadUnpublished, domain.PermCommentCreate, domain.PermTagManage},
+	domain.RoleModerator: {domain.PermBlogCreate, domain.PermBlogPublish, domain.PermCommentCreate, domain.PermCommentModerate},
+}
+
+// roleLector es un rol sin permisos de escritura
+const roleLector domain.Role = "Lector"
+
+// roleGestor administra usuarios pero no roles
+const roleGestor domain.Role = "Gestor"
+
+// fakeRoleRepo guarda los roles predefinidos y los roles Lector y Gestor
+type fakeRoleRepo struct {
+	roles map[domain.Role]*domain.RoleDefinition
+}
+
+func newFakeRoleRepo() *fakeRoleRepo {
+	repo := &fakeRoleRepo{roles: map[domain.Role]*domain.RoleDefinition{
+		roleLector: {Name: roleLector, Permissions: []domain.Permission{}},
+		roleGestor: {Name: roleGestor, Permissions: []domain.Permission{
+			domain.PermUserManage, domain.PermBlogCreate, domain.PermBlogPublish, domain.PermCommentCreate,
+		}},
+	}}
+	for name, permissions := range builtInRoles {
+		repo.roles[name] = &domain.RoleDefinition{Name: name, BuiltIn: true, Permissions: permissions}
+	}
+	return repo
+}
+
+func (r *fakeRoleRepo) Create(_ context.Context, role *domain.RoleDefinition) error {
+	r.roles[role.Name] = role
+	return nil
+}
+
+func (r *fakeRoleRepo) FindByName(_ context.Context, name domain.Role) (*domain.RoleDefinition, error) {
+	role, ok := r.roles[name]
+	if !ok {
+		return nil, domain.ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (r *fakeRoleRepo) List(_ context.Context) ([]domain.RoleDefinition, error) {
+	var roles []domain.RoleDefinition
+	for _, role := range r.roles {
+		roles = append(roles, *role)
+	}
+	return roles, nil
+}
+
+func (r *fakeRoleRepo) Update(_ context.Context, role *domain.RoleDefinition) error {
+	r.roles[role.Name] = role
+	return nil
+}
+
+func (r *fakeRoleRepo) Delete(_ context.Context, name domain.Role) error {
+	delete(r.roles, name)
+	return nil
+}
+
+func (r *fakeRoleRepo) InUse(_ context.Context, name domain.Role) (bool, error) {
+	return false, nil
+}
+
+// fakeAuthorizer aplica los permisos de fakeRoleRepo como el autorizador basado en roles
+type fakeAuthorizer struct {
+	roles *fakeRoleRepo
+}
+
+func (a *fakeAuthorizer) Authorize(ctx context.Context, role domain.Role, permission domain.Permission) error {
+	definition, err := a.roles.FindByName(ctx, role)
+	if err != nil || !definition.Has(permission) {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+func (a *fakeAuthorizer) AuthorizeOwner(ctx context.Context, userID int64, role domain.Role, ownerID int64, permission domain.Permission) error {
+	if userID != 0 && userID == ownerID {
+		return nil
+	}
+	return a.Authorize(ctx, role, permission)
+}
+
+// fakeRevisionRepo guarda las revisiones en memoria
+type fakeRevisionRepo struct {
+	revisions []domain.Revision
+	// err, si no es nil, es el error que retorna Create
+	err error
+}
+
+func (r *fakeRevisionRepo) Create(_ context.Context, revision *domain.Revision) error {
+	if r.err != nil {
+		return r.err
+	}
+	revision.ID = int64(len(r.revisions) + 1)
+	revision.CreatedAt = time.Now()
+	r.revisions = append(r.revisions, *revision)
+	return nil
+}
+
+func (r *fakeRevisionRepo) FindByID(_ context.Context, id int64) (*domain.Revision, error) {
+	if id < 1 || id > int64(len(r.revisions)) {
+		return nil, domain.ErrRevisionNotFound
+	}
+	revision := r.revisions[id-1]
+	return &revision, nil
+}
+
+// FindByEntity retorna las revisiones de la más reciente a la más antigua
+func (r *fakeRevisionRepo) FindByEntity(_ context.Context, entityType domain.RevisionEntity, entityID int64) ([]domain.Revision, error) {
+	var revisions []domain.Revision
+	for i := len(r.revisions) - 1; i >= 0; i-- {
+		if r.revisions[i].EntityType == entityType && r.revisions[i].EntityID == entityID {
+			revisions = append(revisions, r.revisions[i])
+		}
+	}
+	return revisions, nil
+}
+
+// fakeRefreshTokenRepo guarda los tokens de refresco en memoria
+type fakeRefreshTokenRepo struct {
+	tokens []*domain.RefreshToken
+}
+
+func (r *fakeRefreshTokenRepo) Create(_ context.Context, token *domain.RefreshToken) error {
+	token.ID = int64(len(r.tokens) + 1)
+	stored := *token
+	r.tokens = append(r.tokens, &stored)
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) FindByHash(_ context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrInvalidToken
+}
+
+func (r *fakeRefreshTokenRepo) Revoke(_ context.Context, id int64) error {
+	token := r.tokens[id-1]
+	if token.RevokedAt != nil {
+		return domain.ErrTokenReused
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeFamily(_ context.Context, familyID string) error {
+	return r.revokeWhere(func(token *domain.RefreshToken) bool { return token.FamilyID == familyID })
+}
+
+func (r *fakeRefreshTokenRepo) RevokeAllForUser(_ context.Context, userID int64) error {
+	return r.revokeWhere(func(token *domain.RefreshToken) bool { return token.UserID == userID })
+}
+
+func (r *fakeRefreshTokenRepo) revokeWhere(match func(token *domain.RefreshToken) bool) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) IsFamilyActive(_ context.Context, familyID string) (bool, error) {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil && !token.IsExpired(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeLoginAttemptRepo guarda la auditoría de inicios de sesión en memoria
+type fakeLoginAttemptRepo struct {
+	attempts []domain.LoginAttempt
+}
+
+func (r *fakeLoginAttemptRepo) Create(_ context.Context, attempt *domain.LoginAttempt) error {
+	attempt.ID = int64(len(r.attempts) + 1)
+	attempt.CreatedAt = time.Now()
+	r.attempts = append(r.attempts, *attempt)
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) CountFailuresByIP(_ context.Context, ip string, since time.Time) (int64, time.Time, error) {
+	var count int64
+	var oldest time.Time
+	for _, attempt := range r.attempts {
+		if attempt.IP == ip && attempt.Reason == domain.LoginReasonInvalidCredentials && !attempt.CreatedAt.Before(since) {
+			if count == 0 {
+				oldest = attempt.CreatedAt
+			}
+			count++
+		}
+	}
+	return count, oldest, nil
+}
+
+func (r *fakeLoginAttemptRepo) ListByUser(_ context.Context, userID int64, page domain.PageRequest) (*domain.Page[domain.LoginAttempt], error) {
+	var attempts []domain.LoginAttempt
+	for i := len(r.attempts) - 1; i >= 0; i-- {
+		if r.attempts[i].UserID != nil && *r.attempts[i].UserID == userID {
+			attempts = append(attempts, r.attempts[i])
+		}
+	}
+	return domain.NewPage(attempts, page, func(attempt domain.LoginAttempt) domain.Cursor {
+		return domain.Cursor{ID: attempt.ID}
+	}), nil
+}
+
+// reasons retorna los motivos de los intentos registrados ("" para los correctos)
+func (r *fakeLoginAttemptRepo) reasons() []string {
+	reasons := make([]string, len(r.attempts))
+	for i, attempt := range r.attempts {
+		reasons[i] = attempt.Reason
+	}
+	return reasons
+}
+
+// fakeAuth implementa ports.AuthService sin criptografía: los hashes son
+// prefijos y los tokens de acceso contienen el usuario y la sesión en claro
+type fakeAuth struct {
+	nextToken int
+	// checks cuenta las comparaciones de contraseñas
+	checks int
+}
+
+func (a *fakeAuth) GenerateToken(user *domain.User, sessionID string) (string, error) {
+	return fmt.Sprintf("access:%d:%s", user.ID, sessionID), nil
+}
+
+func (a *fakeAuth) AccessTokenTTL() time.Duration {
+	return 15 * time.Minute
+}
+
+func (a *fakeAuth) ValidateToken(token string) (*domain.TokenClaims, error) {
+	parts := strings.SplitN(token, ":", 3)
+	if len(parts) != 3 || parts[0] != "access" {
+		return nil, domain.ErrInvalidToken
+	}
+	userID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+	return &domain.TokenClaims{UserID: userID, SessionID: parts[2]}, nil
+}
+
+func (a *fakeAuth) HashPassword(password string) (string, error) {
+	return "hash:" + password, nil
+}
+
+func (a *fakeAuth) CheckPassword(password, hash string) bool {
+	a.checks++
+	return hash == "hash:"+password
+}
+
+func (a *fakeAuth) GenerateOpaqueToken() (string, error) {
+	a.nextToken++
+	return fmt.Sprintf("opaque-%d", a.nextToken), nil
+}
+
+func (a *fakeAuth) HashToken(token string) string {
+	return "sha:" + token
+}
+
+// fakeLinkSigner guarda los enlaces firmados y los identifica por un token secuencial
+type fakeLinkSigner struct {
+	links map[string]domain.SignedLink
+}
+
+func (s *fakeLinkSigner) Sign(link domain.SignedLink) (string, error) {
+	if s.links == nil {
+		s.links = make(map[string]domain.SignedLink)
+	}
+	token := fmt.Sprintf("link-%d", len(s.links)+1)
+	s.links[token] = link
+	return token, nil
+}
+
+func (s *fakeLinkSigner) Verify(purpose, token string) (*domain.SignedLink, error) {
+	link, ok := s.links[token]
+	if !ok || link.Purpose != purpose || !time.Now().Before(link.ExpiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+	return &link, nil
+}
+
+// fakeMailer guarda los correos enviados; con err definido todos los envíos fallan
+type fakeMailer struct {
+	sent []domain.EmailMessage
+	err  error
+}
+
+func (m *fakeMailer) Send(_ context.Context, message domain.EmailMessage) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, message)
+	return nil
+}
+
+// fakeContentFilter retiene los comentarios que contienen "[hold]" y rechaza los que contienen "[reject]"
+type fakeContentFilter struct{}
+
+func (fakeContentFilter) Check(_ context.Context, input domain.FilterInput) (domain.FilterVerdict, error) {
+	switch {
+	case strings.Contains(input.Content, "[reject]"):
+		return domain.FilterVerdict{Action: domain.FilterReject, Filter: "prueba"}, nil
+	case strings.Contains(input.Content, "[hold]"):
+		return domain.FilterVerdict{Action: domain.FilterHold, Filter: "prueba"}, nil
+	}
+	return domain.AllowVerdict, nil
+}
+
+// fakeTOTP acepta cualquier código de seis dígitos distinto de cero y usa su
+// valor como intervalo, de modo que las pruebas eligen códigos crecientes
+type fakeTOTP struct {
+	nextRecovery int
+}
+
+func (f *fakeTOTP) GenerateSecret() (string, error) {
+	return "SECRETO", nil
+}
+
+func (f *fakeTOTP) ProvisioningURI(secret, accountName string) string {
+	return "otpauth://totp/" + accountName + "?secret=" + secret
+}
+
+func (f *fakeTOTP) Validate(secret, code string, now time.Time) (int64, bool) {
+	step, err := strconv.ParseInt(code, 10, 64)
+	if secret == "" || err != nil || step <= 0 {
+		return 0, false
+	}
+	return step, true
+}
+
+func (f *fakeTOTP) GenerateRecoveryCode() (string, error) {
+	f.nextRecovery++
+	return fmt.Sprintf("recuperacion%d", f.nextRecovery), nil
+}
+
+// fakeCipher "cifra" los secretos con un prefijo
+type fakeCipher struct{}
+
+func (fakeCipher) Encrypt(plaintext string) (string, error) {
+	return "cifrado:" + plaintext, nil
+}
+
+func (fakeCipher) Decrypt(ciphertext string) (string, error) {
+	plaintext, ok := strings.CutPrefix(ciphertext, "cifrado:")
+	if !ok {
+		return "", errors.New("secreto sin cifrar")
+	}
+	return plaintext, nil
+}
+
+// fakeRecoveryCodeRepo guarda los hashes de los códigos de recuperación sin usar
+type fakeRecoveryCodeRepo struct {
+	codes map[int64]map[string]bool
+}
+
+func (r *fakeRecoveryCodeRepo) ReplaceForUser(_ context.Context, userID int64, codeHashes []string) error {
+	if r.codes == nil {
+		r.codes = make(map[int64]map[string]bool)
+	}
+	r.codes[userID] = make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		r.codes[userID][hash] = true
+	}
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepo) Use(_ context.Context, userID int64, codeHash string) error {
+	if !r.codes[userID][codeHash] {
+		return domain.ErrInvalidTOTPCode
+	}
+	delete(r.codes[userID], codeHash)
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepo) CountUnused(_ context.Context, userID int64) (int, error) {
+	return len(r.codes[userID]), nil
+}
+
+func (r *fakeRecoveryCodeRepo) DeleteForUser(_ context.Context, userID int64) error {
+	delete(r.codes, userID)
+	return nil
+}
+
+// fakePasswordResetTokenRepo guarda los tokens de restablecimiento en memoria
+type fakePasswordResetTokenRepo struct {
+	tokens []*domain.PasswordResetToken
+}
+
+func (r *fakePasswordResetTokenRepo) Create(_ context.Context, token *domain.PasswordResetToken) error {
+	token.ID = int64(len(r.tokens) + 1)
+	stored := *token
+	r.tokens = append(r.tokens, &stored)
+	return nil
+}
+
+func (r *fakePasswordResetTokenRepo) FindByHash(_ context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	for _, token := range r.tokens {
+		if token != nil && token.TokenHash == tokenHash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrInvalidToken
+}
+
+func (r *fakePasswordResetTokenRepo) MarkUsed(_ context.Context, id int64) error {
+	token := r.tokens[id-1]
+	if token == nil || token.UsedAt != nil {
+		return domain.ErrInvalidToken
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	return nil
+}
+
+func (r *fakePasswordResetTokenRepo) DeleteForUser(_ context.Context, userID int64) error {
+	for i, token := range r.tokens {
+		if token != nil && token.UserID == userID {
+			r.tokens[i] = nil
+		}
+	}
+	return nil
+}
+
+// fakeIdentityProvider canjea los códigos por los claims registrados en claims
+// si el code_verifier es el del state con el que se pidió la autorización
+type fakeIdentityProvider struct {
+	claims    map[string]*domain.IdentityClaims
+	verifiers map[string]string
+}
+
+func (p *fakeIdentityProvider) Name() string {
+	return "idp"
+}
+
+func (p *fakeIdentityProvider) AuthCodeURL(_ context.Context, state, _, codeVerifier string) (string, error) {
+	if p.verifiers == nil {
+		p.verifiers = make(map[string]string)
+	}
+	p.verifiers[codeVerifier] = state
+	return "https://idp.example.com/authorize?state=" + state, nil
+}
+
+func (p *fakeIdentityProvider) Exchange(_ context.Context, code, codeVerifier, _ string) (*domain.IdentityClaims, error) {
+	claims, ok := p.claims[code]
+	if !ok || p.verifiers[codeVerifier] == "" {
+		return nil, domain.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// fakeIdentityRepo guarda las identidades externas en memoria
+type fakeIdentityRepo struct {
+	identities []*domain.ExternalIdentity
+}
+
+func (r *fakeIdentityRepo) Create(_ context.Context, identity *domain.ExternalIdentity) error {
+	identity.ID = int64(len(r.identities) + 1)
+	identity.CreatedAt = time.Now()
+	stored := *identity
+	r.identities = append(r.identities, &stored)
+	return nil
+}
+
+func (r *fakeIdentityRepo) FindBySubject(_ context.Context, provider, subject string) (*domain.ExternalIdentity, error) {
+	for _, identity := range r.identities {
+		if identity != nil && identity.Provider == provider && identity.Subject == subject {
+			found := *identity
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrIdentityNotFound
+}
+
+func (r *fakeIdentityRepo) ListByUser(_ context.Context, userID int64) ([]domain.ExternalIdentity, error) {
+	var identities []domain.ExternalIdentity
+	for _, identity := range r.identities {
+		if identity != nil && identity.UserID == userID {
+			identities = append(identities, *identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *fakeIdentityRepo) UpdateLastLogin(_ context.Context, id int64, at time.Time) error {
+	r.identities[id-1].LastLoginAt = &at
+	return nil
+}
+
+func (r *fakeIdentityRepo) Delete(_ context.Context, id, userID int64) error {
+	if id < 1 || id > int64(len(r.identities)) || r.identities[id-1] == nil || r.identities[id-1].UserID != userID {
+		return domain.ErrIdentityNotFound
+	}
+	r.identities[id-1] = nil
+	return nil
+}
+
+// fakeOIDCStateRepo guarda los logins con OpenID Connect en curso
+type fakeOIDCStateRepo struct {
+	states []domain.OIDCLoginState
+}
+
+func (r *fakeOIDCStateRepo) Create(_ context.Context, state *domain.OIDCLoginState) error {
+	state.ID = int64(len(r.states) + 1)
+	r.states = append(r.states, *state)
+	return nil
+}
+
+func (r *fakeOIDCStateRepo) Take(_ context.Context, stateHash string) (*domain.OIDCLoginState, error) {
+	for i, state := range r.states {
+		if state.StateHash == stateHash {
+			r.states = append(r.states[:i], r.states[i+1:]...)
+			return &state, nil
+		}
+	}
+	return nil, domain.ErrInvalidToken
+}
+
+func (r *fakeOIDCStateRepo) DeleteExpired(_ context.Context, now time.Time) error {
+	states := r.states[:0]
+	for _, state := range r.states {
+		if !state.IsExpired(now) {
+			states = append(states, state)
+		}
+	}
+	r.states = states
+	return nil
+}
+
+// testEnv reúne los servicios bajo prueba sobre los repositorios en memoria y los dobles de prueba
+type testEnv struct {
+	users    ports.UserRepository
+	blogs    ports.BlogRepository
+	comments ports.CommentRepository
+	tags     ports.TagRepository
+
+	revisions     *fakeRevisionRepo
+	refreshTokens *fakeRefreshTokenRepo
+	loginAttempts *fakeLoginAttemptRepo
+	auth          *fakeAuth
+	mailer        *fakeMailer
+	links         *fakeLinkSigner
+	recoveryCodes *fakeRecoveryCodeRepo
+	resetTokens   *fakePasswordResetTokenRepo
+	tx            *memory.TxManager
+
+	blogService    *BlogService
+	commentService *CommentService
+	userService    *UserService
+	authService    *AuthService
+	twoFactor      *TwoFactorService
+	trashService   *TrashService
+	passwordReset  *PasswordResetService
+}
+
+// testLockout bloquea la cuenta tras tres fallos y la IP tras cinco
+var testLockout = domain.LockoutPolicy{
+	MaxAccountFailures: 3,
+	MaxIPFailures:      5,
+	FailureWindow:      time.Hour,
+	LockoutDuration:    time.Hour,
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	return newTestEnvWithPolicies(t, domain.DeletionCascade, domain.DeletionCascade)
+}
+
+// newTestEnvWithPolicies crea el entorno con las políticas de eliminación de usuarios y blogs indicadas
+func newTestEnvWithPolicies(t *testing.T, userPolicy, blogPolicy domain.DeletionPolicy) *testEnv {
+	t.Helper()
+
+	store := memory.NewStore()
+	roles := newFakeRoleRepo()
+	authorizer := &fakeAuthorizer{roles: roles}
+	env := &testEnv{
+		users:         memory.NewUserRepository(store),
+		blogs:         memory.NewBlogRepository(store),
+		comments:      memory.NewCommentRepository(store),
+		tags:          memory.NewTagRepository(store),
+		revisions:     &fakeRevisionRepo{},
+		refreshTokens: &fakeRefreshTokenRepo{},
+		loginAttempts: &fakeLoginAttemptRepo{},
+		auth:          &fakeAuth{},
+		mailer:        &fakeMailer{},
+		links:         &fakeLinkSigner{},
+		recoveryCodes: &fakeRecoveryCodeRepo{},
+		resetTokens:   &fakePasswordResetTokenRepo{},
+		tx:            memory.NewTxManager(store),
+	}
+
+	emailVerification := NewEmailVerificationService(env.users, env.auth, env.links, env.mailer,
+		"https://blog.example.com/verificar", 24*time.Hour, time.Minute)
+	env.twoFactor = NewTwoFactorService(env.users, roles, env.recoveryCodes, env.refreshTokens, env.auth, &fakeTOTP{}, fakeCipher{})
+	env.blogService = NewBlogService(env.blogs, env.users, env.revisions, env.tags, env.comments, authorizer, env.tx, blogPolicy)
+	env.commentService = NewCommentService(env.comments, env.blogs, env.users, env.revisions, authorizer, fakeContentFilter{}, env.tx, false)
+	env.userService = NewUserService(env.users, roles, env.refreshTokens, env.blogs, env.comments, env.auth, emailVerification, env.tx, userPolicy)
+	env.authService = NewAuthService(env.users, env.refreshTokens, env.loginAttempts, env.auth, env.links, env.twoFactor,
+		24*time.Hour, 5*time.Minute, testLockout)
+	env.trashService = NewTrashService(env.blogs, env.comments, env.users, env.tx, 30*24*time.Hour)
+	env.passwordReset = NewPasswordResetService(env.users, env.resetTokens, env.refreshTokens, env.auth, env.mailer, env.tx,
+		"https://blog.example.com/restablecer", time.Hour)
+	return env
+}
+
+// createUser guarda un usuario con la contraseña "secreto" y el rol indicado
+func (env *testEnv) createUser(t *testing.T, username string, role domain.Role) *domain.User {
+	t.Helper()
+	user := &domain.User{Username: username, Email: username + "@example.com", Password: "hash:secreto", Role: role}
+	if err := env.users.Create(ctx, user); err != nil {
+		t.Fatalf("creando usuario %s: %v", username, err)
+	}
+	return user
+}
+
+// createBlog guarda un blog de author con el estado indicado
+func (env *testEnv) createBlog(t *testing.T, author *domain.User, status domain.BlogStatus) *domain.Blog {
+	t.Helper()
+	blog := &domain.Blog{Title: "Título", Content: "Contenido", AuthorID: author.ID}
+	var publishAt *time.Time
+	if status == domain.BlogStatusScheduled {
+		at := time.Now().Add(time.Hour)
+		publishAt = &at
+	}
+	if err := blog.SetStatus(status, publishAt, time.Now()); err != nil {
+		t.Fatalf("estado del blog: %v", err)
+	}
+	if err := env.blogs.Create(ctx, blog); err != nil {
+		t.Fatalf("creando blog: %v", err)
+	}
+	return blog
+}
+
+// createComment guarda un comentario aprobado de user; parent es nil para los comentarios raíz
+func (env *testEnv) createComment(t *testing.T, blog *domain.Blog, user *domain.User, parent *domain.Comment) *domain.Comment {
+	t.Helper()
+	var parentID *int64
+	if parent != nil {
+		parentID = &parent.ID
+	}
+	comment, err := env.commentService.CreateComment(ctx, blog.ID, user.ID, "comentario", parentID)
+	if err != nil {
+		t.Fatalf("creando comentario: %v", err)
+	}
+	return comment
+}
+
+// checkErr falla si err no es want
+func checkErr(t *testing.T, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Fatalf("error = %v, se esperaba %v", err, want)
+	}
+}
+
+// firstPage es la primera página de un listado con el orden indicado
+func firstPage(sort domain.SortOrder) domain.PageRequest {
+	return domain.PageRequest{Limit: domain.DefaultPageLimit, Sort: sort}
+}
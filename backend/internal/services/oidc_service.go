@@ -0,0 +1,321 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxUsernameLength es la longitud máxima de la columna users.username
+const maxUsernameLength = 50
+
+// OIDCService implementa el inicio de sesión con un proveedor de identidad
+// OpenID Connect y la vinculación de sus cuentas con los usuarios locales
+type OIDCService struct {
+	provider     ports.IdentityProvider
+	identityRepo ports.IdentityRepository
+	stateRepo    ports.OIDCStateRepository
+	userRepo     ports.UserRepository
+	authService  ports.AuthService
+	auth         *AuthService
+	policy       domain.IdentityPolicy
+	stateTTL     time.Duration
+}
+
+// NewOIDCService crea una nueva instancia del servicio de OpenID Connect. Con
+// provider nil el inicio de sesión con OpenID Connect queda desactivado.
+// stateTTL es el tiempo que tiene el usuario para autenticarse en el proveedor.
+func NewOIDCService(provider ports.IdentityProvider, identityRepo ports.IdentityRepository, stateRepo ports.OIDCStateRepository, userRepo ports.UserRepository, authService ports.AuthService, auth *AuthService, policy domain.IdentityPolicy, stateTTL time.Duration) *OIDCService {
+	return &OIDCService{
+		provider:     provider,
+		identityRepo: identityRepo,
+		stateRepo:    stateRepo,
+		userRepo:     userRepo,
+		authService:  authService,
+		auth:         auth,
+		policy:       policy,
+		stateTTL:     stateTTL,
+	}
+}
+
+// StartLogin inicia un login con el proveedor y retorna la URL de autorización
+// a la que se debe redirigir al usuario y el state del login. El state se debe
+// guardar en el navegador que inició el login (por ejemplo en una cookie
+// HttpOnly) y entregar a CompleteLogin para que el login no se pueda completar
+// desde otro navegador.
+func (s *OIDCService) StartLogin(ctx context.Context) (authURL, state string, err error) {
+	return s.start(ctx, 0)
+}
+
+// StartLink inicia la vinculación de la cuenta del proveedor con el usuario
+// autenticado y retorna la URL de autorización y el state, como StartLogin
+func (s *OIDCService) StartLink(ctx context.Context, userID int64) (authURL, state string, err error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user.IsDeleted() {
+		return "", "", domain.ErrUserNotFound
+	}
+	return s.start(ctx, user.ID)
+}
+
+// start guarda el state, el nonce y el code_verifier PKCE del login y construye
+// la URL de autorización. Solo el state viaja por el navegador.
+func (s *OIDCService) start(ctx context.Context, userID int64) (string, string, error) {
+	if s.provider == nil {
+		return "", "", domain.ErrOIDCDisabled
+	}
+
+	now := time.Now()
+	if err := s.stateRepo.DeleteExpired(ctx, now); err != nil {
+		return "", "", err
+	}
+
+	var values [3]string
+	for i := range values {
+		value, err := s.authService.GenerateOpaqueToken()
+		if err != nil {
+			return "", "", err
+		}
+		values[i] = value
+	}
+	state, nonce, codeVerifier := values[0], values[1], values[2]
+
+	stored := &domain.OIDCLoginState{
+		StateHash:    s.authService.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		UserID:       userID,
+		ExpiresAt:    now.Add(s.stateTTL),
+	}
+	if err := s.stateRepo.Create(ctx, stored); err != nil {
+		return "", "", err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// CompleteLogin canjea el código de autorización que el proveedor entregó al
+// volver con state y abre una sesión para el usuario vinculado a la identidad.
+// browserState es el state que guardó el navegador al iniciar el login; si no
+// coincide el login se rechaza sin consumir el state, porque lo inició otro
+// navegador. Si el login empezó con StartLink la identidad se vincula antes a
+// ese usuario.
+func (s *OIDCService) CompleteLogin(ctx context.Context, code, state, browserState string, client domain.LoginClient) (*domain.LoginResult, error) {
+	if s.provider == nil {
+		return nil, domain.ErrOIDCDisabled
+	}
+
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, domain.ErrInvalidToken
+	}
+
+	stored, err := s.stateRepo.Take(ctx, s.authService.HashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if stored.IsExpired(time.Now()) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	claims, err := s.provider.Exchange(ctx, code, stored.CodeVerifier, stored.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, identity, err := s.resolveUser(ctx, claims, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRoleMapping(ctx, user, claims); err != nil {
+		return nil, err
+	}
+
+	if err := s.identityRepo.UpdateLastLogin(ctx, identity.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.auth.LoginWithIdentity(ctx, user, client)
+}
+
+// resolveUser busca el usuario vinculado a la identidad. Si no hay ninguno la
+// vincula con linkUserID, con el usuario del mismo correo o con un usuario
+// nuevo, según la política de identidades.
+func (s *OIDCService) resolveUser(ctx context.Context, claims *domain.IdentityClaims, linkUserID int64) (*domain.User, *domain.ExternalIdentity, error) {
+	identity, err := s.identityRepo.FindBySubject(ctx, s.provider.Name(), claims.Subject)
+	if err == nil {
+		if linkUserID != 0 && identity.UserID != linkUserID {
+			return nil, nil, domain.ErrIdentityLinked
+		}
+
+		user, err := s.userRepo.FindByID(ctx, identity.UserID)
+		if err != nil || user.IsDeleted() {
+			return nil, nil, domain.ErrInvalidCredentials
+		}
+		return user, identity, nil
+	}
+	if err != domain.ErrIdentityNotFound {
+		return nil, nil, err
+	}
+
+	var user *domain.User
+	switch {
+	case linkUserID != 0:
+		if user, err = s.userRepo.FindByID(ctx, linkUserID); err != nil || user.IsDeleted() {
+			return nil, nil, domain.ErrUserNotFound
+		}
+	default:
+		if user, err = s.findUserByEmail(ctx, claims); err != nil {
+			return nil, nil, err
+		}
+		if user == nil {
+			if !s.policy.AutoCreateUsers {
+				return nil, nil, domain.ErrAccountNotLinked
+			}
+			if user, err = s.createUser(ctx, claims); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	identity = &domain.ExternalIdentity{
+		UserID:   user.ID,
+		Provider: s.provider.Name(),
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, nil, err
+	}
+
+	return user, identity, nil
+}
+
+// findUserByEmail busca el usuario con el correo de la identidad si la política
+// lo permite y tanto el proveedor como el usuario lo verificaron. Si no hay
+// ninguno retorna nil sin error.
+func (s *OIDCService) findUserByEmail(ctx context.Context, claims *domain.IdentityClaims) (*domain.User, error) {
+	if !s.policy.LinkByEmail || !claims.EmailVerified {
+		return nil, nil
+	}
+
+	email, err := domain.NormalizeEmail(claims.Email)
+	if err != nil || email == "" {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if err == domain.ErrUserNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if user.IsDeleted() || !user.IsEmailVerified() {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// createUser crea un usuario para una identidad sin vincular. Su contraseña es
+// aleatoria: para entrar con contraseña debe restablecerla por correo.
+func (s *OIDCService) createUser(ctx context.Context, claims *domain.IdentityClaims) (*domain.User, error) {
+	username, err := s.availableUsername(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := s.authService.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := s.authService.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		Username: username,
+		Password: hashedPassword,
+		Role:     domain.RoleUser,
+	}
+	if role, ok := s.policy.RoleMapping.Resolve(claims.Roles); ok {
+		user.Role = role
+	}
+
+	// El correo solo se copia si nadie más lo usa
+	if email, err := domain.NormalizeEmail(claims.Email); err == nil && email != "" {
+		if _, err := s.userRepo.FindByEmail(ctx, email); err == domain.ErrUserNotFound {
+			user.Email = email
+			if claims.EmailVerified {
+				now := time.Now()
+				user.EmailVerifiedAt = &now
+			}
+		}
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// availableUsername elige un nombre de usuario libre y no reservado a partir
+// del preferred_username o del correo de la identidad
+func (s *OIDCService) availableUsername(ctx context.Context, claims *domain.IdentityClaims) (string, error) {
+	base := strings.TrimSpace(claims.PreferredUsername)
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+		base = strings.TrimSpace(base)
+	}
+	if base == "" {
+		base = "usuario"
+	}
+	// Se reserva espacio para el sufijo numérico
+	if runes := []rune(base); len(runes) > maxUsernameLength-4 {
+		base = string(runes[:maxUsernameLength-4])
+	}
+
+	username := base
+	for i := 2; i <= 100; i++ {
+		if domain.IsReservedUsername(username) {
+			username = fmt.Sprintf("%s-%d", base, i)
+			continue
+		}
+		if existingUser, _ := s.userRepo.FindByUsername(ctx, username); existingUser == nil {
+			return username, nil
+		}
+		username = fmt.Sprintf("%s-%d", base, i)
+	}
+	return "", domain.ErrUserAlreadyExists
+}
+
+// applyRoleMapping asigna al usuario el rol que corresponde a los valores del
+// claim de roles. Si ninguna regla coincide conserva su rol.
+func (s *OIDCService) applyRoleMapping(ctx context.Context, user *domain.User, claims *domain.IdentityClaims) error {
+	role, ok := s.policy.RoleMapping.Resolve(claims.Roles)
+	if !ok || role == user.Role {
+		return nil
+	}
+
+	user.Role = role
+	return s.userRepo.Update(ctx, user)
+}
+
+// ListIdentities lista las identidades externas vinculadas a un usuario
+func (s *OIDCService) ListIdentities(ctx context.Context, userID int64) ([]domain.ExternalIdentity, error) {
+	return s.identityRepo.ListByUser(ctx, userID)
+}
+
+// Unlink desvincula una identidad externa del usuario
+func (s *OIDCService) Unlink(ctx context.Context, userID, identityID int64) error {
+	return s.identityRepo.Delete(ctx, identityID, userID)
+}
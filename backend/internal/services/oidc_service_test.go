@@ -0,0 +1,290 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"testing"
+	"time"
+)
+
+// oidcEnv reúne el servicio de OpenID Connect con su proveedor y sus repositorios de prueba
+type oidcEnv struct {
+	*testEnv
+	provider   *fakeIdentityProvider
+	identities *fakeIdentityRepo
+	states     *fakeOIDCStateRepo
+	service    *OIDCService
+}
+
+func newOIDCEnv(t *testing.T, policy domain.IdentityPolicy) *oidcEnv {
+	t.Helper()
+	env := &oidcEnv{
+		testEnv:    newTestEnv(t),
+		provider:   &fakeIdentityProvider{claims: make(map[string]*domain.IdentityClaims)},
+		identities: &fakeIdentityRepo{},
+		states:     &fakeOIDCStateRepo{},
+	}
+	env.service = NewOIDCService(env.provider, env.identities, env.states, env.users, env.auth, env.authService, policy, 10*time.Minute)
+	return env
+}
+
+// complete vuelve del proveedor con code desde el navegador que inició el login
+func (env *oidcEnv) complete(t *testing.T, code string, linkUserID int64) (*domain.LoginResult, error) {
+	t.Helper()
+	var state string
+	var err error
+	if linkUserID != 0 {
+		_, state, err = env.service.StartLink(ctx, linkUserID)
+	} else {
+		_, state, err = env.service.StartLogin(ctx)
+	}
+	checkErr(t, err, nil)
+	return env.service.CompleteLogin(ctx, code, state, state, domain.LoginClient{IP: "10.0.0.1"})
+}
+
+// verifyEmail marca como verificado el correo de user
+func (env *oidcEnv) verifyEmail(t *testing.T, user *domain.User) {
+	t.Helper()
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	checkErr(t, env.users.UpdateEmailVerification(ctx, user), nil)
+}
+
+func TestOIDCServiceState(t *testing.T) {
+	env := newOIDCEnv(t, domain.IdentityPolicy{AutoCreateUsers: true})
+	env.provider.claims["code"] = &domain.IdentityClaims{Subject: "sub-ana", PreferredUsername: "ana"}
+	client := domain.LoginClient{IP: "10.0.0.1"}
+
+	authURL, state, err := env.service.StartLogin(ctx)
+	checkErr(t, err, nil)
+	if authURL != "https://idp.example.com/authorize?state="+state {
+		t.Fatalf("StartLogin = %q", authURL)
+	}
+
+	// Otro navegador no puede completar el login ni consumir el state
+	_, err = env.service.CompleteLogin(ctx, "code", state, "", client)
+	checkErr(t, err, domain.ErrInvalidToken)
+	_, err = env.service.CompleteLogin(ctx, "code", state, "otro", client)
+	checkErr(t, err, domain.ErrInvalidToken)
+
+	result, err := env.service.CompleteLogin(ctx, "code", state, state, client)
+	checkErr(t, err, nil)
+	if result.Tokens == nil || result.User.Username != "ana" {
+		t.Fatalf("CompleteLogin = %+v", result)
+	}
+
+	// Cada state sirve una vez
+	_, err = env.service.CompleteLogin(ctx, "code", state, state, client)
+	checkErr(t, err, domain.ErrInvalidToken)
+
+	// Los states caducados no sirven
+	_, state, err = env.service.StartLogin(ctx)
+	checkErr(t, err, nil)
+	env.states.states[0].ExpiresAt = time.Now().Add(-time.Second)
+	_, err = env.service.CompleteLogin(ctx, "code", state, state, client)
+	checkErr(t, err, domain.ErrInvalidToken)
+
+	// Sin proveedor el login con OpenID Connect está desactivado
+	disabled := NewOIDCService(nil, env.identities, env.states, env.users, env.auth, env.authService, domain.IdentityPolicy{}, time.Minute)
+	_, _, err = disabled.StartLogin(ctx)
+	checkErr(t, err, domain.ErrOIDCDisabled)
+	_, err = disabled.CompleteLogin(ctx, "code", state, state, client)
+	checkErr(t, err, domain.ErrOIDCDisabled)
+}
+
+func TestOIDCServiceResolveUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy domain.IdentityPolicy
+		claims domain.IdentityClaims
+		// verified indica si el usuario existente "ana" verificó su correo
+		verified bool
+		want     string
+		err      error
+	}{
+		{
+			name:   "crea un usuario",
+			policy: domain.IdentityPolicy{AutoCreateUsers: true},
+			claims: domain.IdentityClaims{Subject: "sub", Email: "eva@corp.example", EmailVerified: true, PreferredUsername: "eva"},
+			want:   "eva",
+		},
+		{
+			name:   "nombre ocupado",
+			policy: domain.IdentityPolicy{AutoCreateUsers: true},
+			claims: domain.IdentityClaims{Subject: "sub", PreferredUsername: "ana"},
+			want:   "ana-2",
+		},
+		{
+			name:   "nombre a partir del correo",
+			policy: domain.IdentityPolicy{AutoCreateUsers: true},
+			claims: domain.IdentityClaims{Subject: "sub", Email: "eva@corp.example"},
+			want:   "eva",
+		},
+		{
+			name:   "nombre reservado",
+			policy: domain.IdentityPolicy{AutoCreateUsers: true},
+			claims: domain.IdentityClaims{Subject: "sub", PreferredUsername: domain.DeletedUsername},
+			want:   domain.DeletedUsername + "-2",
+		},
+		{
+			name:     "vincula por correo",
+			policy:   domain.IdentityPolicy{LinkByEmail: true},
+			claims:   domain.IdentityClaims{Subject: "sub", Email: "ANA@example.com", EmailVerified: true},
+			verified: true,
+			want:     "ana",
+		},
+		{
+			name:     "correo sin verificar en el proveedor",
+			policy:   domain.IdentityPolicy{LinkByEmail: true},
+			claims:   domain.IdentityClaims{Subject: "sub", Email: "ana@example.com"},
+			verified: true,
+			err:      domain.ErrAccountNotLinked,
+		},
+		{
+			name:   "correo sin verificar en la cuenta",
+			policy: domain.IdentityPolicy{LinkByEmail: true},
+			claims: domain.IdentityClaims{Subject: "sub", Email: "ana@example.com", EmailVerified: true},
+			err:    domain.ErrAccountNotLinked,
+		},
+		{
+			name:     "sin vincular por correo",
+			policy:   domain.IdentityPolicy{},
+			claims:   domain.IdentityClaims{Subject: "sub", Email: "ana@example.com", EmailVerified: true},
+			verified: true,
+			err:      domain.ErrAccountNotLinked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCEnv(t, tt.policy)
+			ana := env.createUser(t, "ana", domain.RoleUser)
+			if tt.verified {
+				env.verifyEmail(t, ana)
+			}
+			env.provider.claims["code"] = &tt.claims
+
+			result, err := env.complete(t, "code", 0)
+			checkErr(t, err, tt.err)
+			if err != nil {
+				if len(env.identities.identities) != 0 {
+					t.Errorf("identidades = %+v", env.identities.identities)
+				}
+				return
+			}
+			if result.User.Username != tt.want {
+				t.Fatalf("usuario = %q, se esperaba %q", result.User.Username, tt.want)
+			}
+
+			// La identidad queda vinculada: el siguiente login entra con el mismo usuario
+			again, err := env.complete(t, "code", 0)
+			checkErr(t, err, nil)
+			if again.User.ID != result.User.ID || len(env.identities.identities) != 1 {
+				t.Errorf("segundo login = %+v, identidades = %d", again.User, len(env.identities.identities))
+			}
+			if env.identities.identities[0].LastLoginAt == nil {
+				t.Error("no se registró el último login de la identidad")
+			}
+		})
+	}
+}
+
+func TestOIDCServiceCreatedUser(t *testing.T) {
+	env := newOIDCEnv(t, domain.IdentityPolicy{AutoCreateUsers: true})
+	env.createUser(t, "ana", domain.RoleUser)
+	env.provider.claims["verificado"] = &domain.IdentityClaims{Subject: "1", Email: "Eva@Corp.example", EmailVerified: true}
+	env.provider.claims["repetido"] = &domain.IdentityClaims{Subject: "2", Email: "ana@example.com", EmailVerified: true}
+
+	result, err := env.complete(t, "verificado", 0)
+	checkErr(t, err, nil)
+	stored, err := env.users.FindByID(ctx, result.User.ID)
+	checkErr(t, err, nil)
+	if stored.Email != "eva@corp.example" || !stored.IsEmailVerified() || stored.Role != domain.RoleUser {
+		t.Errorf("usuario creado = %+v", stored)
+	}
+	// La contraseña es aleatoria
+	if env.auth.CheckPassword("", stored.Password) {
+		t.Error("el usuario creado tiene la contraseña vacía")
+	}
+
+	// Un correo que ya usa otro usuario no se copia
+	result, err = env.complete(t, "repetido", 0)
+	checkErr(t, err, nil)
+	if result.User.Username != "ana-2" || result.User.Email != "" {
+		t.Errorf("usuario creado = %+v", result.User)
+	}
+}
+
+func TestOIDCServiceRoleMapping(t *testing.T) {
+	mapping := domain.RoleMapping{
+		{Value: "blog-admins", Role: domain.RoleAdmin},
+		{Value: "blog-editors", Role: domain.RoleEditor},
+	}
+	env := newOIDCEnv(t, domain.IdentityPolicy{AutoCreateUsers: true, RoleMapping: mapping})
+	claims := &domain.IdentityClaims{Subject: "sub", PreferredUsername: "eva", Roles: []string{"blog-editors"}}
+	env.provider.claims["code"] = claims
+
+	tests := []struct {
+		name  string
+		roles []string
+		want  domain.Role
+	}{
+		{name: "al crear", roles: []string{"blog-editors"}, want: domain.RoleEditor},
+		{name: "gana la primera regla", roles: []string{"blog-editors", "blog-admins"}, want: domain.RoleAdmin},
+		{name: "sin reglas conserva el rol", roles: []string{"otros"}, want: domain.RoleAdmin},
+		{name: "en cada login", roles: []string{"blog-editors"}, want: domain.RoleEditor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims.Roles = tt.roles
+			result, err := env.complete(t, "code", 0)
+			checkErr(t, err, nil)
+			stored, err := env.users.FindByID(ctx, result.User.ID)
+			checkErr(t, err, nil)
+			if stored.Role != tt.want {
+				t.Errorf("rol = %q, se esperaba %q", stored.Role, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCServiceLink(t *testing.T) {
+	env := newOIDCEnv(t, domain.IdentityPolicy{})
+	ana := env.createUser(t, "ana", domain.RoleUser)
+	bob := env.createUser(t, "bob", domain.RoleUser)
+	env.provider.claims["code"] = &domain.IdentityClaims{Subject: "sub", Email: "otra@corp.example"}
+
+	_, _, err := env.service.StartLink(ctx, 999)
+	checkErr(t, err, domain.ErrUserNotFound)
+
+	// Sin vincular ni crear usuarios el login se rechaza
+	_, err = env.complete(t, "code", 0)
+	checkErr(t, err, domain.ErrAccountNotLinked)
+
+	result, err := env.complete(t, "code", ana.ID)
+	checkErr(t, err, nil)
+	if result.User.ID != ana.ID {
+		t.Fatalf("usuario = %+v", result.User)
+	}
+	identities, err := env.service.ListIdentities(ctx, ana.ID)
+	checkErr(t, err, nil)
+	if len(identities) != 1 || identities[0].Provider != "idp" || identities[0].Subject != "sub" {
+		t.Fatalf("identidades = %+v", identities)
+	}
+
+	// Una identidad vinculada no se puede vincular a otro usuario
+	_, err = env.complete(t, "code", bob.ID)
+	checkErr(t, err, domain.ErrIdentityLinked)
+
+	// Ahora el login entra como el usuario vinculado
+	result, err = env.complete(t, "code", 0)
+	checkErr(t, err, nil)
+	if result.User.ID != ana.ID {
+		t.Errorf("usuario = %+v", result.User)
+	}
+
+	checkErr(t, env.service.Unlink(ctx, bob.ID, identities[0].ID), domain.ErrIdentityNotFound)
+	checkErr(t, env.service.Unlink(ctx, ana.ID, identities[0].ID), nil)
+	_, err = env.complete(t, "code", 0)
+	checkErr(t, err, domain.ErrAccountNotLinked)
+}
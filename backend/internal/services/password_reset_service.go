@@ -0,0 +1,158 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// PasswordResetService implementa el restablecimiento de contraseña mediante
+// tokens de un solo uso enviados por correo
+type PasswordResetService struct {
+	userRepo         ports.UserRepository
+	resetTokenRepo   ports.PasswordResetTokenRepository
+	refreshTokenRepo ports.RefreshTokenRepository
+	authService      ports.AuthService
+	mailer           ports.Mailer
+	txManager        ports.TxManager
+	resetURL         string
+	tokenTTL         time.Duration
+}
+
+// NewPasswordResetService crea una nueva instancia del servicio de restablecimiento.
+// resetURL es la página del frontend que recibe el token en el parámetro "token".
+// mailer debería enviar en segundo plano (ver mail.AsyncMailer) para que la
+// respuesta no revele si el correo está registrado.
+func NewPasswordResetService(userRepo ports.UserRepository, resetTokenRepo ports.PasswordResetTokenRepository, refreshTokenRepo ports.RefreshTokenRepository, authService ports.AuthService, mailer ports.Mailer, txManager ports.TxManager, resetURL string, tokenTTL time.Duration) *PasswordResetService {
+	return &PasswordResetService{
+		userRepo:         userRepo,
+		resetTokenRepo:   resetTokenRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		authService:      authService,
+		mailer:           mailer,
+		txManager:        txManager,
+		resetURL:         resetURL,
+		tokenTTL:         tokenTTL,
+	}
+}
+
+// RequestReset envía un enlace de restablecimiento al correo indicado. Si el
+// correo no pertenece a ningún usuario activo no hace nada, para no revelar
+// qué correos están registrados. Un enlace nuevo invalida los anteriores.
+func (s *PasswordResetService) RequestReset(ctx context.Context, email string) error {
+	email, err := domain.NormalizeEmail(email)
+	if err != nil {
+		return err
+	}
+	if email == "" {
+		return domain.ErrInvalidEmail
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if err == domain.ErrUserNotFound {
+			return nil
+		}
+		return err
+	}
+	if user.IsDeleted() {
+		return nil
+	}
+
+	token, err := s.authService.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.resetTokenRepo.DeleteForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	stored := &domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: s.authService.HashToken(token),
+		ExpiresAt: time.Now().Add(s.tokenTTL),
+	}
+	if err := s.resetTokenRepo.Create(ctx, stored); err != nil {
+		return err
+	}
+
+	link, err := s.resetLink(token)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, domain.EmailMessage{
+		To:      user.Email,
+		Subject: "Restablecer tu contraseña",
+		Body: fmt.Sprintf("Hola %s:\n\n"+
+			"Recibimos una solicitud para restablecer la contraseña de tu cuenta. "+
+			"Para elegir una nueva abre este enlace antes de %d minutos:\n\n%s\n\n"+
+			"El enlace solo se puede usar una vez. Si no lo solicitaste puedes ignorar este correo.\n",
+			user.Username, int(s.tokenTTL.Minutes()), link),
+	})
+}
+
+// resetLink añade el token a la URL de restablecimiento
+func (s *PasswordResetService) resetLink(token string) (string, error) {
+	link, err := url.Parse(s.resetURL)
+	if err != nil {
+		return "", fmt.Errorf("URL de restablecimiento inválida: %w", err)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+// ResetPassword cambia la contraseña del usuario dueño del token, que queda
+// usado. Como el usuario demostró controlar su correo también se levanta el
+// bloqueo de inicio de sesión, y se cierran todas sus sesiones. El token solo
+// se consume si el cambio se guarda: todo ocurre en una transacción.
+func (s *PasswordResetService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	stored, err := s.resetTokenRepo.FindByHash(ctx, s.authService.HashToken(token))
+	if err != nil {
+		return domain.ErrInvalidToken
+	}
+
+	if !stored.IsUsable(time.Now()) {
+		return domain.ErrInvalidToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		return domain.ErrInvalidToken
+	}
+
+	hashedPassword, err := s.authService.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// MarkUsed falla si otra petición ya usó el token
+		if err := s.resetTokenRepo.MarkUsed(ctx, stored.ID); err != nil {
+			return err
+		}
+
+		user.Password = hashedPassword
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		user.ResetLoginFailures()
+		if err := s.userRepo.UpdateLoginState(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.resetTokenRepo.DeleteForUser(ctx, user.ID); err != nil {
+			return err
+		}
+
+		return s.refreshTokenRepo.RevokeAllForUser(ctx, user.ID)
+	})
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// resetToken extrae el token del último enlace de restablecimiento enviado
+func (env *testEnv) resetToken(t *testing.T) string {
+	t.Helper()
+	if len(env.mailer.sent) == 0 {
+		t.Fatal("no se envió ningún correo")
+	}
+	body := env.mailer.sent[len(env.mailer.sent)-1].Body
+	start := strings.Index(body, "https://")
+	if start < 0 {
+		t.Fatalf("el correo no contiene el enlace: %q", body)
+	}
+	link, err := url.Parse(strings.Fields(body[start:])[0])
+	if err != nil {
+		t.Fatalf("enlace inválido: %v", err)
+	}
+	return link.Query().Get("token")
+}
+
+func TestPasswordResetServiceRequestReset(t *testing.T) {
+	env := newTestEnv(t)
+	env.createUser(t, "ana", domain.RoleUser)
+
+	// Un correo desconocido recibe la misma respuesta sin enviar nada
+	checkErr(t, env.passwordReset.RequestReset(ctx, "nadie@example.com"), nil)
+	if len(env.mailer.sent) != 0 {
+		t.Fatalf("correos enviados = %d, se esperaba ninguno", len(env.mailer.sent))
+	}
+	checkErr(t, env.passwordReset.RequestReset(ctx, "no es un correo"), domain.ErrInvalidEmail)
+
+	checkErr(t, env.passwordReset.RequestReset(ctx, "ANA@example.com"), nil)
+	if len(env.mailer.sent) != 1 || env.mailer.sent[0].To != "ana@example.com" {
+		t.Fatalf("correos enviados = %+v", env.mailer.sent)
+	}
+	first := env.resetToken(t)
+
+	// Un enlace nuevo invalida el anterior
+	checkErr(t, env.passwordReset.RequestReset(ctx, "ana@example.com"), nil)
+	checkErr(t, env.passwordReset.ResetPassword(ctx, first, "nueva"), domain.ErrInvalidToken)
+	checkErr(t, env.passwordReset.ResetPassword(ctx, env.resetToken(t), "nueva"), nil)
+}
+
+func TestPasswordResetServiceResetPassword(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	session := env.login(t, "ana")
+
+	// Bloquear la cuenta con fallos de inicio de sesión
+	for i := 0; i < testLockout.MaxAccountFailures; i++ {
+		env.authService.Login(ctx, "ana", "otra", domain.LoginClient{IP: "10.0.0.1"})
+	}
+
+	checkErr(t, env.passwordReset.RequestReset(ctx, user.Email), nil)
+	token := env.resetToken(t)
+
+	checkErr(t, env.passwordReset.ResetPassword(ctx, "desconocido", "nueva"), domain.ErrInvalidToken)
+	checkErr(t, env.passwordReset.ResetPassword(ctx, token, "nueva"), nil)
+
+	// El token es de un solo uso
+	checkErr(t, env.passwordReset.ResetPassword(ctx, token, "otra-mas"), domain.ErrInvalidToken)
+
+	// Se cierran las sesiones, se levanta el bloqueo y vale la contraseña nueva
+	_, err := env.authService.ValidateToken(ctx, session.AccessToken)
+	checkErr(t, err, domain.ErrUnauthorized)
+	_, err = env.authService.Login(ctx, "ana", "secreto", domain.LoginClient{IP: "10.0.0.2"})
+	checkErr(t, err, domain.ErrInvalidCredentials)
+	_, err = env.authService.Login(ctx, "ana", "nueva", domain.LoginClient{IP: "10.0.0.2"})
+	checkErr(t, err, nil)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+)
+
+// RoleService implementa los casos de uso para gestión de roles y permisos
+type RoleService struct {
+	roleRepo ports.RoleRepository
+}
+
+// NewRoleService crea una nueva instancia del servicio de roles
+func NewRoleService(roleRepo ports.RoleRepository) *RoleService {
+	return &RoleService{
+		roleRepo: roleRepo,
+	}
+}
+
+// ListPermissions lista todos los permisos que se pueden asignar a un rol
+func (s *RoleService) ListPermissions() []domain.Permission {
+	return domain.Permissions
+}
+
+// ListRoles lista todos los roles con sus permisos
+func (s *RoleService) ListRoles(ctx context.Context) ([]domain.RoleDefinition, error) {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if roles == nil {
+		roles = []domain.RoleDefinition{}
+	}
+	return roles, nil
+}
+
+// CreateRole crea un rol nuevo con los permisos indicados
+func (s *RoleService) CreateRole(ctx context.Context, name domain.Role, description string, permissions []domain.Permission) (*domain.RoleDefinition, error) {
+	role, err := domain.NewRoleDefinition(name, description, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verificar si el rol ya existe
+	existingRole, _ := s.roleRepo.FindByName(ctx, name)
+	if existingRole != nil {
+		return nil, domain.ErrRoleAlreadyExists
+	}
+
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// UpdateRole cambia la descripción y los permisos de un rol. Los permisos del
+// rol de administrador no se pueden modificar para no perder el acceso.
+func (s *RoleService) UpdateRole(ctx context.Context, name domain.Role, description string, permissions []domain.Permission) (*domain.RoleDefinition, error) {
+	role, err := s.roleRepo.FindByName(ctx, name)
+	if err != nil {
+		return nil, domain.ErrRoleNotFound
+	}
+
+	if role.Name == domain.RoleAdmin {
+		return nil, domain.ErrAdminRoleImmutable
+	}
+
+	role.Description = description
+	if err := role.SetPermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	if err := s.roleRepo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// DeleteRole elimina un rol creado por un administrador que no esté asignado a ningún usuario
+func (s *RoleService) DeleteRole(ctx context.Context, name domain.Role) error {
+	role, err := s.roleRepo.FindByName(ctx, name)
+	if err != nil {
+		return domain.ErrRoleNotFound
+	}
+
+	if role.BuiltIn {
+		return domain.ErrBuiltInRole
+	}
+
+	inUse, err := s.roleRepo.InUse(ctx, name)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return domain.ErrRoleInUse
+	}
+
+	return s.roleRepo.Delete(ctx, name)
+}
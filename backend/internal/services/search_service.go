@@ -0,0 +1,156 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"html"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// snippetLength es la longitud máxima (en caracteres) de un fragmento resaltado
+const snippetLength = 200
+
+// SearchService implementa los casos de uso de búsqueda
+type SearchService struct {
+	searchRepo ports.SearchRepository
+}
+
+// NewSearchService crea una nueva instancia del servicio de búsqueda
+func NewSearchService(searchRepo ports.SearchRepository) *SearchService {
+	return &SearchService{
+		searchRepo: searchRepo,
+	}
+}
+
+// Search busca blogs y comentarios y genera fragmentos con las coincidencias resaltadas
+func (s *SearchService) Search(ctx context.Context, text string, kind domain.SearchKind, authorID int64, from, to *time.Time, limit, offset int) (*domain.SearchResults, error) {
+	terms := domain.ParseSearchTerms(text)
+	if len(terms) == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if kind != "" && kind != domain.SearchKindBlog && kind != domain.SearchKindComment {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if limit == 0 {
+		limit = domain.DefaultPageLimit
+	}
+	if limit < 0 || limit > domain.MaxPageLimit || offset < 0 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	// Se pide un resultado extra para saber si hay más páginas
+	results, err := s.searchRepo.Search(ctx, domain.SearchQuery{
+		Terms:    terms,
+		Kind:     kind,
+		AuthorID: authorID,
+		From:     from,
+		To:       to,
+		Limit:    limit + 1,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.SearchResults{Items: []domain.SearchResult{}}
+	if len(results) > limit {
+		results = results[:limit]
+		response.HasMore = true
+	}
+
+	for _, result := range results {
+		result.Snippet = buildSnippet(result.Content, terms, snippetLength)
+		response.Items = append(response.Items, result)
+	}
+
+	return response, nil
+}
+
+// buildSnippet extrae un fragmento del contenido alrededor de la primera coincidencia
+// y resalta los términos con <mark>. El resto del texto se escapa como HTML.
+func buildSnippet(content string, terms []domain.SearchTerm, maxLen int) string {
+	runes := []rune(content)
+	lower := lowerRunes(content)
+
+	// Marcar las posiciones que forman parte de alguna coincidencia
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		needle := lowerRunes(term.Value)
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if !hasRunePrefix(lower[i:], needle) {
+				continue
+			}
+			for j := i; j < i+len(needle); j++ {
+				marked[j] = true
+			}
+			if first == -1 || i < first {
+				first = i
+			}
+		}
+	}
+
+	// Centrar la ventana en la primera coincidencia
+	start := 0
+	if first > maxLen/4 {
+		start = first - maxLen/4
+	}
+	end := start + maxLen
+	if end > len(runes) {
+		end = len(runes)
+		start = max(0, end-maxLen)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+
+	inMark := false
+	for i := start; i < end; i++ {
+		if marked[i] && !inMark {
+			b.WriteString("<mark>")
+			inMark = true
+		} else if !marked[i] && inMark {
+			b.WriteString("</mark>")
+			inMark = false
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+	}
+	if inMark {
+		b.WriteString("</mark>")
+	}
+
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
+
+// lowerRunes convierte el texto a minúsculas conservando el número de caracteres
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// hasRunePrefix indica si s comienza con prefix
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(prefix) == 0 || len(s) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"blog-backend/adapters/persistence/memory"
+	"blog-backend/internal/domain"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSearchEnv crea un servicio de búsqueda sobre un índice en memoria con los documentos indicados
+func newSearchEnv(documents ...domain.SearchResult) *SearchService {
+	index := memory.NewSearchRepository()
+	for _, document := range documents {
+		index.Index(ctx, document)
+	}
+	return NewSearchService(index)
+}
+
+// resultIDs identifica cada resultado por su tipo y su ID
+func resultIDs(results *domain.SearchResults) []string {
+	ids := []string{}
+	for _, result := range results.Items {
+		id := result.BlogID
+		if result.Kind == domain.SearchKindComment {
+			id = result.CommentID
+		}
+		ids = append(ids, string(result.Kind)+":"+strconv.FormatInt(id, 10))
+	}
+	return ids
+}
+
+func TestSearchServiceSearch(t *testing.T) {
+	day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		date := day.AddDate(0, 0, days)
+		return &date
+	}
+	service := newSearchEnv(
+		domain.SearchResult{Kind: domain.SearchKindBlog, BlogID: 1, Title: "Pan casero", AuthorID: 1, Content: "Receta de pan casero con masa madre", CreatedAt: *at(0)},
+		domain.SearchResult{Kind: domain.SearchKindBlog, BlogID: 2, Title: "Viajes", AuthorID: 2, Content: "Casero y pan, en otro orden", CreatedAt: *at(1)},
+		domain.SearchResult{Kind: domain.SearchKindComment, BlogID: 1, CommentID: 3, AuthorID: 2, Content: "¡Qué buen pan casero!", CreatedAt: *at(2)},
+	)
+
+	tests := []struct {
+		name     string
+		text     string
+		kind     domain.SearchKind
+		authorID int64
+		from, to *time.Time
+		want     []string
+	}{
+		// Las coincidencias en el título valen el doble y a igual relevancia va primero lo más reciente
+		{name: "palabras en cualquier orden", text: "casero pan", want: []string{"blog:1", "comment:3", "blog:2"}},
+		{name: "frase exacta", text: `"pan casero"`, want: []string{"blog:1", "comment:3"}},
+		{name: "frase y palabra", text: `"pan casero" masa`, want: []string{"blog:1"}},
+		{name: "sin coincidencias", text: `"casero pan masa"`, want: []string{}},
+		{name: "solo comentarios", text: "pan", kind: domain.SearchKindComment, want: []string{"comment:3"}},
+		{name: "por autor", text: "pan", authorID: 2, want: []string{"comment:3", "blog:2"}},
+		{name: "desde una fecha (incluida)", text: "pan", from: at(1), want: []string{"comment:3", "blog:2"}},
+		{name: "hasta una fecha (excluida)", text: "pan", to: at(1), want: []string{"blog:1"}},
+		{name: "entre fechas", text: "pan", from: at(1), to: at(2), want: []string{"blog:2"}},
+		{name: "autor y fechas", text: "pan", authorID: 1, from: at(1), want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := service.Search(ctx, tt.text, tt.kind, tt.authorID, tt.from, tt.to, 10, 0)
+			checkErr(t, err, nil)
+			if got := resultIDs(results); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resultados = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchServiceSearchInvalid(t *testing.T) {
+	service := newSearchEnv()
+	tests := []struct {
+		name          string
+		text          string
+		kind          domain.SearchKind
+		limit, offset int
+	}{
+		{name: "sin términos", text: " ¿? "},
+		{name: "comillas vacías", text: `""`},
+		{name: "tipo desconocido", text: "pan", kind: "usuario"},
+		{name: "límite negativo", text: "pan", limit: -1},
+		{name: "límite excesivo", text: "pan", limit: domain.MaxPageLimit + 1},
+		{name: "desplazamiento negativo", text: "pan", offset: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Search(ctx, tt.text, tt.kind, 0, nil, nil, tt.limit, tt.offset)
+			checkErr(t, err, domain.ErrInvalidInput)
+		})
+	}
+}
+
+func TestSearchServiceSearchPagination(t *testing.T) {
+	var documents []domain.SearchResult
+	for id := int64(1); id <= 3; id++ {
+		documents = append(documents, domain.SearchResult{
+			Kind: domain.SearchKindBlog, BlogID: id, Content: strings.Repeat("pan ", int(4-id)),
+		})
+	}
+	service := newSearchEnv(documents...)
+
+	pages := []struct {
+		offset  int
+		want    []string
+		hasMore bool
+	}{
+		{offset: 0, want: []string{"blog:1", "blog:2"}, hasMore: true},
+		{offset: 2, want: []string{"blog:3"}, hasMore: false},
+		{offset: 4, want: []string{}, hasMore: false},
+	}
+	for _, page := range pages {
+		results, err := service.Search(ctx, "pan", "", 0, nil, nil, 2, page.offset)
+		checkErr(t, err, nil)
+		if got := resultIDs(results); !reflect.DeepEqual(got, page.want) || results.HasMore != page.hasMore {
+			t.Errorf("página %d = %v (has_more %v), se esperaba %v (has_more %v)", page.offset, got, results.HasMore, page.want, page.hasMore)
+		}
+	}
+}
+
+func TestSearchServiceSnippet(t *testing.T) {
+	long := strings.Repeat("relleno ", 40)
+	tests := []struct {
+		name    string
+		text    string
+		content string
+		want    string
+	}{
+		{
+			name:    "resalta sin distinguir mayúsculas",
+			text:    "pan",
+			content: "Pan y más PAN",
+			want:    "<mark>Pan</mark> y más <mark>PAN</mark>",
+		},
+		{
+			name:    "frase completa",
+			text:    `"pan casero"`,
+			content: "Un pan casero, no pan de molde",
+			want:    "Un <mark>pan casero</mark>, no pan de molde",
+		},
+		{
+			name:    "cada término por separado",
+			text:    "pan casero",
+			content: "pan casero",
+			want:    "<mark>pan</mark> <mark>casero</mark>",
+		},
+		{
+			name:    "caracteres no ASCII",
+			text:    "ñandú",
+			content: "El ÑANDÚ corre",
+			want:    "El <mark>ÑANDÚ</mark> corre",
+		},
+		{
+			name:    "escapa el HTML",
+			text:    "pan",
+			content: `<script>alert("pan")</script>`,
+			want:    `&lt;script&gt;alert(&#34;<mark>pan</mark>&#34;)&lt;/script&gt;`,
+		},
+		{
+			name:    "recorta alrededor de la coincidencia",
+			text:    "pan",
+			content: long + "pan" + long,
+			want:    "…" + long[len(long)-snippetLength/4:] + "<mark>pan</mark>" + long[:snippetLength-snippetLength/4-3] + "…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newSearchEnv(domain.SearchResult{Kind: domain.SearchKindBlog, BlogID: 1, Content: tt.content})
+			results, err := service.Search(ctx, tt.text, "", 0, nil, nil, 10, 0)
+			checkErr(t, err, nil)
+			if len(results.Items) != 1 {
+				t.Fatalf("resultados = %+v", results.Items)
+			}
+			if got := results.Items[0].Snippet; got != tt.want {
+				t.Errorf("Snippet = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+)
+
+// TagService implementa los casos de uso para gestión de etiquetas
+type TagService struct {
+	tagRepo ports.TagRepository
+}
+
+// NewTagService crea una nueva instancia del servicio de etiquetas
+func NewTagService(tagRepo ports.TagRepository) *TagService {
+	return &TagService{
+		tagRepo: tagRepo,
+	}
+}
+
+// ListTags lista todas las etiquetas con su número de blogs publicados
+func (s *TagService) ListTags(ctx context.Context) ([]domain.Tag, error) {
+	return s.tagRepo.List(ctx)
+}
+
+// RenameTag cambia el nombre de una etiqueta. Si el nuevo slug ya pertenece a
+// otra etiqueta se devuelve ErrTagAlreadyExists; en ese caso hay que fusionarlas.
+func (s *TagService) RenameTag(ctx context.Context, id int64, name string) (*domain.Tag, error) {
+	tag, err := s.tagRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, domain.ErrTagNotFound
+	}
+
+	renamed, err := domain.NewTag(name)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.tagRepo.FindBySlug(ctx, renamed.Slug)
+	if err == nil && existing.ID != tag.ID {
+		return nil, domain.ErrTagAlreadyExists
+	}
+
+	tag.Name = renamed.Name
+	tag.Slug = renamed.Slug
+
+	if err := s.tagRepo.Rename(ctx, tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// MergeTags mueve los blogs de la etiqueta origen a la de destino y elimina la de origen
+func (s *TagService) MergeTags(ctx context.Context, sourceID, targetID int64) (*domain.Tag, error) {
+	if sourceID == targetID {
+		return nil, domain.ErrInvalidInput
+	}
+
+	if _, err := s.tagRepo.FindByID(ctx, sourceID); err != nil {
+		return nil, domain.ErrTagNotFound
+	}
+
+	target, err := s.tagRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return nil, domain.ErrTagNotFound
+	}
+
+	if err := s.tagRepo.Merge(ctx, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"time"
+)
+
+// TrashService implementa los casos de uso de la papelera: listar y restaurar
+// elementos eliminados y purgar los que superan el periodo de retención
+type TrashService struct {
+	blogRepo    ports.BlogRepository
+	commentRepo ports.CommentRepository
+	userRepo    ports.UserRepository
+	txManager   ports.TxManager
+	retention   time.Duration
+}
+
+// NewTrashService crea una nueva instancia del servicio de papelera
+func NewTrashService(blogRepo ports.BlogRepository, commentRepo ports.CommentRepository, userRepo ports.UserRepository, txManager ports.TxManager, retention time.Duration) *TrashService {
+	return &TrashService{
+		blogRepo:    blogRepo,
+		commentRepo: commentRepo,
+		userRepo:    userRepo,
+		txManager:   txManager,
+		retention:   retention,
+	}
+}
+
+// ListDeletedBlogs lista una página de blogs de la papelera
+func (s *TrashService) ListDeletedBlogs(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.Blog], error) {
+	return s.blogRepo.ListDeleted(ctx, page)
+}
+
+// ListDeletedComments lista una página de comentarios de la papelera
+func (s *TrashService) ListDeletedComments(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.Comment], error) {
+	return s.commentRepo.ListDeleted(ctx, page)
+}
+
+// ListDeletedUsers lista una página de usuarios de la papelera
+func (s *TrashService) ListDeletedUsers(ctx context.Context, page domain.PageRequest) (*domain.Page[domain.User], error) {
+	users, err := s.userRepo.ListDeleted(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ocultar contraseñas
+	for i := range users.Items {
+		users.Items[i].Password = ""
+	}
+	return users, nil
+}
+
+// RestoreBlog saca un blog de la papelera junto con los comentarios que se
+// eliminaron con él. Los que ya estaban en la papelera antes se quedan en ella.
+// Retorna domain.ErrAuthorDeleted mientras su autor siga en la papelera.
+func (s *TrashService) RestoreBlog(ctx context.Context, id int64) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		blog, err := s.blogRepo.FindDeleted(ctx, id)
+		if err != nil {
+			return domain.ErrBlogNotFound
+		}
+
+		if _, err := s.userRepo.FindByID(ctx, blog.AuthorID); err != nil {
+			if err == domain.ErrUserNotFound {
+				return domain.ErrAuthorDeleted
+			}
+			return err
+		}
+
+		if err := s.commentRepo.RestoreByBlog(ctx, id); err != nil {
+			return err
+		}
+		return s.blogRepo.Restore(ctx, id)
+	})
+}
+
+// RestoreComment saca un comentario de la papelera. Si alguno de sus ancestros
+// también está en la papelera se recupera como "comentario eliminado" para que
+// la respuesta vuelva a colgar de su hilo. Retorna domain.ErrBlogDeleted
+// mientras su blog siga en la papelera: se restaura con él.
+func (s *TrashService) RestoreComment(ctx context.Context, id int64) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		comment, err := s.commentRepo.FindDeleted(ctx, id)
+		if err != nil {
+			return domain.ErrCommentNotFound
+		}
+
+		// El bloqueo impide que el blog vaya a la papelera mientras tanto
+		if _, err := s.blogRepo.FindByIDForUpdate(ctx, comment.BlogID); err != nil {
+			if err == domain.ErrBlogNotFound {
+				return domain.ErrBlogDeleted
+			}
+			return err
+		}
+
+		if err := s.commentRepo.Restore(ctx, id); err != nil {
+			return err
+		}
+
+		return s.restoreAncestors(ctx, comment)
+	})
+}
+
+// restoreAncestors recupera como "comentario eliminado" los ancestros de un
+// comentario que estén en la papelera, hasta el primero que no lo esté
+func (s *TrashService) restoreAncestors(ctx context.Context, comment *domain.Comment) error {
+	parentID := comment.ParentID
+	for parentID != nil {
+		if _, err := s.commentRepo.FindByID(ctx, *parentID); err == nil {
+			return nil
+		}
+
+		parent, err := s.commentRepo.FindDeleted(ctx, *parentID)
+		if err != nil {
+			if err == domain.ErrCommentNotFound {
+				return nil
+			}
+			return err
+		}
+
+		if err := s.commentRepo.MarkRemoved(ctx, parent.ID); err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+
+	return nil
+}
+
+// RestoreUser saca un usuario de la papelera. Sus blogs y comentarios, que se
+// eliminaron o reasignaron con él, no se restauran.
+func (s *TrashService) RestoreUser(ctx context.Context, id int64) error {
+	return s.userRepo.Restore(ctx, id)
+}
+
+// PurgeExpired elimina definitivamente los elementos que llevan en la papelera
+// más tiempo que el periodo de retención
+func (s *TrashService) PurgeExpired(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-s.retention)
+
+	comments, err := s.commentRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	blogs, err := s.blogRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return comments, err
+	}
+
+	users, err := s.userRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return comments + blogs, err
+	}
+
+	return comments + blogs + users, nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"testing"
+)
+
+func TestTrashServiceRestoreBlog(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	comment := env.createComment(t, blog, author, nil)
+
+	// Sus blogs van a la papelera con el autor y no vuelven sin él
+	checkErr(t, env.userService.DeleteUser(ctx, author.ID, domain.RoleAdmin), nil)
+	checkErr(t, env.trashService.RestoreBlog(ctx, blog.ID), domain.ErrAuthorDeleted)
+	_, err := env.blogs.FindDeleted(ctx, blog.ID)
+	checkErr(t, err, nil)
+	_, err = env.comments.FindDeleted(ctx, comment.ID)
+	checkErr(t, err, nil)
+
+	checkErr(t, env.trashService.RestoreUser(ctx, author.ID), nil)
+	checkErr(t, env.trashService.RestoreBlog(ctx, blog.ID), nil)
+	_, err = env.comments.FindByID(ctx, comment.ID)
+	checkErr(t, err, nil)
+
+	checkErr(t, env.trashService.RestoreBlog(ctx, blog.ID), domain.ErrBlogNotFound)
+}
+
+func TestTrashServiceRestoreComment(t *testing.T) {
+	env := newTestEnv(t)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	parent := env.createComment(t, blog, author, nil)
+	reply := env.createComment(t, blog, commenter, parent)
+	checkErr(t, env.commentService.DeleteComment(ctx, reply.ID, commenter.ID, domain.RoleUser), nil)
+	checkErr(t, env.commentService.DeleteComment(ctx, parent.ID, author.ID, domain.RoleUser), nil)
+
+	// Un comentario no se restaura mientras su blog está en la papelera
+	checkErr(t, env.blogService.DeleteBlog(ctx, blog.ID, author.ID, domain.RoleUser), nil)
+	checkErr(t, env.trashService.RestoreComment(ctx, reply.ID), domain.ErrBlogDeleted)
+	_, err := env.comments.FindDeleted(ctx, reply.ID)
+	checkErr(t, err, nil)
+
+	// Con el blog restaurado la respuesta vuelve y su padre se muestra como eliminado
+	checkErr(t, env.trashService.RestoreBlog(ctx, blog.ID), nil)
+	checkErr(t, env.trashService.RestoreComment(ctx, reply.ID), nil)
+	_, err = env.comments.FindByID(ctx, reply.ID)
+	checkErr(t, err, nil)
+	found, err := env.comments.FindByID(ctx, parent.ID)
+	checkErr(t, err, nil)
+	if !found.Removed {
+		t.Errorf("padre = %+v, se esperaba marcado como eliminado", found)
+	}
+
+	checkErr(t, env.trashService.RestoreComment(ctx, reply.ID), domain.ErrCommentNotFound)
+}
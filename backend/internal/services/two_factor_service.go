@@ -0,0 +1,240 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"blog-backend/internal/ports"
+	"context"
+	"time"
+)
+
+// TwoFactorService implementa la autenticación en dos pasos: activación de
+// TOTP, códigos de recuperación y verificación de los códigos en el login
+type TwoFactorService struct {
+	userRepo         ports.UserRepository
+	roleRepo         ports.RoleRepository
+	recoveryCodeRepo ports.RecoveryCodeRepository
+	refreshTokenRepo ports.RefreshTokenRepository
+	authService      ports.AuthService
+	totp             ports.TOTPProvider
+	secretCipher     ports.SecretCipher
+}
+
+// NewTwoFactorService crea una nueva instancia del servicio de autenticación en
+// dos pasos. Los secretos TOTP se guardan cifrados con secretCipher.
+func NewTwoFactorService(userRepo ports.UserRepository, roleRepo ports.RoleRepository, recoveryCodeRepo ports.RecoveryCodeRepository, refreshTokenRepo ports.RefreshTokenRepository, authService ports.AuthService, totp ports.TOTPProvider, secretCipher ports.SecretCipher) *TwoFactorService {
+	return &TwoFactorService{
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		authService:      authService,
+		totp:             totp,
+		secretCipher:     secretCipher,
+	}
+}
+
+// Status resume la autenticación en dos pasos de un usuario
+func (s *TwoFactorService) Status(ctx context.Context, userID int64) (*domain.TwoFactorStatus, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	status := &domain.TwoFactorStatus{
+		Enabled: user.TwoFactorEnabled(),
+		Pending: !user.TwoFactorEnabled() && user.TOTPSecret != "",
+	}
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = s.recoveryCodeRepo.CountUnused(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// Enroll genera un secreto TOTP nuevo para el usuario. La autenticación en dos
+// pasos no se activa hasta que Confirm recibe un código válido del secreto.
+func (s *TwoFactorService) Enroll(ctx context.Context, userID int64) (*domain.TwoFactorEnrollment, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	if user.TwoFactorEnabled() {
+		return nil, domain.ErrTwoFactorEnabled
+	}
+
+	secret, err := s.totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := s.secretCipher.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = sealed
+	user.TOTPLastStep = 0
+	if err := s.userRepo.UpdateTwoFactor(ctx, user); err != nil {
+		return nil, err
+	}
+
+	accountName := user.Username
+	if user.Email != "" {
+		accountName = user.Email
+	}
+
+	return &domain.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: s.totp.ProvisioningURI(secret, accountName),
+	}, nil
+}
+
+// Confirm activa la autenticación en dos pasos con un código del secreto
+// pendiente y retorna los códigos de recuperación, que solo se muestran una vez
+func (s *TwoFactorService) Confirm(ctx context.Context, userID int64, code string) ([]string, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	if user.TwoFactorEnabled() {
+		return nil, domain.ErrTwoFactorEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, domain.ErrNoTOTPEnrollment
+	}
+
+	now := time.Now()
+	if err := s.verifyTOTP(ctx, user, code, now); err != nil {
+		return nil, err
+	}
+
+	user.TOTPEnabledAt = &now
+	if err := s.userRepo.UpdateTwoFactor(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes invalida los códigos de recuperación del usuario y
+// genera otros nuevos. Exige un código TOTP: un código de recuperación no basta.
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	if !user.TwoFactorEnabled() {
+		return nil, domain.ErrTwoFactorDisabled
+	}
+
+	if err := s.verifyTOTP(ctx, user, code, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(ctx, user.ID)
+}
+
+// Disable desactiva la autenticación en dos pasos tras verificar la contraseña
+// y un código TOTP o de recuperación
+func (s *TwoFactorService) Disable(ctx context.Context, userID int64, password, code string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	if !user.TwoFactorEnabled() {
+		return domain.ErrTwoFactorDisabled
+	}
+
+	if !s.authService.CheckPassword(password, user.Password) {
+		return domain.ErrInvalidCredentials
+	}
+
+	if err := s.VerifyCode(ctx, user, code); err != nil {
+		return err
+	}
+
+	return s.disable(ctx, user)
+}
+
+// Reset desactiva la autenticación en dos pasos de un usuario que perdió su
+// dispositivo y sus códigos de recuperación, y cierra todas sus sesiones
+// (requiere el permiso user:manage). callerRole debe poder asignar el rol del usuario.
+func (s *TwoFactorService) Reset(ctx context.Context, userID int64, callerRole domain.Role) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+
+	if err := checkCanManage(ctx, s.roleRepo, user, callerRole); err != nil {
+		return err
+	}
+
+	if err := s.disable(ctx, user); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepo.RevokeAllForUser(ctx, user.ID)
+}
+
+// disable descarta el secreto TOTP y los códigos de recuperación del usuario
+func (s *TwoFactorService) disable(ctx context.Context, user *domain.User) error {
+	user.DisableTwoFactor()
+	if err := s.userRepo.UpdateTwoFactor(ctx, user); err != nil {
+		return err
+	}
+	return s.recoveryCodeRepo.DeleteForUser(ctx, user.ID)
+}
+
+// VerifyCode verifica un código TOTP o, si no tiene seis dígitos, un código de
+// recuperación, que queda usado
+func (s *TwoFactorService) VerifyCode(ctx context.Context, user *domain.User, code string) error {
+	if domain.IsTOTPCode(code) {
+		return s.verifyTOTP(ctx, user, code, time.Now())
+	}
+
+	codeHash := s.authService.HashToken(domain.NormalizeRecoveryCode(code))
+	return s.recoveryCodeRepo.Use(ctx, user.ID, codeHash)
+}
+
+// verifyTOTP verifica un código TOTP. Cada código se acepta una sola vez: los
+// de intervalos ya usados se rechazan aunque sigan siendo válidos.
+func (s *TwoFactorService) verifyTOTP(ctx context.Context, user *domain.User, code string, now time.Time) error {
+	secret, err := s.secretCipher.Decrypt(user.TOTPSecret)
+	if err != nil {
+		return err
+	}
+
+	step, ok := s.totp.Validate(secret, code, now)
+	if !ok || step <= user.TOTPLastStep {
+		return domain.ErrInvalidTOTPCode
+	}
+
+	if err := s.userRepo.AdvanceTOTPStep(ctx, user.ID, step); err != nil {
+		return err
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+// replaceRecoveryCodes genera los códigos de recuperación y guarda sus hashes
+func (s *TwoFactorService) replaceRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes := make([]string, domain.RecoveryCodeCount)
+	hashes := make([]string, domain.RecoveryCodeCount)
+	for i := range codes {
+		code, err := s.totp.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = s.authService.HashToken(domain.NormalizeRecoveryCode(code))
+	}
+
+	if err := s.recoveryCodeRepo.ReplaceForUser(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"testing"
+)
+
+func TestTwoFactorServiceEnroll(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+
+	enrollment, err := env.twoFactor.Enroll(ctx, user.ID)
+	checkErr(t, err, nil)
+	if enrollment.Secret != "SECRETO" || enrollment.ProvisioningURI != "otpauth://totp/ana@example.com?secret=SECRETO" {
+		t.Errorf("Enroll = %+v", enrollment)
+	}
+
+	// El secreto se guarda cifrado
+	stored, err := env.users.FindByID(ctx, user.ID)
+	checkErr(t, err, nil)
+	if stored.TOTPSecret != "cifrado:SECRETO" {
+		t.Errorf("secreto guardado = %q", stored.TOTPSecret)
+	}
+
+	status, err := env.twoFactor.Status(ctx, user.ID)
+	checkErr(t, err, nil)
+	if status.Enabled || !status.Pending {
+		t.Errorf("Status = %+v", status)
+	}
+
+	_, err = env.twoFactor.Confirm(ctx, user.ID, "000000")
+	checkErr(t, err, domain.ErrInvalidTOTPCode)
+	codes, err := env.twoFactor.Confirm(ctx, user.ID, "000001")
+	checkErr(t, err, nil)
+	if len(codes) != domain.RecoveryCodeCount {
+		t.Errorf("códigos de recuperación = %d", len(codes))
+	}
+
+	_, err = env.twoFactor.Enroll(ctx, user.ID)
+	checkErr(t, err, domain.ErrTwoFactorEnabled)
+}
+
+func TestTwoFactorServiceReplay(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	env.enableTwoFactor(t, user)
+
+	// fakeTOTP usa el código como intervalo: cada código sirve una vez y
+	// tampoco se aceptan los de intervalos anteriores al último usado
+	tests := []struct {
+		name string
+		code string
+		err  error
+	}{
+		{name: "intervalo nuevo", code: "000005"},
+		{name: "mismo código", code: "000005", err: domain.ErrInvalidTOTPCode},
+		{name: "intervalo anterior", code: "000004", err: domain.ErrInvalidTOTPCode},
+		{name: "intervalo siguiente", code: "000006"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := env.twoFactor.RegenerateRecoveryCodes(ctx, user.ID, tt.code)
+			checkErr(t, err, tt.err)
+		})
+	}
+
+	stored, err := env.users.FindByID(ctx, user.ID)
+	checkErr(t, err, nil)
+	if stored.TOTPLastStep != 6 {
+		t.Errorf("TOTPLastStep = %d, se esperaba 6", stored.TOTPLastStep)
+	}
+}
+
+func TestTwoFactorServiceRecoveryCodes(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	codes := env.enableTwoFactor(t, user)
+
+	stored, err := env.users.FindByID(ctx, user.ID)
+	checkErr(t, err, nil)
+
+	// Cada código sirve una vez
+	checkErr(t, env.twoFactor.VerifyCode(ctx, stored, codes[0]), nil)
+	checkErr(t, env.twoFactor.VerifyCode(ctx, stored, codes[0]), domain.ErrInvalidTOTPCode)
+	checkErr(t, env.twoFactor.VerifyCode(ctx, stored, "no-existe"), domain.ErrInvalidTOTPCode)
+
+	status, err := env.twoFactor.Status(ctx, user.ID)
+	checkErr(t, err, nil)
+	if !status.Enabled || status.RecoveryCodesLeft != domain.RecoveryCodeCount-1 {
+		t.Errorf("Status = %+v", status)
+	}
+
+	// Un código de recuperación no basta para regenerarlos y los nuevos anulan los anteriores
+	_, err = env.twoFactor.RegenerateRecoveryCodes(ctx, user.ID, codes[1])
+	checkErr(t, err, domain.ErrInvalidTOTPCode)
+	fresh, err := env.twoFactor.RegenerateRecoveryCodes(ctx, user.ID, "000002")
+	checkErr(t, err, nil)
+	checkErr(t, env.twoFactor.VerifyCode(ctx, stored, codes[1]), domain.ErrInvalidTOTPCode)
+	checkErr(t, env.twoFactor.VerifyCode(ctx, stored, fresh[0]), nil)
+}
@@ -3,27 +3,147 @@ package services
 import (
 	"blog-backend/internal/domain"
 	"blog-backend/internal/ports"
+	"context"
+	"log"
+	"strings"
+	"time"
 )
 
 // UserService implementa los casos de uso para gestión de usuarios
 type UserService struct {
-	userRepo   ports.UserRepository
-	authService ports.AuthService
+	userRepo          ports.UserRepository
+	roleRepo          ports.RoleRepository
+	refreshTokenRepo  ports.RefreshTokenRepository
+	blogRepo          ports.BlogRepository
+	commentRepo       ports.CommentRepository
+	authService       ports.AuthService
+	emailVerification *EmailVerificationService
+	txManager         ports.TxManager
+	// deletionPolicy indica qué ocurre con los blogs y comentarios al eliminar un usuario
+	deletionPolicy domain.DeletionPolicy
 }
 
 // NewUserService crea una nueva instancia del servicio de usuario
-func NewUserService(userRepo ports.UserRepository, authService ports.AuthService) *UserService {
+func NewUserService(userRepo ports.UserRepository, roleRepo ports.RoleRepository, refreshTokenRepo ports.RefreshTokenRepository, blogRepo ports.BlogRepository, commentRepo ports.CommentRepository, authService ports.AuthService, emailVerification *EmailVerificationService, txManager ports.TxManager, deletionPolicy domain.DeletionPolicy) *UserService {
 	return &UserService{
-		userRepo:    userRepo,
-		authService: authService,
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		blogRepo:          blogRepo,
+		commentRepo:       commentRepo,
+		authService:       authService,
+		emailVerification: emailVerification,
+		txManager:         txManager,
+		deletionPolicy:    deletionPolicy,
 	}
 }
 
-// Register registra un nuevo usuario
-func (s *UserService) Register(username, password string, role domain.Role) (*domain.User, error) {
-	// Verificar si el usuario ya existe
-	existingUser, _ := s.userRepo.FindByUsername(username)
-	if existingUser != nil {
+// Register registra un nuevo usuario desde el registro público y le envía el
+// enlace para verificar su correo. Las cuentas públicas siempre se crean con
+// el rol de usuario.
+func (s *UserService) Register(ctx context.Context, username, email, password string) (*domain.User, error) {
+	email, err := domain.NormalizeEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if email == "" {
+		return nil, domain.ErrEmailRequired
+	}
+
+	user, err := s.createUser(ctx, username, email, password, domain.RoleUser, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// La cuenta ya existe: si el correo falla el usuario puede pedir que se reenvíe
+	if err := s.emailVerification.SendVerification(ctx, user); err != nil {
+		log.Printf("Error enviando la verificación de correo a %s: %v", user.Email, err)
+	}
+
+	return user, nil
+}
+
+// CreateUser crea un usuario con el rol indicado (requiere el permiso user:manage).
+// callerRole es el rol de quien crea la cuenta y limita los roles asignables.
+// Las cuentas creadas por un administrador se dan por verificadas.
+func (s *UserService) CreateUser(ctx context.Context, username, email, password string, role, callerRole domain.Role) (*domain.User, error) {
+	if err := s.validateRole(ctx, role, callerRole); err != nil {
+		return nil, err
+	}
+	return s.createUser(ctx, username, email, password, role, true)
+}
+
+// validateRole verifica que el rol exista y que callerRole pueda asignarlo.
+// Con role:manage se puede asignar cualquier rol; sin él, solo roles cuyos
+// permisos estén todos incluidos en los de callerRole.
+func (s *UserService) validateRole(ctx context.Context, role, callerRole domain.Role) error {
+	return validateRole(ctx, s.roleRepo, role, callerRole)
+}
+
+// validateRole implementa UserService.validateRole sobre roleRepo
+func validateRole(ctx context.Context, roleRepo ports.RoleRepository, role, callerRole domain.Role) error {
+	definition, err := roleRepo.FindByName(ctx, role)
+	if err != nil {
+		if err == domain.ErrRoleNotFound {
+			return domain.ErrInvalidRole
+		}
+		return err
+	}
+
+	caller, err := roleRepo.FindByName(ctx, callerRole)
+	if err != nil {
+		if err == domain.ErrRoleNotFound {
+			return domain.ErrForbidden
+		}
+		return err
+	}
+
+	if caller.Has(domain.PermRoleManage) || caller.Covers(definition) {
+		return nil
+	}
+	return domain.ErrForbidden
+}
+
+// checkCanManage retorna domain.ErrForbidden si callerRole no puede asignar el
+// rol de user, de modo que nadie gestione cuentas con más permisos que los suyos
+func checkCanManage(ctx context.Context, roleRepo ports.RoleRepository, user *domain.User, callerRole domain.Role) error {
+	if err := validateRole(ctx, roleRepo, user.Role, callerRole); err != nil {
+		if err == domain.ErrInvalidRole {
+			return domain.ErrForbidden
+		}
+		return err
+	}
+	return nil
+}
+
+// BootstrapAdmin crea el primer administrador del sistema.
+// Solo funciona mientras no exista ningún administrador.
+func (s *UserService) BootstrapAdmin(ctx context.Context, username, password string) (*domain.User, error) {
+	if username == "" || len(password) < 6 {
+		return nil, domain.ErrInvalidInput
+	}
+
+	exists, err := s.userRepo.ExistsByRole(ctx, domain.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, domain.ErrAdminAlreadyExists
+	}
+
+	return s.createUser(ctx, username, "", password, domain.RoleAdmin, true)
+}
+
+// createUser valida que el usuario y el correo no existan y lo guarda con la contraseña hasheada
+func (s *UserService) createUser(ctx context.Context, username, email, password string, role domain.Role, verified bool) (*domain.User, error) {
+	email, err := s.checkEmailAvailable(ctx, email, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verificar si el usuario ya existe o el nombre está reservado
+	existingUser, _ := s.userRepo.FindByUsername(ctx, username)
+	if existingUser != nil || domain.IsReservedUsername(username) {
 		return nil, domain.ErrUserAlreadyExists
 	}
 
@@ -36,11 +156,17 @@ func (s *UserService) Register(username, password string, role domain.Role) (*do
 	// Crear nuevo usuario
 	user := &domain.User{
 		Username: username,
+		Email:    email,
 		Password: hashedPassword,
 		Role:     role,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	if verified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, err
 	}
 
@@ -50,8 +176,8 @@ func (s *UserService) Register(username, password string, role domain.Role) (*do
 }
 
 // GetUserByID obtiene un usuario por su ID
-func (s *UserService) GetUserByID(id int64) (*domain.User, error) {
-	user, err := s.userRepo.FindByID(id)
+func (s *UserService) GetUserByID(ctx context.Context, id int64) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, domain.ErrUserNotFound
 	}
@@ -60,9 +186,9 @@ func (s *UserService) GetUserByID(id int64) (*domain.User, error) {
 }
 
 // GetUserByUsername obtiene un usuario por su nombre de usuario
-func (s *UserService) GetUserByUsername(username string) (*domain.User, error) {
-	user, err := s.userRepo.FindByUsername(username)
-	if err != nil {
+func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil || user.IsDeleted() {
 		return nil, domain.ErrUserNotFound
 	}
 	user.Password = ""
@@ -70,8 +196,8 @@ func (s *UserService) GetUserByUsername(username string) (*domain.User, error) {
 }
 
 // ListUsers lista todos los usuarios
-func (s *UserService) ListUsers() ([]domain.User, error) {
-	users, err := s.userRepo.List()
+func (s *UserService) ListUsers(ctx context.Context) ([]domain.User, error) {
+	users, err := s.userRepo.List(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -83,17 +209,62 @@ func (s *UserService) ListUsers() ([]domain.User, error) {
 	return users, nil
 }
 
-// UpdateUser actualiza un usuario existente
-func (s *UserService) UpdateUser(id int64, username string, role domain.Role) (*domain.User, error) {
-	user, err := s.userRepo.FindByID(id)
+// checkEmailAvailable normaliza un correo y verifica que no lo use otro usuario
+// distinto de userID. El correo vacío siempre está disponible.
+func (s *UserService) checkEmailAvailable(ctx context.Context, email string, userID int64) (string, error) {
+	email, err := domain.NormalizeEmail(email)
+	if err != nil || email == "" {
+		return email, err
+	}
+
+	existingUser, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if err == domain.ErrUserNotFound {
+			return email, nil
+		}
+		return "", err
+	}
+	if existingUser.ID != userID {
+		return "", domain.ErrEmailAlreadyExists
+	}
+	return email, nil
+}
+
+// UpdateUser actualiza un usuario existente. Si email es nil se conserva el actual.
+// callerRole debe poder asignar tanto el rol actual del usuario como el nuevo,
+// de modo que no se puedan editar ni degradar cuentas con más permisos.
+func (s *UserService) UpdateUser(ctx context.Context, id int64, username string, email *string, role, callerRole domain.Role) (*domain.User, error) {
+	if err := s.validateRole(ctx, role, callerRole); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, domain.ErrUserNotFound
 	}
 
+	if user.Role != role {
+		if err := checkCanManage(ctx, s.roleRepo, user, callerRole); err != nil {
+			return nil, err
+		}
+	}
+
+	if email != nil {
+		normalized, err := s.checkEmailAvailable(ctx, *email, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		user.ChangeEmail(normalized)
+	}
+
+	if !strings.EqualFold(username, user.Username) && domain.IsReservedUsername(username) {
+		return nil, domain.ErrUserAlreadyExists
+	}
+
 	user.Username = username
 	user.Role = role
 
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, err
 	}
 
@@ -101,7 +272,145 @@ func (s *UserService) UpdateUser(id int64, username string, role domain.Role) (*
 	return user, nil
 }
 
-// DeleteUser elimina un usuario
-func (s *UserService) DeleteUser(id int64) error {
-	return s.userRepo.Delete(id)
+// DeleteUser mueve un usuario a la papelera y revoca todas sus sesiones. Sus
+// blogs y comentarios se tratan según la política de eliminación: con
+// DeletionCascade van a la papelera, con DeletionReassign pasan al usuario
+// domain.DeletedUsername y con DeletionBlock impiden la eliminación. Al
+// restaurar el usuario no se restaura su contenido. callerRole debe poder
+// asignar el rol del usuario.
+func (s *UserService) DeleteUser(ctx context.Context, id int64, callerRole domain.Role) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.FindByID(ctx, id)
+		if err != nil {
+			return domain.ErrUserNotFound
+		}
+
+		// El usuario que recibe el contenido reasignado no se puede eliminar
+		if domain.IsReservedUsername(user.Username) {
+			return domain.ErrForbidden
+		}
+		if err := checkCanManage(ctx, s.roleRepo, user, callerRole); err != nil {
+			return err
+		}
+
+		switch s.deletionPolicy {
+		case domain.DeletionBlock:
+			err = s.checkNoContent(ctx, id)
+		case domain.DeletionReassign:
+			err = s.reassignContent(ctx, id)
+		default:
+			err = s.deleteContent(ctx, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := s.refreshTokenRepo.RevokeAllForUser(ctx, id); err != nil {
+			return err
+		}
+		return s.userRepo.Delete(ctx, id)
+	})
+}
+
+// checkNoContent retorna domain.ErrUserHasContent si el usuario tiene blogs o comentarios
+func (s *UserService) checkNoContent(ctx context.Context, id int64) error {
+	hasBlogs, err := s.blogRepo.ExistsByAuthor(ctx, id)
+	if err != nil {
+		return err
+	}
+	hasComments, err := s.commentRepo.ExistsByUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasBlogs || hasComments {
+		return domain.ErrUserHasContent
+	}
+	return nil
+}
+
+// reassignContent pasa los blogs y comentarios del usuario, incluidos los de
+// la papelera, al usuario domain.DeletedUsername, que se crea si aún no existe
+func (s *UserService) reassignContent(ctx context.Context, id int64) error {
+	heir, err := s.userRepo.FindByUsername(ctx, domain.DeletedUsername)
+	if err == domain.ErrUserNotFound {
+		heir, err = s.userRepo.CreateDeletedUser(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.blogRepo.ReassignAuthor(ctx, id, heir.ID); err != nil {
+		return err
+	}
+	return s.commentRepo.ReassignUser(ctx, id, heir.ID)
+}
+
+// deleteContent mueve a la papelera los blogs del usuario, con sus
+// comentarios, y los comentarios que escribió en otros blogs
+func (s *UserService) deleteContent(ctx context.Context, id int64) error {
+	blogIDs, err := s.blogIDsByAuthor(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, blogID := range blogIDs {
+		if err := deleteBlog(ctx, s.blogRepo, s.commentRepo, blogID, domain.DeletionCascade); err != nil {
+			return err
+		}
+	}
+
+	comments, err := s.commentRepo.FindRecentByUser(ctx, id, time.Time{})
+	if err != nil {
+		return err
+	}
+	for i := range comments {
+		if err := removeComment(ctx, s.commentRepo, &comments[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// blogIDsByAuthor retorna los IDs de todos los blogs de un autor que no están en la papelera
+func (s *UserService) blogIDsByAuthor(ctx context.Context, authorID int64) ([]int64, error) {
+	var ids []int64
+	page := domain.PageRequest{Limit: domain.MaxPageLimit, Sort: domain.SortOldest}
+	for {
+		blogs, err := s.blogRepo.FindByAuthorID(ctx, authorID, domain.BlogFilter{}, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, blog := range blogs.Items {
+			ids = append(ids, blog.ID)
+		}
+		if !blogs.HasMore {
+			return ids, nil
+		}
+
+		page.After, err = domain.DecodeCursor(blogs.NextCursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// UnlockUser levanta el bloqueo de inicio de sesión de un usuario y olvida sus
+// fallos recientes. callerRole debe poder asignar el rol del usuario.
+func (s *UserService) UnlockUser(ctx context.Context, id int64, callerRole domain.Role) (*domain.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	if err := checkCanManage(ctx, s.roleRepo, user, callerRole); err != nil {
+		return nil, err
+	}
+
+	user.ResetLoginFailures()
+	if err := s.userRepo.UpdateLoginState(ctx, user); err != nil {
+		return nil, err
+	}
+
+	user.Password = ""
+	return user, nil
 }
@@ -0,0 +1,432 @@
+package services
+
+import (
+	"blog-backend/internal/domain"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestUserServiceRegister(t *testing.T) {
+	env := newTestEnv(t)
+	env.createUser(t, "ana", domain.RoleUser)
+
+	tests := []struct {
+		name     string
+		username string
+		email    string
+		err      error
+	}{
+		{name: "nuevo usuario", username: "bob", email: " Bob@Example.com "},
+		{name: "sin correo", username: "eva", email: "", err: domain.ErrEmailRequired},
+		{name: "correo inválido", username: "eva", email: "eva", err: domain.ErrInvalidEmail},
+		{name: "correo repetido", username: "eva", email: "ana@example.com", err: domain.ErrEmailAlreadyExists},
+		{name: "usuario repetido", username: "ANA", email: "otra@example.com", err: domain.ErrUserAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := len(env.mailer.sent)
+			user, err := env.userService.Register(ctx, tt.username, tt.email, "secreto")
+			checkErr(t, err, tt.err)
+			if err != nil {
+				if len(env.mailer.sent) != sent {
+					t.Error("se envió un correo para un registro fallido")
+				}
+				return
+			}
+
+			if user.Role != domain.RoleUser || user.Email != "bob@example.com" || user.Password != "" || user.IsEmailVerified() {
+				t.Errorf("Register = %+v", user)
+			}
+			if len(env.mailer.sent) != sent+1 || env.mailer.sent[sent].To != "bob@example.com" {
+				t.Errorf("correos enviados = %+v", env.mailer.sent)
+			}
+			stored, err := env.users.FindByID(ctx, user.ID)
+			checkErr(t, err, nil)
+			if !env.auth.CheckPassword("secreto", stored.Password) {
+				t.Errorf("la contraseña guardada es %q", stored.Password)
+			}
+		})
+	}
+
+	// Un fallo del correo no impide el registro: la verificación se puede reenviar
+	env.mailer.err = errors.New("servidor no disponible")
+	user, err := env.userService.Register(ctx, "eva", "eva@example.com", "secreto")
+	checkErr(t, err, nil)
+	if _, err := env.users.FindByID(ctx, user.ID); err != nil {
+		t.Errorf("el usuario no se guardó: %v", err)
+	}
+}
+
+func TestUserServiceCreateUser(t *testing.T) {
+	env := newTestEnv(t)
+	env.createUser(t, "ana", domain.RoleUser)
+
+	tests := []struct {
+		name     string
+		username string
+		email    string
+		role     domain.Role
+		err      error
+	}{
+		{name: "editor", username: "bob", email: "bob@example.com", role: domain.RoleEditor},
+		{name: "rol personalizado sin correo", username: "eva", role: roleLector},
+		{name: "rol inexistente", username: "leo", role: "Invitado", err: domain.ErrInvalidRole},
+		{name: "usuario repetido", username: "ana", role: domain.RoleUser, err: domain.ErrUserAlreadyExists},
+		{name: "correo repetido", username: "leo", email: "ANA@example.com", role: domain.RoleUser, err: domain.ErrEmailAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := env.userService.CreateUser(ctx, tt.username, tt.email, "secreto", tt.role, domain.RoleAdmin)
+			checkErr(t, err, tt.err)
+			if err == nil && (user.Role != tt.role || !user.IsEmailVerified() || user.Password != "") {
+				t.Errorf("CreateUser = %+v", user)
+			}
+		})
+	}
+
+	if len(env.mailer.sent) != 0 {
+		t.Errorf("se enviaron %d correos de verificación", len(env.mailer.sent))
+	}
+}
+
+func TestUserServiceBootstrapAdmin(t *testing.T) {
+	env := newTestEnv(t)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		err      error
+	}{
+		{name: "sin usuario", username: "", password: "secreto", err: domain.ErrInvalidInput},
+		{name: "contraseña corta", username: "admin", password: "123", err: domain.ErrInvalidInput},
+		{name: "nombre reservado", username: "USUARIO-ELIMINADO", password: "secreto", err: domain.ErrUserAlreadyExists},
+		{name: "primer administrador", username: "admin", password: "secreto"},
+		{name: "segundo administrador", username: "otro", password: "secreto", err: domain.ErrAdminAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := env.userService.BootstrapAdmin(ctx, tt.username, tt.password)
+			checkErr(t, err, tt.err)
+			if err == nil && (user.Role != domain.RoleAdmin || user.Email != "") {
+				t.Errorf("BootstrapAdmin = %+v", user)
+			}
+		})
+	}
+}
+
+func TestUserServiceGetUser(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	deleted := env.createUser(t, "bob", domain.RoleUser)
+	checkErr(t, env.users.Delete(ctx, deleted.ID), nil)
+
+	t.Run("GetUserByID", func(t *testing.T) {
+		found, err := env.userService.GetUserByID(ctx, user.ID)
+		checkErr(t, err, nil)
+		if found.Username != "ana" || found.Password != "" {
+			t.Errorf("GetUserByID = %+v", found)
+		}
+		_, err = env.userService.GetUserByID(ctx, 999)
+		checkErr(t, err, domain.ErrUserNotFound)
+	})
+
+	t.Run("GetUserByUsername", func(t *testing.T) {
+		tests := []struct {
+			username string
+			err      error
+		}{
+			{username: "ana"},
+			{username: "ANA"},
+			{username: "bob", err: domain.ErrUserNotFound},
+			{username: "nadie", err: domain.ErrUserNotFound},
+		}
+
+		for _, tt := range tests {
+			found, err := env.userService.GetUserByUsername(ctx, tt.username)
+			checkErr(t, err, tt.err)
+			if err == nil && (found.ID != user.ID || found.Password != "") {
+				t.Errorf("GetUserByUsername(%q) = %+v", tt.username, found)
+			}
+		}
+	})
+
+	t.Run("ListUsers", func(t *testing.T) {
+		users, err := env.userService.ListUsers(ctx)
+		checkErr(t, err, nil)
+		if len(users) != 1 || users[0].ID != user.ID || users[0].Password != "" {
+			t.Errorf("ListUsers = %+v", users)
+		}
+	})
+}
+
+func TestUserServiceUpdateUser(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	env.createUser(t, "bob", domain.RoleUser)
+	email := func(s string) *string { return &s }
+
+	tests := []struct {
+		name  string
+		id    int64
+		email *string
+		role  domain.Role
+		want  string
+		err   error
+	}{
+		{name: "conserva el correo", id: user.ID, role: domain.RoleEditor, want: "ana@example.com"},
+		{name: "mismo correo", id: user.ID, email: email("ANA@example.com"), role: domain.RoleEditor, want: "ana@example.com"},
+		{name: "nuevo correo", id: user.ID, email: email("nueva@example.com"), role: domain.RoleModerator, want: "nueva@example.com"},
+		{name: "quita el correo", id: user.ID, email: email(""), role: domain.RoleUser, want: ""},
+		{name: "correo de otro usuario", id: user.ID, email: email("bob@example.com"), role: domain.RoleUser, err: domain.ErrEmailAlreadyExists},
+		{name: "correo inválido", id: user.ID, email: email("no es correo"), role: domain.RoleUser, err: domain.ErrInvalidEmail},
+		{name: "rol inexistente", id: user.ID, role: "Invitado", err: domain.ErrInvalidRole},
+		{name: "inexistente", id: 999, role: domain.RoleUser, err: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated, err := env.userService.UpdateUser(ctx, tt.id, "ana2", tt.email, tt.role, domain.RoleAdmin)
+			checkErr(t, err, tt.err)
+			if err != nil {
+				return
+			}
+			stored, err := env.users.FindByID(ctx, tt.id)
+			checkErr(t, err, nil)
+			if updated.Password != "" || stored.Username != "ana2" || stored.Email != tt.want || stored.Role != tt.role {
+				t.Errorf("usuario guardado = %+v", stored)
+			}
+		})
+	}
+
+	// Cambiar el correo obliga a verificarlo de nuevo; conservarlo no
+	verified := time.Now()
+	stored, err := env.users.FindByID(ctx, user.ID)
+	checkErr(t, err, nil)
+	stored.EmailVerifiedAt = &verified
+	checkErr(t, env.users.UpdateEmailVerification(ctx, stored), nil)
+
+	for _, change := range []struct {
+		email    string
+		verified bool
+	}{{email: "", verified: true}, {email: "otra@example.com", verified: false}} {
+		_, err := env.userService.UpdateUser(ctx, user.ID, "ana2", email(change.email), domain.RoleUser, domain.RoleAdmin)
+		checkErr(t, err, nil)
+		stored, err := env.users.FindByID(ctx, user.ID)
+		checkErr(t, err, nil)
+		if stored.IsEmailVerified() != change.verified {
+			t.Errorf("correo %q: verificado = %v, se esperaba %v", change.email, stored.IsEmailVerified(), change.verified)
+		}
+	}
+}
+
+func TestUserServiceRoleEscalation(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	admin := env.createUser(t, "root", domain.RoleAdmin)
+
+	tests := []struct {
+		name   string
+		create bool
+		id     int64
+		role   domain.Role
+		caller domain.Role
+		err    error
+	}{
+		{name: "crear con un rol incluido", create: true, role: domain.RoleUser, caller: roleGestor},
+		{name: "crear con un rol sin permisos", create: true, role: roleLector, caller: roleGestor},
+		{name: "crear administrador sin role:manage", create: true, role: domain.RoleAdmin, caller: roleGestor, err: domain.ErrForbidden},
+		{name: "crear editor sin role:manage", create: true, role: domain.RoleEditor, caller: roleGestor, err: domain.ErrForbidden},
+		{name: "crear con un rol desconocido como solicitante", create: true, role: domain.RoleUser, caller: "Invitado", err: domain.ErrForbidden},
+		{name: "crear administrador con role:manage", create: true, role: domain.RoleAdmin, caller: domain.RoleAdmin},
+		{name: "promover a administrador sin role:manage", id: user.ID, role: domain.RoleAdmin, caller: roleGestor, err: domain.ErrForbidden},
+		{name: "editar un administrador sin role:manage", id: admin.ID, role: domain.RoleAdmin, caller: roleGestor, err: domain.ErrForbidden},
+		{name: "degradar un administrador sin role:manage", id: admin.ID, role: domain.RoleUser, caller: roleGestor, err: domain.ErrForbidden},
+		{name: "cambiar a un rol incluido", id: user.ID, role: roleLector, caller: roleGestor},
+		{name: "promover a administrador con role:manage", id: user.ID, role: domain.RoleAdmin, caller: domain.RoleAdmin},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.create {
+				_, err = env.userService.CreateUser(ctx, fmt.Sprintf("nuevo%d", i), "", "secreto", tt.role, tt.caller)
+			} else {
+				before, findErr := env.users.FindByID(ctx, tt.id)
+				checkErr(t, findErr, nil)
+				_, err = env.userService.UpdateUser(ctx, tt.id, before.Username, nil, tt.role, tt.caller)
+			}
+			checkErr(t, err, tt.err)
+			if err != nil && !tt.create {
+				stored, findErr := env.users.FindByID(ctx, tt.id)
+				checkErr(t, findErr, nil)
+				if stored.Role == tt.role && tt.id == user.ID {
+					t.Errorf("el rol cambió a %s pese al error", stored.Role)
+				}
+			}
+		})
+	}
+}
+
+func TestUserServiceManageAdmin(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.createUser(t, "root", domain.RoleAdmin)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	for i, target := range []*domain.User{admin, user} {
+		env.enableTwoFactor(t, target)
+		client := domain.LoginClient{IP: fmt.Sprintf("10.0.0.%d", i+1)}
+		for j := 0; j < testLockout.MaxAccountFailures; j++ {
+			_, err := env.authService.Login(ctx, target.Username, "incorrecta", client)
+			checkErr(t, err, domain.ErrInvalidCredentials)
+		}
+	}
+
+	// Un gestor sin role:manage no puede actuar sobre un administrador
+	_, err := env.userService.UnlockUser(ctx, admin.ID, roleGestor)
+	checkErr(t, err, domain.ErrForbidden)
+	checkErr(t, env.twoFactor.Reset(ctx, admin.ID, roleGestor), domain.ErrForbidden)
+	checkErr(t, env.userService.DeleteUser(ctx, admin.ID, roleGestor), domain.ErrForbidden)
+
+	stored, err := env.users.FindByID(ctx, admin.ID)
+	checkErr(t, err, nil)
+	if !stored.IsLocked(time.Now()) || !stored.TwoFactorEnabled() {
+		t.Errorf("administrador = %+v, se esperaba bloqueado y con dos pasos", stored)
+	}
+
+	// pero sí sobre los usuarios cuyo rol puede asignar
+	_, err = env.userService.UnlockUser(ctx, user.ID, roleGestor)
+	checkErr(t, err, nil)
+	checkErr(t, env.twoFactor.Reset(ctx, user.ID, roleGestor), nil)
+	checkErr(t, env.userService.DeleteUser(ctx, user.ID, roleGestor), nil)
+}
+
+func TestUserServiceDeleteUser(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	result, err := env.authService.Login(ctx, "ana", "secreto", domain.LoginClient{IP: "10.0.0.1"})
+	checkErr(t, err, nil)
+
+	checkErr(t, env.userService.DeleteUser(ctx, user.ID, domain.RoleAdmin), nil)
+	_, err = env.users.FindByID(ctx, user.ID)
+	checkErr(t, err, domain.ErrUserNotFound)
+
+	// Las sesiones abiertas se revocan
+	_, err = env.authService.ValidateToken(ctx, result.Tokens.AccessToken)
+	checkErr(t, err, domain.ErrUnauthorized)
+
+	checkErr(t, env.userService.DeleteUser(ctx, user.ID, domain.RoleAdmin), domain.ErrUserNotFound)
+}
+
+func TestUserServiceDeleteUserCascade(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	other := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, user, domain.BlogStatusPublished)
+	onOwnBlog := env.createComment(t, blog, other, nil)
+	otherBlog := env.createBlog(t, other, domain.BlogStatusPublished)
+	answered := env.createComment(t, otherBlog, user, nil)
+	reply := env.createComment(t, otherBlog, other, answered)
+	single := env.createComment(t, otherBlog, user, nil)
+
+	checkErr(t, env.userService.DeleteUser(ctx, user.ID, domain.RoleAdmin), nil)
+
+	// Sus blogs van a la papelera con todos sus comentarios
+	_, err := env.blogs.FindByID(ctx, blog.ID)
+	checkErr(t, err, domain.ErrBlogNotFound)
+	_, err = env.comments.FindDeleted(ctx, onOwnBlog.ID)
+	checkErr(t, err, nil)
+
+	// Sus comentarios en otros blogs se eliminan sin dejar huérfanas las respuestas
+	_, err = env.comments.FindDeleted(ctx, single.ID)
+	checkErr(t, err, nil)
+	found, err := env.comments.FindByID(ctx, answered.ID)
+	checkErr(t, err, nil)
+	if !found.Removed {
+		t.Errorf("comentario con respuestas = %+v, se esperaba marcado como eliminado", found)
+	}
+	_, err = env.comments.FindByID(ctx, reply.ID)
+	checkErr(t, err, nil)
+}
+
+func TestUserServiceDeleteUserReassign(t *testing.T) {
+	env := newTestEnvWithPolicies(t, domain.DeletionReassign, domain.DeletionCascade)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	other := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, user, domain.BlogStatusPublished)
+	comment := env.createComment(t, blog, user, nil)
+	otherBlog := env.createBlog(t, other, domain.BlogStatusPublished)
+	env.createComment(t, otherBlog, other, nil)
+
+	checkErr(t, env.userService.DeleteUser(ctx, user.ID, domain.RoleAdmin), nil)
+
+	// El contenido se conserva a nombre del usuario eliminado
+	heir, err := env.users.FindByUsername(ctx, domain.DeletedUsername)
+	checkErr(t, err, nil)
+	found, err := env.blogs.FindByID(ctx, blog.ID)
+	checkErr(t, err, nil)
+	foundComment, err := env.comments.FindByID(ctx, comment.ID)
+	checkErr(t, err, nil)
+	if found.AuthorID != heir.ID || foundComment.UserID != heir.ID {
+		t.Errorf("autores = %d y %d, se esperaba %d", found.AuthorID, foundComment.UserID, heir.ID)
+	}
+
+	// El mismo usuario recibe el contenido de las siguientes eliminaciones
+	checkErr(t, env.userService.DeleteUser(ctx, other.ID, domain.RoleAdmin), nil)
+	found, err = env.blogs.FindByID(ctx, otherBlog.ID)
+	checkErr(t, err, nil)
+	if found.AuthorID != heir.ID {
+		t.Errorf("AuthorID = %d, se esperaba %d", found.AuthorID, heir.ID)
+	}
+
+	// y no se puede eliminar ni suplantar
+	checkErr(t, env.userService.DeleteUser(ctx, heir.ID, domain.RoleAdmin), domain.ErrForbidden)
+	_, err = env.userService.CreateUser(ctx, "Usuario-Eliminado", "", "secreto", domain.RoleUser, domain.RoleAdmin)
+	checkErr(t, err, domain.ErrUserAlreadyExists)
+}
+
+func TestUserServiceDeleteUserBlock(t *testing.T) {
+	env := newTestEnvWithPolicies(t, domain.DeletionBlock, domain.DeletionCascade)
+	author := env.createUser(t, "ana", domain.RoleUser)
+	commenter := env.createUser(t, "bob", domain.RoleUser)
+	blog := env.createBlog(t, author, domain.BlogStatusPublished)
+	comment := env.createComment(t, blog, commenter, nil)
+
+	checkErr(t, env.userService.DeleteUser(ctx, author.ID, domain.RoleAdmin), domain.ErrUserHasContent)
+	checkErr(t, env.userService.DeleteUser(ctx, commenter.ID, domain.RoleAdmin), domain.ErrUserHasContent)
+	_, err := env.users.FindByID(ctx, author.ID)
+	checkErr(t, err, nil)
+
+	// Sin contenido fuera de la papelera ya se pueden eliminar
+	checkErr(t, env.commentService.DeleteComment(ctx, comment.ID, commenter.ID, domain.RoleUser), nil)
+	checkErr(t, env.userService.DeleteUser(ctx, commenter.ID, domain.RoleAdmin), nil)
+	checkErr(t, env.blogService.DeleteBlog(ctx, blog.ID, author.ID, domain.RoleUser), nil)
+	checkErr(t, env.userService.DeleteUser(ctx, author.ID, domain.RoleAdmin), nil)
+}
+
+func TestUserServiceUnlockUser(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createUser(t, "ana", domain.RoleUser)
+	client := domain.LoginClient{IP: "10.0.0.1"}
+	for i := 0; i < testLockout.MaxAccountFailures; i++ {
+		_, err := env.authService.Login(ctx, "ana", "incorrecta", client)
+		checkErr(t, err, domain.ErrInvalidCredentials)
+	}
+	_, err := env.authService.Login(ctx, "ana", "secreto", client)
+	checkErr(t, err, domain.ErrInvalidCredentials)
+
+	unlocked, err := env.userService.UnlockUser(ctx, user.ID, domain.RoleAdmin)
+	checkErr(t, err, nil)
+	if unlocked.IsLocked(time.Now()) || unlocked.FailedLogins != 0 || unlocked.Password != "" {
+		t.Errorf("UnlockUser = %+v", unlocked)
+	}
+	_, err = env.authService.Login(ctx, "ana", "secreto", client)
+	checkErr(t, err, nil)
+
+	_, err = env.userService.UnlockUser(ctx, 999, domain.RoleAdmin)
+	checkErr(t, err, domain.ErrUserNotFound)
+}